@@ -29,6 +29,12 @@ func main() {
 		cmd.NewAddCmd(kpmcli),
 		cmd.NewPkgCmd(kpmcli),
 		cmd.NewMetadataCmd(kpmcli),
+		cmd.NewSearchCmd(kpmcli),
+		cmd.NewInfoCmd(kpmcli),
+		cmd.NewSchemaCmd(kpmcli),
+		cmd.NewDocCmd(kpmcli),
+		cmd.NewServeCmd(kpmcli),
+		cmd.NewYankCmd(kpmcli),
 		cmd.NewImportCmd(kpmcli),
 
 		// todo: The following commands are bound to the oci registry.
@@ -0,0 +1,486 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"kcl-lang.io/kcl-go/pkg/parser"
+	"kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+)
+
+const (
+	DOC_FORMAT_MARKDOWN = "markdown"
+	DOC_FORMAT_HTML     = "html"
+)
+
+// DocOptions are the options to generate the docs of a kcl package.
+type DocOptions struct {
+	// Format is the format of the docs, 'markdown' or 'html'.
+	Format string
+	// OutputDir is the directory where the docs are written.
+	OutputDir string
+	// DepsDocUrl is the base url of the docs of the dependencies, e.g. the schemas in the kcl package 'k8s.api.apps.v1'
+	// are linked to '<DepsDocUrl>/k8s.api.apps.v1.md'. They are linked to the docs in the same directory if it is empty.
+	DepsDocUrl string
+}
+
+// PkgDoc is the doc of a kcl package path, i.e. a directory of the package.
+type PkgDoc struct {
+	// Id is the kcl package path of the directory, e.g. 'aaa' and 'aaa.sub', which is also the name of the doc file.
+	Id      string
+	Schemas []*SchemaDoc
+}
+
+// SchemaDoc is the doc of a schema with its docstring, attributes and validation rules.
+type SchemaDoc struct {
+	Name       string
+	Doc        string
+	Source     string
+	Attributes []*AttrDoc
+	Checks     []string
+}
+
+// AttrDoc is the doc of a schema attribute.
+type AttrDoc struct {
+	Name        string
+	Type        []TypePart
+	Required    bool
+	Default     string
+	Description string
+}
+
+// TypePart is a part of the kcl type string, the schema types are linked to their docs.
+type TypePart struct {
+	Text string
+	Link string
+}
+
+// GenDocs renders the docs of the schemas in the package into 'opts.OutputDir', a doc file for each directory
+// with the schemas and an index file, and returns the paths of the generated files.
+func (pkg *KclPackage) GenDocs(opts DocOptions) ([]string, error) {
+	mapping, err := pkg.GetSchemaTypeMappingWithFilters([]KclTypeFilterFunc{IsSchemaType})
+	if err != nil {
+		return nil, err
+	}
+	docs := newDocBuilder(pkg.GetPkgName(), pkg.GetPkgHomePath(), opts).build(mapping)
+	return renderDocs(pkg.GetPkgName(), pkg.GetVersion(), docs, opts)
+}
+
+// docBuilder builds the docs from the schema type mapping of the package.
+type docBuilder struct {
+	pkgName  string
+	homePath string
+	ext      string
+	opts     DocOptions
+	// docIds are the ids of the docs of the package, used to tell the schemas of the package from the dependencies.
+	docIds map[string]bool
+}
+
+func newDocBuilder(pkgName, homePath string, opts DocOptions) *docBuilder {
+	return &docBuilder{
+		pkgName:  pkgName,
+		homePath: homePath,
+		ext:      docExt(opts.Format),
+		opts:     opts,
+		docIds:   map[string]bool{},
+	}
+}
+
+func docExt(format string) string {
+	if format == DOC_FORMAT_HTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+// docId returns the id of the doc of the directory 'relPath' relative to the package home path.
+func (b *docBuilder) docId(relPath string) string {
+	relPath = filepath.ToSlash(relPath)
+	if relPath == "" || relPath == "." {
+		return b.pkgName
+	}
+	return b.pkgName + "." + strings.ReplaceAll(relPath, "/", ".")
+}
+
+// build builds the docs sorted by the ids, the schemas in each doc are sorted by the names.
+func (b *docBuilder) build(mapping map[string]map[string]*KclType) []*PkgDoc {
+	for relPath := range mapping {
+		b.docIds[b.docId(relPath)] = true
+	}
+
+	var docs []*PkgDoc
+	for relPath, schemas := range mapping {
+		doc := &PkgDoc{Id: b.docId(relPath)}
+		for _, t := range schemas {
+			doc.Schemas = append(doc.Schemas, b.schemaDoc(doc.Id, t.KclType))
+		}
+		sort.Slice(doc.Schemas, func(i, j int) bool {
+			return doc.Schemas[i].Name < doc.Schemas[j].Name
+		})
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Id < docs[j].Id
+	})
+	return docs
+}
+
+func (b *docBuilder) schemaDoc(docId string, t *gpyrpc.KclType) *SchemaDoc {
+	schema := &SchemaDoc{
+		Name: t.SchemaName,
+		Doc:  strings.TrimSpace(t.SchemaDoc),
+	}
+	if t.Filename != "" {
+		source := t.Filename
+		if relPath, err := filepath.Rel(b.homePath, t.Filename); err == nil && !strings.HasPrefix(relPath, "..") {
+			source = relPath
+		}
+		schema.Source = fmt.Sprintf("%s:%d", filepath.ToSlash(source), t.Line)
+		schema.Checks = schemaChecks(t.Filename, t.SchemaName)
+	}
+
+	required := map[string]bool{}
+	for _, name := range t.Required {
+		required[name] = true
+	}
+	for name, attr := range t.Properties {
+		schema.Attributes = append(schema.Attributes, &AttrDoc{
+			Name:        name,
+			Type:        b.typeParts(docId, attr),
+			Required:    required[name],
+			Default:     attr.Default,
+			Description: strings.TrimSpace(attr.Description),
+		})
+	}
+	sort.Slice(schema.Attributes, func(i, j int) bool {
+		return schema.Attributes[i].Name < schema.Attributes[j].Name
+	})
+	return schema
+}
+
+// typeParts renders the kcl type 't' in the doc 'docId' into the parts, the schema types are linked to their docs.
+func (b *docBuilder) typeParts(docId string, t *gpyrpc.KclType) []TypePart {
+	if t == nil {
+		return []TypePart{{Text: "any"}}
+	}
+	switch t.Type {
+	case "schema":
+		name, link := b.schemaLink(docId, t)
+		return []TypePart{{Text: name, Link: link}}
+	case "list":
+		parts := []TypePart{{Text: "["}}
+		parts = append(parts, b.typeParts(docId, t.Item)...)
+		return append(parts, TypePart{Text: "]"})
+	case "dict":
+		parts := []TypePart{{Text: "{"}}
+		parts = append(parts, b.typeParts(docId, t.Key)...)
+		parts = append(parts, TypePart{Text: ":"})
+		parts = append(parts, b.typeParts(docId, t.Item)...)
+		return append(parts, TypePart{Text: "}"})
+	case "union":
+		var parts []TypePart
+		for i, unionType := range t.UnionTypes {
+			if i != 0 {
+				parts = append(parts, TypePart{Text: " | "})
+			}
+			parts = append(parts, b.typeParts(docId, unionType)...)
+		}
+		return parts
+	}
+	return []TypePart{{Text: t.Type}}
+}
+
+// schemaLink returns the name and the link of the schema type 't' referenced in the doc 'docId'.
+// The schemas in other kcl package paths are named with the package paths, e.g. 'v1.Deployment'.
+func (b *docBuilder) schemaLink(docId string, t *gpyrpc.KclType) (string, string) {
+	anchor := "#" + strings.ToLower(t.SchemaName)
+	if t.PkgPath == "" || t.PkgPath == mainPkgPath {
+		return t.SchemaName, anchor
+	}
+
+	name := t.PkgPath[strings.LastIndex(t.PkgPath, ".")+1:] + "." + t.SchemaName
+	for _, id := range []string{t.PkgPath, b.pkgName + "." + t.PkgPath} {
+		if b.docIds[id] {
+			if id == docId {
+				return t.SchemaName, anchor
+			}
+			return name, id + b.ext + anchor
+		}
+	}
+
+	// The schemas from the dependencies are linked to the docs of the dependencies.
+	link := t.PkgPath + b.ext + anchor
+	if b.opts.DepsDocUrl != "" {
+		link = strings.TrimSuffix(b.opts.DepsDocUrl, "/") + "/" + link
+	}
+	return name, link
+}
+
+// astPos is the position of a node in the json of the kcl AST, the lines start from 1 and the columns start from 0.
+type astPos struct {
+	Line      int `json:"line"`
+	Column    int `json:"column"`
+	EndLine   int `json:"end_line"`
+	EndColumn int `json:"end_column"`
+}
+
+// astModule is the part of the json of the kcl AST used to find the check blocks of the schemas.
+type astModule struct {
+	Body []struct {
+		Node struct {
+			Type string `json:"type"`
+			Name struct {
+				Node string `json:"node"`
+			} `json:"name"`
+			Checks []astPos `json:"checks"`
+		} `json:"node"`
+	} `json:"body"`
+}
+
+// schemaChecks returns the expressions in the 'check' block of the schema 'name' defined in 'filename'.
+func schemaChecks(filename, name string) []string {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	astJson, err := parser.ParseFileASTJson(filename, content)
+	if err != nil {
+		return nil
+	}
+	var module astModule
+	if err := json.Unmarshal([]byte(astJson), &module); err != nil {
+		return nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for _, stmt := range module.Body {
+		if stmt.Node.Type != "Schema" || stmt.Node.Name.Node != name {
+			continue
+		}
+		var checks []string
+		for _, pos := range stmt.Node.Checks {
+			if check := sourceText(lines, pos); check != "" {
+				checks = append(checks, check)
+			}
+		}
+		return checks
+	}
+	return nil
+}
+
+// sourceText returns the source code at 'pos' in 'lines', the expressions in multiple lines are joined into one line.
+func sourceText(lines []string, pos astPos) string {
+	if pos.Line < 1 || pos.EndLine < pos.Line || pos.EndLine > len(lines) {
+		return ""
+	}
+	var parts []string
+	for i := pos.Line; i <= pos.EndLine; i++ {
+		line := []rune(lines[i-1])
+		begin, end := 0, len(line)
+		if i == pos.Line {
+			begin = pos.Column
+		}
+		if i == pos.EndLine && pos.EndColumn < end {
+			end = pos.EndColumn
+		}
+		if begin > end {
+			return ""
+		}
+		part := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(string(line[begin:end])), "\\"))
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// docData is the data to render the doc of a kcl package path.
+type docData struct {
+	PkgName    string
+	PkgVersion string
+	Ext        string
+	Doc        *PkgDoc
+	Docs       []*PkgDoc
+}
+
+// renderDocs writes the docs and the index into 'opts.OutputDir' and returns the paths of the written files.
+func renderDocs(pkgName, pkgVersion string, docs []*PkgDoc, opts DocOptions) ([]string, error) {
+	var render func(name string, data *docData) ([]byte, error)
+	var indexName string
+	switch opts.Format {
+	case DOC_FORMAT_MARKDOWN, "":
+		render = renderMarkdown
+		indexName = "README.md"
+	case DOC_FORMAT_HTML:
+		render = renderHtml
+		indexName = "index.html"
+	default:
+		return nil, fmt.Errorf("invalid doc format '%s', only support '%s' and '%s'", opts.Format, DOC_FORMAT_MARKDOWN, DOC_FORMAT_HTML)
+	}
+
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return nil, err
+	}
+
+	ext := docExt(opts.Format)
+	var files []string
+	write := func(fileName, templateName string, doc *PkgDoc) error {
+		content, err := render(templateName, &docData{
+			PkgName:    pkgName,
+			PkgVersion: pkgVersion,
+			Ext:        ext,
+			Doc:        doc,
+			Docs:       docs,
+		})
+		if err != nil {
+			return err
+		}
+		filePath := filepath.Join(opts.OutputDir, fileName)
+		if err := os.WriteFile(filePath, content, 0644); err != nil {
+			return err
+		}
+		files = append(files, filePath)
+		return nil
+	}
+
+	for _, doc := range docs {
+		if err := write(doc.Id+ext, "doc", doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := write(indexName, "index", nil); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+var docFuncs = map[string]interface{}{
+	"anchor": func(name string) string {
+		return strings.ToLower(name)
+	},
+}
+
+const markdownTemplates = `{{define "type"}}{{range .}}{{if .Link}}[{{.Text | cell}}]({{.Link}}){{else}}{{.Text | cell}}{{end}}{{end}}{{end}}
+{{- define "index"}}# {{.PkgName}}
+
+Version: {{.PkgVersion}}
+
+## Packages
+{{range $doc := .Docs}}
+- [{{$doc.Id}}]({{$doc.Id}}{{$.Ext}}){{range $doc.Schemas}}
+  - [{{.Name}}]({{$doc.Id}}{{$.Ext}}#{{anchor .Name}}){{end}}{{end}}
+{{end}}
+{{- define "doc"}}# {{.Doc.Id}}
+
+Package: {{.PkgName}} {{.PkgVersion}}
+
+## Schemas
+{{range .Doc.Schemas}}
+- [{{.Name}}](#{{anchor .Name}}){{end}}
+{{range .Doc.Schemas}}
+## {{.Name}}
+{{if .Doc}}
+{{.Doc}}
+{{end}}{{if .Source}}
+Source: ` + "`{{.Source}}`" + `
+{{end}}{{if .Attributes}}
+### Attributes
+
+| name | type | description | default value |
+| --- | --- | --- | --- |
+{{range .Attributes}}| **{{.Name}}**{{if .Required}} ` + "`required`" + `{{end}} | {{template "type" .Type}} | {{.Description | cell}} | {{.Default | cell}} |
+{{end}}{{end}}{{if .Checks}}
+### Validation rules
+{{range .Checks}}
+- ` + "`{{.}}`" + `{{end}}
+{{end}}{{end}}{{end}}`
+
+const htmlTemplates = `{{define "type"}}{{range .}}{{if .Link}}<a href="{{.Link}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}{{end}}{{end}}
+{{- define "head"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 6px; text-align: left; vertical-align: top; }
+code, pre { background: #f5f5f5; }
+</style>
+</head>
+<body>
+{{end}}
+{{- define "index"}}{{template "head" .PkgName}}<h1>{{.PkgName}}</h1>
+<p>Version: {{.PkgVersion}}</p>
+<h2>Packages</h2>
+<ul>
+{{range $doc := .Docs}}<li><a href="{{$doc.Id}}{{$.Ext}}">{{$doc.Id}}</a>
+<ul>
+{{range $doc.Schemas}}<li><a href="{{$doc.Id}}{{$.Ext}}#{{anchor .Name}}">{{.Name}}</a></li>
+{{end}}</ul>
+</li>
+{{end}}</ul>
+</body>
+</html>
+{{end}}
+{{- define "doc"}}{{template "head" .Doc.Id}}<h1>{{.Doc.Id}}</h1>
+<p>Package: {{.PkgName}} {{.PkgVersion}}</p>
+<h2>Schemas</h2>
+<ul>
+{{range .Doc.Schemas}}<li><a href="#{{anchor .Name}}">{{.Name}}</a></li>
+{{end}}</ul>
+{{range .Doc.Schemas}}<h2 id="{{anchor .Name}}">{{.Name}}</h2>
+{{if .Doc}}<pre>{{.Doc}}</pre>
+{{end}}{{if .Source}}<p>Source: <code>{{.Source}}</code></p>
+{{end}}{{if .Attributes}}<h3>Attributes</h3>
+<table>
+<tr><th>name</th><th>type</th><th>description</th><th>default value</th></tr>
+{{range .Attributes}}<tr><td><strong>{{.Name}}</strong>{{if .Required}} <code>required</code>{{end}}</td><td>{{template "type" .Type}}</td><td>{{.Description}}</td><td>{{.Default}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Checks}}<h3>Validation rules</h3>
+<ul>
+{{range .Checks}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{end}}{{end}}</body>
+</html>
+{{end}}`
+
+func renderMarkdown(name string, data *docData) ([]byte, error) {
+	funcs := texttemplate.FuncMap{
+		// cell escapes the text in the cells of the markdown tables.
+		"cell": func(text string) string {
+			return strings.ReplaceAll(strings.ReplaceAll(text, "|", `\|`), "\n", " ")
+		},
+	}
+	for k, v := range docFuncs {
+		funcs[k] = v
+	}
+	tmpl, err := texttemplate.New("markdown").Funcs(funcs).Parse(markdownTemplates)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func renderHtml(name string, data *docData) ([]byte, error) {
+	tmpl, err := htmltemplate.New("html").Funcs(htmltemplate.FuncMap(docFuncs)).Parse(htmlTemplates)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
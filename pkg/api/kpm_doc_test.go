@@ -0,0 +1,108 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+)
+
+func TestSchemaChecks(t *testing.T) {
+	filename := filepath.Join(getTestDir("test_gen_docs"), "main.k")
+	assert.DeepEqual(t, schemaChecks(filename, "Service"), []string{
+		`port > 0, "port should be positive"`,
+		`len(labels) < 10 if labels else True`,
+	})
+	assert.Equal(t, len(schemaChecks(filename, "App")), 0)
+	assert.Equal(t, len(schemaChecks(filename, "NotExist")), 0)
+	assert.Equal(t, len(schemaChecks(filepath.Join(getTestDir("test_gen_docs"), "not_exist.k"), "Service")), 0)
+}
+
+func TestGenDocs(t *testing.T) {
+	homePath := getTestDir("test_gen_docs")
+	service := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "Service",
+		SchemaDoc:  "Service exposes the app.",
+		PkgPath:    "__main__",
+		Filename:   filepath.Join(homePath, "main.k"),
+		Line:       1,
+		Required:   []string{"port"},
+		Properties: map[string]*gpyrpc.KclType{
+			"port":   {Type: "union", UnionTypes: []*gpyrpc.KclType{{Type: "int"}, {Type: "str"}}, Default: "80", Description: "the port"},
+			"labels": {Type: "dict", Key: &gpyrpc.KclType{Type: "str"}, Item: &gpyrpc.KclType{Type: "str"}},
+		},
+	}
+	app := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "App",
+		PkgPath:    "__main__",
+		Properties: map[string]*gpyrpc.KclType{
+			"service":    service,
+			"deployment": {Type: "schema", SchemaName: "Deployment", PkgPath: "k8s.api.apps.v1"},
+			"config":     {Type: "schema", SchemaName: "Config", PkgPath: "gen_docs.sub"},
+		},
+	}
+	config := &gpyrpc.KclType{Type: "schema", SchemaName: "Config", PkgPath: "__main__"}
+	mapping := map[string]map[string]*KclType{
+		".":   {"Service": {KclType: service}, "App": {KclType: app}},
+		"sub": {"Config": {KclType: config}},
+	}
+
+	opts := DocOptions{
+		Format:     DOC_FORMAT_MARKDOWN,
+		OutputDir:  t.TempDir(),
+		DepsDocUrl: "https://docs.example.com/k8s/",
+	}
+	docs := newDocBuilder("gen_docs", homePath, opts).build(mapping)
+	assert.Equal(t, len(docs), 2)
+	assert.Equal(t, docs[0].Id, "gen_docs")
+	assert.Equal(t, docs[1].Id, "gen_docs.sub")
+	assert.Equal(t, docs[0].Schemas[0].Name, "App")
+	assert.Equal(t, docs[0].Schemas[1].Source, "main.k:1")
+	assert.Equal(t, len(docs[0].Schemas[1].Checks), 2)
+
+	files, err := renderDocs("gen_docs", "0.0.1", docs, opts)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{
+		filepath.Join(opts.OutputDir, "gen_docs.md"),
+		filepath.Join(opts.OutputDir, "gen_docs.sub.md"),
+		filepath.Join(opts.OutputDir, "README.md"),
+	})
+
+	content, err := os.ReadFile(files[0])
+	assert.NilError(t, err)
+	markdown := string(content)
+	for _, expected := range []string{
+		"## Service",
+		"Service exposes the app.",
+		"| **port** `required` | int \\| str | the port | 80 |",
+		"| **labels** | {str:str} |  |  |",
+		"| **service** | [Service](#service) |",
+		"| **deployment** | [v1.Deployment](https://docs.example.com/k8s/k8s.api.apps.v1.md#deployment) |",
+		"| **config** | [sub.Config](gen_docs.sub.md#config) |",
+		"- `port > 0, \"port should be positive\"`",
+	} {
+		assert.Assert(t, strings.Contains(markdown, expected), "'%s' not found in:\n%s", expected, markdown)
+	}
+
+	index, err := os.ReadFile(files[2])
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(index), "  - [Config](gen_docs.sub.md#config)"))
+
+	opts.Format = DOC_FORMAT_HTML
+	files, err = renderDocs("gen_docs", "0.0.1", newDocBuilder("gen_docs", homePath, opts).build(mapping), opts)
+	assert.NilError(t, err)
+	assert.Equal(t, files[2], filepath.Join(opts.OutputDir, "index.html"))
+	content, err = os.ReadFile(files[0])
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), `<h2 id="service">Service</h2>`))
+	assert.Assert(t, strings.Contains(string(content), `<a href="https://docs.example.com/k8s/k8s.api.apps.v1.html#deployment">v1.Deployment</a>`))
+
+	opts.Format = "pdf"
+	_, err = renderDocs("gen_docs", "0.0.1", docs, opts)
+	assert.ErrorContains(t, err, "invalid doc format 'pdf'")
+}
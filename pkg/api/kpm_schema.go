@@ -0,0 +1,253 @@
+package api
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+	"kcl-lang.io/kcl-go/pkg/tools/gen"
+)
+
+const (
+	// JSON_SCHEMA_DIALECT is the meta schema of the JSON Schema draft 2020-12.
+	JSON_SCHEMA_DIALECT = "https://json-schema.org/draft/2020-12/schema"
+	// OPENAPI_V3_VERSION is the version of the exported OpenAPI documents.
+	OPENAPI_V3_VERSION = "3.1.0"
+	// JSON_SCHEMA_REF_PREFIX is the prefix of the '$ref' to the schemas in the '$defs' of the JSON Schema.
+	JSON_SCHEMA_REF_PREFIX = "#/$defs/"
+	// OPENAPI_V3_REF_PREFIX is the prefix of the '$ref' to the schemas in the components of the OpenAPI document.
+	OPENAPI_V3_REF_PREFIX = "#/components/schemas/"
+)
+
+// The main package of the kcl program, the schemas in it are identified by the relative path to the package home path.
+const mainPkgPath = "__main__"
+
+// JsonSchema is the JSON Schema draft 2020-12 of a kcl type, it is also the schema object of OpenAPI 3.1.
+type JsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Defs                 map[string]*JsonSchema `json:"$defs,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *JsonSchema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*JsonSchema          `json:"anyOf,omitempty"`
+	Const                interface{}            `json:"const,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Deprecated           bool                   `json:"deprecated,omitempty"`
+}
+
+// OpenAPIV3Spec is the OpenAPI 3.1 document with the schemas of a kcl package in its components.
+type OpenAPIV3Spec struct {
+	OpenAPI           string                 `json:"openapi"`
+	Info              gen.SpecInfo           `json:"info"`
+	JsonSchemaDialect string                 `json:"jsonSchemaDialect"`
+	Paths             map[string]interface{} `json:"paths"`
+	Components        OpenAPIV3Components    `json:"components"`
+}
+
+// OpenAPIV3Components are the components of the OpenAPI 3.1 document.
+type OpenAPIV3Components struct {
+	Schemas map[string]*JsonSchema `json:"schemas"`
+}
+
+// ExportOpenAPIV3Spec extracts the OpenAPI 3.1 representation of a kcl package
+// with external dependencies, the schemas are in the components of the document.
+func (pkg *KclPackage) ExportOpenAPIV3Spec() (*OpenAPIV3Spec, error) {
+	spec := &OpenAPIV3Spec{
+		OpenAPI:           OPENAPI_V3_VERSION,
+		JsonSchemaDialect: JSON_SCHEMA_DIALECT,
+		Paths:             map[string]interface{}{},
+		Info: gen.SpecInfo{
+			Title:   pkg.GetPkgName(),
+			Version: pkg.GetVersion(),
+		},
+	}
+	schemas, err := pkg.exportJsonSchemas(OPENAPI_V3_REF_PREFIX)
+	if err != nil {
+		return spec, err
+	}
+	spec.Components.Schemas = schemas
+	return spec, nil
+}
+
+// ExportJsonSchema extracts the JSON Schema draft 2020-12 representation of a kcl package
+// with external dependencies, the schemas are in the '$defs' of the JSON Schema.
+func (pkg *KclPackage) ExportJsonSchema() (*JsonSchema, error) {
+	schema := &JsonSchema{
+		Schema: JSON_SCHEMA_DIALECT,
+		Title:  pkg.GetPkgName(),
+	}
+	defs, err := pkg.exportJsonSchemas(JSON_SCHEMA_REF_PREFIX)
+	if err != nil {
+		return schema, err
+	}
+	schema.Defs = defs
+	return schema, nil
+}
+
+// exportJsonSchemas returns the JSON Schemas of all the schema types in the package and its dependencies,
+// the schemas reference each other by '$ref' with 'refPrefix'.
+func (pkg *KclPackage) exportJsonSchemas(refPrefix string) (map[string]*JsonSchema, error) {
+	pkgMapping, err := pkg.GetAllSchemaTypeMapping()
+	if err != nil {
+		return nil, err
+	}
+	converter := newJsonSchemaConverter(refPrefix)
+	// package path -> package
+	for packagePath, p := range pkgMapping {
+		// schema name -> schema type
+		for _, t := range p {
+			converter.addSchema(packagePath, t.KclType)
+		}
+	}
+	return converter.defs, nil
+}
+
+// jsonSchemaConverter converts the kcl types into the JSON Schemas,
+// the schema types are collected into 'defs' and referenced by '$ref' with 'refPrefix'.
+type jsonSchemaConverter struct {
+	refPrefix string
+	defs      map[string]*JsonSchema
+}
+
+func newJsonSchemaConverter(refPrefix string) *jsonSchemaConverter {
+	return &jsonSchemaConverter{
+		refPrefix: refPrefix,
+		defs:      make(map[string]*JsonSchema),
+	}
+}
+
+// jsonSchemaId returns the id of the schema type 't' in the package 'packagePath' relative to the package home path.
+// The schemas from the dependencies are identified by their kcl package paths, e.g. 'k8s.api.apps.v1.Deployment'.
+func jsonSchemaId(packagePath string, t *gpyrpc.KclType) string {
+	pkgPath := t.PkgPath
+	if pkgPath == "" || pkgPath == mainPkgPath {
+		pkgPath = strings.ReplaceAll(filepath.ToSlash(packagePath), "/", ".")
+	}
+	if pkgPath == "" || pkgPath == "." {
+		return t.SchemaName
+	}
+	return pkgPath + "." + t.SchemaName
+}
+
+// addSchema adds the schema type 't' and the schema types referenced by it into the defs, and returns its id.
+func (c *jsonSchemaConverter) addSchema(packagePath string, t *gpyrpc.KclType) string {
+	id := jsonSchemaId(packagePath, t)
+	if _, ok := c.defs[id]; ok {
+		return id
+	}
+	// The schema is added before its properties are converted to stop at the recursive references.
+	schema := &JsonSchema{
+		Type:        "object",
+		Title:       t.SchemaName,
+		Description: t.SchemaDoc,
+		Required:    t.Required,
+		Deprecated:  isDeprecated(t),
+	}
+	c.defs[id] = schema
+	if len(t.Properties) != 0 {
+		schema.Properties = make(map[string]*JsonSchema, len(t.Properties))
+		for name, property := range t.Properties {
+			schema.Properties[name] = c.convert(packagePath, property)
+		}
+	}
+	return id
+}
+
+// convert converts the kcl type 't' in the package 'packagePath' into the JSON Schema.
+func (c *jsonSchemaConverter) convert(packagePath string, t *gpyrpc.KclType) *JsonSchema {
+	if t == nil {
+		return &JsonSchema{}
+	}
+
+	schema := &JsonSchema{}
+	switch t.Type {
+	case "schema":
+		schema.Ref = c.refPrefix + c.addSchema(packagePath, t)
+	case "str":
+		schema.Type = "string"
+	case "int":
+		schema.Type = "integer"
+	case "float":
+		schema.Type = "number"
+	case "bool":
+		schema.Type = "boolean"
+	case "NoneType", "None":
+		schema.Type = "null"
+	// The number multipliers are output as the strings, e.g. '1Gi'.
+	case "number_multiplier":
+		schema.Type = "string"
+	case "list":
+		schema.Type = "array"
+		schema.Items = c.convert(packagePath, t.Item)
+	case "dict":
+		schema.Type = "object"
+		schema.AdditionalProperties = c.convert(packagePath, t.Item)
+	case "union":
+		for _, unionType := range t.UnionTypes {
+			schema.AnyOf = append(schema.AnyOf, c.convert(packagePath, unionType))
+		}
+	case "any", "":
+	default:
+		// The literal types are the literal values, e.g. '"Deployment"', '1' and 'True'.
+		if value, ok := literalValue(t.Type); ok {
+			schema.Const = value
+		}
+	}
+
+	schema.Description = t.Description
+	schema.Deprecated = isDeprecated(t)
+	if value, ok := literalValue(t.Default); ok {
+		schema.Default = value
+	}
+	return schema
+}
+
+// isDeprecated returns true if the kcl type is decorated by '@deprecated'.
+func isDeprecated(t *gpyrpc.KclType) bool {
+	for _, decorator := range t.Decorators {
+		if decorator != nil && decorator.Name == "deprecated" {
+			return true
+		}
+	}
+	return false
+}
+
+// literalValue parses the kcl literal 'literal' into the json value, e.g. '"a"', '1', '1.5', 'True' and '[1, 2]'.
+// The literals of 'None' and the expressions are not parsed.
+func literalValue(literal string) (interface{}, bool) {
+	literal = strings.TrimSpace(literal)
+	if literal == "" || literal == "None" || literal == "Undefined" {
+		return nil, false
+	}
+	if strings.HasPrefix(literal, "'") && strings.HasSuffix(literal, "'") && len(literal) >= 2 {
+		return literal[1 : len(literal)-1], true
+	}
+	if strings.HasPrefix(literal, `"`) {
+		value, err := strconv.Unquote(literal)
+		return value, err == nil
+	}
+	switch literal {
+	case "True":
+		return true, true
+	case "False":
+		return false, true
+	}
+	if value, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return value, true
+	}
+	if value, err := strconv.ParseFloat(literal, 64); err == nil {
+		return value, true
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(literal), &value); err == nil {
+		return value, true
+	}
+	return nil, false
+}
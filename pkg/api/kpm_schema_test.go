@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"kcl-lang.io/kcl-go/pkg/spec/gpyrpc"
+	"kcl-lang.io/kpm/pkg/client"
+)
+
+func TestJsonSchemaConverter(t *testing.T) {
+	labels := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "Labels",
+		PkgPath:    "k8s.api.core.v1",
+		Properties: map[string]*gpyrpc.KclType{
+			"app": {Type: "str"},
+		},
+	}
+	node := &gpyrpc.KclType{
+		Type:       "schema",
+		SchemaName: "Node",
+		SchemaDoc:  "Node is a node of the tree.",
+		PkgPath:    "__main__",
+		Required:   []string{"kind"},
+	}
+	node.Properties = map[string]*gpyrpc.KclType{
+		"kind":     {Type: "union", UnionTypes: []*gpyrpc.KclType{{Type: `"Leaf"`}, {Type: `"Branch"`}}, Default: `"Leaf"`},
+		"children": {Type: "list", Item: node},
+		"labels":   labels,
+		"weights":  {Type: "dict", Key: &gpyrpc.KclType{Type: "str"}, Item: &gpyrpc.KclType{Type: "float"}},
+		"memory":   {Type: "number_multiplier", Description: "the memory of the node", Default: "1Gi"},
+		"enabled":  {Type: "bool", Default: "True", Decorators: []*gpyrpc.Decorator{{Name: "deprecated"}}},
+		"extra":    {Type: "any"},
+	}
+
+	converter := newJsonSchemaConverter(OPENAPI_V3_REF_PREFIX)
+	id := converter.addSchema(filepath.Join("sub", "tree"), node)
+	assert.Equal(t, id, "sub.tree.Node")
+	assert.Equal(t, len(converter.defs), 2)
+
+	schema := converter.defs["sub.tree.Node"]
+	assert.Equal(t, schema.Type, "object")
+	assert.Equal(t, schema.Description, "Node is a node of the tree.")
+	assert.DeepEqual(t, schema.Required, []string{"kind"})
+	assert.Equal(t, schema.Properties["children"].Items.Ref, "#/components/schemas/sub.tree.Node")
+	assert.Equal(t, schema.Properties["labels"].Ref, "#/components/schemas/k8s.api.core.v1.Labels")
+	assert.Equal(t, schema.Properties["weights"].AdditionalProperties.Type, "number")
+	assert.Equal(t, schema.Properties["memory"].Type, "string")
+	assert.Equal(t, schema.Properties["memory"].Default, nil)
+	assert.Equal(t, schema.Properties["enabled"].Default, true)
+	assert.Equal(t, schema.Properties["enabled"].Deprecated, true)
+	assert.Equal(t, schema.Properties["extra"].Type, "")
+	assert.Equal(t, converter.defs["k8s.api.core.v1.Labels"].Properties["app"].Type, "string")
+
+	kind, err := json.Marshal(schema.Properties["kind"])
+	assert.NilError(t, err)
+	assert.Equal(t, string(kind), `{"anyOf":[{"const":"Leaf"},{"const":"Branch"}],"default":"Leaf"}`)
+}
+
+func TestLiteralValue(t *testing.T) {
+	tests := []struct {
+		literal  string
+		expected interface{}
+		ok       bool
+	}{
+		{`"a"`, "a", true},
+		{`'a'`, "a", true},
+		{"1", int64(1), true},
+		{"1.5", 1.5, true},
+		{"False", false, true},
+		{"[1, 2]", []interface{}{float64(1), float64(2)}, true},
+		{"None", nil, false},
+		{"1Gi", nil, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		value, ok := literalValue(tt.literal)
+		assert.Equal(t, ok, tt.ok, tt.literal)
+		assert.DeepEqual(t, value, tt.expected)
+	}
+}
+
+func TestExportOpenAPIV3SpecAndJsonSchema(t *testing.T) {
+	pkg_path := filepath.Join(getTestDir("test_kpm_package"), "export_swagger", "aaa")
+	pkg, err := GetKclPackage(pkg_path)
+	assert.Equal(t, err, nil)
+	kpmcli, err := client.NewKpmClient()
+	assert.Equal(t, err, nil)
+	err = kpmcli.ResolvePkgDepsMetadata(pkg.pkg, true)
+	assert.Equal(t, err, nil)
+
+	spec, err := pkg.ExportOpenAPIV3Spec()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, spec.OpenAPI, "3.1.0")
+	assert.Equal(t, spec.Info.Title, "aaa")
+	assert.Equal(t, len(spec.Components.Schemas), 2)
+	assert.Equal(t, spec.Components.Schemas["A"].Properties["b"].Ref, "#/components/schemas/bbb.B")
+	assert.Equal(t, spec.Components.Schemas["bbb.B"].Properties["name"].Type, "string")
+
+	schema, err := pkg.ExportJsonSchema()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, schema.Schema, "https://json-schema.org/draft/2020-12/schema")
+	assert.Equal(t, len(schema.Defs), 2)
+	assert.Equal(t, schema.Defs["A"].Properties["b"].Ref, "#/$defs/bbb.B")
+}
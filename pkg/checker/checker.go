@@ -147,7 +147,7 @@ func (sc *SumChecker) Check(kclPkg pkg.KclPkg) error {
 
 	for _, key := range kclPkg.Dependencies.Deps.Keys() {
 		dep, _ := kclPkg.Dependencies.Deps.Get(key)
-		trustedSum, err := sc.getTrustedSum(dep)
+		trustedSum, err := sc.getTrustedSum(dep, kclPkg.ModFile.Registries...)
 		if err != nil {
 			return fmt.Errorf("failed to get checksum from trusted source: %w", err)
 		}
@@ -171,13 +171,14 @@ func isValidDependencyVersion(v string) bool {
 	return err == nil
 }
 
-// getTrustedSum retrieves the trusted checksum for the given dependency.
-func (sc *SumChecker) getTrustedSum(dep pkg.Dependency) (string, error) {
+// getTrustedSum retrieves the trusted checksum for the given dependency,
+// the missing registry and repo are resolved by the named 'registries' in kcl.mod and the settings.
+func (sc *SumChecker) getTrustedSum(dep pkg.Dependency, registries ...settings.OciRegistry) (string, error) {
 	if dep.Source.Oci == nil {
 		return "", fmt.Errorf("dependency is not from OCI")
 	}
 
-	sc.populateOciFields(dep)
+	dep.Source.Oci.FillRepo(dep.Name, &sc.settings, registries...)
 
 	manifest, err := sc.fetchOciManifest(dep)
 	if err != nil {
@@ -187,28 +188,25 @@ func (sc *SumChecker) getTrustedSum(dep pkg.Dependency) (string, error) {
 	return sc.extractChecksumFromManifest(manifest)
 }
 
-// populateOciFields fills in missing OCI fields with default values from settings.
-func (sc *SumChecker) populateOciFields(dep pkg.Dependency) {
-	if len(dep.Source.Oci.Reg) == 0 {
-		dep.Source.Oci.Reg = sc.settings.DefaultOciRegistry()
-	}
-
-	if len(dep.Source.Oci.Repo) == 0 {
-		dep.Source.Oci.Repo = utils.JoinPath(sc.settings.DefaultOciRepo(), dep.Name)
-	}
-}
-
 // fetchOciManifest retrieves the OCI manifest for the given dependency.
 func (sc *SumChecker) fetchOciManifest(dep pkg.Dependency) (ocispec.Manifest, error) {
 	manifest := ocispec.Manifest{}
-	jsonDesc, err := sc.FetchOciManifestIntoJsonStr(opt.OciFetchOptions{
+	fetchOpts := opt.OciFetchOptions{
 		FetchBytesOptions: oras.DefaultFetchBytesOptions,
 		OciOptions: opt.OciOptions{
 			Reg:  dep.Source.Oci.Reg,
 			Repo: dep.Source.Oci.Repo,
 			Tag:  dep.Source.Oci.Tag,
 		},
-	})
+	}
+
+	var jsonDesc string
+	var err error
+	if dep.Source.Oci.Layout {
+		jsonDesc, err = fetchLayoutManifestIntoJsonStr(dep.Source.Oci, fetchOpts)
+	} else {
+		jsonDesc, err = sc.FetchOciManifestIntoJsonStr(fetchOpts)
+	}
 	if err != nil {
 		return manifest, reporter.NewErrorEvent(reporter.FailedFetchOciManifest, err, fmt.Sprintf("failed to fetch the manifest of '%s'", dep.Name))
 	}
@@ -221,6 +219,15 @@ func (sc *SumChecker) fetchOciManifest(dep pkg.Dependency) (ocispec.Manifest, er
 	return manifest, nil
 }
 
+// fetchLayoutManifestIntoJsonStr fetches the OCI manifest from the local OCI image layout and returns it as a JSON string.
+func fetchLayoutManifestIntoJsonStr(ociSource *downloader.Oci, opts opt.OciFetchOptions) (string, error) {
+	ociCli, err := oci.NewOciClientWithOpts(ociSource.RepoOption())
+	if err != nil {
+		return "", err
+	}
+	return ociCli.FetchManifestIntoJsonStr(opts)
+}
+
 // FetchOciManifestIntoJsonStr fetches the OCI manifest and returns it as a JSON string.
 func (sc *SumChecker) FetchOciManifestIntoJsonStr(opts opt.OciFetchOptions) (string, error) {
 	repoPath := utils.JoinPath(opts.Reg, opts.Repo)
@@ -247,7 +254,7 @@ func (sc *SumChecker) FetchOciManifestIntoJsonStr(opts opt.OciFetchOptions) (str
 
 // GetCredentials retrieves the OCI credentials for the given hostname.
 func (sc *SumChecker) GetCredentials(hostName string) (*remoteauth.Credential, error) {
-	credCli, err := downloader.LoadCredentialFile(sc.settings.CredentialsFile)
+	credCli, err := downloader.LoadCredentialFileWithSettings(&sc.settings)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,7 @@
 package checker
 
 import (
-	"runtime"
+	"path/filepath"
 	"testing"
 
 	"github.com/elliotchance/orderedmap/v2"
@@ -147,34 +147,33 @@ func getTestSettings() (*settings.Settings, error) {
 
 func TestModCheckerCheck_WithTrustedSum(t *testing.T) {
 	testFunc := func(t *testing.T) {
-		if runtime.GOOS == "windows" {
-			t.Skip("Skipping TestModCheckerCheck_WithTrustedSum test on Windows")
-		}
-
-		// Start the local Docker registry required for testing
-		err := mock.StartDockerRegistry()
+		// Start the in-process OCI registry required for testing
+		reg, err := mock.NewOciRegistry()
 		assert.Equal(t, err, nil)
+		defer reg.Close()
 
-		// Push the test package to the local OCI registry
-		err = mock.PushTestPkgToRegistry()
+		// Push the test package to the OCI registry
+		sum, err := reg.PushPkg(filepath.Join("..", "mock", "test_data"), "test/test_data")
 		assert.Equal(t, err, nil)
 
 		// Initialize settings for use with the ModChecker
 		settings, err := getTestSettings()
 		assert.Equal(t, err, nil)
+		regSettings := *settings
+		reg.Configure(&regSettings)
 
 		// Initialize the ModChecker with required checkers
-		ModChecker := NewModChecker(WithCheckers(NewIdentChecker(), NewVersionChecker(), NewSumChecker(WithSettings(*settings))))
+		ModChecker := NewModChecker(WithCheckers(NewIdentChecker(), NewVersionChecker(), NewSumChecker(WithSettings(regSettings))))
 
 		deps1 := orderedmap.NewOrderedMap[string, pkg.Dependency]()
 		deps1.Set("kcl1", pkg.Dependency{
 			Name:     "test_data",
 			FullName: "test_data",
 			Version:  "0.0.1",
-			Sum:      "RpZZIvrXwfn5dpt6LqBR8+FlPE9Y+BEou47L3qaCCqk=",
+			Sum:      sum,
 			Source: downloader.Source{
 				Oci: &downloader.Oci{
-					Reg:  "localhost:5001",
+					Reg:  reg.Host,
 					Repo: "test/test_data",
 					Tag:  "0.0.1",
 				},
@@ -189,7 +188,7 @@ func TestModCheckerCheck_WithTrustedSum(t *testing.T) {
 			Sum:      "Invalid-sum",
 			Source: downloader.Source{
 				Oci: &downloader.Oci{
-					Reg:  "localhost:5001",
+					Reg:  reg.Host,
 					Repo: "test/test_data",
 					Tag:  "0.0.1",
 				},
@@ -265,10 +264,6 @@ func TestModCheckerCheck_WithTrustedSum(t *testing.T) {
 				}
 			})
 		}
-
-		// Clean the environment after all tests have been run
-		err = mock.CleanTestEnv()
-		assert.Equal(t, err, nil)
 	}
 
 	test.RunTestWithGlobalLock(t, "TestModCheckerCheck_WithTrustedSum", testFunc)
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 
@@ -8,7 +9,6 @@ import (
 	"kcl-lang.io/kpm/pkg/features"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
-	"kcl-lang.io/kpm/pkg/utils"
 	"kcl-lang.io/kpm/pkg/visitor"
 )
 
@@ -88,6 +88,11 @@ func NewAddOptions(opts ...AddOption) *AddOptions {
 }
 
 func (c *KpmClient) Add(options ...AddOption) error {
+	return c.AddWithContext(context.Background(), options...)
+}
+
+// AddWithContext is the same as Add, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) AddWithContext(ctx context.Context, options ...AddOption) error {
 	opts := &AddOptions{}
 	for _, option := range options {
 		if err := option(opts); err != nil {
@@ -125,14 +130,9 @@ func (c *KpmClient) Add(options ...AddOption) error {
 		}
 	}
 
-	// Set the default OCI registry and repo if the source is nil and the package spec is not nil.
-	if depSource.IsNilSource() && !depSource.ModSpec.IsNil() {
-		depSource.Oci = &downloader.Oci{
-			Reg:  c.GetSettings().Conf.DefaultOciRegistry,
-			Repo: utils.JoinPath(c.GetSettings().Conf.DefaultOciRepo, depSource.ModSpec.Name),
-			Tag:  depSource.ModSpec.Version,
-		}
-	}
+	// Set the OCI registry and repo resolved by the named registries in kcl.mod and the settings
+	// if the source has only the package spec.
+	c.resolveSpecOnlySource(depSource, addedPkg.ModFile.Registries...)
 
 	var fullSouce *downloader.Source
 	// Transform the relative path to the full path.
@@ -147,7 +147,7 @@ func (c *KpmClient) Add(options ...AddOption) error {
 		fullSouce = depSource
 	}
 
-	visitor, err := visitorSelector(fullSouce)
+	depVisitor, err := visitorSelector(fullSouce)
 	if err != nil {
 		return err
 	}
@@ -155,7 +155,7 @@ func (c *KpmClient) Add(options ...AddOption) error {
 	// Visit the dependency source
 	// If the dependency is remote, the visitor will download it to the local.
 	// If the dependency is already in local cache, the visitor will not download it again.
-	err = visitor.Visit(fullSouce, func(depPkg *pkg.KclPkg) error {
+	err = depVisitor.Visit(ctx, fullSouce, func(depPkg *pkg.KclPkg) error {
 		reporter.ReportMsgTo(
 			fmt.Sprintf("adding dependency '%s'", depPkg.GetPkgName()),
 			c.logWriter,
@@ -25,48 +25,48 @@ func TestAddWithModSpec(t *testing.T) {
 		{
 			name:       "TestAddOciWithModSpec",
 			pkgSubPath: "oci",
-			sourceUrl:  "oci://ghcr.io/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld:0.0.1",
-			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'ghcr.io/kcl-lang/helloworld:0.1.4'" +
+			sourceUrl:  "oci://localhost:5101/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld:0.0.1",
+			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'localhost:5101/kcl-lang/helloworld:0.1.4'" +
 				"adding dependency 'subhelloworld'" +
 				"add dependency 'subhelloworld:0.0.1' successfully",
 		},
 		{
 			name:       "TestAddGitWithModSpec",
 			pkgSubPath: "git",
-			sourceUrl:  "git://github.com/kcl-lang/flask-demo-kcl-manifests.git?commit=8308200&mod=cc:0.0.1",
-			msg: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit '8308200'" +
+			sourceUrl:  "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git?commit=4954771&mod=cc:0.0.1",
+			msg: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '4954771'" +
 				"adding dependency 'cc'" +
 				"add dependency 'cc:0.0.1' successfully",
 		},
 		{
 			name:       "TestAddGitWithModSpec",
 			pkgSubPath: "git_mod_0",
-			sourceUrl:  "git://github.com/kcl-lang/flask-demo-kcl-manifests.git?commit=8308200&mod=cc",
-			msg: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit '8308200'" +
+			sourceUrl:  "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git?commit=4954771&mod=cc",
+			msg: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '4954771'" +
 				"adding dependency 'cc'" +
 				"add dependency 'cc:0.0.1' successfully",
 		},
 		{
 			name:       "TestAddGitWithoutModFileWithModSpec",
 			pkgSubPath: "git_mod_1",
-			sourceUrl:  "git://github.com/kcl-lang/flask-demo-kcl-manifests.git?commit=5ab0fff&mod=cc",
-			msg: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit '5ab0fff'" +
+			sourceUrl:  "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git?commit=e3e6ee4&mod=cc",
+			msg: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit 'e3e6ee4'" +
 				"adding dependency 'cc'" +
 				"add dependency 'cc:0.0.1' successfully",
 		},
 		{
 			name:       "TestAddGitWithoutModFileWithModSpec",
 			pkgSubPath: "git_mod_2",
-			sourceUrl:  "git://github.com/kcl-lang/flask-demo-kcl-manifests.git?commit=3adfc81&mod=cc",
-			msg: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit '3adfc81'" +
+			sourceUrl:  "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git?commit=45c6faf&mod=cc",
+			msg: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '45c6faf'" +
 				"adding dependency 'cc'" +
 				"add dependency 'cc:0.0.2' successfully",
 		},
 		{
 			name:       "TestAddGitWithoutModFileWithModSpec",
 			pkgSubPath: "git_mod_3",
-			sourceUrl:  "git://github.com/kcl-lang/flask-demo-kcl-manifests.git?commit=3adfc81&mod=cc:0.0.1",
-			msg: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit '3adfc81'" +
+			sourceUrl:  "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git?commit=45c6faf&mod=cc:0.0.1",
+			msg: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '45c6faf'" +
 				"adding dependency 'cc'" +
 				"add dependency 'cc:0.0.1' successfully",
 		},
@@ -80,34 +80,34 @@ func TestAddWithModSpec(t *testing.T) {
 		{
 			name:       "TestAddOciWithEmptyVersion",
 			pkgSubPath: "empty_version",
-			sourceUrl:  "oci://ghcr.io/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld",
-			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'ghcr.io/kcl-lang/helloworld:0.1.4'" +
+			sourceUrl:  "oci://localhost:5101/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld",
+			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'localhost:5101/kcl-lang/helloworld:0.1.4'" +
 				"adding dependency 'subhelloworld'" +
 				"add dependency 'subhelloworld:0.0.1' successfully",
 		},
 		{
 			name:       "TestAddOciWithNoSpec",
 			pkgSubPath: "no_spec",
-			sourceUrl:  "oci://ghcr.io/kcl-lang/helloworld?tag=0.1.4",
-			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'ghcr.io/kcl-lang/helloworld:0.1.4'" +
+			sourceUrl:  "oci://localhost:5101/kcl-lang/helloworld?tag=0.1.4",
+			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'localhost:5101/kcl-lang/helloworld:0.1.4'" +
 				"adding dependency 'helloworld'" +
 				"add dependency 'helloworld:0.1.4' successfully",
 		},
 		{
 			name:       "TestAddOciWithNoTag",
 			pkgSubPath: "no_oci_ref",
-			sourceUrl:  "oci://ghcr.io/kcl-lang/helloworld",
+			sourceUrl:  "oci://localhost:5101/kcl-lang/helloworld",
 			msg: "the latest version '0.1.4' will be downloaded" +
-				"downloading 'kcl-lang/helloworld:0.1.4' from 'ghcr.io/kcl-lang/helloworld:0.1.4'" +
+				"downloading 'kcl-lang/helloworld:0.1.4' from 'localhost:5101/kcl-lang/helloworld:0.1.4'" +
 				"adding dependency 'helloworld'" +
 				"add dependency 'helloworld:0.1.4' successfully",
 		},
 		{
 			name:       "TestAddGitWithNoTag",
 			pkgSubPath: "no_git_ref",
-			sourceUrl:  "git://github.com/kcl-lang/flask-demo-kcl-manifests.git",
-			msg: "the latest version 'ade147b' will be downloaded" +
-				"cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit 'ade147b'" +
+			sourceUrl:  "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git",
+			msg: "the latest version '2d93a6a' will be downloaded" +
+				"cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '2d93a6a'" +
 				"adding dependency 'flask_manifests'" +
 				"add dependency 'flask_manifests:0.0.1' successfully",
 		},
@@ -253,7 +253,7 @@ func TestAddRenameWithModSpec(t *testing.T) {
 		}
 
 		assert.Equal(t, utils.RmNewline(
-			"downloading 'kcl-lang/helloworld:0.1.2' from 'ghcr.io/kcl-lang/helloworld:0.1.2'"+
+			"downloading 'kcl-lang/helloworld:0.1.2' from 'localhost:5101/kcl-lang/helloworld:0.1.2'"+
 				"adding dependency 'helloworld'"+
 				"add dependency 'helloworld:0.1.2' successfully",
 		), utils.RmNewline(buf.String()))
@@ -296,7 +296,7 @@ func TestAddWithOnlyModSpec(t *testing.T) {
 			name:      "TestAddWithOnlyModSpec",
 			testDir:   "add_with_mod_spec",
 			pkgSubDir: "spec_only",
-			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'ghcr.io/kcl-lang/helloworld:0.1.4'" +
+			msg: "downloading 'kcl-lang/helloworld:0.1.4' from 'localhost:5101/kcl-lang/helloworld:0.1.4'" +
 				"adding dependency 'helloworld'" +
 				"add dependency 'helloworld:0.1.4' successfully",
 			modSpec: &downloader.ModSpec{
@@ -309,7 +309,7 @@ func TestAddWithOnlyModSpec(t *testing.T) {
 			testDir:   "add_with_mod_spec",
 			pkgSubDir: "spec_only_no_ver",
 			msg: "the latest version '0.1.4' will be downloaded" +
-				"downloading 'kcl-lang/helloworld:0.1.4' from 'ghcr.io/kcl-lang/helloworld:0.1.4'" +
+				"downloading 'kcl-lang/helloworld:0.1.4' from 'localhost:5101/kcl-lang/helloworld:0.1.4'" +
 				"adding dependency 'helloworld'" +
 				"add dependency 'helloworld:0.1.4' successfully",
 			modSpec: &downloader.ModSpec{
@@ -456,7 +456,7 @@ func TestAddRenameWithNoSpec(t *testing.T) {
 					Alias: "newpkg",
 				},
 				Oci: &downloader.Oci{
-					Reg:  "ghcr.io",
+					Reg:  "localhost:5101",
 					Repo: "kcl-lang/helloworld",
 				},
 			}),
@@ -464,7 +464,7 @@ func TestAddRenameWithNoSpec(t *testing.T) {
 
 		assert.Equal(t,
 			"the latest version '0.1.4' will be downloaded"+
-				"downloading 'kcl-lang/helloworld:0.1.4' from 'ghcr.io/kcl-lang/helloworld:0.1.4'"+
+				"downloading 'kcl-lang/helloworld:0.1.4' from 'localhost:5101/kcl-lang/helloworld:0.1.4'"+
 				"adding dependency 'helloworld'"+
 				"add dependency 'helloworld:0.1.4' successfully",
 			utils.RmNewline(buf.String()),
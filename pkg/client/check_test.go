@@ -114,7 +114,7 @@ func TestCheckDepSumFailed(t *testing.T) {
 		)
 
 		assert.Equal(t, err.Error(), "checksum verification failed for 'helloworld': "+
-			"expected '9J9HOMhdypaDYf0J7PqtpGTdlkbxkN0HFEYhosHhf4U=', got 'invalid_sum'")
+			"expected '2k7uHMKtgzRGakvPque1N5PHb9Ouwtuz3FUbec5BMdY=', got 'invalid_sum'")
 	}
 
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestCheckDepSumFailed", TestFunc: testDepSumFunc}})
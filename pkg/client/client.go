@@ -78,7 +78,7 @@ func (c *KpmClient) SetNoSumCheck(noSumCheck bool) {
 // GetCredsClient will return the credential client.
 func (c *KpmClient) GetCredsClient() (*downloader.CredClient, error) {
 	if c.credsClient == nil {
-		credCli, err := downloader.LoadCredentialFile(c.settings.CredentialsFile)
+		credCli, err := downloader.LoadCredentialFileWithSettings(&c.settings)
 		if err != nil {
 			return nil, err
 		}
@@ -171,7 +171,7 @@ func (c *KpmClient) dependencyExistsLocal(searchPath string, dep *pkg.Dependency
 		dep.FullName = dep.GenDepFullName()
 
 		if dep.GetPackage() != "" {
-			dep.LocalFullPath, err = utils.FindPackage(dep.LocalFullPath, dep.GetPackage())
+			dep.LocalFullPath, err = c.findGitPackage(dep.LocalFullPath, dep.Source.Git)
 			if err != nil {
 				return nil, err
 			}
@@ -181,6 +181,30 @@ func (c *KpmClient) dependencyExistsLocal(searchPath string, dep *pkg.Dependency
 	return nil, nil
 }
 
+// findGitPackage finds the package of the git source in the repository cloned in 'root',
+// and records the directory of the package in the git source.
+func (c *KpmClient) findGitPackage(root string, gitSource *downloader.Git) (string, error) {
+	credCli, err := c.GetCredsClient()
+	if err != nil {
+		return "", err
+	}
+	gitUrl, err := gitSource.GetCanonicalizedUrl()
+	if err != nil {
+		return "", err
+	}
+	cred, err := credCli.GitCredential(gitUrl)
+	if err != nil {
+		return "", err
+	}
+	return gitSource.FindPackage(root, cred)
+}
+
+// resolveSpecOnlySource sets the OCI source of the source with only the package spec, e.g. 'helloworld:0.1.0',
+// the registry and repo are resolved by the named 'registries' in kcl.mod and the settings of the client.
+func (c *KpmClient) resolveSpecOnlySource(source *downloader.Source, registries ...settings.OciRegistry) {
+	source.ResolveSpecOnly(c.GetSettings(), registries...)
+}
+
 // NewVisitor is a factory function to create a new Visitor.
 func newVisitor(source downloader.Source, kpmcli *KpmClient) visitor.Visitor {
 	PkgVisitor := &visitor.PkgVisitor{
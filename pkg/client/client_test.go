@@ -3,6 +3,7 @@ package client
 import (
 	"archive/tar"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -93,7 +94,7 @@ func testDownloadOci(t *testing.T) {
 		Version: "0.1.2",
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "0.1.2",
 			},
@@ -107,7 +108,7 @@ func testDownloadOci(t *testing.T) {
 	assert.Equal(t, dep.FullName, "helloworld_0.1.2")
 	assert.Equal(t, dep.Version, "0.1.2")
 	assert.NotEqual(t, dep.Source.Oci, nil)
-	assert.Equal(t, dep.Source.Oci.Reg, "ghcr.io")
+	assert.Equal(t, dep.Source.Oci.Reg, "localhost:5101")
 	assert.Equal(t, dep.Source.Oci.Repo, "kcl-lang/helloworld")
 	assert.Equal(t, dep.Source.Oci.Tag, "0.1.2")
 	assert.Equal(t, dep.LocalFullPath, testPath)
@@ -136,7 +137,7 @@ func testDownloadLatestOci(t *testing.T) {
 		Version: "",
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "",
 			},
@@ -149,9 +150,9 @@ func testDownloadLatestOci(t *testing.T) {
 	assert.Equal(t, dep.Name, "helloworld")
 	assert.Equal(t, dep.FullName, "helloworld_0.1.4")
 	assert.Equal(t, dep.Version, "0.1.4")
-	assert.Equal(t, dep.Sum, "9J9HOMhdypaDYf0J7PqtpGTdlkbxkN0HFEYhosHhf4U=")
+	assert.Equal(t, dep.Sum, "2k7uHMKtgzRGakvPque1N5PHb9Ouwtuz3FUbec5BMdY=")
 	assert.NotEqual(t, dep.Source.Oci, nil)
-	assert.Equal(t, dep.Source.Oci.Reg, "ghcr.io")
+	assert.Equal(t, dep.Source.Oci.Reg, "localhost:5101")
 	assert.Equal(t, dep.Source.Oci.Repo, "kcl-lang/helloworld")
 	assert.Equal(t, dep.Source.Oci.Tag, "0.1.4")
 	assert.Equal(t, dep.LocalFullPath, filepath.Join(getTestDir("download"), "helloworld_0.1.4"))
@@ -181,8 +182,8 @@ func testDownloadGitWithPackage(t *testing.T) {
 		Version: "",
 		Source: downloader.Source{
 			Git: &downloader.Git{
-				Url:     "https://github.com/kcl-lang/modules.git",
-				Commit:  "bdd4d00a88bc3534ae50affa8328df2927fd2171",
+				Url:     "https://127.0.0.1:5102/kcl-lang/modules.git",
+				Commit:  "701a2ca0ec788b847a7e82781aa95376d8bb588b",
 				Package: "add-ndots",
 			},
 		},
@@ -244,8 +245,8 @@ func testModandLockFilesWithGitPackageDownload(t *testing.T) {
 		LocalPath: testPkgPath,
 		RegistryOpts: opt.RegistryOptions{
 			Git: &opt.GitOptions{
-				Url:     "https://github.com/kcl-lang/flask-demo-kcl-manifests.git",
-				Commit:  "8308200",
+				Url:     "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git",
+				Commit:  "4954771",
 				Package: "cc",
 			},
 		},
@@ -572,7 +573,7 @@ func testResolveDepsVendorMode(t *testing.T) {
 		Sum:      kcl1Sum,
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/kcl1",
 				Tag:  "0.0.1",
 			},
@@ -586,7 +587,7 @@ func testResolveDepsVendorMode(t *testing.T) {
 		Sum:      kcl2Sum,
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/kcl2",
 				Tag:  "0.0.1",
 			},
@@ -651,7 +652,7 @@ func testCompileWithEntryFile(t *testing.T) {
 		Sum:      kcl1Sum,
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/kcl1",
 				Tag:  "0.0.1",
 			},
@@ -665,7 +666,7 @@ func testCompileWithEntryFile(t *testing.T) {
 		Sum:      kcl2Sum,
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/kcl2",
 				Tag:  "0.0.1",
 			},
@@ -771,9 +772,9 @@ func testResolveMetadataInJsonStr(t *testing.T) {
 
 	expectedDep.Deps["flask_demo_kcl_manifests"] = pkg.Dependency{
 		Name:          "flask_demo_kcl_manifests",
-		FullName:      "flask-demo-kcl-manifests_ade147b",
+		FullName:      "flask-demo-kcl-manifests_2d93a6a",
 		Version:       "0.1.0",
-		LocalFullPath: filepath.Join(globalPkgPath, "flask-demo-kcl-manifests_ade147b"),
+		LocalFullPath: filepath.Join(globalPkgPath, "flask-demo-kcl-manifests_2d93a6a"),
 	}
 
 	expectedDepStr, err := json.Marshal(expectedDep)
@@ -790,13 +791,13 @@ func testResolveMetadataInJsonStr(t *testing.T) {
 	res, err = kpmcli.ResolveDepsMetadataInJsonStr(kclpkg, true)
 	assert.Equal(t, err, nil)
 	assert.Equal(t, utils.DirExists(vendorDir), true)
-	assert.Equal(t, utils.DirExists(filepath.Join(vendorDir, "flask-demo-kcl-manifests_ade147b")), true)
+	assert.Equal(t, utils.DirExists(filepath.Join(vendorDir, "flask-demo-kcl-manifests_2d93a6a")), true)
 
 	expectedDep.Deps["flask_demo_kcl_manifests"] = pkg.Dependency{
 		Name:          "flask_demo_kcl_manifests",
-		FullName:      "flask-demo-kcl-manifests_ade147b",
+		FullName:      "flask-demo-kcl-manifests_2d93a6a",
 		Version:       "0.1.0",
-		LocalFullPath: filepath.Join(vendorDir, "flask-demo-kcl-manifests_ade147b"),
+		LocalFullPath: filepath.Join(vendorDir, "flask-demo-kcl-manifests_2d93a6a"),
 	}
 
 	expectedDepStr, err = json.Marshal(expectedDep)
@@ -814,9 +815,9 @@ func testResolveMetadataInJsonStr(t *testing.T) {
 	res, err = kpmcli.ResolveDepsMetadataInJsonStr(kclpkg, false)
 	assert.Equal(t, err, nil)
 	assert.Equal(t, utils.DirExists(vendorDir), false)
-	assert.Equal(t, utils.DirExists(filepath.Join(vendorDir, "flask-demo-kcl-manifests_ade147b")), false)
+	assert.Equal(t, utils.DirExists(filepath.Join(vendorDir, "flask-demo-kcl-manifests_2d93a6a")), false)
 	assert.Equal(t, err, nil)
-	expectedPath := filepath.Join("not_exist", "flask-demo-kcl-manifests_ade147b")
+	expectedPath := filepath.Join("not_exist", "flask-demo-kcl-manifests_2d93a6a")
 	if runtime.GOOS == "windows" {
 		expectedPath = strings.ReplaceAll(expectedPath, "\\", "\\\\")
 	}
@@ -849,13 +850,13 @@ func testResolveMetadataInJsonStrWithPackage(t *testing.T) {
 		Deps: make(map[string]pkg.Dependency),
 	}
 
-	localFullPath, err := utils.FindPackage(filepath.Join(globalPkgPath, "flask-demo-kcl-manifests_8308200"), "cc")
+	localFullPath, err := utils.FindPackage(filepath.Join(globalPkgPath, "flask-demo-kcl-manifests_4954771"), "cc")
 	assert.Equal(t, err, nil)
 
 	expectedDep.Deps["cc"] = pkg.Dependency{
 		Name:          "cc",
-		FullName:      "flask-demo-kcl-manifests_8308200",
-		Version:       "8308200",
+		FullName:      "flask-demo-kcl-manifests_4954771",
+		Version:       "4954771",
 		LocalFullPath: localFullPath,
 	}
 
@@ -883,9 +884,9 @@ func testResolveMetadataInJsonStrWithPackage(t *testing.T) {
 	assert.Equal(t, err, nil)
 
 	assert.Equal(t, utils.DirExists(vendorDir), true)
-	assert.Equal(t, utils.DirExists(filepath.Join(vendorDir, "flask-demo-kcl-manifests_8308200")), true)
+	assert.Equal(t, utils.DirExists(filepath.Join(vendorDir, "flask-demo-kcl-manifests_4954771")), true)
 
-	localFullPath, err = utils.FindPackage(filepath.Join(vendorDir, "flask-demo-kcl-manifests_8308200"), "cc")
+	localFullPath, err = utils.FindPackage(filepath.Join(vendorDir, "flask-demo-kcl-manifests_4954771"), "cc")
 	assert.Equal(t, err, nil)
 
 	expectedDep = pkg.DependenciesUI{
@@ -894,8 +895,8 @@ func testResolveMetadataInJsonStrWithPackage(t *testing.T) {
 
 	expectedDep.Deps["cc"] = pkg.Dependency{
 		Name:          "cc",
-		FullName:      "flask-demo-kcl-manifests_8308200",
-		Version:       "8308200",
+		FullName:      "flask-demo-kcl-manifests_4954771",
+		Version:       "4954771",
 		LocalFullPath: localFullPath,
 	}
 
@@ -997,7 +998,7 @@ func testNewKpmClient(t *testing.T) {
 	assert.Equal(t, kpmcli.GetSettings().KpmConfFile, filepath.Join(kpmhome, ".kpm", "config", "kpm.json"))
 	assert.Equal(t, kpmcli.GetSettings().CredentialsFile, filepath.Join(kpmhome, ".kpm", "config", "config.json"))
 	assert.Equal(t, kpmcli.GetSettings().Conf.DefaultOciRepo, "kcl-lang")
-	assert.Equal(t, kpmcli.GetSettings().Conf.DefaultOciRegistry, "ghcr.io")
+	assert.Equal(t, kpmcli.GetSettings().Conf.DefaultOciRegistry, "localhost:5101")
 	plainHttp, force := kpmcli.GetSettings().ForceOciPlainHttp()
 	assert.Equal(t, plainHttp, false)
 	assert.Equal(t, force, false)
@@ -1010,7 +1011,7 @@ func TestParseOciOptionFromString(t *testing.T) {
 	ociOption, err := kpmcli.ParseOciOptionFromString(oci_ref_with_tag, "test_tag")
 	assert.Equal(t, err, nil)
 	assert.Equal(t, ociOption.Ref, "")
-	assert.Equal(t, ociOption.Reg, "ghcr.io")
+	assert.Equal(t, ociOption.Reg, "localhost:5101")
 	assert.Equal(t, ociOption.Repo, "kcl-lang/test_oci_repo")
 	assert.Equal(t, ociOption.Tag, "test_oci_tag")
 
@@ -1018,7 +1019,7 @@ func TestParseOciOptionFromString(t *testing.T) {
 	ociOption, err = kpmcli.ParseOciOptionFromString(oci_ref_without_tag, "test_tag")
 	assert.Equal(t, err, nil)
 	assert.Equal(t, ociOption.Ref, "")
-	assert.Equal(t, ociOption.Reg, "ghcr.io")
+	assert.Equal(t, ociOption.Reg, "localhost:5101")
 	assert.Equal(t, ociOption.Repo, "kcl-lang/test_oci_repo")
 	assert.Equal(t, ociOption.Tag, "test_oci_tag")
 
@@ -1051,7 +1052,7 @@ func TestGetReleasesFromSource(t *testing.T) {
 		return res, nil
 	}
 
-	releases, err := GetReleasesFromSource(pkg.GIT, "https://github.com/kcl-lang/kpm")
+	releases, err := GetReleasesFromSource(pkg.GIT, "https://127.0.0.1:5102/kcl-lang/kpm")
 	assert.Equal(t, err, nil)
 	length := len(releases)
 	assert.True(t, length >= 5)
@@ -1059,7 +1060,7 @@ func TestGetReleasesFromSource(t *testing.T) {
 	assert.Equal(t, err, nil)
 	assert.Equal(t, releasesVersions[:5], []string{"v0.1.0", "v0.2.0", "v0.2.1", "v0.2.2", "v0.2.3"})
 
-	releases, err = GetReleasesFromSource(pkg.OCI, "oci://ghcr.io/kcl-lang/k8s")
+	releases, err = GetReleasesFromSource(pkg.OCI, "oci://localhost:5101/kcl-lang/k8s")
 	assert.Equal(t, err, nil)
 	length = len(releases)
 	assert.True(t, length >= 5)
@@ -1193,7 +1194,7 @@ func testAddWithNoSumCheck(t *testing.T) {
 		LocalPath: pkgPath,
 		RegistryOpts: opt.RegistryOptions{
 			Oci: &opt.OciOptions{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Ref:  "helloworld",
 				Tag:  "0.1.0",
@@ -1266,7 +1267,7 @@ func testAddWithDiffVersionNoSumCheck(t *testing.T) {
 		LocalPath: pkgPath,
 		RegistryOpts: opt.RegistryOptions{
 			Oci: &opt.OciOptions{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Ref:  "helloworld",
 				Tag:  "0.1.2",
@@ -1330,7 +1331,7 @@ func testAddWithDiffVersionWithSumCheck(t *testing.T) {
 		LocalPath: pkgPath,
 		RegistryOpts: opt.RegistryOptions{
 			Oci: &opt.OciOptions{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Ref:  "helloworld",
 				Tag:  "0.1.2",
@@ -1400,8 +1401,8 @@ func testAddWithGitCommit(t *testing.T) {
 		LocalPath: testPkgPath,
 		RegistryOpts: opt.RegistryOptions{
 			Git: &opt.GitOptions{
-				Url:    "https://github.com/kcl-lang/flask-demo-kcl-manifests.git",
-				Commit: "ade147b",
+				Url:    "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git",
+				Commit: "2d93a6a",
 			},
 		},
 	}
@@ -1448,13 +1449,13 @@ func testLoadPkgFormOci(t *testing.T) {
 
 	testCases := []testCase{
 		{
-			Reg:  "ghcr.io",
+			Reg:  "localhost:5101",
 			Repo: "kusionstack/opsrule",
 			Tag:  "0.0.9",
 			Name: "opsrule",
 		},
 		{
-			Reg:  "ghcr.io",
+			Reg:  "localhost:5101",
 			Repo: "kcl-lang/helloworld",
 			Tag:  "0.1.2",
 			Name: "helloworld",
@@ -1510,7 +1511,7 @@ func testAddWithLocalPath(t *testing.T) {
 		LocalPath: tmpPkgPath,
 		RegistryOpts: opt.RegistryOptions{
 			Oci: &opt.OciOptions{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Ref:  "helloworld",
 				Tag:  "0.1.1",
@@ -1565,7 +1566,7 @@ func testAddWithOciDownloader(t *testing.T) {
 		FullName: "helloworld_0.0.3",
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "zong-zhe/helloworld",
 				Tag:  "0.0.3",
 			},
@@ -1673,7 +1674,7 @@ func testAddDefaultRegistryDep(t *testing.T) {
 			LocalPath: tc.pkgPath,
 			RegistryOpts: opt.RegistryOptions{
 				Registry: &opt.OciOptions{
-					Reg:  "ghcr.io",
+					Reg:  "localhost:5101",
 					Repo: "kcl-lang/helloworld",
 					Ref:  "helloworld",
 					Tag:  tc.tag,
@@ -2010,8 +2011,8 @@ func testRunRemoteWithArgsInvalid(t *testing.T, kpmcli *KpmClient) {
 
 	testCases := []testCase{
 		{
-			sourceURL:      "git://github.com/kcl-lang/flask-demo-kcl-manifests?commit=8308200&mod=cc:0.0.2",
-			expectedLog:    "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests' with commit '8308200'\n",
+			sourceURL:      "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests?commit=4954771&mod=cc:0.0.2",
+			expectedLog:    "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests' with commit '4954771'\n",
 			expectedErrMsg: "package 'cc:0.0.2' not found",
 		},
 	}
@@ -2039,29 +2040,29 @@ func testRunRemoteWithArgs(t *testing.T, kpmcli *KpmClient) {
 
 	testCases := []testCase{
 		{
-			sourceURL:    "oci://ghcr.io/kcl-lang/helloworld?tag=0.1.2",
-			expectedLog:  "downloading 'kcl-lang/helloworld:0.1.2' from 'ghcr.io/kcl-lang/helloworld:0.1.2'\n",
+			sourceURL:    "oci://localhost:5101/kcl-lang/helloworld?tag=0.1.2",
+			expectedLog:  "downloading 'kcl-lang/helloworld:0.1.2' from 'localhost:5101/kcl-lang/helloworld:0.1.2'\n",
 			expectedYaml: "The_first_kcl_program: Hello World!",
 		},
 		{
-			sourceURL:   "git://github.com/kcl-lang/flask-demo-kcl-manifests?branch=main",
-			expectedLog: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests' with branch 'main'\n",
+			sourceURL:   "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests?branch=main",
+			expectedLog: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests' with branch 'main'\n",
 			expectedYamlFn: func(pkgPath string) (string, error) {
 				expected, err := os.ReadFile(filepath.Join(pkgPath, "remote", "expect_1.yaml"))
 				return string(expected), err
 			},
 		},
 		{
-			sourceURL:   "git://github.com/kcl-lang/flask-demo-kcl-manifests?commit=ade147b",
-			expectedLog: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests' with commit 'ade147b'\n",
+			sourceURL:   "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests?commit=2d93a6a",
+			expectedLog: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests' with commit '2d93a6a'\n",
 			expectedYamlFn: func(pkgPath string) (string, error) {
 				expected, err := os.ReadFile(filepath.Join(pkgPath, "remote", "expect_2.yaml"))
 				return string(expected), err
 			},
 		},
 		{
-			sourceURL:   "git://github.com/kcl-lang/flask-demo-kcl-manifests?commit=8308200&mod=cc:0.0.1",
-			expectedLog: "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests' with commit '8308200'\n",
+			sourceURL:   "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests?commit=4954771&mod=cc:0.0.1",
+			expectedLog: "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests' with commit '4954771'\n",
 			expectedYamlFn: func(pkgPath string) (string, error) {
 				expected, err := os.ReadFile(filepath.Join(pkgPath, "remote", "expect_3.yaml"))
 				return string(expected), err
@@ -2133,7 +2134,7 @@ func TestVirtualPackageVisiter(t *testing.T) {
 	assert.Equal(t, err, nil)
 
 	v := newVisitor(*pkgSource, kpmcli)
-	err = v.Visit(pkgSource, func(p *pkg.KclPkg) error {
+	err = v.Visit(context.Background(), pkgSource, func(p *pkg.KclPkg) error {
 		assert.Contains(t, p.GetPkgName(), "vPkg_")
 		_, err = os.Stat(filepath.Join(pkgPath, "kcl.mod"))
 		assert.Equal(t, os.IsNotExist(err), true)
@@ -2267,12 +2268,12 @@ func testPushWithInsecureSkipTLSverify(t *testing.T) {
 	kpmcli, err := NewKpmClient()
 	assert.Equal(t, err, nil)
 
-	_ = kpmcli.pushToOci("test", ociOpts)
+	_ = kpmcli.pushToOci(context.Background(), "test", ociOpts)
 
 	assert.Equal(t, buf.String(), "")
 
 	kpmcli.SetInsecureSkipTLSverify(true)
-	_ = kpmcli.pushToOci("test", ociOpts)
+	_ = kpmcli.pushToOci(context.Background(), "test", ociOpts)
 
 	assert.Equal(t, buf.String(), "Called Success\n")
 }
@@ -2288,11 +2289,11 @@ func TestValidateDependency(t *testing.T) {
 		Name:          "helloworld",
 		FullName:      "helloworld_0.1.2",
 		Version:       "0.1.2",
-		Sum:           "PN0OMEV9M8VGFn1CtA/T3bcgZmMJmOo+RkBrLKIWYeQ=",
+		Sum:           "HgVvh/H6DztMe9RZJkTEGt9QDR4LxrQA2npoIXYYKVM=",
 		LocalFullPath: "path/to/kcl/package",
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "0.1.2",
 			},
@@ -2310,7 +2311,7 @@ func TestValidateDependency(t *testing.T) {
 		LocalFullPath: "path/to/kcl/package",
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "0.1.2",
 			},
@@ -2348,7 +2349,7 @@ func TestAddOciDepLocalStorage(t *testing.T) {
 			WithAddKclPkg(mod),
 			WithAddSource(&downloader.Source{
 				Oci: &downloader.Oci{
-					Reg:  "ghcr.io",
+					Reg:  "localhost:5101",
 					Repo: "kcl-lang/helloworld",
 					Tag:  "0.1.2",
 				},
@@ -2364,7 +2365,7 @@ func TestAddOciDepLocalStorage(t *testing.T) {
 		}
 
 		assert.Equal(t, buf.String(),
-			"downloading 'kcl-lang/helloworld:0.1.2' from 'ghcr.io/kcl-lang/helloworld:0.1.2'\n"+
+			"downloading 'kcl-lang/helloworld:0.1.2' from 'localhost:5101/kcl-lang/helloworld:0.1.2'\n"+
 				"adding dependency 'helloworld'\n"+
 				"add dependency 'helloworld:0.1.2' successfully\n",
 		)
@@ -2407,7 +2408,7 @@ func TestAddOciNewLocalStorage(t *testing.T) {
 
 		depSource := &downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "0.1.2",
 			},
@@ -2430,7 +2431,7 @@ func TestAddOciNewLocalStorage(t *testing.T) {
 		assert.Equal(t, true, utils.DirExists(cachePath))
 
 		assert.Equal(t, buf.String(),
-			"downloading 'kcl-lang/helloworld:0.1.2' from 'ghcr.io/kcl-lang/helloworld:0.1.2'\n"+
+			"downloading 'kcl-lang/helloworld:0.1.2' from 'localhost:5101/kcl-lang/helloworld:0.1.2'\n"+
 				"adding dependency 'helloworld'\n"+
 				"add dependency 'helloworld:0.1.2' successfully\n",
 		)
@@ -2475,7 +2476,7 @@ func TestAddGitDepLocalStorage(t *testing.T) {
 			WithAddKclPkg(mod),
 			WithAddSource(&downloader.Source{
 				Git: &downloader.Git{
-					Url:    "https://github.com/kcl-lang/flask-demo-kcl-manifests.git",
+					Url:    "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git",
 					Branch: "main",
 				},
 			}),
@@ -2486,7 +2487,7 @@ func TestAddGitDepLocalStorage(t *testing.T) {
 		}
 
 		assert.Equal(t, buf.String(),
-			"cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n"+
+			"cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n"+
 				"adding dependency 'flask_manifests'\n"+
 				"add dependency 'flask_manifests:0.0.1' successfully\n",
 		)
@@ -2530,7 +2531,7 @@ func TestAddGitNewLocalStorage(t *testing.T) {
 
 		gitSource := &downloader.Source{
 			Git: &downloader.Git{
-				Url:    "https://github.com/kcl-lang/flask-demo-kcl-manifests.git",
+				Url:    "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git",
 				Branch: "main",
 			},
 		}
@@ -2544,7 +2545,7 @@ func TestAddGitNewLocalStorage(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		assert.Equal(t, buf.String(), "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n"+
+		assert.Equal(t, buf.String(), "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n"+
 			"adding dependency 'flask_manifests'\n"+
 			"add dependency 'flask_manifests:0.0.1' successfully\n",
 		)
@@ -2599,7 +2600,7 @@ func TestUpdateOciDepLocalStorage(t *testing.T) {
 		}
 
 		assert.Equal(t, buf.String(),
-			"downloading 'kcl-lang/helloworld:0.1.2' from 'ghcr.io/kcl-lang/helloworld:0.1.2'\n")
+			"downloading 'kcl-lang/helloworld:0.1.2' from 'localhost:5101/kcl-lang/helloworld:0.1.2'\n")
 
 		depPath := filepath.Join(testKpmHome, "helloworld_0.1.2")
 		_, err = pkg.LoadKclPkgWithOpts(
@@ -2639,7 +2640,7 @@ func TestUpdateOciNewLocalStorage(t *testing.T) {
 
 		depSource := &downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "0.1.2",
 			},
@@ -2661,7 +2662,7 @@ func TestUpdateOciNewLocalStorage(t *testing.T) {
 		assert.Equal(t, true, utils.DirExists(cachePath))
 
 		assert.Equal(t, buf.String(),
-			"downloading 'kcl-lang/helloworld:0.1.2' from 'ghcr.io/kcl-lang/helloworld:0.1.2'\n",
+			"downloading 'kcl-lang/helloworld:0.1.2' from 'localhost:5101/kcl-lang/helloworld:0.1.2'\n",
 		)
 
 		depPath := filepath.Join(depSource.LocalPath(filepath.Join(testKpmHome, "oci", "src")))
@@ -2709,7 +2710,7 @@ func TestUpdateGitDepLocalStorage(t *testing.T) {
 		}
 
 		assert.Equal(t, buf.String(),
-			"cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n",
+			"cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n",
 		)
 
 		depPath := filepath.Join(testKpmHome, "flask-demo-kcl-manifests_main")
@@ -2751,7 +2752,7 @@ func TestUpdateGitNewLocalStorage(t *testing.T) {
 
 		gitSource := &downloader.Source{
 			Git: &downloader.Git{
-				Url:    "https://github.com/kcl-lang/flask-demo-kcl-manifests.git",
+				Url:    "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git",
 				Branch: "main",
 			},
 		}
@@ -2764,7 +2765,7 @@ func TestUpdateGitNewLocalStorage(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		assert.Equal(t, buf.String(), "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n")
+		assert.Equal(t, buf.String(), "cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n")
 
 		depPath := gitSource.LocalPath(filepath.Join(testKpmHome, "git", "src"))
 		_, err = pkg.LoadKclPkgWithOpts(
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"os"
@@ -22,6 +23,7 @@ import (
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/runner"
+	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -342,7 +344,7 @@ func (c *KpmClient) resolvePkgDeps(kclPkg *pkg.KclPkg, lockDeps *pkg.Dependencie
 				if update {
 					// re-vendor it.
 					if kclPkg.IsVendorMode() {
-						err := c.vendorDeps(kclPkg, kclPkg.LocalVendorPath())
+						err := c.vendorDeps(context.Background(), kclPkg, kclPkg.LocalVendorPath())
 						if err != nil {
 							return err
 						}
@@ -362,7 +364,7 @@ func (c *KpmClient) resolvePkgDeps(kclPkg *pkg.KclPkg, lockDeps *pkg.Dependencie
 		}
 
 		if d.GetPackage() != "" {
-			depPath, _ = utils.FindPackage(depPath, d.GetPackage())
+			depPath, _ = c.findGitPackage(depPath, d.Source.Git)
 		}
 
 		// If the dependency exists locally, load the dependency package.
@@ -413,7 +415,7 @@ func GetReleasesFromSource(sourceType, uri string) ([]string, error) {
 
 	switch sourceType {
 	case pkg.GIT:
-		releases, err = git.GetAllGithubReleases(uri)
+		releases, err = getGitReleases(uri)
 	case pkg.OCI:
 		releases, err = oci.GetAllImageTags(uri)
 	}
@@ -424,6 +426,19 @@ func GetReleasesFromSource(sourceType, uri string) ([]string, error) {
 	return releases, nil
 }
 
+// getGitReleases lists the releases of the git repository 'uri' with the git credential in the global settings.
+func getGitReleases(uri string) ([]string, error) {
+	credCli, err := downloader.LoadCredentialFileWithSettings(settings.GetSettings())
+	if err != nil {
+		return nil, err
+	}
+	gitCred, err := credCli.GitCredential(uri)
+	if err != nil {
+		return nil, err
+	}
+	return git.ListReleasesWithContext(context.Background(), git.ReleaseListerWithCredential(git.GetReleaseLister(uri), gitCred), uri)
+}
+
 // UpdateDeps will update the dependencies.
 // Deprecated: Use `Update` instead.
 func (c *KpmClient) UpdateDeps(kclPkg *pkg.KclPkg) error {
@@ -657,7 +672,7 @@ func (c *KpmClient) downloadPkg(options ...downloader.Option) (*pkg.KclPkg, erro
 		return nil, err
 	}
 
-	err = c.DepDownloader.Download(downloader.NewDownloadOptions(
+	err = c.DepDownloader.Download(context.Background(), downloader.NewDownloadOptions(
 		downloader.WithLocalPath(tmpDir),
 		downloader.WithSource(opts.Source),
 		downloader.WithLogWriter(c.GetLogWriter()),
@@ -707,11 +722,16 @@ func (c *KpmClient) downloadPkg(options ...downloader.Option) (*pkg.KclPkg, erro
 // Deprecated: the function is not used anymore, use `downloader.Download` instead.
 func (c *KpmClient) Download(dep *pkg.Dependency, homePath, localPath string) (*pkg.Dependency, error) {
 	if dep.Source.Git != nil {
-		err := c.DepDownloader.Download(downloader.NewDownloadOptions(
+		credCli, err := c.GetCredsClient()
+		if err != nil {
+			return nil, err
+		}
+		err = c.DepDownloader.Download(context.Background(), downloader.NewDownloadOptions(
 			downloader.WithLocalPath(localPath),
 			downloader.WithSource(dep.Source),
 			downloader.WithLogWriter(c.logWriter),
 			downloader.WithSettings(c.settings),
+			downloader.WithCredsClient(credCli),
 		))
 		if err != nil {
 			return nil, err
@@ -720,7 +740,7 @@ func (c *KpmClient) Download(dep *pkg.Dependency, homePath, localPath string) (*
 		dep.FullName = dep.GenDepFullName()
 
 		if dep.GetPackage() != "" {
-			localFullPath, err := utils.FindPackage(localPath, dep.GetPackage())
+			localFullPath, err := c.findGitPackage(localPath, dep.Source.Git)
 			if err != nil {
 				return nil, err
 			}
@@ -779,7 +799,7 @@ func (c *KpmClient) Download(dep *pkg.Dependency, homePath, localPath string) (*
 		if err != nil {
 			return nil, err
 		}
-		err = c.DepDownloader.Download(downloader.NewDownloadOptions(
+		err = c.DepDownloader.Download(context.Background(), downloader.NewDownloadOptions(
 			downloader.WithLocalPath(localPath),
 			downloader.WithSource(dep.Source),
 			downloader.WithLogWriter(c.logWriter),
@@ -859,12 +879,22 @@ func (c *KpmClient) DownloadFromGit(dep *downloader.Git, localPath string) (stri
 		c.logWriter,
 	)
 
-	_, err := git.CloneWithOpts(
+	credCli, err := c.GetCredsClient()
+	if err != nil {
+		return localPath, err
+	}
+	gitCred, err := credCli.GitCredential(dep.Url)
+	if err != nil {
+		return localPath, err
+	}
+
+	_, err = git.CloneWithOpts(
 		git.WithCommit(dep.Commit),
 		git.WithTag(dep.Tag),
 		git.WithRepoURL(dep.Url),
 		git.WithLocalPath(localPath),
 		git.WithWriter(c.logWriter),
+		git.WithCredential(gitCred),
 	)
 
 	if err != nil {
@@ -1007,6 +1037,19 @@ func (c *KpmClient) PullFromOci(localPath, source, tag string) error {
 		)
 	}
 
+	// The package in the local OCI image layout is pulled by the 'Pull' method.
+	if u, parseErr := url.Parse(source); parseErr == nil && u.Scheme == constants.OciFileScheme {
+		layoutSource := &downloader.Oci{}
+		if err := layoutSource.FromString(source); err != nil {
+			return err
+		}
+		if len(tag) != 0 {
+			layoutSource.Tag = tag
+		}
+		_, err = c.Pull(WithPullSource(&downloader.Source{Oci: layoutSource}), WithLocalPath(localPath))
+		return err
+	}
+
 	ociOpts, err := c.ParseOciOptionFromString(source, tag)
 	if err != nil {
 		return err
@@ -1243,12 +1286,12 @@ func (c *KpmClient) DownloadDeps(deps *pkg.Dependencies, lockDeps *pkg.Dependenc
 		var deppkg *pkg.KclPkg
 		if len(d.LocalFullPath) != 0 {
 			if d.GetPackage() != "" {
-				d.LocalFullPath, _ = utils.FindPackage(d.LocalFullPath, d.GetPackage())
+				d.LocalFullPath, _ = c.findGitPackage(d.LocalFullPath, d.Source.Git)
 			}
 		} else {
 			// Load kcl.mod file of the new downloaded dependencies.
 			if d.GetPackage() != "" {
-				d.LocalFullPath, _ = utils.FindPackage(filepath.Join(c.homePath, d.FullName), d.GetPackage())
+				d.LocalFullPath, _ = c.findGitPackage(filepath.Join(c.homePath, d.FullName), d.Source.Git)
 			}
 		}
 		deppkg, err = c.LoadPkgFromPath(d.LocalFullPath)
@@ -71,7 +71,7 @@ func testRunWithOciDownloader(t *testing.T, kpmCli *KpmClient) {
 		opt.WithLogWriter(writer),
 	)
 	assert.Equal(t, err, nil)
-	strings.Contains(buf.String(), "downloading 'zong-zhe/helloworld:0.0.3' from 'ghcr.io/zong-zhe/helloworld:0.0.3'")
+	strings.Contains(buf.String(), "downloading 'zong-zhe/helloworld:0.0.3' from 'localhost:5101/zong-zhe/helloworld:0.0.3'")
 	assert.Equal(t, res.GetRawYamlResult(), "The_first_kcl_program: Hello World!")
 }
 
@@ -79,7 +79,7 @@ func testRunGit(t *testing.T, kpmcli *KpmClient) {
 	testPath := getTestDir("test_run_git")
 
 	opts := opt.DefaultCompileOptions()
-	gitOpts := git.NewCloneOptions("https://github.com/kcl-lang/flask-demo-kcl-manifests.git", "", "", "main", filepath.Join(testPath, "flask-demo-kcl-manifests"), nil)
+	gitOpts := git.NewCloneOptions("https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git", "", "", "main", filepath.Join(testPath, "flask-demo-kcl-manifests"), nil)
 	defer func() {
 		_ = os.RemoveAll(filepath.Join(testPath, "flask-demo-kcl-manifests"))
 	}()
@@ -101,7 +101,7 @@ func testRunOciWithSettingsFile(t *testing.T, kpmcli *KpmClient) {
 	opts.SetEntries([]string{})
 	opts.Merge(kcl.WithSettings(filepath.Join(".", "test_data", "test_run_oci_with_settings", "kcl.yaml")))
 	opts.SetHasSettingsYaml(true)
-	_, err := kpmcli.CompileOciPkg("oci://ghcr.io/kcl-lang/helloworld", "", opts)
+	_, err := kpmcli.CompileOciPkg("oci://localhost:5101/kcl-lang/helloworld", "", opts)
 	assert.Equal(t, err, nil)
 }
 
@@ -119,7 +119,7 @@ func testRunOciWithSettingsFile(t *testing.T, kpmcli *KpmClient) {
 // 		ref        string
 // 		expectFile string
 // 	}{
-// 		{"8308200", "expect1.yaml"},
+// 		{"4954771", "expect1.yaml"},
 // 		{"0b3f5ab", "expect2.yaml"},
 // 	}
 
@@ -127,7 +127,7 @@ func testRunOciWithSettingsFile(t *testing.T, kpmcli *KpmClient) {
 
 // 		expectPath := filepath.Join(testPath, tc.expectFile)
 // 		opts := opt.DefaultCompileOptions()
-// 		gitOpts := git.NewCloneOptions("https://github.com/kcl-lang/flask-demo-kcl-manifests.git", tc.ref, "", "", "", nil)
+// 		gitOpts := git.NewCloneOptions("https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git", tc.ref, "", "", "", nil)
 
 // 		result, err := kpmcli.CompileGitPkg(gitOpts, opts)
 // 		assert.Equal(t, err, nil)
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/dominikbraun/graph"
@@ -92,6 +93,11 @@ func format(m module.Version) string {
 
 // Graph creates a dependency graph for the given KCL Module.
 func (c *KpmClient) Graph(opts ...GraphOption) (*DepGraph, error) {
+	return c.GraphWithContext(context.Background(), opts...)
+}
+
+// GraphWithContext is the same as Graph, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) GraphWithContext(ctx context.Context, opts ...GraphOption) (*DepGraph, error) {
 	options := &GraphOptions{}
 	for _, o := range opts {
 		err := o(options)
@@ -154,7 +160,8 @@ func (c *KpmClient) Graph(opts ...GraphOption) (*DepGraph, error) {
 	}
 	depResolver.ResolveFuncs = append(depResolver.ResolveFuncs, resolverFunc)
 
-	err := depResolver.Resolve(
+	err := depResolver.ResolveWithContext(
+		ctx,
 		resolver.WithEnableCache(true),
 		resolver.WithResolveKclMod(kMod),
 	)
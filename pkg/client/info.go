@@ -0,0 +1,313 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/oci"
+	"kcl-lang.io/kpm/pkg/opt"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/semver"
+	"kcl-lang.io/kpm/pkg/settings"
+)
+
+// PackageInfo is the information of a remote kcl package returned by the Info method.
+type PackageInfo struct {
+	// Name is the name of the package in its kcl.mod.
+	Name string `json:"name"`
+	// Version is the inspected version of the package, i.e. the OCI tag or the git ref.
+	Version string `json:"version"`
+	// Description is the description of the package.
+	Description string `json:"description,omitempty"`
+	// Source is the url of the package.
+	Source string `json:"source"`
+	// Versions are all the available versions of the package in ascending order.
+	Versions []string `json:"versions,omitempty"`
+	// Sum is the checksum in the 'org.kcllang.package.sum' annotation of the OCI manifest.
+	Sum string `json:"sum,omitempty"`
+	// Created is the creation time in the 'org.opencontainers.image.created' annotation of the OCI manifest.
+	Created string `json:"created,omitempty"`
+	// Size is the total size of the package layers of the OCI manifest in bytes, it is 0 for the git packages.
+	Size int64 `json:"size,omitempty"`
+	// Dependencies are the dependencies declared in the kcl.mod of the package.
+	Dependencies []InfoDependency `json:"dependencies,omitempty"`
+}
+
+// InfoDependency is a dependency declared in the kcl.mod of the inspected package.
+type InfoDependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Source  string `json:"source,omitempty"`
+}
+
+// InfoOptions contains the options for the Info method.
+type InfoOptions struct {
+	// Source is the source of the package to inspect.
+	Source *downloader.Source
+	// Registries are the named registries in kcl.mod to resolve the source with only the package spec.
+	Registries []settings.OciRegistry
+}
+
+type InfoOption func(*InfoOptions) error
+
+// WithInfoSource sets the source of the package to inspect for the Info method.
+func WithInfoSource(source *downloader.Source) InfoOption {
+	return func(opts *InfoOptions) error {
+		if source == nil {
+			return errors.New("source cannot be nil")
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithInfoSourceUrl sets the url of the package to inspect for the Info method,
+// e.g. 'oci://ghcr.io/kcl-lang/helloworld?tag=0.1.0' or 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git'.
+func WithInfoSourceUrl(sourceUrl string) InfoOption {
+	return func(opts *InfoOptions) error {
+		source, err := downloader.NewSourceFromStr(sourceUrl)
+		if err != nil {
+			return err
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithInfoRegistries sets the named registries in kcl.mod to resolve the source with only the package spec for the Info method.
+func WithInfoRegistries(registries ...settings.OciRegistry) InfoOption {
+	return func(opts *InfoOptions) error {
+		opts.Registries = registries
+		return nil
+	}
+}
+
+// Info inspects the remote package without downloading it. For the OCI packages, only the tags, the manifest and
+// the kcl.mod in the layer are fetched. For the git packages, only the kcl.mod of the ref is fetched where possible.
+// The latest version is inspected if the version of the package is not specified.
+func (c *KpmClient) Info(options ...InfoOption) (*PackageInfo, error) {
+	return c.InfoWithContext(context.Background(), options...)
+}
+
+// InfoWithContext is the same as Info, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) InfoWithContext(ctx context.Context, options ...InfoOption) (*PackageInfo, error) {
+	opts := &InfoOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Source == nil {
+		return nil, errors.New("the package to inspect is required")
+	}
+
+	c.resolveSpecOnlySource(opts.Source, opts.Registries...)
+
+	if opts.Source.Oci != nil {
+		return c.ociInfo(ctx, opts.Source.Oci)
+	}
+	if opts.Source.Git != nil {
+		return c.gitInfo(ctx, opts.Source.Git)
+	}
+
+	sourceStr, _ := opts.Source.ToString()
+	return nil, fmt.Errorf("'%s' is not an oci or git source, only support oci and git sources", sourceStr)
+}
+
+// ociInfo inspects the package in the OCI registry by its tags, manifest and the kcl.mod in the layer.
+func (c *KpmClient) ociInfo(ctx context.Context, ociSource *downloader.Oci) (*PackageInfo, error) {
+	ociCli, err := c.newOciClient(ctx, ociSource)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := ociCli.ListTags()
+	if err != nil {
+		return nil, reporter.NewErrorEvent(
+			reporter.FailedGetPackageVersions,
+			err,
+			fmt.Sprintf("failed to list the tags of '%s'", ociCli.GetReference()),
+		)
+	}
+
+	tag := ociSource.Tag
+	if tag == "" {
+		tag, err = semver.LatestVersion(tags)
+		if err != nil {
+			return nil, reporter.NewErrorEvent(
+				reporter.FailedSelectLatestVersion,
+				err,
+				fmt.Sprintf("failed to select latest version from '%s'", ociCli.GetReference()),
+			)
+		}
+	}
+
+	manifestJson, err := ociCli.FetchManifestIntoJsonStr(opt.OciFetchOptions{
+		FetchBytesOptions: oras.DefaultFetchBytesOptions,
+		OciOptions: opt.OciOptions{
+			Reg:  ociSource.Reg,
+			Repo: ociSource.Repo,
+			Tag:  tag,
+		},
+	})
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedFetchOciManifest, err, fmt.Sprintf("failed to fetch the manifest of '%s:%s'", ociSource.Repo, tag))
+	}
+
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal([]byte(manifestJson), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	// The source is the url of the package without the tag.
+	sourceUrl, err := (&downloader.Oci{Reg: ociSource.Reg, Repo: ociSource.Repo, Layout: ociSource.Layout}).ToString()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &PackageInfo{
+		Name:        manifest.Annotations[constants.DEFAULT_KCL_OCI_MANIFEST_NAME],
+		Version:     tag,
+		Description: manifest.Annotations[constants.DEFAULT_KCL_OCI_MANIFEST_DESCRIPTION],
+		Source:      sourceUrl,
+		Versions:    semver.SortVersions(tags),
+		Sum:         manifest.Annotations[constants.DEFAULT_KCL_OCI_MANIFEST_SUM],
+		Created:     manifest.Annotations[ocispec.AnnotationCreated],
+	}
+
+	var kclMod []byte
+	for _, layer := range manifest.Layers {
+		// The docs pushed with the package are not a part of the package.
+		if layer.MediaType == oci.OCI_DOCS_MEDIA_TYPE {
+			continue
+		}
+		info.Size += layer.Size
+		if kclMod != nil {
+			continue
+		}
+		content, err := ociCli.ReadLayerFile(layer, constants.KCL_MOD)
+		if err != nil {
+			reporter.ReportMsgTo(fmt.Sprintf("skip the layer '%s': %v", layer.Digest, err), c.GetLogWriter())
+			continue
+		}
+		kclMod = content
+	}
+	if kclMod == nil {
+		return nil, fmt.Errorf("'%s' not found in '%s:%s'", constants.KCL_MOD, ociCli.GetReference(), tag)
+	}
+
+	if err := info.fillFromModFile(kclMod); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// gitInfo inspects the package in the git repository by its releases and the kcl.mod of the ref.
+func (c *KpmClient) gitInfo(ctx context.Context, gitSource *downloader.Git) (*PackageInfo, error) {
+	gitUrl, err := gitSource.GetCanonicalizedUrl()
+	if err != nil {
+		return nil, err
+	}
+	credCli, err := c.GetCredsClient()
+	if err != nil {
+		return nil, err
+	}
+	gitCred, err := credCli.GitCredential(gitUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	releases, err := git.ListReleasesWithContext(ctx, git.ReleaseListerWithCredential(git.GetReleaseLister(gitUrl), gitCred), gitUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the releases of '%s': %w", gitSource.Url, err)
+	}
+
+	cloneOpts := &git.CloneOptions{
+		RepoURL:    gitUrl,
+		Branch:     gitSource.Branch,
+		Commit:     gitSource.Commit,
+		Tag:        gitSource.Tag,
+		Package:    gitSource.Package,
+		Credential: gitCred,
+		Writer:     c.GetLogWriter(),
+		Context:    ctx,
+	}
+	version := gitSource.GetRef()
+	if version == "" && len(releases) > 0 {
+		// The default branch is inspected if the releases are not semantic versions.
+		if latest, err := semver.LatestVersion(releases); err == nil {
+			cloneOpts.Tag = latest
+			version = latest
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "info")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	cloneOpts.LocalPath = filepath.Join(tmpDir, constants.GitScheme)
+
+	reporter.ReportMsgTo(fmt.Sprintf("fetching '%s' from '%s'", constants.KCL_MOD, gitSource.Url), c.GetLogWriter())
+	kclMod, _, err := cloneOpts.ReadModFile()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &PackageInfo{
+		Version:  version,
+		Source:   gitSource.Url,
+		Versions: semver.SortVersions(releases),
+	}
+	if err := info.fillFromModFile([]byte(kclMod)); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// fillFromModFile fills the name, the description and the dependencies of the package from the content of its kcl.mod.
+func (info *PackageInfo) fillFromModFile(content []byte) error {
+	modFile := pkg.ModFile{}
+	if err := toml.Unmarshal(content, &modFile); err != nil {
+		return fmt.Errorf("failed to parse '%s': %w", constants.KCL_MOD, err)
+	}
+
+	if modFile.Pkg.Name != "" {
+		info.Name = modFile.Pkg.Name
+	}
+	if info.Description == "" {
+		info.Description = modFile.Pkg.Description
+	}
+	if info.Version == "" {
+		info.Version = modFile.Pkg.Version
+	}
+
+	if modFile.Deps == nil {
+		return nil
+	}
+	for _, name := range modFile.Deps.Keys() {
+		dep, _ := modFile.Deps.Get(name)
+		source, _ := dep.Source.ToString()
+		info.Dependencies = append(info.Dependencies, InfoDependency{
+			Name:    name,
+			Version: dep.Version,
+			Source:  source,
+		})
+	}
+
+	return nil
+}
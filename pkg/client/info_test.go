@@ -0,0 +1,165 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/settings"
+)
+
+const testInfoKclMod = `[package]
+name = "helloworld"
+edition = "v0.9.0"
+version = "0.1.1"
+description = "This is the hello world package"
+
+[dependencies]
+k8s = "1.31.2"
+`
+
+// newTestPackageRegistry starts a registry serving the package 'kcl-lang/helloworld' whose layer contains the 'files',
+// and returns its host.
+func newTestPackageRegistry(t *testing.T, tags []string, files map[string]string) string {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		assert.Nil(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tw.Close())
+	assert.Nil(t, gw.Close())
+	layer := buf.Bytes()
+	layerDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(layer))
+
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     ocispec.MediaTypeImageManifest,
+		"config":        ocispec.DescriptorEmptyJSON,
+		"layers": []map[string]interface{}{{
+			"mediaType": ocispec.MediaTypeImageLayerGzip,
+			"digest":    layerDigest,
+			"size":      len(layer),
+		}},
+		"annotations": map[string]string{
+			constants.DEFAULT_KCL_OCI_MANIFEST_NAME: "helloworld",
+			constants.DEFAULT_KCL_OCI_MANIFEST_SUM:  "sum",
+			ocispec.AnnotationCreated:               "2024-01-01T00:00:00Z",
+		},
+	})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v2/kcl-lang/helloworld/")
+		switch {
+		case path == "tags/list":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": "kcl-lang/helloworld", "tags": tags})
+		case strings.HasPrefix(path, "manifests/"):
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(manifest)))
+			if r.Method != http.MethodHead {
+				_, _ = w.Write(manifest)
+			}
+		case path == "blobs/"+layerDigest:
+			w.Header().Set("Content-Length", fmt.Sprint(len(layer)))
+			_, _ = w.Write(layer)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	// The plain http is used for the localhost registry.
+	return strings.Replace(strings.TrimPrefix(server.URL, "http://"), "127.0.0.1", "localhost", 1)
+}
+
+func TestInfoOci(t *testing.T) {
+	reg := newTestPackageRegistry(t, []string{"0.1.1", "0.1.0", "latest"}, map[string]string{
+		constants.KCL_MOD: testInfoKclMod,
+		"main.k":          "a = 1",
+	})
+
+	kpmcli, err := NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	info, err := kpmcli.Info(WithInfoSourceUrl(fmt.Sprintf("oci://%s/kcl-lang/helloworld", reg)))
+	assert.Nil(t, err)
+	assert.Equal(t, "helloworld", info.Name)
+	assert.Equal(t, "0.1.1", info.Version)
+	assert.Equal(t, "This is the hello world package", info.Description)
+	assert.Equal(t, fmt.Sprintf("oci://%s/kcl-lang/helloworld", reg), info.Source)
+	assert.Equal(t, []string{"0.1.0", "0.1.1", "latest"}, info.Versions)
+	assert.Equal(t, "sum", info.Sum)
+	assert.Equal(t, "2024-01-01T00:00:00Z", info.Created)
+	assert.NotZero(t, info.Size)
+	assert.Equal(t, 1, len(info.Dependencies))
+	assert.Equal(t, "k8s", info.Dependencies[0].Name)
+	assert.Equal(t, "1.31.2", info.Dependencies[0].Version)
+
+	// The package with only the spec is resolved by the named registries in kcl.mod.
+	info, err = kpmcli.Info(
+		WithInfoSource(&downloader.Source{ModSpec: &downloader.ModSpec{Name: "helloworld", Version: "0.1.0"}}),
+		WithInfoRegistries(settings.OciRegistry{Name: "test", Registry: reg, Repo: "kcl-lang", Scopes: []string{"helloworld"}}),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1.0", info.Version)
+	assert.Equal(t, fmt.Sprintf("oci://%s/kcl-lang/helloworld", reg), info.Source)
+
+	_, err = kpmcli.Info(WithInfoSource(&downloader.Source{Http: &downloader.Http{HttpUrl: "https://example.com/a.tar.gz"}}))
+	assert.NotNil(t, err)
+}
+
+func TestInfoGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@test.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(output))
+	}
+	runGit("init", "-q")
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, constants.KCL_MOD), []byte(strings.Replace(testInfoKclMod, "0.1.1", "0.1.0", 1)), 0644))
+	runGit("add", "-A")
+	runGit("commit", "-q", "-m", "v0.1.0")
+	runGit("tag", "v0.1.0")
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, constants.KCL_MOD), []byte(testInfoKclMod), 0644))
+	runGit("commit", "-q", "-am", "v0.1.1")
+	runGit("tag", "v0.1.1")
+
+	kpmcli, err := NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	info, err := kpmcli.Info(WithInfoSource(&downloader.Source{Git: &downloader.Git{Url: repo}}))
+	assert.Nil(t, err)
+	assert.Equal(t, "helloworld", info.Name)
+	assert.Equal(t, "v0.1.1", info.Version)
+	assert.Equal(t, []string{"v0.1.0", "v0.1.1"}, info.Versions)
+	assert.Equal(t, 1, len(info.Dependencies))
+
+	info, err = kpmcli.Info(WithInfoSource(&downloader.Source{Git: &downloader.Git{Url: repo, Tag: "v0.1.0"}}))
+	assert.Nil(t, err)
+	assert.Equal(t, "v0.1.0", info.Version)
+	assert.Equal(t, "This is the hello world package", info.Description)
+}
@@ -1,15 +1,21 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/template"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -19,6 +25,17 @@ type InitOptions struct {
 	ModName    string
 	ModVersion string
 	WorkDir    string
+	// Template is the name of the built-in template or the url of the template package,
+	// the package is scaffolded from the template if it is not empty.
+	Template string
+	// ExternalPkgs are the '<name>=<path>' args of the external packages used by the existing kcl project,
+	// which are added as the dependencies.
+	ExternalPkgs []string
+	// ResolveOci resolves the external packages into the registry dependencies if they are published with the same versions.
+	ResolveOci bool
+	// Confirm is called with the proposed kcl.mod of the existing kcl project before it is written,
+	// the initialization is canceled if it returns false.
+	Confirm func(modFile string) (bool, error)
 }
 
 type InitOption func(*InitOptions) error
@@ -44,6 +61,41 @@ func WithInitModPath(modPath string) InitOption {
 	}
 }
 
+// WithInitTemplate scaffolds the package from 'template', which is the name of a built-in template, e.g. 'lib', 'k8s' and 'policy',
+// or the url of the template package, e.g. 'https://github.com/kcl-lang/templates.git?tag=v0.1.0' and 'oci://ghcr.io/kcl-lang/templates?tag=0.1.0'.
+func WithInitTemplate(template string) InitOption {
+	return func(opts *InitOptions) error {
+		opts.Template = template
+		return nil
+	}
+}
+
+// WithInitExternalPkgs adds the external packages of the existing kcl project as the local dependencies,
+// e.g. 'k8s=./external/k8s' passed to the kcl cli by '-E k8s=./external/k8s'.
+func WithInitExternalPkgs(externalPkgs []string) InitOption {
+	return func(opts *InitOptions) error {
+		opts.ExternalPkgs = externalPkgs
+		return nil
+	}
+}
+
+// WithInitResolveOci resolves the external packages of the existing kcl project into the registry dependencies
+// if they are published to the registry with the same versions.
+func WithInitResolveOci(resolveOci bool) InitOption {
+	return func(opts *InitOptions) error {
+		opts.ResolveOci = resolveOci
+		return nil
+	}
+}
+
+// WithInitConfirm sets the function to confirm the proposed kcl.mod of the existing kcl project before it is written.
+func WithInitConfirm(confirm func(modFile string) (bool, error)) InitOption {
+	return func(opts *InitOptions) error {
+		opts.Confirm = confirm
+		return nil
+	}
+}
+
 func WithInitModName(modName string) InitOption {
 	return func(opts *InitOptions) error {
 		opts.ModName = modName
@@ -52,6 +104,11 @@ func WithInitModName(modName string) InitOption {
 }
 
 func (c *KpmClient) Init(options ...InitOption) error {
+	return c.InitWithContext(context.Background(), options...)
+}
+
+// InitWithContext is the same as Init, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) InitWithContext(ctx context.Context, options ...InitOption) error {
 	opts := &InitOptions{}
 	for _, option := range options {
 		if err := option(opts); err != nil {
@@ -98,7 +155,62 @@ func (c *KpmClient) Init(options ...InitOption) error {
 		Version:  modVer,
 	})
 
-	return c.InitEmptyPkg(&kclPkg)
+	if len(opts.Template) != 0 {
+		return c.InitPkgFromTemplateWithContext(ctx, &kclPkg, opts.Template)
+	}
+	// The existing package is not migrated.
+	if utils.FileExists(kclPkg.ModFile.GetModFilePath()) {
+		return c.InitEmptyPkg(&kclPkg)
+	}
+
+	project, err := detectProject(modPath, workDir, opts.ExternalPkgs)
+	if err != nil {
+		return err
+	}
+	if project.isEmpty() {
+		return c.InitEmptyPkg(&kclPkg)
+	}
+	return c.initPkgFromProject(&kclPkg, project, opts)
+}
+
+// initPkgFromProject will initialize the kcl package from the existing kcl project without kcl.mod,
+// the proposed kcl.mod is shown and confirmed by 'opts.Confirm' before it is written.
+func (c *KpmClient) initPkgFromProject(kclPkg *pkg.KclPkg, project *existingProject, opts *InitOptions) error {
+	err := c.migrateProject(kclPkg, project, opts.ResolveOci)
+	if err != nil {
+		return err
+	}
+
+	modFile := kclPkg.ModFile.MarshalTOML()
+	reporter.ReportMsgTo(
+		fmt.Sprintf("the kcl project is found in '%s', the proposed %s:\n%s", kclPkg.HomePath, constants.KCL_MOD, modFile),
+		c.GetLogWriter(),
+	)
+	if opts.Confirm != nil {
+		ok, err := opts.Confirm(modFile)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return reporter.NewErrorEvent(reporter.InitCanceled, fmt.Errorf("init is canceled"), fmt.Sprintf("the initialization of '%s' is canceled", kclPkg.HomePath))
+		}
+	}
+
+	err = c.createIfNotExist(kclPkg.ModFile.GetModFilePath(), kclPkg.ModFile.StoreModFile)
+	if err != nil {
+		return err
+	}
+
+	err = c.createIfNotExist(kclPkg.ModFile.GetModLockFilePath(), kclPkg.LockDepsVersion)
+	if err != nil {
+		return err
+	}
+
+	// The default 'main.k' is not added next to the existing kcl files.
+	if len(project.kFiles) == 0 {
+		return c.createIfNotExist(filepath.Join(kclPkg.ModFile.HomePath, constants.DEFAULT_KCL_FILE_NAME), kclPkg.CreateDefaultMain)
+	}
+	return nil
 }
 
 // createIfNotExist will create a file if it does not exist.
@@ -142,3 +254,136 @@ func (c *KpmClient) InitEmptyPkg(kclPkg *pkg.KclPkg) error {
 
 	return nil
 }
+
+// InitPkgFromTemplate will initialize the kcl package from the template 'tmplName',
+// which is the name of a built-in template or the url of a template package fetched by the downloader.
+// The files existing in the package are not overwritten.
+func (c *KpmClient) InitPkgFromTemplate(kclPkg *pkg.KclPkg, tmplName string) error {
+	return c.InitPkgFromTemplateWithContext(context.Background(), kclPkg, tmplName)
+}
+
+// InitPkgFromTemplateWithContext is the same as InitPkgFromTemplate,
+// and the download of the template is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) InitPkgFromTemplateWithContext(ctx context.Context, kclPkg *pkg.KclPkg, tmplName string) error {
+	tmplFS, cleanup, err := c.loadTemplate(ctx, tmplName)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	modFilePath := kclPkg.ModFile.GetModFilePath()
+	modFileExists := utils.FileExists(modFilePath)
+
+	values := template.Values{
+		Name:    kclPkg.ModFile.Pkg.Name,
+		Version: kclPkg.ModFile.Pkg.Version,
+		Edition: kclPkg.ModFile.Pkg.Edition,
+	}
+	err = template.Render(tmplFS, values, func(path string, content []byte) error {
+		filePath := filepath.Join(kclPkg.HomePath, filepath.FromSlash(path))
+		return c.createIfNotExist(filePath, func() error {
+			if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+				return err
+			}
+			return os.WriteFile(filePath, content, 0644)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.createIfNotExist(modFilePath, kclPkg.ModFile.StoreModFile)
+	if err != nil {
+		return err
+	}
+
+	tmplPkg, err := pkg.LoadKclPkgWithOpts(
+		pkg.WithPath(kclPkg.HomePath),
+		pkg.WithSettings(c.GetSettings()),
+	)
+	if err != nil {
+		return err
+	}
+
+	// The template packages fetched from the registries are kcl packages with their own names and versions,
+	// which are replaced by the name and the version of the new package.
+	if !modFileExists && (tmplPkg.ModFile.Pkg.Name != values.Name || tmplPkg.ModFile.Pkg.Version != values.Version) {
+		tmplPkg.ModFile.Pkg.Name = values.Name
+		tmplPkg.ModFile.Pkg.Version = values.Version
+		if err := tmplPkg.ModFile.StoreModFile(); err != nil {
+			return err
+		}
+	}
+
+	return c.createIfNotExist(tmplPkg.ModFile.GetModLockFilePath(), tmplPkg.LockDepsVersion)
+}
+
+// loadTemplate returns the files of the template 'tmplName' and the function to clean up the downloaded template.
+func (c *KpmClient) loadTemplate(ctx context.Context, tmplName string) (fs.FS, func(), error) {
+	if tmplFS, ok := template.Builtin(tmplName); ok {
+		return tmplFS, func() {}, nil
+	}
+
+	source, err := downloader.NewSourceFromStr(tmplName)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The http urls except the archives are the urls of the git repositories,
+	// e.g. 'https://github.com/kcl-lang/templates.git?tag=v0.1.0'.
+	if tmplUrl, err := url.Parse(tmplName); err == nil && source.Http == nil &&
+		(tmplUrl.Scheme == constants.HttpScheme || tmplUrl.Scheme == constants.HttpsScheme) {
+		query := tmplUrl.Query()
+		tmplUrl.RawQuery = ""
+		source = &downloader.Source{
+			Git: &downloader.Git{
+				Url:    tmplUrl.String(),
+				Tag:    query.Get(constants.Tag),
+				Commit: query.Get(constants.GitCommit),
+				Branch: query.Get(constants.GitBranch),
+			},
+		}
+	}
+
+	if source.IsLocalPath() {
+		if !utils.DirExists(source.Local.Path) {
+			return nil, nil, fmt.Errorf(
+				"template '%s' not found, the built-in templates are: %s",
+				tmplName, strings.Join(template.BuiltinNames(), ", "),
+			)
+		}
+		return os.DirFS(source.Local.Path), func() {}, nil
+	}
+
+	if source.SpecOnly() {
+		c.resolveSpecOnlySource(source)
+		source.ModSpec = nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kpm_template")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(tmpDir) }
+
+	credCli, err := c.GetCredsClient()
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	reporter.ReportMsgTo(fmt.Sprintf("downloading template '%s'", tmplName), c.GetLogWriter())
+	err = c.DepDownloader.Download(ctx, downloader.NewDownloadOptions(
+		downloader.WithLocalPath(tmpDir),
+		downloader.WithSource(*source),
+		downloader.WithLogWriter(c.GetLogWriter()),
+		downloader.WithSettings(*c.GetSettings()),
+		downloader.WithCredsClient(credCli),
+		downloader.WithInsecureSkipTLSverify(c.insecureSkipTLSverify),
+	))
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return os.DirFS(tmpDir), cleanup, nil
+}
@@ -7,8 +7,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/otiai10/copy"
 	"github.com/stretchr/testify/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/mock"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/runner"
 	"kcl-lang.io/kpm/pkg/utils"
@@ -293,3 +295,232 @@ func TestModInitWithExistFile(t *testing.T) {
 
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestModInitWithExitModFile", TestFunc: testFunc}})
 }
+
+func TestInitWithTemplate(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		var buf bytes.Buffer
+		kpmcli.SetLogWriter(&buf)
+
+		workDir := t.TempDir()
+		err := kpmcli.Init(
+			WithInitWorkDir(workDir),
+			WithInitModName("policy_demo"),
+			WithInitTemplate("policy"),
+		)
+		assert.Nil(t, err)
+
+		modPath := filepath.Join(workDir, "policy_demo")
+		for _, file := range []string{"kcl.mod", "kcl.mod.lock", "main.k", "main_test.k"} {
+			assert.True(t, utils.FileExists(filepath.Join(modPath, file)), file)
+		}
+		kmod, err := pkg.LoadKclPkgWithOpts(pkg.WithPath(modPath))
+		assert.Nil(t, err)
+		assert.Equal(t, "policy_demo", kmod.ModFile.Pkg.Name)
+		assert.Equal(t, "0.0.1", kmod.ModFile.Pkg.Version)
+		assert.Equal(t, runner.GetKclVersion(), kmod.ModFile.Pkg.Edition)
+
+		// The template package is rendered with the name and the version of the new package.
+		customPath := filepath.Join(workDir, "custom_demo")
+		err = kpmcli.Init(
+			WithInitModPath(customPath),
+			WithInitModVersion("0.2.0"),
+			WithInitTemplate(filepath.Join(getTestDir("test_init_template"), "custom")),
+		)
+		assert.Nil(t, err)
+		kmod, err = pkg.LoadKclPkgWithOpts(pkg.WithPath(customPath))
+		assert.Nil(t, err)
+		assert.Equal(t, "custom_demo", kmod.ModFile.Pkg.Name)
+		assert.Equal(t, "0.2.0", kmod.ModFile.Pkg.Version)
+		assert.Equal(t, "v0.9.0", kmod.ModFile.Pkg.Edition)
+		mainK, err := os.ReadFile(filepath.Join(customPath, "main.k"))
+		assert.Nil(t, err)
+		assert.Equal(t, "name = \"custom_demo\"\nversion = \"0.2.0\"\n", string(mainK))
+		assert.False(t, utils.DirExists(filepath.Join(customPath, "main.k.tmpl")))
+
+		err = kpmcli.Init(
+			WithInitModPath(filepath.Join(workDir, "unknown")),
+			WithInitTemplate("unknown"),
+		)
+		assert.ErrorContains(t, err, "template 'unknown' not found")
+	}
+
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestInitWithTemplate", TestFunc: testFunc}})
+}
+
+func TestInitWithGitTemplate(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		server, err := mock.NewGitServer()
+		if err != nil {
+			t.Skipf("Skipping test without the git server: %v", err)
+		}
+		defer server.Close()
+		_, err = server.Seed("custom", filepath.Join(getTestDir("test_init_template"), "custom"), "v0.1.0")
+		assert.Nil(t, err)
+
+		var buf bytes.Buffer
+		kpmcli.SetLogWriter(&buf)
+
+		modPath := filepath.Join(t.TempDir(), "git_demo")
+		err = kpmcli.Init(
+			WithInitModPath(modPath),
+			WithInitTemplate(server.RepoUrl("custom")+"?tag=v0.1.0"),
+		)
+		assert.Nil(t, err)
+		assert.Contains(t, buf.String(), "downloading template")
+		assert.False(t, utils.DirExists(filepath.Join(modPath, ".git")))
+
+		kmod, err := pkg.LoadKclPkgWithOpts(pkg.WithPath(modPath))
+		assert.Nil(t, err)
+		assert.Equal(t, "git_demo", kmod.ModFile.Pkg.Name)
+		mainK, err := os.ReadFile(filepath.Join(modPath, "main.k"))
+		assert.Nil(t, err)
+		assert.Equal(t, "name = \"git_demo\"\nversion = \"0.0.1\"\n", string(mainK))
+	}
+
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestInitWithGitTemplate", TestFunc: testFunc}})
+}
+
+func TestInitMigrateProject(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		var buf bytes.Buffer
+		kpmcli.SetLogWriter(&buf)
+
+		projectPath := filepath.Join(t.TempDir(), "project")
+		assert.Nil(t, copy.Copy(filepath.Join(getTestDir("test_init_migrate"), "project"), projectPath))
+
+		// The proposed kcl.mod is not written if it is not confirmed.
+		var proposed string
+		err := kpmcli.Init(
+			WithInitModPath(projectPath),
+			WithInitConfirm(func(modFile string) (bool, error) {
+				proposed = modFile
+				return false, nil
+			}),
+		)
+		assert.ErrorContains(t, err, "is canceled")
+		assert.Contains(t, buf.String(), "the proposed kcl.mod")
+		assert.Contains(t, proposed, `helper = { path = "libs/helper" }`)
+		assert.Contains(t, proposed, `k8s = { path = "external/k8s" }`)
+		assert.False(t, utils.DirExists(filepath.Join(projectPath, "kcl.mod")))
+
+		err = kpmcli.Init(
+			WithInitModPath(projectPath),
+			WithInitConfirm(func(modFile string) (bool, error) {
+				return true, nil
+			}),
+		)
+		assert.Nil(t, err)
+
+		kmod, err := pkg.LoadKclPkgWithOpts(pkg.WithPath(projectPath))
+		assert.Nil(t, err)
+		assert.Equal(t, "project", kmod.ModFile.Pkg.Name)
+		assert.Equal(t, []string{"main.k", "base.k"}, *kmod.ModFile.Profiles.Entries)
+		assert.True(t, *kmod.ModFile.Profiles.DisableNone)
+		assert.Equal(t, []string{"env=prod", "replicas=3"}, *kmod.ModFile.Profiles.Options)
+
+		helper, ok := kmod.ModFile.Dependencies.Deps.Get("helper")
+		assert.True(t, ok)
+		assert.Equal(t, filepath.Join("libs", "helper"), helper.Source.Local.Path)
+		k8s, ok := kmod.ModFile.Dependencies.Deps.Get("k8s")
+		assert.True(t, ok)
+		assert.Equal(t, filepath.Join("external", "k8s"), k8s.Source.Local.Path)
+
+		mainK, err := os.ReadFile(filepath.Join(projectPath, "main.k"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(mainK), "import helper")
+	}
+
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestInitMigrateProject", TestFunc: testFunc}})
+}
+
+func TestDetectProjectWithDirs(t *testing.T) {
+	projectPath := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(projectPath, "main.k"), []byte("a = 1\n"), 0644))
+	// The directories named 'kcl.yaml' and 'kcl.mod' are not the settings file and the vendored packages.
+	assert.Nil(t, os.MkdirAll(filepath.Join(projectPath, "kcl.yaml"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(projectPath, "external", "bad", "kcl.mod"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(projectPath, "external", "good"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(projectPath, "external", "good", "kcl.mod"), []byte("[package]\nname = \"good\"\n"), 0644))
+
+	project, err := detectProject(projectPath, projectPath, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, project.profile)
+	assert.Equal(t, []externalPkg{{name: "good", path: filepath.Join(projectPath, "external", "good")}}, project.externalPkgs)
+}
+
+func TestInitMigrateProjectWithResolveOci(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		reg, err := mock.NewOciRegistry()
+		if err != nil {
+			t.Fatalf("Error starting the oci registry: %v", err)
+		}
+		defer reg.Close()
+		reg.Configure(kpmcli.GetSettings())
+
+		var buf bytes.Buffer
+		kpmcli.SetLogWriter(&buf)
+
+		projectPath := filepath.Join(t.TempDir(), "project")
+		assert.Nil(t, copy.Copy(filepath.Join(getTestDir("test_init_migrate"), "project"), projectPath))
+		// Only the vendored 'k8s' is published to the registry.
+		err = kpmcli.Push(
+			WithPushModPath(filepath.Join(projectPath, "external", "k8s")),
+			WithPushSource(downloader.Source{
+				Oci: &downloader.Oci{
+					Reg:  reg.Host,
+					Repo: mock.DEFAULT_OCI_REPO + "/k8s",
+				},
+			}),
+		)
+		assert.Nil(t, err)
+
+		otherPath := filepath.Join(t.TempDir(), "other")
+		assert.Nil(t, copy.Copy(filepath.Join(projectPath, "libs", "helper"), otherPath))
+
+		err = kpmcli.Init(
+			WithInitModPath(projectPath),
+			WithInitWorkDir(filepath.Dir(otherPath)),
+			WithInitExternalPkgs([]string{"utils=other"}),
+			WithInitResolveOci(true),
+		)
+		assert.Nil(t, err)
+
+		kmod, err := pkg.LoadKclPkgWithOpts(pkg.WithPath(projectPath))
+		assert.Nil(t, err)
+		k8s, ok := kmod.ModFile.Dependencies.Deps.Get("k8s")
+		assert.True(t, ok)
+		assert.Equal(t, "1.28", k8s.Version)
+		assert.Nil(t, k8s.Source.Local)
+		assert.Equal(t, "1.28", k8s.Source.ModSpec.Version)
+
+		helper, ok := kmod.ModFile.Dependencies.Deps.Get("helper")
+		assert.True(t, ok)
+		assert.Equal(t, filepath.Join("libs", "helper"), helper.Source.Local.Path)
+		utilsDep, ok := kmod.ModFile.Dependencies.Deps.Get("utils")
+		assert.True(t, ok)
+		assert.Equal(t, otherPath, utilsDep.Source.Local.Path)
+		assert.Contains(t, buf.String(), "'helper 0.1.0' is not found in the registry")
+	}
+
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestInitMigrateProjectWithResolveOci", TestFunc: testFunc}})
+}
+
+func TestExternalPkgIntoDepWithDotDotName(t *testing.T) {
+	kpmcli, err := NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	homePath := t.TempDir()
+	inside := filepath.Join(homePath, "..helper")
+	outside := filepath.Join(t.TempDir(), "helper")
+	assert.Nil(t, os.MkdirAll(inside, 0755))
+	assert.Nil(t, os.MkdirAll(outside, 0755))
+
+	dep, err := kpmcli.externalPkgIntoDep(homePath, externalPkg{name: "helper", path: inside}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "..helper", dep.Source.Local.Path)
+
+	dep, err = kpmcli.externalPkgIntoDep(homePath, externalPkg{name: "helper", path: outside}, false)
+	assert.Nil(t, err)
+	assert.Equal(t, outside, dep.Source.Local.Path)
+}
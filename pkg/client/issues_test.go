@@ -82,16 +82,16 @@ func TestKclIssue1760(t *testing.T) {
 
 			assert.Contains(t,
 				utils.RmNewline(buf.String()),
-				"downloading 'kcl-lang/fluxcd-source-controller:v1.3.2' from 'ghcr.io/kcl-lang/fluxcd-source-controller:v1.3.2'",
+				"downloading 'kcl-lang/fluxcd-source-controller:v1.3.2' from 'localhost:5101/kcl-lang/fluxcd-source-controller:v1.3.2'",
 			)
 			assert.Contains(t,
 				utils.RmNewline(buf.String()),
-				"downloading 'kcl-lang/k8s:1.31.2' from 'ghcr.io/kcl-lang/k8s:1.31.2'",
+				"downloading 'kcl-lang/k8s:1.31.2' from 'localhost:5101/kcl-lang/k8s:1.31.2'",
 			)
 
 			assert.Contains(t,
 				utils.RmNewline(buf.String()),
-				"downloading 'kcl-lang/fluxcd-helm-controller:v1.0.3' from 'ghcr.io/kcl-lang/fluxcd-helm-controller:v1.0.3'",
+				"downloading 'kcl-lang/fluxcd-helm-controller:v1.0.3' from 'localhost:5101/kcl-lang/fluxcd-helm-controller:v1.0.3'",
 			)
 			assert.Equal(t, res.GetRawYamlResult(), "The_first_kcl_program: Hello World!")
 		}
@@ -123,8 +123,8 @@ func TestKpmIssue550(t *testing.T) {
 				features.Enable(features.SupportNewStorage)
 				features.Disable(features.SupportMVS)
 			},
-			expected:    filepath.Join("git", "src", "200297ed26e4aeb7", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
-			winExpected: filepath.Join("git", "src", "3523a44a55384201", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
+			expected:    filepath.Join("git", "src", "980ff72d5a133c05", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
+			winExpected: filepath.Join("git", "src", "ac446d76d8a07030", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
 		},
 		{
 			name: "SupportMVS",
@@ -141,8 +141,8 @@ func TestKpmIssue550(t *testing.T) {
 				features.Enable(features.SupportNewStorage)
 				features.Enable(features.SupportMVS)
 			},
-			expected:    filepath.Join("git", "src", "200297ed26e4aeb7", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
-			winExpected: filepath.Join("git", "src", "3523a44a55384201", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
+			expected:    filepath.Join("git", "src", "980ff72d5a133c05", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
+			winExpected: filepath.Join("git", "src", "ac446d76d8a07030", "flask-demo-kcl-manifests", "test-branch-without-modfile", "aa", "cc"),
 		},
 	}
 
@@ -198,7 +198,7 @@ func TestKpmIssue550(t *testing.T) {
 			fmt.Printf("buf.String(): %v\n", buf.String())
 			assert.Contains(t,
 				utils.RmNewline(buf.String()),
-				"cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with branch 'test-branch-without-modfile'",
+				"cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with branch 'test-branch-without-modfile'",
 			)
 			assert.Equal(t, len(resMap), 1)
 			if runtime.GOOS == "windows" {
@@ -254,8 +254,8 @@ func TestKpmIssue226(t *testing.T) {
 			WithAddSource(
 				&downloader.Source{
 					Git: &downloader.Git{
-						Url:    "https://github.com/kcl-lang/flask-demo-kcl-manifests.git",
-						Commit: "ade147b",
+						Url:    "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git",
+						Commit: "2d93a6a",
 					},
 				},
 			),
@@ -266,7 +266,7 @@ func TestKpmIssue226(t *testing.T) {
 		}
 
 		assert.Equal(t, utils.RmNewline(buf.String()),
-			"cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit 'ade147b'"+
+			"cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '2d93a6a'"+
 				"adding dependency 'flask_manifests'"+
 				"add dependency 'flask_manifests:0.0.1' successfully")
 
@@ -337,7 +337,7 @@ func TestKpmIssue226(t *testing.T) {
 		}
 
 		assert.Equal(t, utils.RmNewline(buf.String()),
-			"cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit 'ade147b'")
+			"cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '2d93a6a'")
 
 		modFileContent, err := os.ReadFile(modFile)
 		if err != nil {
@@ -404,7 +404,7 @@ func TestKpmIssue226(t *testing.T) {
 		assert.Equal(t, err.Error(), "package 'flask_manifests:0.100.0' not found")
 
 		assert.Equal(t, utils.RmNewline(buf.String()),
-			"cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with commit 'ade147b'")
+			"cloning 'https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with commit '2d93a6a'")
 
 		modFileContent, err := os.ReadFile(modFile)
 		if err != nil {
@@ -437,31 +437,21 @@ func TestKpmIssue226(t *testing.T) {
 func TestKclIssue1768(t *testing.T) {
 	testPath := "github.com/kcl-lang/kcl/issues/1768"
 	test_push_with_tag := func(t *testing.T, kpmcli *KpmClient) {
-		if runtime.GOOS == "windows" {
-			t.Skip("Skipping test on Windows")
-		}
-		err := mock.StartDockerRegistry()
-		if err != nil {
-			t.Errorf("Error starting docker registry: %v", err)
-		}
-
-		defer func() {
-			err = mock.CleanTestEnv()
-			if err != nil {
-				t.Errorf("Error stopping docker registry: %v", err)
-			}
-		}()
-
-		kpmcli.SetInsecureSkipTLSverify(true)
-		err = kpmcli.LoginOci("localhost:5001", "test", "1234")
+		reg, err := mock.NewOciRegistry(mock.WithOciAuth("test", "1234"))
 		if err != nil {
-			t.Errorf("Error logging in to docker registry: %v", err)
+			t.Fatalf("Error starting the oci registry: %v", err)
 		}
+		defer reg.Close()
+		reg.Configure(kpmcli.GetSettings())
 
 		rootPath := getTestDir("issues")
 		pushedModPath := filepath.Join(rootPath, testPath, "pushed_mod")
 
-		modPath := filepath.Join(rootPath, testPath, "depends_on_pushed_mod")
+		modPath := filepath.Join(t.TempDir(), "depends_on_pushed_mod")
+		err = copy.Copy(filepath.Join(rootPath, testPath, "depends_on_pushed_mod"), modPath)
+		if err != nil {
+			t.Fatal(err)
+		}
 		modFileBk := filepath.Join(modPath, "kcl.mod.bk")
 		LockFileBk := filepath.Join(modPath, "kcl.mod.lock.bk")
 		modFile := filepath.Join(modPath, "kcl.mod")
@@ -469,11 +459,6 @@ func TestKclIssue1768(t *testing.T) {
 		modFileExpect := filepath.Join(modPath, "kcl.mod.expect")
 		LockFileExpect := filepath.Join(modPath, "kcl.mod.lock.expect")
 
-		defer func() {
-			_ = os.RemoveAll(modFile)
-			_ = os.RemoveAll(LockFile)
-		}()
-
 		err = copy.Copy(modFileBk, modFile)
 		if err != nil {
 			t.Fatal(err)
@@ -490,7 +475,7 @@ func TestKclIssue1768(t *testing.T) {
 			WithPushModPath(pushedModPath),
 			WithPushSource(downloader.Source{
 				Oci: &downloader.Oci{
-					Reg:  "localhost:5001",
+					Reg:  reg.Host,
 					Repo: "test/oci_pushed_mod",
 					Tag:  "v9.9.9",
 				},
@@ -502,7 +487,7 @@ func TestKclIssue1768(t *testing.T) {
 		}
 
 		assert.Contains(t, buf.String(), "package 'pushed_mod' will be pushed")
-		assert.Contains(t, buf.String(), "pushed [registry] localhost:5001/test/oci_pushed_mod")
+		assert.Contains(t, buf.String(), "pushed [registry] "+reg.Host+"/test/oci_pushed_mod")
 		assert.Contains(t, buf.String(), "digest: sha256:")
 
 		kmod, err := pkg.LoadKclPkgWithOpts(
@@ -514,7 +499,7 @@ func TestKclIssue1768(t *testing.T) {
 			WithAddSource(
 				&downloader.Source{
 					Oci: &downloader.Oci{
-						Reg:  "localhost:5001",
+						Reg:  reg.Host,
 						Repo: "test/oci_pushed_mod",
 						Tag:  "v9.9.9",
 					}},
@@ -550,8 +535,11 @@ func TestKclIssue1768(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		assert.Equal(t, utils.RmNewline(string(modFileContent)), utils.RmNewline(string(modFileExpectContent)))
-		assert.Equal(t, utils.RmNewline(string(lockFileContent)), utils.RmNewline(string(lockFileExpectContent)))
+		// The expected files are written for the registry 'localhost:5001'.
+		modFileExpectStr := strings.ReplaceAll(string(modFileExpectContent), "localhost:5001", reg.Host)
+		lockFileExpectStr := strings.ReplaceAll(string(lockFileExpectContent), "localhost:5001", reg.Host)
+		assert.Equal(t, utils.RmNewline(string(modFileContent)), utils.RmNewline(modFileExpectStr))
+		assert.Equal(t, utils.RmNewline(string(lockFileContent)), utils.RmNewline(lockFileExpectStr))
 	}
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "test_push_with_tag", TestFunc: test_push_with_tag}})
 }
@@ -618,7 +606,7 @@ func TestKpmIssue587(t *testing.T) {
 		modLockFilePath := filepath.Join(testPath, "kcl.mod.lock")
 
 		assert.Equal(t, res.GetRawYamlResult(), "The_first_kcl_program: Hello World!")
-		assert.Equal(t, buf.String(), "cloning 'git://github.com/kcl-lang/flask-demo-kcl-manifests.git' with tag 'v0.1.0'\n")
+		assert.Equal(t, buf.String(), "cloning 'git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git' with tag 'v0.1.0'\n")
 		assert.Equal(t, utils.DirExists(modFilePath), false)
 		assert.Equal(t, utils.DirExists(modLockFilePath), false)
 	}
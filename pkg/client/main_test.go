@@ -0,0 +1,93 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kcl-lang.io/kpm/pkg/mock"
+	"kcl-lang.io/kpm/pkg/settings"
+)
+
+// The remote packages of the tests are served by the in-process registry and git server instead of 'localhost:5101' and 'github.com',
+// their addresses are fixed because they are recorded in the kcl.mod and kcl.mod.lock files in 'test_data'.
+const (
+	// testRegistry is the host of the registry with the packages in 'test_data/remotes/oci',
+	// it is the default registry of the KpmClients in the tests.
+	testRegistry = "localhost:5101"
+	// testGitHost is the host of the git server with the repositories seeded from 'test_data/remotes/git',
+	// the repositories are served by https.
+	testGitHost = "127.0.0.1:5102"
+)
+
+// testGitCommits are the commits of the repositories on the test git server in order,
+// the files of each commit are in the directory 'fixture' under 'test_data/remotes/git/<repo>'.
+var testGitCommits = []struct {
+	repo    string
+	branch  string
+	fixture string
+	tags    []string
+}{
+	{repo: "kcl-lang/flask-demo-kcl-manifests", branch: "main", fixture: "with_modfile"},
+	{repo: "kcl-lang/flask-demo-kcl-manifests", branch: "main", fixture: "without_modfile"},
+	{repo: "kcl-lang/flask-demo-kcl-manifests", branch: "main", fixture: "cc_0.0.2"},
+	{repo: "kcl-lang/flask-demo-kcl-manifests", branch: "main", fixture: "main", tags: []string{"v0.1.0"}},
+	{repo: "kcl-lang/flask-demo-kcl-manifests", branch: "test-branch-without-modfile", fixture: "test-branch-without-modfile"},
+	{repo: "kcl-lang/modules", branch: "main", fixture: "."},
+	{repo: "kcl-lang/kpm", branch: "main", fixture: ".", tags: []string{"v0.1.0", "v0.2.0", "v0.2.1", "v0.2.2", "v0.2.3"}},
+}
+
+func TestMain(m *testing.M) {
+	os.Exit(runWithTestRemotes(m))
+}
+
+// runWithTestRemotes runs the tests with the test registry and git server.
+func runWithTestRemotes(m *testing.M) int {
+	reg, err := mock.NewOciRegistry(mock.WithOciAddr("127.0.0.1:5101"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start the test registry: %v\n", err)
+		return 1
+	}
+	defer reg.Close()
+
+	// The packages are in the directories 'test_data/remotes/oci/<repo>/<version>'.
+	pkgPaths, err := filepath.Glob(filepath.Join(getTestDir("remotes"), "oci", "*", "*", "*"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to find the test packages: %v\n", err)
+		return 1
+	}
+	for _, pkgPath := range pkgPaths {
+		repo, err := filepath.Rel(filepath.Join(getTestDir("remotes"), "oci"), filepath.Dir(pkgPath))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to push the test package '%s': %v\n", pkgPath, err)
+			return 1
+		}
+		if _, err := reg.PushPkg(pkgPath, filepath.ToSlash(repo)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to push the test package '%s': %v\n", pkgPath, err)
+			return 1
+		}
+	}
+
+	gitServer, err := mock.NewGitServer(mock.WithGitAddr(testGitHost), mock.WithGitTLS())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start the test git server: %v\n", err)
+		return 1
+	}
+	defer gitServer.Close()
+
+	for _, commit := range testGitCommits {
+		fixture := filepath.Join(getTestDir("remotes"), "git", filepath.FromSlash(commit.repo), commit.fixture)
+		if _, err := gitServer.SeedBranch(commit.repo, commit.branch, fixture, commit.tags...); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to seed the test repository '%s': %v\n", commit.repo, err)
+			return 1
+		}
+	}
+	// The settings are loaded from the environment variables once by the first KpmClient.
+	os.Setenv(settings.DEFAULT_REGISTRY_ENV, testRegistry)
+	os.Setenv(settings.DEFAULT_REPO_ENV, "kcl-lang")
+	// The KpmClients trust the certificate of the git server by the CA file in the git credentials of the settings.
+	gitServer.Configure(settings.GetSettings())
+
+	return m.Run()
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 
 	pkg "kcl-lang.io/kpm/pkg/package"
@@ -13,11 +14,18 @@ import (
 // Since redownloads are not triggered if local dependencies exists,
 // indirect dependencies are also synchronized to the lock file by `lockDeps`.
 func (c *KpmClient) ResolvePkgDepsMetadata(kclPkg *pkg.KclPkg, update bool) error {
+	return c.ResolvePkgDepsMetadataWithContext(context.Background(), kclPkg, update)
+}
+
+// ResolvePkgDepsMetadataWithContext is the same as ResolvePkgDepsMetadata,
+// and the download of the dependencies is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) ResolvePkgDepsMetadataWithContext(ctx context.Context, kclPkg *pkg.KclPkg, update bool) error {
 	var err error
 	if kclPkg.IsVendorMode() {
-		err = c.VendorDeps(kclPkg)
+		err = c.VendorDepsWithContext(ctx, kclPkg)
 	} else {
-		_, err = c.Update(
+		_, err = c.UpdateWithContext(
+			ctx,
 			WithUpdatedKclPkg(kclPkg),
 			WithOffline(!update),
 			WithUpdateModFile(false),
@@ -30,9 +38,15 @@ func (c *KpmClient) ResolvePkgDepsMetadata(kclPkg *pkg.KclPkg, update bool) erro
 // and check whether the package exists locally. If the package does not exist, it will re-download to the local.
 // Finally, the calculated metadata of the dependent packages is serialized into a json string and returned.
 func (c *KpmClient) ResolveDepsMetadataInJsonStr(kclPkg *pkg.KclPkg, update bool) (string, error) {
+	return c.ResolveDepsMetadataInJsonStrWithContext(context.Background(), kclPkg, update)
+}
+
+// ResolveDepsMetadataInJsonStrWithContext is the same as ResolveDepsMetadataInJsonStr,
+// and the download of the dependencies is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) ResolveDepsMetadataInJsonStrWithContext(ctx context.Context, kclPkg *pkg.KclPkg, update bool) (string, error) {
 	// 1. Calculate the dependency path, check whether the dependency exists
 	// and re-download the dependency that does not exist.
-	err := c.ResolvePkgDepsMetadata(kclPkg, update)
+	err := c.ResolvePkgDepsMetadataWithContext(ctx, kclPkg, update)
 	if err != nil {
 		return "", err
 	}
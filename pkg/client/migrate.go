@@ -0,0 +1,253 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// EXTERNAL_PKGS_DIR is the directory of the vendored external packages in the kcl projects without kcl.mod.
+const EXTERNAL_PKGS_DIR = "external"
+
+// kclYaml is the settings file 'kcl.yaml' of the kcl cli.
+type kclYaml struct {
+	CliConfigs struct {
+		Files        []string          `yaml:"files"`
+		File         []string          `yaml:"file"`
+		DisableNone  *bool             `yaml:"disable_none"`
+		SortKeys     *bool             `yaml:"sort_keys"`
+		Overrides    []string          `yaml:"overrides"`
+		PathSelector []string          `yaml:"path_selector"`
+		PackageMaps  map[string]string `yaml:"package_maps"`
+	} `yaml:"kcl_cli_configs"`
+	Options []struct {
+		Key   string      `yaml:"key"`
+		Value interface{} `yaml:"value"`
+	} `yaml:"kcl_options"`
+}
+
+// externalPkg is the external package passed to the kcl cli by '-E name=path'.
+type externalPkg struct {
+	name string
+	path string
+}
+
+// existingProject is the kcl project without kcl.mod found in the directory where the package is initialized.
+type existingProject struct {
+	// kFiles are the kcl files in the root of the project.
+	kFiles []string
+	// profile is derived from the 'kcl.yaml' of the project, it is nil if there is no 'kcl.yaml'.
+	profile *pkg.Profile
+	// externalPkgs are the external packages from the cli args, the 'package_maps' in 'kcl.yaml'
+	// and the vendored packages in 'external/', sorted by the names.
+	externalPkgs []externalPkg
+}
+
+func (p *existingProject) isEmpty() bool {
+	return len(p.kFiles) == 0 && p.profile == nil && len(p.externalPkgs) == 0
+}
+
+// detectProject inspects the kcl files, the 'kcl.yaml' and the vendored packages in 'external/' of 'pkgPath'.
+// 'externalPkgArgs' are the '<name>=<path>' args of the external packages, the relative paths are relative to 'workDir'.
+// The external packages from the args take precedence over the ones from 'kcl.yaml', and then the vendored ones.
+func detectProject(pkgPath, workDir string, externalPkgArgs []string) (*existingProject, error) {
+	project := &existingProject{}
+	kFiles, err := utils.FindKFiles(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	project.kFiles = kFiles
+
+	externalPkgs := map[string]string{}
+	vendoredPath := filepath.Join(pkgPath, EXTERNAL_PKGS_DIR)
+	if entries, err := os.ReadDir(vendoredPath); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && utils.FileExists(filepath.Join(vendoredPath, entry.Name(), constants.KCL_MOD)) {
+				externalPkgs[entry.Name()] = filepath.Join(vendoredPath, entry.Name())
+			}
+		}
+	}
+
+	kclYamlPath := filepath.Join(pkgPath, constants.KCL_YAML)
+	if utils.FileExists(kclYamlPath) {
+		settings, err := loadKclYaml(kclYamlPath)
+		if err != nil {
+			return nil, err
+		}
+		profile, err := settings.intoProfile()
+		if err != nil {
+			return nil, err
+		}
+		project.profile = profile
+		for name, path := range settings.CliConfigs.PackageMaps {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(pkgPath, path)
+			}
+			externalPkgs[name] = path
+		}
+	}
+
+	for _, arg := range externalPkgArgs {
+		name, path, found := strings.Cut(arg, "=")
+		if !found || name == "" || path == "" {
+			return nil, fmt.Errorf("invalid external package '%s', the format should be '<name>=<path>'", arg)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		externalPkgs[name] = path
+	}
+
+	for name, path := range externalPkgs {
+		project.externalPkgs = append(project.externalPkgs, externalPkg{name: name, path: filepath.Clean(path)})
+	}
+	sort.Slice(project.externalPkgs, func(i, j int) bool {
+		return project.externalPkgs[i].name < project.externalPkgs[j].name
+	})
+
+	return project, nil
+}
+
+// loadKclYaml loads the settings file 'kcl.yaml' from 'path'.
+func loadKclYaml(path string) (*kclYaml, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings := &kclYaml{}
+	if err := yaml.Unmarshal(content, settings); err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedLoadSettings, err, fmt.Sprintf("failed to load '%s'", path))
+	}
+	return settings, nil
+}
+
+// intoProfile transforms the compile options in 'kcl.yaml' into the profile of kcl.mod.
+func (settings *kclYaml) intoProfile() (*pkg.Profile, error) {
+	profile := pkg.NewProfile()
+	entries := append(append([]string{}, settings.CliConfigs.Files...), settings.CliConfigs.File...)
+	if len(entries) != 0 {
+		profile.Entries = &entries
+	}
+	profile.DisableNone = settings.CliConfigs.DisableNone
+	profile.SortKeys = settings.CliConfigs.SortKeys
+	if len(settings.CliConfigs.Overrides) != 0 {
+		profile.Overrides = &settings.CliConfigs.Overrides
+	}
+	if len(settings.CliConfigs.PathSelector) != 0 {
+		profile.Selectors = &settings.CliConfigs.PathSelector
+	}
+
+	var options []string
+	for _, option := range settings.Options {
+		value, ok := option.Value.(string)
+		if !ok {
+			jsonValue, err := json.Marshal(option.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of the option '%s': %w", option.Key, err)
+			}
+			value = string(jsonValue)
+		}
+		options = append(options, fmt.Sprintf("%s=%s", option.Key, value))
+	}
+	if len(options) != 0 {
+		profile.Options = &options
+	}
+	return &profile, nil
+}
+
+// migrateProject fills the profile and the dependencies of 'kclPkg' from the existing 'project'.
+// If 'resolveOci' is true, the external packages published to the registry with the same versions are added as the registry dependencies,
+// otherwise they are added as the local dependencies.
+func (c *KpmClient) migrateProject(kclPkg *pkg.KclPkg, project *existingProject, resolveOci bool) error {
+	if project.profile != nil {
+		kclPkg.ModFile.Profiles = project.profile
+	}
+
+	for _, external := range project.externalPkgs {
+		dep, err := c.externalPkgIntoDep(kclPkg.HomePath, external, resolveOci)
+		if err != nil {
+			return err
+		}
+		kclPkg.ModFile.Dependencies.Deps.Set(external.name, *dep)
+	}
+	return nil
+}
+
+// externalPkgIntoDep transforms the external package into the dependency of the package in 'homePath'.
+func (c *KpmClient) externalPkgIntoDep(homePath string, external externalPkg, resolveOci bool) (*pkg.Dependency, error) {
+	if !utils.DirExists(external.path) {
+		return nil, fmt.Errorf("external package '%s' not found in '%s'", external.name, external.path)
+	}
+
+	localPath := external.path
+	if relPath, err := filepath.Rel(homePath, external.path); err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		localPath = relPath
+	}
+	dep := &pkg.Dependency{
+		Name:     external.name,
+		FullName: external.name,
+		Source: downloader.Source{
+			Local: &downloader.Local{
+				Path: localPath,
+			},
+		},
+	}
+
+	depPkg, err := pkg.LoadKclPkgWithOpts(
+		pkg.WithPath(external.path),
+		pkg.WithSettings(c.GetSettings()),
+	)
+	if err != nil {
+		reporter.ReportMsgTo(
+			fmt.Sprintf("'%s' in '%s' is not a kcl package, it is added as the local dependency", external.name, external.path),
+			c.GetLogWriter(),
+		)
+		return dep, nil
+	}
+	dep.FullName = depPkg.GetPkgFullName()
+	dep.Version = depPkg.GetPkgVersion()
+
+	if !resolveOci || len(dep.Version) == 0 {
+		return dep, nil
+	}
+
+	info, err := c.Info(WithInfoSource(&downloader.Source{
+		ModSpec: &downloader.ModSpec{
+			Name:    depPkg.GetPkgName(),
+			Version: depPkg.GetPkgVersion(),
+		},
+	}))
+	if err != nil {
+		reporter.ReportMsgTo(
+			fmt.Sprintf("'%s %s' is not found in the registry, it is added as the local dependency: %v", depPkg.GetPkgName(), depPkg.GetPkgVersion(), err),
+			c.GetLogWriter(),
+		)
+		return dep, nil
+	}
+
+	modSpec := &downloader.ModSpec{
+		Name:    depPkg.GetPkgName(),
+		Version: info.Version,
+	}
+	if external.name != modSpec.Name {
+		modSpec.Alias = external.name
+	}
+	return &pkg.Dependency{
+		Name:     modSpec.Name,
+		FullName: depPkg.GetPkgFullName(),
+		Version:  info.Version,
+		Source: downloader.Source{
+			ModSpec: modSpec,
+		},
+	}, nil
+}
@@ -1,6 +1,9 @@
 package client
 
 import (
+	"strings"
+
+	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/env"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
@@ -29,6 +32,8 @@ func (c *KpmClient) PackagePkg(kclPkg *pkg.KclPkg, vendorMode bool) (string, err
 }
 
 // Package will package the current kcl package into a "*.tar" file into 'tarPath'.
+// If 'tarPath' ends with ".tgz", the tar will be compressed by gzip.
+// The same package sources always produce the same tar bytes.
 func (c *KpmClient) Package(kclPkg *pkg.KclPkg, tarPath string, vendorMode bool) error {
 	// Vendor all the dependencies into the current kcl package.
 	if vendorMode {
@@ -38,8 +43,12 @@ func (c *KpmClient) Package(kclPkg *pkg.KclPkg, tarPath string, vendorMode bool)
 		}
 	}
 
-	// Tar the current kcl package into a "*.tar" file.
-	err := utils.TarDir(kclPkg.HomePath, tarPath, kclPkg.GetPkgInclude(), kclPkg.GetPkgExclude())
+	// Tar the current kcl package into a "*.tar" or "*.tgz" file.
+	tarDir := utils.TarDir
+	if strings.HasSuffix(tarPath, constants.TgzPathSuffix) {
+		tarDir = utils.TarGzDir
+	}
+	err := tarDir(kclPkg.HomePath, tarPath, kclPkg.GetPkgInclude(), kclPkg.GetPkgExclude())
 	if err != nil {
 		return reporter.NewErrorEvent(reporter.FailedPackage, err, "failed to package the kcl module")
 	}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"kcl-lang.io/kpm/pkg/downloader"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
@@ -19,6 +21,8 @@ type PullOptions struct {
 	Source *downloader.Source
 	// LocalPath is the local path to download the package.
 	LocalPath string
+	// Registries are the named registries in kcl.mod to resolve the source with only the package spec.
+	Registries []settings.OciRegistry
 }
 
 type PullOption func(*PullOptions) error
@@ -68,6 +72,14 @@ func WithLocalPath(path string) PullOption {
 	}
 }
 
+// WithPullRegistries sets the named registries in kcl.mod to resolve the source with only the package spec.
+func WithPullRegistries(registries ...settings.OciRegistry) PullOption {
+	return func(opts *PullOptions) error {
+		opts.Registries = registries
+		return nil
+	}
+}
+
 func NewPullOptions(opts ...PullOption) *PullOptions {
 	do := &PullOptions{}
 	for _, opt := range opts {
@@ -77,6 +89,11 @@ func NewPullOptions(opts ...PullOption) *PullOptions {
 }
 
 func (c *KpmClient) Pull(options ...PullOption) (*pkg.KclPkg, error) {
+	return c.PullWithContext(context.Background(), options...)
+}
+
+// PullWithContext is the same as Pull, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) PullWithContext(ctx context.Context, options ...PullOption) (*pkg.KclPkg, error) {
 	opts := &PullOptions{}
 	for _, option := range options {
 		if err := option(opts); err != nil {
@@ -84,13 +101,7 @@ func (c *KpmClient) Pull(options ...PullOption) (*pkg.KclPkg, error) {
 		}
 	}
 
-	if opts.Source.SpecOnly() {
-		opts.Source.Oci = &downloader.Oci{
-			Reg:  c.GetSettings().DefaultOciRegistry(),
-			Repo: utils.JoinPath(c.GetSettings().DefaultOciRepo(), opts.Source.ModSpec.Name),
-			Tag:  opts.Source.ModSpec.Version,
-		}
-	}
+	c.resolveSpecOnlySource(opts.Source, opts.Registries...)
 
 	sourceFilePath, err := opts.Source.ToFilePath()
 	if err != nil {
@@ -110,7 +121,7 @@ func (c *KpmClient) Pull(options ...PullOption) (*pkg.KclPkg, error) {
 	pkgSource := opts.Source
 	pulledFullPath := filepath.Join(opts.LocalPath, sourceFilePath)
 
-	err = newVisitor(*pkgSource, c).Visit(pkgSource, func(kPkg *pkg.KclPkg) error {
+	err = newVisitor(*pkgSource, c).Visit(ctx, pkgSource, func(kPkg *pkg.KclPkg) error {
 		if !utils.DirExists(filepath.Dir(pulledFullPath)) {
 			err := os.MkdirAll(filepath.Dir(pulledFullPath), os.ModePerm)
 			if err != nil {
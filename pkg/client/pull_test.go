@@ -2,13 +2,17 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
@@ -31,14 +35,14 @@ func testPull(t *testing.T) {
 		WithLocalPath(pulledPath),
 		WithPullSource(&downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "0.0.1",
 			},
 		}),
 	)
 
-	pkgPath := filepath.Join(pulledPath, "oci", "ghcr.io", "kcl-lang", "helloworld", "0.0.1")
+	pkgPath := filepath.Join(pulledPath, "oci", "localhost:5101", "kcl-lang", "helloworld", "0.0.1")
 	assert.NilError(t, err)
 	assert.Equal(t, kPkg.GetPkgName(), "helloworld")
 	assert.Equal(t, kPkg.GetPkgVersion(), "0.0.1")
@@ -48,9 +52,9 @@ func testPull(t *testing.T) {
 
 	kPkg, err = kpmcli.Pull(
 		WithLocalPath(pulledPath),
-		WithPullSourceUrl("oci://ghcr.io/kcl-lang/helloworld?tag=0.1.0"),
+		WithPullSourceUrl("oci://localhost:5101/kcl-lang/helloworld?tag=0.1.0"),
 	)
-	pkgPath = filepath.Join(pulledPath, "oci", "ghcr.io", "kcl-lang", "helloworld", "0.1.0")
+	pkgPath = filepath.Join(pulledPath, "oci", "localhost:5101", "kcl-lang", "helloworld", "0.1.0")
 	assert.NilError(t, err)
 	assert.Equal(t, kPkg.GetPkgName(), "helloworld")
 	assert.Equal(t, kPkg.GetPkgVersion(), "0.1.0")
@@ -74,9 +78,9 @@ func testPullWithInsecureSkipTLSverify(t *testing.T) {
 
 	kPkg, err := kpmcli.Pull(
 		WithLocalPath(pulledPath),
-		WithPullSourceUrl("oci://ghcr.io/kcl-lang/helloworld?tag=0.1.0"),
+		WithPullSourceUrl("oci://localhost:5101/kcl-lang/helloworld?tag=0.1.0"),
 	)
-	pkgPath := filepath.Join(pulledPath, "oci", "ghcr.io", "kcl-lang", "helloworld", "0.1.0")
+	pkgPath := filepath.Join(pulledPath, "oci", "localhost:5101", "kcl-lang", "helloworld", "0.1.0")
 	assert.NilError(t, err)
 	assert.Equal(t, kPkg.GetPkgName(), "helloworld")
 	assert.Equal(t, kPkg.GetPkgVersion(), "0.1.0")
@@ -149,14 +153,14 @@ func testPullWithModSpec(t *testing.T) {
 				Version: "0.0.1",
 			},
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/helloworld",
 				Tag:  "0.1.4",
 			},
 		}),
 	)
 
-	pkgPath := filepath.Join(pulledPath, "oci", "ghcr.io", "kcl-lang", "helloworld", "0.1.4", "subhelloworld", "0.0.1")
+	pkgPath := filepath.Join(pulledPath, "oci", "localhost:5101", "kcl-lang", "helloworld", "0.1.4", "subhelloworld", "0.0.1")
 	assert.NilError(t, err)
 	assert.Equal(t, kPkg.GetPkgName(), "subhelloworld")
 	assert.Equal(t, kPkg.GetPkgVersion(), "0.0.1")
@@ -166,9 +170,9 @@ func testPullWithModSpec(t *testing.T) {
 
 	kPkg, err = kpmcli.Pull(
 		WithLocalPath(pulledPath),
-		WithPullSourceUrl("oci://ghcr.io/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld:0.0.1"),
+		WithPullSourceUrl("oci://localhost:5101/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld:0.0.1"),
 	)
-	pkgPath = filepath.Join(pulledPath, "oci", "ghcr.io", "kcl-lang", "helloworld", "0.1.4", "subhelloworld", "0.0.1")
+	pkgPath = filepath.Join(pulledPath, "oci", "localhost:5101", "kcl-lang", "helloworld", "0.1.4", "subhelloworld", "0.0.1")
 	assert.NilError(t, err)
 	assert.Equal(t, kPkg.GetPkgName(), "subhelloworld")
 	assert.Equal(t, kPkg.GetPkgVersion(), "0.0.1")
@@ -178,7 +182,7 @@ func testPullWithModSpec(t *testing.T) {
 
 	_, err = kpmcli.Pull(
 		WithLocalPath(pulledPath),
-		WithPullSourceUrl("oci://ghcr.io/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld:0.0.2"),
+		WithPullSourceUrl("oci://localhost:5101/kcl-lang/helloworld?tag=0.1.4&mod=subhelloworld:0.0.2"),
 	)
 	assert.Equal(t, err.Error(), "package 'subhelloworld:0.0.2' not found")
 }
@@ -206,7 +210,7 @@ func testPullWithOnlySpec(t *testing.T) {
 		}),
 	)
 
-	pkgPath := filepath.Join(pulledPath, "oci", "ghcr.io", "kcl-lang", "helloworld", "0.1.4", "helloworld", "0.1.4")
+	pkgPath := filepath.Join(pulledPath, "oci", "localhost:5101", "kcl-lang", "helloworld", "0.1.4", "helloworld", "0.1.4")
 	assert.NilError(t, err)
 	assert.Equal(t, kPkg.GetPkgName(), "helloworld")
 	assert.Equal(t, kPkg.GetPkgVersion(), "0.1.4")
@@ -214,3 +218,69 @@ func testPullWithOnlySpec(t *testing.T) {
 	err = os.RemoveAll(filepath.Join(pulledPath, "oci"))
 	assert.NilError(t, err)
 }
+
+func TestPullWithContext(t *testing.T) {
+	// The registry does not respond until the request is aborted.
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer registry.Close()
+	reg := strings.Replace(strings.TrimPrefix(registry.URL, "http://"), "127.0.0.1", "localhost", 1)
+
+	kpmcli, err := NewKpmClient()
+	assert.NilError(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	pulledPath := t.TempDir()
+	_, err = kpmcli.PullWithContext(
+		ctx,
+		WithLocalPath(pulledPath),
+		WithPullSourceUrl(fmt.Sprintf("oci://%s/kcl-lang/helloworld?tag=0.1.0", reg)),
+	)
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	entries, err := os.ReadDir(pulledPath)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 0)
+}
+
+type ctxKey struct{}
+
+// ctxDownloader writes a package into the local path and records the value of 'ctxKey' in the context it is called with.
+type ctxDownloader struct {
+	value interface{}
+}
+
+func (d *ctxDownloader) Download(ctx context.Context, opts *downloader.DownloadOptions) error {
+	d.value = ctx.Value(ctxKey{})
+	if err := os.MkdirAll(opts.LocalPath, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(opts.LocalPath, "kcl.mod"), []byte("[package]\nname = \"helloworld\"\nversion = \"0.1.0\"\n"), 0644)
+}
+
+func (d *ctxDownloader) LatestVersion(ctx context.Context, opts *downloader.DownloadOptions) (string, error) {
+	d.value = ctx.Value(ctxKey{})
+	return "0.1.0", nil
+}
+
+func TestPullWithContextToDownloader(t *testing.T) {
+	d := &ctxDownloader{}
+	assert.NilError(t, downloader.RegisterDownloader("ctxtest", d))
+	defer downloader.UnregisterDownloader("ctxtest")
+
+	kpmcli, err := NewKpmClient()
+	assert.NilError(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "pull")
+	kPkg, err := kpmcli.PullWithContext(
+		ctx,
+		WithLocalPath(t.TempDir()),
+		WithPullSourceUrl("ctxtest://example.com/helloworld?tag=0.1.0"),
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, kPkg.GetPkgName(), "helloworld")
+	assert.Equal(t, d.value, "pull")
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -23,6 +24,8 @@ type PushOptions struct {
 	Source     downloader.Source
 	ModPath    string
 	VendorMode bool
+	// DocsPath is the directory of the package docs generated by 'kpm doc', which is pushed with the package.
+	DocsPath string
 }
 
 type PushOption func(*PushOptions) error
@@ -54,6 +57,17 @@ func WithPushModPath(modPath string) PushOption {
 	}
 }
 
+// WithPushDocs sets the directory of the package docs pushed with the package for the Push method.
+func WithPushDocs(docsPath string) PushOption {
+	return func(opts *PushOptions) error {
+		if docsPath != "" && !utils.DirExists(docsPath) {
+			return fmt.Errorf("docs '%s' not found", docsPath)
+		}
+		opts.DocsPath = docsPath
+		return nil
+	}
+}
+
 // fillDefaultPushOptions will fill the default values for the PushOptions.
 func (c *KpmClient) fillDefaultPushOptions(ociOpt *opt.OciOptions, kMod *pkg.KclPkg) {
 	if ociOpt.Reg == "" {
@@ -71,6 +85,11 @@ func (c *KpmClient) fillDefaultPushOptions(ociOpt *opt.OciOptions, kMod *pkg.Kcl
 
 // Push will push a kcl package to a registry.
 func (c *KpmClient) Push(opts ...PushOption) error {
+	return c.PushWithContext(context.Background(), opts...)
+}
+
+// PushWithContext is the same as Push, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) PushWithContext(ctx context.Context, opts ...PushOption) error {
 	pushOpts := &PushOptions{}
 	for _, opt := range opts {
 		if err := opt(pushOpts); err != nil {
@@ -103,16 +122,26 @@ func (c *KpmClient) Push(opts ...PushOption) error {
 		tag = source.Oci.Tag
 	}
 
-	ociOpts, err := c.ParseOciOptionFromString(ociUrl, tag)
-	if err != nil {
-		return err
+	var ociOpts *opt.OciOptions
+	if source.Oci.Layout {
+		// The package is pushed into the local OCI image layout, which is created if it does not exist.
+		if err := os.MkdirAll(source.Oci.Repo, 0755); err != nil {
+			return err
+		}
+		ociOpts = &opt.OciOptions{Repo: source.Oci.Repo, Tag: tag}
+	} else {
+		ociOpts, err = c.ParseOciOptionFromString(ociUrl, tag)
+		if err != nil {
+			return err
+		}
+		c.fillDefaultPushOptions(ociOpts, kMod)
 	}
-	c.fillDefaultPushOptions(ociOpts, kMod)
 
 	ociOpts.Annotations, err = kMod.GenOciManifestFromPkg()
 	if err != nil {
 		return err
 	}
+	ociOpts.DocsPath = pushOpts.DocsPath
 
 	tarPath, err := c.PackagePkg(kMod, pushOpts.VendorMode)
 	if err != nil {
@@ -130,30 +159,32 @@ func (c *KpmClient) Push(opts ...PushOption) error {
 	}()
 
 	reporter.ReportMsgTo(fmt.Sprintf("package '%s' will be pushed", kMod.GetPkgName()), c.GetLogWriter())
-	return c.pushToOci(tarPath, ociOpts)
+	if source.Oci.Layout {
+		ociCli, err := c.newOciClient(ctx, source.Oci)
+		if err != nil {
+			return err
+		}
+		return pushWithOciClient(ociCli, tarPath, ociOpts)
+	}
+	return c.pushToOci(ctx, tarPath, ociOpts)
 }
 
 // PushToOci will push a kcl package to oci registry.
-func (c *KpmClient) pushToOci(localPath string, ociOpts *opt.OciOptions) error {
-	repoPath := utils.JoinPath(ociOpts.Reg, ociOpts.Repo, ociOpts.Ref)
-	cred, err := c.GetCredentials(ociOpts.Reg)
-	if err != nil {
-		return err
-	}
-
-	ociCli, err := oci.NewOciClientWithOpts(
-		oci.WithCredential(cred),
-		oci.WithRepoPath(repoPath),
-		oci.WithSettings(c.GetSettings()),
-		oci.WithInsecureSkipTLSverify(c.insecureSkipTLSverify),
-	)
-
+func (c *KpmClient) pushToOci(ctx context.Context, localPath string, ociOpts *opt.OciOptions) error {
+	ociCli, err := c.newOciClient(ctx, &downloader.Oci{
+		Reg:  ociOpts.Reg,
+		Repo: utils.JoinPath(ociOpts.Repo, ociOpts.Ref),
+	})
 	if err != nil {
 		return err
 	}
 
-	ociCli.SetLogWriter(c.logWriter)
+	return pushWithOciClient(ociCli, localPath, ociOpts)
+}
 
+// pushWithOciClient pushes the kcl package tar 'localPath' by the OciClient with the tag and the annotations in 'ociOpts',
+// the tag that already exists is not overwritten.
+func pushWithOciClient(ociCli *oci.OciClient, localPath string, ociOpts *opt.OciOptions) error {
 	exist, err := ociCli.ContainsTag(ociOpts.Tag)
 	if err != (*reporter.KpmEvent)(nil) {
 		return err
@@ -168,5 +199,6 @@ func (c *KpmClient) pushToOci(localPath string, ociOpts *opt.OciOptions) error {
 
 	return ociCli.PushWithOciManifest(localPath, ociOpts.Tag, &opt.OciManifestOptions{
 		Annotations: ociOpts.Annotations,
+		DocsPath:    ociOpts.DocsPath,
 	})
 }
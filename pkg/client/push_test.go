@@ -2,15 +2,23 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/mock"
+	"kcl-lang.io/kpm/pkg/oci"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
+	"oras.land/oras-go/v2"
+	ocilayout "oras.land/oras-go/v2/content/oci"
 )
 
 func TestPush(t *testing.T) {
@@ -18,23 +26,12 @@ func TestPush(t *testing.T) {
 		if runtime.GOOS == "windows" {
 			t.Skip("Skipping test on Windows")
 		}
-		err := mock.StartDockerRegistry()
+		reg, err := mock.NewOciRegistry(mock.WithOciAuth("test", "1234"))
 		if err != nil {
-			t.Errorf("Error starting docker registry: %v", err)
-		}
-
-		defer func() {
-			err = mock.CleanTestEnv()
-			if err != nil {
-				t.Errorf("Error stopping docker registry: %v", err)
-			}
-		}()
-
-		kpmcli.SetInsecureSkipTLSverify(true)
-		err = kpmcli.LoginOci("localhost:5001", "test", "1234")
-		if err != nil {
-			t.Errorf("Error logging in to docker registry: %v", err)
+			t.Fatalf("Error starting the oci registry: %v", err)
 		}
+		defer reg.Close()
+		reg.Configure(kpmcli.GetSettings())
 
 		var buf bytes.Buffer
 		kpmcli.SetLogWriter(&buf)
@@ -47,7 +44,7 @@ func TestPush(t *testing.T) {
 			WithPushSource(
 				downloader.Source{
 					Oci: &downloader.Oci{
-						Reg:  "localhost:5001",
+						Reg:  reg.Host,
 						Repo: "test/push_0",
 					},
 				},
@@ -59,10 +56,10 @@ func TestPush(t *testing.T) {
 		}
 
 		assert.Contains(t, buf.String(), "package 'push_0' will be pushed")
-		assert.Contains(t, buf.String(), "pushed [registry] localhost:5001/test/push_0")
+		assert.Contains(t, buf.String(), "pushed [registry] "+reg.Host+"/test/push_0")
 		assert.Contains(t, buf.String(), "digest: sha256:")
 
-		testPushModPath := filepath.Join(testDir, "test_pushed_mod")
+		testPushModPath := filepath.Join(t.TempDir(), "test_pushed_mod")
 
 		err = kpmcli.Init(
 			WithInitModPath(testPushModPath),
@@ -77,7 +74,7 @@ func TestPush(t *testing.T) {
 
 		err = kpmcli.Add(
 			WithAddKclPkg(testMod),
-			WithAddSourceUrl("oci://localhost:5001/test/push_0"),
+			WithAddSourceUrl("oci://"+reg.Host+"/test/push_0"),
 			WithAddModSpec(&downloader.ModSpec{
 				Name:    "push_0",
 				Version: "0.0.1",
@@ -91,3 +88,93 @@ func TestPush(t *testing.T) {
 	}
 	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestPush", TestFunc: testFunc}})
 }
+
+func TestPushToOciLayout(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		var buf bytes.Buffer
+		kpmcli.SetLogWriter(&buf)
+
+		layoutPath := filepath.Join(t.TempDir(), "layout")
+		err := kpmcli.Push(
+			WithPushModPath(filepath.Join(getTestDir("test_push"), "push_0")),
+			WithPushSource(
+				downloader.Source{
+					Oci: &downloader.Oci{
+						Repo:   layoutPath,
+						Layout: true,
+					},
+				},
+			),
+		)
+		if err != (*reporter.KpmEvent)(nil) {
+			t.Fatalf("Error pushing kcl package: %v", err)
+		}
+		assert.Contains(t, buf.String(), "package 'push_0' will be pushed")
+
+		pulledPath := t.TempDir()
+		pulledPkg, pullErr := kpmcli.Pull(
+			WithPullSourceUrl("oci+file://"+filepath.ToSlash(layoutPath)+"?tag=0.0.1"),
+			WithLocalPath(pulledPath),
+		)
+		assert.Nil(t, pullErr)
+		assert.Equal(t, "push_0", pulledPkg.GetPkgName())
+		assert.Equal(t, "0.0.1", pulledPkg.GetPkgVersion())
+	}
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestPushToOciLayout", TestFunc: testFunc}})
+}
+
+func TestPushWithDocs(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		docsPath := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(docsPath, "README.md"), []byte("# push_0\n"), 0644))
+
+		pushWithDocs := func() (string, v1.Manifest) {
+			layoutPath := filepath.Join(t.TempDir(), "layout")
+			err := kpmcli.Push(
+				WithPushModPath(filepath.Join(getTestDir("test_push"), "push_0")),
+				WithPushSource(downloader.Source{Oci: &downloader.Oci{Repo: layoutPath, Layout: true}}),
+				WithPushDocs(docsPath),
+			)
+			if err != (*reporter.KpmEvent)(nil) {
+				t.Fatalf("Error pushing kcl package: %v", err)
+			}
+
+			store, storeErr := ocilayout.New(layoutPath)
+			assert.Nil(t, storeErr)
+			_, content, fetchErr := oras.FetchBytes(context.Background(), store, "0.0.1", oras.DefaultFetchBytesOptions)
+			assert.Nil(t, fetchErr)
+			var manifest v1.Manifest
+			assert.Nil(t, json.Unmarshal(content, &manifest))
+			return layoutPath, manifest
+		}
+
+		layoutPath, manifest := pushWithDocs()
+		assert.Equal(t, 2, len(manifest.Layers))
+		assert.Equal(t, oci.DEFAULT_OCI_ARTIFACT_TYPE, manifest.Layers[0].MediaType)
+		assert.Equal(t, oci.OCI_DOCS_MEDIA_TYPE, manifest.Layers[1].MediaType)
+
+		// The same docs are pushed with the same digest.
+		modTime := time.Now().Add(-time.Hour)
+		assert.Nil(t, os.Chtimes(filepath.Join(docsPath, "README.md"), modTime, modTime))
+		_, repushed := pushWithDocs()
+		assert.Equal(t, manifest.Layers[1].Digest, repushed.Layers[1].Digest)
+
+		// The docs are not counted in the size of the package.
+		info, infoErr := kpmcli.Info(WithInfoSourceUrl("oci+file://" + filepath.ToSlash(layoutPath) + "?tag=0.0.1"))
+		assert.Nil(t, infoErr)
+		assert.Equal(t, manifest.Layers[0].Size, info.Size)
+
+		// The docs are not pulled with the package.
+		pulledPath := t.TempDir()
+		pulledPkg, pullErr := kpmcli.Pull(
+			WithPullSourceUrl("oci+file://"+filepath.ToSlash(layoutPath)+"?tag=0.0.1"),
+			WithLocalPath(pulledPath),
+		)
+		assert.Nil(t, pullErr)
+		assert.Equal(t, "push_0", pulledPkg.GetPkgName())
+
+		err := kpmcli.Push(WithPushDocs(filepath.Join(docsPath, "not_exist")))
+		assert.ErrorContains(t, err, "not found")
+	}
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestPushWithDocs", TestFunc: testFunc}})
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// RemoveOptions is the options for removing the dependencies from a package.
+type RemoveOptions struct {
+	kpkg     *pkg.KclPkg
+	depNames []string
+}
+
+type RemoveOption func(*RemoveOptions) error
+
+// WithRemoveKclPkg sets the kcl package to remove the dependencies from.
+func WithRemoveKclPkg(kpkg *pkg.KclPkg) RemoveOption {
+	return func(opts *RemoveOptions) error {
+		if kpkg == nil {
+			return fmt.Errorf("kcl package cannot be nil")
+		}
+		opts.kpkg = kpkg
+		return nil
+	}
+}
+
+// WithRemoveDepNames sets the names of the dependencies to be removed.
+func WithRemoveDepNames(depNames ...string) RemoveOption {
+	return func(opts *RemoveOptions) error {
+		opts.depNames = append(opts.depNames, depNames...)
+		return nil
+	}
+}
+
+// Remove removes the dependencies from the kcl.mod of the package, and updates the kcl.mod and the kcl.mod.lock.
+func (c *KpmClient) Remove(options ...RemoveOption) (*pkg.KclPkg, error) {
+	return c.RemoveWithContext(context.Background(), options...)
+}
+
+// RemoveWithContext is the same as Remove, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) RemoveWithContext(ctx context.Context, options ...RemoveOption) (*pkg.KclPkg, error) {
+	opts := &RemoveOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	kMod := opts.kpkg
+	if kMod == nil {
+		return nil, fmt.Errorf("kcl package is nil")
+	}
+	if len(opts.depNames) == 0 {
+		return nil, fmt.Errorf("the dependencies to be removed are not specified")
+	}
+
+	for _, name := range opts.depNames {
+		if _, ok := kMod.ModFile.Dependencies.Deps.Get(name); !ok {
+			return nil, reporter.NewErrorEvent(
+				reporter.DependencyNotFound,
+				fmt.Errorf("dependency '%s' not found in '%s'", name, kMod.GetPkgName()),
+			)
+		}
+		kMod.ModFile.Dependencies.Deps.Delete(name)
+		kMod.Dependencies.Deps.Delete(name)
+		reporter.ReportMsgTo(fmt.Sprintf("removing '%s'", name), c.logWriter)
+	}
+
+	return c.Update(WithUpdatedKclPkg(kMod))
+}
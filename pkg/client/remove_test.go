@@ -0,0 +1,38 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemove(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		testDir := t.TempDir()
+		if err := copy.Copy(getTestDir("test_remove"), testDir); err != nil {
+			t.Fatal(err)
+		}
+
+		kpkg, err := kpmcli.LoadPkgFromPath(filepath.Join(testDir, "pkg"))
+		assert.Nil(t, err)
+
+		_, err = kpmcli.Remove(WithRemoveKclPkg(kpkg), WithRemoveDepNames("not_exist"))
+		assert.ErrorContains(t, err, "dependency 'not_exist' not found in 'pkg'")
+
+		_, err = kpmcli.Remove(WithRemoveKclPkg(kpkg), WithRemoveDepNames("helper"))
+		assert.Nil(t, err)
+
+		kpkg, err = kpmcli.LoadPkgFromPath(filepath.Join(testDir, "pkg"))
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"other"}, kpkg.ModFile.Dependencies.Deps.Keys())
+		assert.Equal(t, []string{"other"}, kpkg.Dependencies.Deps.Keys())
+
+		modFile, err := os.ReadFile(filepath.Join(testDir, "pkg", "kcl.mod"))
+		assert.Nil(t, err)
+		assert.NotContains(t, string(modFile), "helper")
+	}
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestRemove", TestFunc: testFunc}})
+}
@@ -63,12 +63,14 @@ And take all the KCL program files in the sources as the compile entry to compil
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"kcl-lang.io/kcl-go/pkg/kcl"
 	"kcl-lang.io/kpm/pkg/constants"
@@ -85,11 +87,34 @@ type RunOptions struct {
 	// Sources is the sources of the package.
 	// It can be a local *.k path, a local *.tar/*.tgz path, a local directory, a remote git/oci path,.
 	Sources []*downloader.Source
+	// cache keeps the dependencies resolved by the previous run in the watch mode.
+	cache *runCache
+	// resolveMu serializes the resolving of the dependencies of the packages run in parallel.
+	resolveMu *sync.Mutex
+	// parallelism is the max number of the packages compiled in parallel by RunPkgs.
+	parallelism int
+	// checkYanked is true to warn the yanked versions of the OCI dependencies locked by the package.
+	checkYanked bool
 	*kcl.Option
 }
 
+// runCache keeps the home path of the package and its resolved dependencies across the runs,
+// the dependencies are resolved again only if 'pkgMap' is reset to nil.
+type runCache struct {
+	homePath string
+	pkgMap   map[string]string
+}
+
 type RunOption func(*RunOptions) error
 
+// withRunCache sets the cache of the resolved dependencies for running the kcl package.
+func withRunCache(cache *runCache) RunOption {
+	return func(ro *RunOptions) error {
+		ro.cache = cache
+		return nil
+	}
+}
+
 func WithRunModSpec(modSpec *downloader.ModSpec) RunOption {
 	return func(ro *RunOptions) error {
 		if modSpec == nil {
@@ -357,6 +382,15 @@ func WithVendor(vendor bool) RunOption {
 	}
 }
 
+// WithRunCheckYanked sets whether to warn the yanked versions of the OCI dependencies locked by the package.
+// The registries are requested for each OCI dependency, and the check is skipped in the vendor mode.
+func WithRunCheckYanked(checkYanked bool) RunOption {
+	return func(ro *RunOptions) error {
+		ro.checkYanked = checkYanked
+		return nil
+	}
+}
+
 // applyCompileOptionsFromYaml applies the compile options from the kcl.yaml file.
 func (o *RunOptions) getCompileOptionsFromYaml(workdir string) *kcl.Option {
 	resOpts := kcl.NewOption()
@@ -614,6 +648,11 @@ func (o *RunOptions) getPkgSource() (*downloader.Source, error) {
 
 // Run runs the kcl package.
 func (c *KpmClient) Run(options ...RunOption) (*kcl.KCLResultList, error) {
+	return c.RunWithContext(context.Background(), options...)
+}
+
+// RunWithContext is the same as Run, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) RunWithContext(ctx context.Context, options ...RunOption) (*kcl.KCLResultList, error) {
 	opts := &RunOptions{}
 	for _, option := range options {
 		if err := option(opts); err != nil {
@@ -638,7 +677,7 @@ func (c *KpmClient) Run(options ...RunOption) (*kcl.KCLResultList, error) {
 
 	// Visit the root package source.
 	var res *kcl.KCLResultList
-	err = newVisitor(*pkgSource, c).Visit(pkgSource, func(kclPkg *pkg.KclPkg) error {
+	err = newVisitor(*pkgSource, c).Visit(ctx, pkgSource, func(kclPkg *pkg.KclPkg) error {
 		// Apply the compile options from cli, kcl.yaml or kcl.mod
 		err = opts.applyCompileOptions(*pkgSource, kclPkg, opts.WorkDir)
 		if err != nil {
@@ -648,7 +687,7 @@ func (c *KpmClient) Run(options ...RunOption) (*kcl.KCLResultList, error) {
 		kclPkg.SetVendorMode(opts.vendor)
 
 		// Resolve and update the dependencies into a map.
-		pkgMap, err := c.ResolveDepsIntoMap(kclPkg)
+		pkgMap, err := c.resolveRunDeps(ctx, opts, kclPkg)
 		if err != nil {
 			return err
 		}
@@ -677,9 +716,40 @@ func (c *KpmClient) Run(options ...RunOption) (*kcl.KCLResultList, error) {
 	return res, nil
 }
 
+// resolveRunDeps resolves the dependencies of the package into a map for running it,
+// the dependencies resolved by the previous run are reused if the cache is set.
+func (c *KpmClient) resolveRunDeps(ctx context.Context, opts *RunOptions, kclPkg *pkg.KclPkg) (map[string]string, error) {
+	if opts.resolveMu != nil {
+		opts.resolveMu.Lock()
+		defer opts.resolveMu.Unlock()
+	}
+	if opts.cache != nil && opts.cache.pkgMap != nil && opts.cache.homePath == kclPkg.HomePath {
+		return opts.cache.pkgMap, nil
+	}
+
+	pkgMap, err := c.ResolveDepsIntoMapWithContext(ctx, kclPkg)
+	if err != nil {
+		return nil, err
+	}
+	if opts.checkYanked && !kclPkg.IsVendorMode() {
+		c.warnYankedDeps(ctx, kclPkg)
+	}
+	if opts.cache != nil {
+		opts.cache.homePath = kclPkg.HomePath
+		opts.cache.pkgMap = pkgMap
+	}
+	return pkgMap, nil
+}
+
 // ResolveDepsIntoMap will calculate the map of kcl package name and local storage path of the external packages.
 func (c *KpmClient) ResolveDepsIntoMap(kclPkg *pkg.KclPkg) (map[string]string, error) {
-	err := c.ResolvePkgDepsMetadata(kclPkg, true)
+	return c.ResolveDepsIntoMapWithContext(context.Background(), kclPkg)
+}
+
+// ResolveDepsIntoMapWithContext is the same as ResolveDepsIntoMap,
+// and the download of the dependencies is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) ResolveDepsIntoMapWithContext(ctx context.Context, kclPkg *pkg.KclPkg) (map[string]string, error) {
+	err := c.ResolvePkgDepsMetadataWithContext(ctx, kclPkg, true)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,250 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"kcl-lang.io/kcl-go/pkg/kcl"
+	"kcl-lang.io/kpm/pkg/downloader"
+)
+
+const (
+	// RUN_FORMAT_YAML is the format of the results of RunPkgs as a multi-document YAML stream.
+	RUN_FORMAT_YAML = "yaml"
+	// RUN_FORMAT_JSON is the format of the results of RunPkgs as a JSON map keyed by the sources.
+	RUN_FORMAT_JSON = "json"
+)
+
+// WithRunParallelism sets the max number of the packages compiled in parallel by RunPkgs,
+// it is the number of the CPUs if it is not set.
+func WithRunParallelism(parallelism int) RunOption {
+	return func(ro *RunOptions) error {
+		if parallelism <= 0 {
+			return fmt.Errorf("invalid parallelism '%d'", parallelism)
+		}
+		ro.parallelism = parallelism
+		return nil
+	}
+}
+
+// withResolveMutex sets the mutex to serialize the resolving of the dependencies of the packages run in parallel.
+func withResolveMutex(mu *sync.Mutex) RunOption {
+	return func(ro *RunOptions) error {
+		ro.resolveMu = mu
+		return nil
+	}
+}
+
+// PkgRunResult is the result of one of the packages run by RunPkgs.
+type PkgRunResult struct {
+	// Source is the url of the packaged source, or the root path of the local package.
+	Source string
+	*kcl.KCLResultList
+}
+
+// RunPkgsResult contains the results of the packages run by RunPkgs in the order of the sources.
+type RunPkgsResult struct {
+	Results []*PkgRunResult
+}
+
+// YamlStream returns the results as a multi-document YAML stream,
+// the documents of each package are headed by a comment with the source of the package.
+func (r *RunPkgsResult) YamlStream() string {
+	var docs []string
+	for _, res := range r.Results {
+		docs = append(docs, fmt.Sprintf("# Source: %s\n%s", res.Source, strings.TrimSpace(res.GetRawYamlResult())))
+	}
+	return strings.Join(docs, "\n---\n")
+}
+
+// JsonMap returns the results as a JSON object keyed by the sources of the packages.
+func (r *RunPkgsResult) JsonMap() (string, error) {
+	results := make(map[string]json.RawMessage, len(r.Results))
+	for _, res := range r.Results {
+		raw := strings.TrimSpace(res.GetRawJsonResult())
+		if raw == "" {
+			raw = "null"
+		}
+		results[res.Source] = json.RawMessage(raw)
+	}
+	content, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the results into json: %w", err)
+	}
+	return string(content), nil
+}
+
+// RunPkgs runs the packages of the sources, e.g. the modules of several environments from OCI or git, in one pass.
+// Each packaged source is a package, and the local sources are grouped into the packages by their root paths.
+// The packages are compiled in parallel, each with its own dependencies and the compile options in its kcl.mod,
+// and the dependencies are resolved one package at a time because the package cache is shared.
+// The other packages are canceled if one of them is failed.
+func (c *KpmClient) RunPkgs(options ...RunOption) (*RunPkgsResult, error) {
+	return c.RunPkgsWithContext(context.Background(), options...)
+}
+
+// RunPkgsWithContext is the same as RunPkgs, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) RunPkgsWithContext(ctx context.Context, options ...RunOption) (*RunPkgsResult, error) {
+	opts := &RunOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	groups, err := opts.groupPkgSources()
+	if err != nil {
+		return nil, err
+	}
+
+	parallelism := opts.parallelism
+	if parallelism == 0 {
+		parallelism = runtime.NumCPU()
+	}
+	// The credentials are loaded before the packages are run in parallel.
+	if _, err := c.GetCredsClient(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	logWriter := &syncWriter{writer: c.GetLogWriter()}
+	resolveMu := &sync.Mutex{}
+	sem := make(chan struct{}, parallelism)
+
+	results := make([]*PkgRunResult, len(groups))
+	errs := make([]error, len(groups))
+	var wg sync.WaitGroup
+	for i, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			res, err := c.parallelClient(logWriter).RunWithContext(
+				ctx,
+				append(append([]RunOption{}, options...), WithRunSources(group.sources), withResolveMutex(resolveMu))...,
+			)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to run '%s': %w", group.key, err)
+				cancel()
+				return
+			}
+			results[i] = &PkgRunResult{Source: group.key, KCLResultList: res}
+		}()
+	}
+	wg.Wait()
+
+	// The error of the failed package is returned instead of the errors of the packages canceled by it.
+	var firstErr error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return nil, err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return &RunPkgsResult{Results: results}, nil
+}
+
+// pkgSourceGroup is the sources of one of the packages run by RunPkgs.
+type pkgSourceGroup struct {
+	key     string
+	sources []*downloader.Source
+}
+
+// groupPkgSources groups the sources by the packages, each packaged source is a package,
+// and the local sources are grouped by their root paths.
+// The work directory is the package if no sources are set.
+func (o *RunOptions) groupPkgSources() ([]*pkgSourceGroup, error) {
+	if len(o.Sources) == 0 {
+		workDir := ""
+		if o.Option != nil {
+			workDir = o.WorkDir
+		}
+		workDir, err := filepath.Abs(workDir)
+		if err != nil {
+			return nil, err
+		}
+		return []*pkgSourceGroup{{key: workDir}}, nil
+	}
+
+	var groups []*pkgSourceGroup
+	localGroups := map[string]*pkgSourceGroup{}
+	for _, source := range o.Sources {
+		if source.IsPackaged() {
+			key, err := source.ToString()
+			if err != nil {
+				return nil, err
+			}
+			for _, group := range groups {
+				if group.key == key {
+					return nil, fmt.Errorf("the package '%s' is specified more than once", key)
+				}
+			}
+			groups = append(groups, &pkgSourceGroup{key: key, sources: []*downloader.Source{source}})
+			continue
+		}
+
+		rootPath, err := source.FindRootPath()
+		if err != nil {
+			return nil, err
+		}
+		if group, ok := localGroups[rootPath]; ok {
+			group.sources = append(group.sources, source)
+			continue
+		}
+		group := &pkgSourceGroup{key: rootPath, sources: []*downloader.Source{source}}
+		localGroups[rootPath] = group
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// parallelClient returns a copy of the client to run a package in parallel with the others,
+// the copy has its own downloader and writes the logs by the shared synchronized writer.
+func (c *KpmClient) parallelClient(logWriter io.Writer) *KpmClient {
+	cc := *c
+	if c.DepDownloader != nil {
+		depDownloader := *c.DepDownloader
+		cc.DepDownloader = &depDownloader
+	}
+	cc.logWriter = logWriter
+	return &cc
+}
+
+// syncWriter serializes the logs written by the packages run in parallel.
+type syncWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.writer == nil {
+		return len(p), nil
+	}
+	return w.writer.Write(p)
+}
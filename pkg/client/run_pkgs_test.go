@@ -0,0 +1,95 @@
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRunPkg creates a kcl package 'name' with the 'main.k' in a temporary directory.
+func newTestRunPkg(t *testing.T, name, main string) string {
+	pkgPath := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.MkdirAll(pkgPath, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgPath, "kcl.mod"), []byte("[package]\nname = \""+name+"\"\nversion = \"0.0.1\"\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgPath, "main.k"), []byte(main), 0644))
+	return pkgPath
+}
+
+func TestGroupPkgSources(t *testing.T) {
+	dev := newTestRunPkg(t, "dev", "env = \"dev\"\n")
+	prod := newTestRunPkg(t, "prod", "env = \"prod\"\n")
+	assert.NoError(t, os.WriteFile(filepath.Join(prod, "sub.k"), []byte("replicas = 3\n"), 0644))
+
+	opts := &RunOptions{}
+	assert.NoError(t, WithRunSourceUrls([]string{
+		filepath.Join(dev, "main.k"),
+		"oci://localhost:5101/kcl-lang/helloworld?tag=0.1.0",
+		filepath.Join(prod, "main.k"),
+		filepath.Join(prod, "sub.k"),
+		"git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests?branch=main",
+	})(opts))
+	groups, err := opts.groupPkgSources()
+	assert.NoError(t, err)
+
+	var keys []string
+	for _, group := range groups {
+		keys = append(keys, group.key)
+	}
+	assert.Equal(t, 5, len(opts.Sources))
+	assert.Equal(t, 4, len(groups))
+	assert.Equal(t, dev, keys[0])
+	assert.True(t, strings.Contains(keys[1], "localhost:5101/kcl-lang/helloworld"))
+	assert.Equal(t, prod, keys[2])
+	assert.True(t, strings.Contains(keys[3], "127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests"))
+	assert.Equal(t, 2, len(groups[2].sources))
+
+	opts = &RunOptions{}
+	assert.NoError(t, WithRunSourceUrls([]string{
+		"oci://localhost:5101/kcl-lang/helloworld?tag=0.1.0",
+		"oci://localhost:5101/kcl-lang/helloworld?tag=0.1.0",
+	})(opts))
+	_, err = opts.groupPkgSources()
+	assert.ErrorContains(t, err, "is specified more than once")
+
+	opts = &RunOptions{}
+	assert.NoError(t, WithWorkDir(dev)(opts))
+	groups, err = opts.groupPkgSources()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(groups))
+	assert.Equal(t, dev, groups[0].key)
+	assert.Empty(t, groups[0].sources)
+}
+
+func TestRunPkgs(t *testing.T) {
+	dev := newTestRunPkg(t, "dev", "env = \"dev\"\n")
+	prod := newTestRunPkg(t, "prod", "env = \"prod\"\n")
+
+	kpmcli, err := NewKpmClient()
+	assert.NoError(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	res, err := kpmcli.RunPkgs(WithRunSourceUrls([]string{dev, prod}), WithRunParallelism(2))
+	assert.NoError(t, err)
+	if err != nil {
+		return
+	}
+	assert.Equal(t, 2, len(res.Results))
+	assert.Equal(t, "# Source: "+dev+"\nenv: dev\n---\n# Source: "+prod+"\nenv: prod", res.YamlStream())
+
+	jsonMap, err := res.JsonMap()
+	assert.NoError(t, err)
+	var results map[string]map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(jsonMap), &results))
+	assert.Equal(t, map[string]map[string]string{
+		dev:  {"env": "dev"},
+		prod: {"env": "prod"},
+	}, results)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(prod, "main.k"), []byte("env = \n"), 0644))
+	_, err = kpmcli.RunPkgs(WithRunSourceUrls([]string{dev, prod}))
+	assert.ErrorContains(t, err, "failed to run '"+prod+"'")
+}
@@ -0,0 +1,355 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/oci"
+	"kcl-lang.io/kpm/pkg/opt"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/semver"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// SearchResult is a kcl package found by the Search method.
+type SearchResult struct {
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// Version is the latest version of the package.
+	Version string `json:"version"`
+	// Description is the description of the package.
+	Description string `json:"description,omitempty"`
+	// Source is the url of the package, e.g. 'oci://ghcr.io/kcl-lang/helloworld'.
+	Source string `json:"source"`
+}
+
+// SearchIndex is the backend to search the kcl packages.
+// The registries without the OCI catalog API can be searched by a custom SearchIndex.
+type SearchIndex interface {
+	// Search returns the packages whose name contains 'term', all the packages are returned if 'term' is empty.
+	// The requests to the backend should be aborted when the context 'ctx' is canceled.
+	Search(ctx context.Context, term string) ([]SearchResult, error)
+}
+
+// SearchOptions contains the options for the Search method.
+type SearchOptions struct {
+	// Term is the term to search.
+	Term string
+	// Indexes are the backends to search, the OCI catalog of the default registry is searched if it is empty.
+	Indexes []SearchIndex
+}
+
+type SearchOption func(*SearchOptions) error
+
+// WithSearchTerm sets the term to search for the Search method.
+func WithSearchTerm(term string) SearchOption {
+	return func(opts *SearchOptions) error {
+		opts.Term = term
+		return nil
+	}
+}
+
+// WithSearchIndex adds the backend to search for the Search method.
+func WithSearchIndex(index SearchIndex) SearchOption {
+	return func(opts *SearchOptions) error {
+		if index == nil {
+			return errors.New("search index cannot be nil")
+		}
+		opts.Indexes = append(opts.Indexes, index)
+		return nil
+	}
+}
+
+// Search will search the kcl packages in the indexes, the results are sorted by the name and the source.
+func (c *KpmClient) Search(options ...SearchOption) ([]SearchResult, error) {
+	return c.SearchWithContext(context.Background(), options...)
+}
+
+// SearchWithContext is the same as Search, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) SearchWithContext(ctx context.Context, options ...SearchOption) ([]SearchResult, error) {
+	opts := &SearchOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(opts.Indexes) == 0 {
+		opts.Indexes = append(opts.Indexes, c.NewOciCatalogIndex(
+			c.GetSettings().DefaultOciRegistry(),
+			c.GetSettings().DefaultOciRepo(),
+		))
+	}
+
+	var results []SearchResult
+	for _, index := range opts.Indexes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res, err := index.Search(ctx, opts.Term)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].Source < results[j].Source
+	})
+
+	return results, nil
+}
+
+// matchSearchTerm returns true if 'name' contains the 'term' case-insensitively.
+func matchSearchTerm(name, term string) bool {
+	return strings.Contains(strings.ToLower(name), strings.ToLower(term))
+}
+
+// OciCatalogIndex searches the packages in an OCI registry by the catalog API '/v2/_catalog'.
+type OciCatalogIndex struct {
+	// Reg is the OCI registry, e.g. 'ghcr.io'.
+	Reg string
+	// RepoPrefix is the prefix of the repositories of the packages, e.g. 'kcl-lang'.
+	RepoPrefix string
+	client     *KpmClient
+}
+
+// NewOciCatalogIndex returns the OciCatalogIndex of the registry 'reg' with the credential and settings of the client.
+func (c *KpmClient) NewOciCatalogIndex(reg, repoPrefix string) *OciCatalogIndex {
+	return &OciCatalogIndex{
+		Reg:        reg,
+		RepoPrefix: strings.Trim(repoPrefix, "/"),
+		client:     c,
+	}
+}
+
+// Search lists the repositories under 'RepoPrefix', and fetches the latest version and
+// the 'org.kcllang.package.description' annotation of the manifest of the packages whose name contains 'term'.
+func (idx *OciCatalogIndex) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	cred, err := idx.client.GetCredentials(idx.Reg)
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := oci.ListRepositories(
+		idx.Reg,
+		oci.WithCredential(cred),
+		oci.WithSettings(idx.client.GetSettings()),
+		oci.WithInsecureSkipTLSverify(idx.client.insecureSkipTLSverify),
+		oci.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, repo := range repos {
+		name := repo
+		if idx.RepoPrefix != "" {
+			if !strings.HasPrefix(repo, idx.RepoPrefix+"/") {
+				continue
+			}
+			name = strings.TrimPrefix(repo, idx.RepoPrefix+"/")
+		}
+		if !matchSearchTerm(name, term) {
+			continue
+		}
+
+		result, err := idx.fetchResult(ctx, repo, name, cred)
+		if err != nil {
+			reporter.ReportMsgTo(fmt.Sprintf("skip '%s': %v", utils.JoinPath(idx.Reg, repo), err), idx.client.GetLogWriter())
+			continue
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// fetchResult fetches the latest version and the description of the package in the repository 'repo'.
+func (idx *OciCatalogIndex) fetchResult(ctx context.Context, repo, name string, cred *remoteauth.Credential) (*SearchResult, error) {
+	ociCli, err := oci.NewOciClientWithOpts(
+		oci.WithCredential(cred),
+		oci.WithRepoPath(utils.JoinPath(idx.Reg, repo)),
+		oci.WithSettings(idx.client.GetSettings()),
+		oci.WithInsecureSkipTLSverify(idx.client.insecureSkipTLSverify),
+		oci.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := ociCli.TheLatestTag()
+	if err != nil {
+		return nil, err
+	}
+
+	manifestJson, err := ociCli.FetchManifestIntoJsonStr(opt.OciFetchOptions{
+		OciOptions: opt.OciOptions{Tag: tag},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal([]byte(manifestJson), &manifest); err != nil {
+		return nil, err
+	}
+
+	return &SearchResult{
+		Name:        name,
+		Version:     tag,
+		Description: manifest.Annotations[constants.DEFAULT_KCL_OCI_MANIFEST_DESCRIPTION],
+		Source:      fmt.Sprintf("%s://%s", constants.OciScheme, utils.JoinPath(idx.Reg, repo)),
+	}, nil
+}
+
+// GitIndex searches the packages in the git repositories, the name of a package is the name of its repository,
+// and the latest version is selected from the releases listed by the release lister of the git host.
+type GitIndex struct {
+	// Repos are the urls of the git repositories.
+	Repos  []string
+	client *KpmClient
+}
+
+// NewGitIndex returns the GitIndex of the git repositories 'repos', the releases are listed with the context of the client.
+func (c *KpmClient) NewGitIndex(repos []string) *GitIndex {
+	return &GitIndex{
+		Repos:  repos,
+		client: c,
+	}
+}
+
+// Search returns the packages in the repositories whose name contains 'term'.
+func (idx *GitIndex) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	var results []SearchResult
+	for _, repo := range idx.Repos {
+		name := utils.ParseRepoNameFromGitUrl(strings.TrimSuffix(repo, "/"))
+		if !matchSearchTerm(name, term) {
+			continue
+		}
+
+		lister := git.GetReleaseLister(repo)
+		// The releases are listed with the credential of the git host in the settings of the client.
+		if idx.client != nil {
+			credCli, err := idx.client.GetCredsClient()
+			if err != nil {
+				return nil, err
+			}
+			gitCred, err := credCli.GitCredential(repo)
+			if err != nil {
+				return nil, err
+			}
+			lister = git.ReleaseListerWithCredential(lister, gitCred)
+		}
+		releases, err := git.ListReleasesWithContext(ctx, lister, repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the releases of '%s': %w", repo, err)
+		}
+		latest, err := semver.LatestVersion(releases)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select the latest version of '%s': %w", repo, err)
+		}
+
+		results = append(results, SearchResult{
+			Name:    name,
+			Version: latest,
+			Source:  repo,
+		})
+	}
+
+	return results, nil
+}
+
+// JsonIndex searches the packages in a json index file, which is useful for the registries without the catalog API.
+// The index is a local file or a http(s) url in the format:
+//
+//	{"packages": [{"name": "helloworld", "version": "0.1.0", "description": "...", "source": "oci://ghcr.io/kcl-lang/helloworld"}]}
+type JsonIndex struct {
+	// Location is the local path or the http(s) url of the index file.
+	Location string
+	// Timeout is the time limit of fetching the index from the http(s) url, 'DEFAULT_INDEX_TIMEOUT' by default.
+	Timeout time.Duration
+	client  *KpmClient
+}
+
+// DEFAULT_INDEX_TIMEOUT is the default time limit of fetching the index from the http(s) url.
+const DEFAULT_INDEX_TIMEOUT = 10 * time.Second
+
+// NewJsonIndex returns the JsonIndex of the index file 'location', the index is fetched with the context of the client.
+func (c *KpmClient) NewJsonIndex(location string) *JsonIndex {
+	return &JsonIndex{
+		Location: location,
+		client:   c,
+	}
+}
+
+// Search returns the packages in the index whose name or description contains 'term'.
+func (idx *JsonIndex) Search(ctx context.Context, term string) ([]SearchResult, error) {
+	content, err := idx.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the search index '%s': %w", idx.Location, err)
+	}
+
+	index := struct {
+		Packages []SearchResult `json:"packages"`
+	}{}
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("invalid search index '%s': %w", idx.Location, err)
+	}
+
+	var results []SearchResult
+	for _, pkg := range index.Packages {
+		if matchSearchTerm(pkg.Name, term) || matchSearchTerm(pkg.Description, term) {
+			results = append(results, pkg)
+		}
+	}
+
+	return results, nil
+}
+
+// load reads the index file from the local path or the http(s) url.
+func (idx *JsonIndex) load(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(idx.Location, constants.HttpScheme+"://") && !strings.HasPrefix(idx.Location, constants.HttpsScheme+"://") {
+		return os.ReadFile(idx.Location)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, idx.Location, nil)
+	if err != nil {
+		return nil, err
+	}
+	timeout := idx.Timeout
+	if timeout == 0 {
+		timeout = DEFAULT_INDEX_TIMEOUT
+	}
+	client := http.Client{
+		Timeout: timeout,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
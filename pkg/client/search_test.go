@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/constants"
+)
+
+// newTestCatalogRegistry starts a registry serving the catalog, the tags and the manifests of the 'repos',
+// and returns its host.
+func newTestCatalogRegistry(t *testing.T, repos map[string]map[string]string) string {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v2/")
+		switch {
+		case path == "_catalog":
+			var names []string
+			for name := range repos {
+				names = append(names, name)
+			}
+			_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": names})
+		case strings.HasSuffix(path, "/tags/list"):
+			name := strings.TrimSuffix(path, "/tags/list")
+			var tags []string
+			for tag := range repos[name] {
+				tags = append(tags, tag)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": name, "tags": tags})
+		case strings.Contains(path, "/manifests/"):
+			parts := strings.SplitN(path, "/manifests/", 2)
+			description, ok := repos[parts[0]][parts[1]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			manifest, _ := json.Marshal(ocispec.Manifest{
+				MediaType:   ocispec.MediaTypeImageManifest,
+				Config:      ocispec.DescriptorEmptyJSON,
+				Layers:      []ocispec.Descriptor{},
+				Annotations: map[string]string{constants.DEFAULT_KCL_OCI_MANIFEST_DESCRIPTION: description},
+			})
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+			w.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(manifest)))
+			if r.Method != http.MethodHead {
+				_, _ = w.Write(manifest)
+			}
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	// The plain http is used for the localhost registry.
+	return strings.Replace(strings.TrimPrefix(server.URL, "http://"), "127.0.0.1", "localhost", 1)
+}
+
+func TestSearchOciCatalog(t *testing.T) {
+	reg := newTestCatalogRegistry(t, map[string]map[string]string{
+		"kcl-lang/helloworld": {"0.1.0": "old", "0.1.1": "This is the hello world package"},
+		"kcl-lang/k8s":        {"1.31.2": "Kubernetes schemas"},
+		"other/helloworld":    {"0.0.1": "not in the repo prefix"},
+	})
+
+	kpmcli, err := NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	results, err := kpmcli.Search(
+		WithSearchTerm("Hello"),
+		WithSearchIndex(kpmcli.NewOciCatalogIndex(reg, "kcl-lang")),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []SearchResult{{
+		Name:        "helloworld",
+		Version:     "0.1.1",
+		Description: "This is the hello world package",
+		Source:      fmt.Sprintf("oci://%s/kcl-lang/helloworld", reg),
+	}}, results)
+
+	results, err = kpmcli.Search(WithSearchIndex(kpmcli.NewOciCatalogIndex(reg, "")))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+}
+
+func TestSearchJsonIndex(t *testing.T) {
+	kpmcli, err := NewKpmClient()
+	assert.Nil(t, err)
+
+	index := &JsonIndex{Location: filepath.Join(getTestDir("test_search"), "index.json")}
+	results, err := kpmcli.Search(WithSearchTerm("kubernetes"), WithSearchIndex(index))
+	assert.Nil(t, err)
+	assert.Equal(t, []SearchResult{{
+		Name:        "k8s",
+		Version:     "1.31.2",
+		Description: "Kubernetes schemas",
+		Source:      "oci://localhost:5101/kcl-lang/k8s",
+	}}, results)
+
+	results, err = kpmcli.Search(WithSearchIndex(index))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "helloworld", results[0].Name)
+}
+
+func TestSearchJsonIndexWithContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"packages": [{"name": "helloworld", "version": "0.1.0", "source": "oci://localhost:5101/kcl-lang/helloworld"}]}`)
+	}))
+	defer server.Close()
+
+	kpmcli, err := NewKpmClient()
+	assert.Nil(t, err)
+
+	results, err := kpmcli.NewJsonIndex(server.URL).Search(context.Background(), "hello")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = kpmcli.NewJsonIndex(server.URL).Search(ctx, "hello")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSearchJsonIndexWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	kpmcli, err := NewKpmClient()
+	assert.Nil(t, err)
+
+	idx := kpmcli.NewJsonIndex(server.URL)
+	idx.Timeout = 50 * time.Millisecond
+	_, err = idx.Search(context.Background(), "hello")
+	assert.ErrorContains(t, err, "Client.Timeout exceeded")
+}
@@ -5,4 +5,4 @@ version = "0.0.1"
 
 [dependencies]
 dep_pkg = { path = "../dep_pkg" }
-helloworld = { oci = "oci://ghcr.io/kcl-lang/helloworld", tag = "0.1.2" }
+helloworld = { oci = "oci://localhost:5101/kcl-lang/helloworld", tag = "0.1.2" }
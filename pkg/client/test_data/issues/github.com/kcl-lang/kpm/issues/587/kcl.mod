@@ -4,4 +4,4 @@ edition = "v0.11.2"
 version = "0.0.1"
 
 [dependencies]
-flask_manifests = { git = "git://github.com/kcl-lang/flask-demo-kcl-manifests.git", tag = "v0.1.0", version = "0.0.1" }
+flask_manifests = { git = "git://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git", tag = "v0.1.0", version = "0.0.1" }
@@ -0,0 +1,4 @@
+[package]
+name = "flask_manifests"
+edition = "v0.9.0"
+version = "0.0.1"
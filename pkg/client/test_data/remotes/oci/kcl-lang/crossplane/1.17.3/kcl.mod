@@ -0,0 +1,7 @@
+[package]
+name = "crossplane"
+edition = "v0.9.0"
+version = "1.17.3"
+
+[dependencies]
+k8s = "1.31.2"
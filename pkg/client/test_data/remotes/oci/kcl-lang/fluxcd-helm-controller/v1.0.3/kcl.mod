@@ -0,0 +1,8 @@
+[package]
+name = "fluxcd-helm-controller"
+edition = "v0.9.0"
+version = "v1.0.3"
+
+[dependencies]
+fluxcd-source-controller = "v1.3.2"
+k8s = "1.31.2"
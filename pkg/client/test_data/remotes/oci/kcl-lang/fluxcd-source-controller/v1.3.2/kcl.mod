@@ -0,0 +1,7 @@
+[package]
+name = "fluxcd-source-controller"
+edition = "v0.9.0"
+version = "v1.3.2"
+
+[dependencies]
+k8s = "1.31.2"
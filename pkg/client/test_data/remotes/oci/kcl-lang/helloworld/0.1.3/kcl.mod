@@ -0,0 +1,4 @@
+[package]
+name = "helloworld"
+edition = "*"
+version = "0.1.3"
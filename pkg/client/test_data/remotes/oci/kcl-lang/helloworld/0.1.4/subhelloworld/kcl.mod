@@ -0,0 +1,4 @@
+[package]
+name = "subhelloworld"
+edition = "*"
+version = "0.0.1"
@@ -0,0 +1,4 @@
+[package]
+name = "json_merge_patch"
+edition = "v0.9.0"
+version = "0.1.1"
@@ -0,0 +1,4 @@
+[package]
+name = "k8s"
+edition = "v0.9.0"
+version = "1.15"
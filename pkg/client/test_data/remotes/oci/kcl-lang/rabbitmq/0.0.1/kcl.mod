@@ -0,0 +1,7 @@
+[package]
+name = "rabbitmq"
+edition = "v0.9.0"
+version = "0.0.1"
+
+[dependencies]
+k8s = "1.28"
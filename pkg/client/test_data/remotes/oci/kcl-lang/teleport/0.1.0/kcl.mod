@@ -0,0 +1,7 @@
+[package]
+name = "teleport"
+edition = "v0.9.0"
+version = "0.1.0"
+
+[dependencies]
+k8s = "1.28"
@@ -4,4 +4,4 @@ edition = "0.0.1"
 version = "0.0.1"
 
 [dependencies]
-flask-demo-kcl-manifests = { git = "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", commit = "ade147b" }
+flask-demo-kcl-manifests = { git = "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git", commit = "2d93a6a" }
@@ -4,8 +4,8 @@ edition = "v0.11.2"
 version = "0.0.1"
 
 [dependencies]
-helloworld = { oci = "oci://ghcr.io/kcl-lang/helloworld", tag = "0.1.2" }
-jsonpatch = { oci = "oci://ghcr.io/kcl-lang/jsonpatch", tag = "0.0.5" }
+helloworld = { oci = "oci://localhost:5101/kcl-lang/helloworld", tag = "0.1.2" }
+jsonpatch = { oci = "oci://localhost:5101/kcl-lang/jsonpatch", tag = "0.0.5" }
 
 [profile]
 entries = ["./sub/main.k"]
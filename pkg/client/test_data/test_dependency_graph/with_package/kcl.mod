@@ -4,4 +4,4 @@ edition = "0.0.1"
 version = "0.0.1"
 
 [dependencies]
-agent = { git = "https://github.com/kcl-lang/modules.git", commit = "16eab4efa2af84d7d641b23f3a3d14d4d891cb9e", package = "agent" }
\ No newline at end of file
+agent = { git = "https://127.0.0.1:5102/kcl-lang/modules.git", commit = "701a2ca0ec788b847a7e82781aa95376d8bb588b", package = "agent" }
\ No newline at end of file
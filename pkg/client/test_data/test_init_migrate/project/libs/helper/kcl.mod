@@ -0,0 +1,4 @@
+[package]
+name = "helper"
+edition = "v0.11.2"
+version = "0.1.0"
@@ -4,4 +4,4 @@ edition = "v0.11.2"
 version = "0.0.1"
 
 [dependencies]
-cc = { git = "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", commit = "8308200", package = "cc" }
+cc = { git = "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git", commit = "4954771", package = "cc" }
@@ -4,4 +4,4 @@ edition = "v0.11.0-alpha.1"
 version = "0.0.1"
 
 [dependencies]
-helloworld = { oci = "oci://ghcr.io/kcl-lang/helloworld", tag = "0.1.2", version = "0.1.2" }
+helloworld = { oci = "oci://localhost:5101/kcl-lang/helloworld", tag = "0.1.2", version = "0.1.2" }
@@ -4,4 +4,4 @@ edition = "v0.11.0-alpha.1"
 version = "0.0.1"
 
 [dependencies]
-flask_manifests = { git = "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", branch = "main", version = "0.0.1" }
+flask_manifests = { git = "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git", branch = "main", version = "0.0.1" }
@@ -4,4 +4,4 @@ edition = "v0.11.2"
 version = "0.0.1"
 
 [dependencies]
-helloworld = { oci = "oci://ghcr.io/zong-zhe/helloworld", tag = "0.0.3" }
+helloworld = { oci = "oci://localhost:5101/zong-zhe/helloworld", tag = "0.0.3" }
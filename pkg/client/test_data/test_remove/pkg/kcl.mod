@@ -0,0 +1,8 @@
+[package]
+name = "pkg"
+edition = "v0.9.0"
+version = "0.0.1"
+
+[dependencies]
+helper = { path = "../helper" }
+other = { path = "../other" }
@@ -4,4 +4,4 @@ edition = "v0.8.0"
 version = "0.0.1"
 
 [dependencies]
-k8s = { git = "https://github.com/kcl-lang/modules.git", commit = "ee03122b5f45b09eb48694422fc99a0772f6bba8", package = "k8s", version = "1.31" }
+k8s = { git = "https://127.0.0.1:5102/kcl-lang/modules.git", commit = "701a2ca0ec788b847a7e82781aa95376d8bb588b", package = "k8s", version = "1.31" }
@@ -4,7 +4,7 @@ edition = "v0.11.2"
 version = "0.0.1"
 
 [dependencies]
-flask_manifests = { git = "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", commit = "ade147b", version = "0.0.1" }
+flask_manifests = { git = "https://127.0.0.1:5102/kcl-lang/flask-demo-kcl-manifests.git", commit = "2d93a6a", version = "0.0.1" }
 
 [profile]
 entries = ["main.k", "${flask-manifests:KCL_MOD}/main.k"]
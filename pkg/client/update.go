@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
@@ -13,6 +14,7 @@ import (
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/resolver"
+	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/utils"
 	"oras.land/oras-go/v2"
 )
@@ -53,6 +55,11 @@ func WithUpdatedKclPkg(kpkg *pkg.KclPkg) UpdateOption {
 }
 
 func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
+	return c.UpdateWithContext(context.Background(), options...)
+}
+
+// UpdateWithContext is the same as Update, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) UpdateWithContext(ctx context.Context, options ...UpdateOption) (*pkg.KclPkg, error) {
 	opts := &UpdateOptions{updateModFile: true}
 	for _, option := range options {
 		if err := option(opts); err != nil {
@@ -140,7 +147,7 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 
 		selectedDep.LocalFullPath = dep.LocalFullPath
 		if selectedDep.Sum == "" {
-			sum, err := c.AcquireDepSum(*selectedDep)
+			sum, err := c.AcquireDepSumWithContext(ctx, *selectedDep, kMod.ModFile.Registries...)
 			if err != nil {
 				return err
 			}
@@ -154,7 +161,8 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 	}
 	depResolver.ResolveFuncs = append(depResolver.ResolveFuncs, resolverFunc)
 
-	err := depResolver.Resolve(
+	err := depResolver.ResolveWithContext(
+		ctx,
 		resolver.WithResolveKclMod(kMod),
 		resolver.WithEnableCache(true),
 		resolver.WithCachePath(c.homePath),
@@ -180,24 +188,31 @@ func (c *KpmClient) Update(options ...UpdateOption) (*pkg.KclPkg, error) {
 		}
 	}
 
+	if !opts.offline && !kMod.IsVendorMode() {
+		c.warnYankedDeps(ctx, kMod)
+	}
+
 	return kMod, nil
 }
 
-// AcquireDepSum will acquire the checksum of the dependency from the OCI registry.
-func (c *KpmClient) AcquireDepSum(dep pkg.Dependency) (string, error) {
+// AcquireDepSum will acquire the checksum of the dependency from the OCI registry,
+// the missing registry and repo are resolved by the named 'registries' in kcl.mod and the settings.
+func (c *KpmClient) AcquireDepSum(dep pkg.Dependency, registries ...settings.OciRegistry) (string, error) {
+	return c.AcquireDepSumWithContext(context.Background(), dep, registries...)
+}
+
+// AcquireDepSumWithContext is the same as AcquireDepSum, and the request to the OCI registry is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) AcquireDepSumWithContext(ctx context.Context, dep pkg.Dependency, registries ...settings.OciRegistry) (string, error) {
 	// Only the dependencies from the OCI need can be checked.
 	if dep.Source.Oci != nil {
-		if len(dep.Source.Oci.Reg) == 0 {
-			dep.Source.Oci.Reg = c.GetSettings().DefaultOciRegistry()
-		}
-
-		if len(dep.Source.Oci.Repo) == 0 {
-			urlpath := utils.JoinPath(c.GetSettings().DefaultOciRepo(), dep.Name)
-			dep.Source.Oci.Repo = urlpath
-		}
+		dep.Source.Oci.FillRepo(dep.Name, c.GetSettings(), registries...)
 		// Fetch the metadata of the OCI manifest.
 		manifest := ocispec.Manifest{}
-		jsonDesc, err := c.FetchOciManifestIntoJsonStr(opt.OciFetchOptions{
+		ociCli, err := c.newOciClient(ctx, dep.Source.Oci)
+		if err != nil {
+			return "", err
+		}
+		jsonDesc, err := ociCli.FetchManifestIntoJsonStr(opt.OciFetchOptions{
 			FetchBytesOptions: oras.DefaultFetchBytesOptions,
 			OciOptions: opt.OciOptions{
 				Reg:  dep.Source.Oci.Reg,
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,6 +20,11 @@ import (
 
 // VendorDeps will vendor all the dependencies of the current kcl package.
 func (c *KpmClient) VendorDeps(kclPkg *pkg.KclPkg) error {
+	return c.VendorDepsWithContext(context.Background(), kclPkg)
+}
+
+// VendorDepsWithContext is the same as VendorDeps, and the download of the dependencies is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) VendorDepsWithContext(ctx context.Context, kclPkg *pkg.KclPkg) error {
 	// Mkdir the dir "vendor".
 	vendorPath := kclPkg.LocalVendorPath()
 	err := os.MkdirAll(vendorPath, 0755)
@@ -26,14 +32,14 @@ func (c *KpmClient) VendorDeps(kclPkg *pkg.KclPkg) error {
 		return err
 	}
 
-	return c.vendorDeps(kclPkg, vendorPath)
+	return c.vendorDeps(ctx, kclPkg, vendorPath)
 }
 
-func (c *KpmClient) vendorDeps(kclPkg *pkg.KclPkg, vendorPath string) error {
+func (c *KpmClient) vendorDeps(ctx context.Context, kclPkg *pkg.KclPkg, vendorPath string) error {
 	if ok, err := features.Enabled(features.SupportMVS); err == nil && ok {
 		// Select all the vendored dependencies
 		// and fill the vendored dependencies into kclPkg.Dependencies.Deps
-		err := c.selectVendoredDeps(kclPkg, vendorPath, kclPkg.Dependencies.Deps)
+		err := c.selectVendoredDeps(ctx, kclPkg, vendorPath, kclPkg.Dependencies.Deps)
 		if err != nil {
 			return err
 		}
@@ -91,7 +97,7 @@ func (c *KpmClient) vendorDeps(kclPkg *pkg.KclPkg, vendorPath string) error {
 				if err != nil {
 					return err
 				}
-				err = c.vendorDeps(dpkg, vendorPath)
+				err = c.vendorDeps(ctx, dpkg, vendorPath)
 				if err != nil {
 					return err
 				}
@@ -120,7 +126,7 @@ func (c *KpmClient) vendorDeps(kclPkg *pkg.KclPkg, vendorPath string) error {
 							return err
 						}
 						// re-vendor again with new kcl.mod and kcl.mod.lock
-						err = c.vendorDeps(kclPkg, vendorPath)
+						err = c.vendorDeps(ctx, kclPkg, vendorPath)
 						if err != nil {
 							return err
 						}
@@ -142,7 +148,7 @@ func (c *KpmClient) vendorDeps(kclPkg *pkg.KclPkg, vendorPath string) error {
 				}
 
 				// Vendor the dependencies of the current dependency.
-				err = c.vendorDeps(dpkg, vendorPath)
+				err = c.vendorDeps(ctx, dpkg, vendorPath)
 				if err != nil {
 					return err
 				}
@@ -161,7 +167,7 @@ func (c *KpmClient) vendorDeps(kclPkg *pkg.KclPkg, vendorPath string) error {
 	return nil
 }
 
-func (c *KpmClient) selectVendoredDeps(kpkg *pkg.KclPkg, vendorPath string, vendoredDeps *orderedmap.OrderedMap[string, pkg.Dependency]) error {
+func (c *KpmClient) selectVendoredDeps(ctx context.Context, kpkg *pkg.KclPkg, vendorPath string, vendoredDeps *orderedmap.OrderedMap[string, pkg.Dependency]) error {
 	// visitorSelectorFunc selects the visitor for the source.
 	// For remote source, it will use the RemoteVisitor and enable the cache.
 	// For local source, it will use the PkgVisitor.
@@ -246,7 +252,7 @@ func (c *KpmClient) selectVendoredDeps(kpkg *pkg.KclPkg, vendorPath string, vend
 				return err
 			}
 			// Vendor the indirected dependencies of the vendored dependency
-			err = c.selectVendoredDeps(dpkg, vendorPath, vendoredDeps)
+			err = c.selectVendoredDeps(ctx, dpkg, vendorPath, vendoredDeps)
 			if err != nil {
 				return err
 			}
@@ -270,7 +276,7 @@ func (c *KpmClient) selectVendoredDeps(kpkg *pkg.KclPkg, vendorPath string, vend
 			if err != nil {
 				return err
 			}
-			err = pkgVisitor.Visit(selectDepSource,
+			err = pkgVisitor.Visit(ctx, selectDepSource,
 				func(kclPkg *pkg.KclPkg) error {
 					existLocalDep, err := c.dependencyExistsLocal(c.homePath, selectedDep, false)
 					if err != nil {
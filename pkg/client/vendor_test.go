@@ -28,7 +28,7 @@ func testVendorDeps(t *testing.T, kpmcli *KpmClient) {
 		Sum:      kcl1Sum,
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/kcl1",
 				Tag:  "0.0.1",
 			},
@@ -42,7 +42,7 @@ func testVendorDeps(t *testing.T, kpmcli *KpmClient) {
 		Sum:      kcl2Sum,
 		Source: downloader.Source{
 			Oci: &downloader.Oci{
-				Reg:  "ghcr.io",
+				Reg:  "localhost:5101",
 				Repo: "kcl-lang/kcl2",
 				Tag:  "0.0.1",
 			},
@@ -0,0 +1,312 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/opt"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// VerifyOptions contains the options for the Verify method.
+type VerifyOptions struct {
+	// Source is the OCI source of the published package.
+	Source *downloader.Source
+	// LocalPath is the local checkout of the package sources.
+	LocalPath string
+	// Git is the git ref of the package sources, it is used if 'LocalPath' is empty.
+	Git *downloader.Git
+	// Registries are the named registries in kcl.mod to resolve the source with only the package spec.
+	Registries []settings.OciRegistry
+}
+
+type VerifyOption func(*VerifyOptions) error
+
+// WithVerifySource sets the OCI source of the published package for the Verify method.
+func WithVerifySource(source *downloader.Source) VerifyOption {
+	return func(opts *VerifyOptions) error {
+		if source == nil {
+			return errors.New("source cannot be nil")
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithVerifySourceUrl sets the OCI url of the published package for the Verify method.
+func WithVerifySourceUrl(sourceUrl string) VerifyOption {
+	return func(opts *VerifyOptions) error {
+		source, err := downloader.NewSourceFromStr(sourceUrl)
+		if err != nil {
+			return err
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithVerifyLocalPath sets the local checkout of the package sources for the Verify method.
+func WithVerifyLocalPath(localPath string) VerifyOption {
+	return func(opts *VerifyOptions) error {
+		opts.LocalPath = localPath
+		return nil
+	}
+}
+
+// WithVerifyGit sets the git ref of the package sources for the Verify method.
+func WithVerifyGit(git *downloader.Git) VerifyOption {
+	return func(opts *VerifyOptions) error {
+		opts.Git = git
+		return nil
+	}
+}
+
+// WithVerifyRegistries sets the named registries in kcl.mod to resolve the source with only the package spec for the Verify method.
+func WithVerifyRegistries(registries ...settings.OciRegistry) VerifyOption {
+	return func(opts *VerifyOptions) error {
+		opts.Registries = registries
+		return nil
+	}
+}
+
+// VerifyResult is the difference between the published package and the package sources.
+type VerifyResult struct {
+	// Ref is the reference of the published package.
+	Ref string `json:"ref"`
+	// Sum is the checksum in the 'org.kcllang.package.sum' annotation of the published package.
+	Sum string `json:"sum"`
+	// LocalSum is the checksum of the package sources.
+	LocalSum string `json:"localSum"`
+	// Added are the files only in the package sources, which are added after the package is published.
+	Added []string `json:"added,omitempty"`
+	// Removed are the files only in the published package, which are removed from the package sources.
+	Removed []string `json:"removed,omitempty"`
+	// Changed are the files with different contents.
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Matched returns true if the published package is the same as the package sources.
+func (r *VerifyResult) Matched() bool {
+	return r.Sum == r.LocalSum && len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// Verify will pull the published package from the OCI registry,
+// and compare its files and checksum annotation with the package sources in a local checkout or a git ref.
+func (c *KpmClient) Verify(options ...VerifyOption) (*VerifyResult, error) {
+	return c.VerifyWithContext(context.Background(), options...)
+}
+
+// VerifyWithContext is the same as Verify, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) VerifyWithContext(ctx context.Context, options ...VerifyOption) (*VerifyResult, error) {
+	opts := &VerifyOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Source == nil {
+		return nil, errors.New("the published package is required")
+	}
+
+	c.resolveSpecOnlySource(opts.Source, opts.Registries...)
+
+	if opts.Source.Oci == nil {
+		sourceStr, _ := opts.Source.ToString()
+		return nil, fmt.Errorf("'%s' is not an oci source, only support oci source", sourceStr)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "verify")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	localPath := opts.LocalPath
+	if localPath == "" {
+		if opts.Git == nil {
+			return nil, errors.New("the local path or the git ref of the package sources is required")
+		}
+		localPath = filepath.Join(tmpDir, constants.GitScheme)
+		gitUrl, err := opts.Git.GetCanonicalizedUrl()
+		if err != nil {
+			return nil, err
+		}
+		credCli, err := c.GetCredsClient()
+		if err != nil {
+			return nil, err
+		}
+		gitCred, err := credCli.GitCredential(gitUrl)
+		if err != nil {
+			return nil, err
+		}
+		reporter.ReportMsgTo(fmt.Sprintf("cloning '%s' with ref '%s'", opts.Git.Url, opts.Git.GetRef()), c.GetLogWriter())
+		_, err = git.CloneWithOpts(
+			git.WithCommit(opts.Git.Commit),
+			git.WithBranch(opts.Git.Branch),
+			git.WithTag(opts.Git.Tag),
+			git.WithRepoURL(gitUrl),
+			git.WithLocalPath(localPath),
+			git.WithCredential(gitCred),
+			git.WithPackage(opts.Git.Package),
+			git.WithSparsePath(opts.Git.Subdir),
+			git.WithContext(ctx),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Git.Package != "" {
+			localPath, err = opts.Git.FindPackage(localPath, gitCred)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	localPkg, err := pkg.LoadKclPkgWithOpts(
+		pkg.WithPath(localPath),
+		pkg.WithSettings(c.GetSettings()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The source of the caller is not changed by the default tag.
+	ociSource := *opts.Source.Oci
+	if ociSource.Tag == "" {
+		ociSource.Tag = localPkg.GetPkgVersion()
+	}
+
+	manifest, err := c.fetchVerifyManifest(ctx, &ociSource)
+	if err != nil {
+		return nil, err
+	}
+	publishedPkg, err := c.Pull(
+		WithPullSource(&downloader.Source{Oci: &ociSource}),
+		WithLocalPath(filepath.Join(tmpDir, constants.OciScheme)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	publishedPath := publishedPkg.HomePath
+
+	// The checksum and the files of the package sources are walked with the same patterns.
+	localSum, err := utils.HashPkgDir(localPath, localPkg.GetPkgInclude(), localPkg.GetPkgExclude())
+	if err != nil {
+		return nil, err
+	}
+
+	ref := utils.JoinPath(ociSource.Reg, ociSource.Repo)
+	if ociSource.Layout {
+		ref, err = (&downloader.Oci{Repo: ociSource.Repo, Layout: true}).ToString()
+		if err != nil {
+			return nil, err
+		}
+	}
+	result := &VerifyResult{
+		Ref:      ref + ":" + ociSource.Tag,
+		Sum:      manifest.Annotations[constants.DEFAULT_KCL_OCI_MANIFEST_SUM],
+		LocalSum: localSum,
+	}
+
+	localFiles, err := hashPkgFiles(localPath, localPkg.GetPkgInclude(), localPkg.GetPkgExclude())
+	if err != nil {
+		return nil, err
+	}
+	publishedFiles, err := hashPkgFiles(publishedPath, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The package sources are compared with the published package.
+	for file, sum := range localFiles {
+		publishedSum, ok := publishedFiles[file]
+		if !ok {
+			result.Added = append(result.Added, file)
+		} else if publishedSum != sum {
+			result.Changed = append(result.Changed, file)
+		}
+	}
+	for file := range publishedFiles {
+		if _, ok := localFiles[file]; !ok {
+			result.Removed = append(result.Removed, file)
+		}
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Changed)
+
+	return result, nil
+}
+
+// fetchVerifyManifest fetches the manifest of the published package from the OCI registry.
+func (c *KpmClient) fetchVerifyManifest(ctx context.Context, ociSource *downloader.Oci) (*ocispec.Manifest, error) {
+	ociCli, err := c.newOciClient(ctx, ociSource)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestJson, err := ociCli.FetchManifestIntoJsonStr(opt.OciFetchOptions{
+		FetchBytesOptions: oras.DefaultFetchBytesOptions,
+		OciOptions: opt.OciOptions{
+			Reg:  ociSource.Reg,
+			Repo: ociSource.Repo,
+			Tag:  ociSource.Tag,
+		},
+	})
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.FailedFetchOciManifest, err, fmt.Sprintf("failed to fetch the manifest of '%s:%s'", ociSource.Repo, ociSource.Tag))
+	}
+
+	manifest := &ocispec.Manifest{}
+	err = json.Unmarshal([]byte(manifestJson), manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// hashPkgFiles returns the sha256 of each file in the package under 'root', indexed by the relative path.
+func hashPkgFiles(root string, include, exclude []string) (map[string]string, error) {
+	files := make(map[string]string)
+	// The files are selected in the same way as utils.HashPkgDir.
+	err := utils.WalkPkgDir(root, include, exclude, func(path, relPath string, info os.FileInfo) error {
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, f); err != nil {
+			return err
+		}
+		files[relPath] = hex.EncodeToString(hasher.Sum(nil))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
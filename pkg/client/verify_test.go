@@ -0,0 +1,93 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/mock"
+)
+
+func TestVerify(t *testing.T) {
+	testFunc := func(t *testing.T, kpmcli *KpmClient) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Skipping test on Windows")
+		}
+		reg, err := mock.NewOciRegistry(mock.WithOciAuth("test", "1234"))
+		if err != nil {
+			t.Fatalf("Error starting the oci registry: %v", err)
+		}
+		defer reg.Close()
+		reg.Configure(kpmcli.GetSettings())
+
+		testDir := getTestDir("test_verify")
+		pkgPath := filepath.Join(testDir, "pkg")
+
+		err = kpmcli.Push(
+			WithPushModPath(pkgPath),
+			WithPushSource(
+				downloader.Source{
+					Oci: &downloader.Oci{
+						Reg:  reg.Host,
+						Repo: "test/test_verify",
+					},
+				},
+			),
+		)
+		assert.Nil(t, err)
+
+		res, err := kpmcli.Verify(
+			WithVerifySourceUrl("oci://"+reg.Host+"/test/test_verify?tag=0.0.1"),
+			WithVerifyLocalPath(pkgPath),
+		)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, res.Matched(), true)
+		assert.Equal(t, res.Sum, res.LocalSum)
+
+		res, err = kpmcli.Verify(
+			WithVerifySourceUrl("oci://"+reg.Host+"/test/test_verify?tag=0.0.1"),
+			WithVerifyLocalPath(filepath.Join(testDir, "changed")),
+		)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, res.Matched(), false)
+		assert.NotEqual(t, res.Sum, res.LocalSum)
+		// The files are added to or removed from the sources after the package is published.
+		assert.Equal(t, res.Added, []string{"new.k"})
+		assert.Equal(t, res.Removed, []string{"sub.k"})
+		assert.Equal(t, res.Changed, []string{"main.k"})
+
+		// The files ignored by the package are neither in the checksum nor in the files.
+		ignoredPath := filepath.Join(t.TempDir(), "pkg")
+		assert.Nil(t, copy.Copy(pkgPath, ignoredPath))
+		assert.Nil(t, os.WriteFile(filepath.Join(ignoredPath, ".kclignore"), []byte("*.log\n"), 0644))
+		err = kpmcli.Push(
+			WithPushModPath(ignoredPath),
+			WithPushSource(
+				downloader.Source{
+					Oci: &downloader.Oci{
+						Reg:  reg.Host,
+						Repo: "test/test_verify_ignore",
+					},
+				},
+			),
+		)
+		assert.Nil(t, err)
+		assert.Nil(t, os.WriteFile(filepath.Join(ignoredPath, "debug.log"), []byte("noise"), 0644))
+
+		// The default tag is not written into the source of the caller.
+		source := &downloader.Source{Oci: &downloader.Oci{Reg: reg.Host, Repo: "test/test_verify_ignore"}}
+		res, err = kpmcli.Verify(
+			WithVerifySource(source),
+			WithVerifyLocalPath(ignoredPath),
+		)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, res.Matched(), true)
+		assert.Equal(t, res.Sum, res.LocalSum)
+		assert.Equal(t, source.Oci.Tag, "")
+	}
+	RunTestWithGlobalLockAndKpmCli(t, []TestSuite{{Name: "TestVerify", TestFunc: testFunc}})
+}
@@ -0,0 +1,341 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"kcl-lang.io/kcl-go/pkg/kcl"
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+const (
+	// DEFAULT_WATCH_INTERVAL is the default interval to poll the watched files.
+	DEFAULT_WATCH_INTERVAL = 500 * time.Millisecond
+	// DEFAULT_WATCH_DEBOUNCE is the default quiet period after the last change before the package is run again.
+	DEFAULT_WATCH_DEBOUNCE = 200 * time.Millisecond
+)
+
+// WatchHandler handles the result of each run in the watch mode.
+// It is called after the first run, and then only if the output or the error of the run is changed.
+type WatchHandler func(res *kcl.KCLResultList, err error) error
+
+// WatchOptions contains the options for watching and running a kcl package.
+type WatchOptions struct {
+	runOptions []RunOption
+	handler    WatchHandler
+	// interval is the interval to poll the watched files.
+	interval time.Duration
+	// debounce is the quiet period after the last change, the changes saved together are handled by one run.
+	debounce time.Duration
+	// cacheLock is the flag to acquire the lock of the package cache during each run.
+	cacheLock bool
+}
+
+type WatchOption func(*WatchOptions) error
+
+// WithWatchRunOptions sets the options for running the kcl package on each change.
+func WithWatchRunOptions(options ...RunOption) WatchOption {
+	return func(opts *WatchOptions) error {
+		opts.runOptions = append(opts.runOptions, options...)
+		return nil
+	}
+}
+
+// WithWatchHandler sets the handler of the results of the runs.
+func WithWatchHandler(handler WatchHandler) WatchOption {
+	return func(opts *WatchOptions) error {
+		if handler == nil {
+			return errors.New("watch handler cannot be nil")
+		}
+		opts.handler = handler
+		return nil
+	}
+}
+
+// WithWatchInterval sets the interval to poll the watched files.
+func WithWatchInterval(interval time.Duration) WatchOption {
+	return func(opts *WatchOptions) error {
+		if interval <= 0 {
+			return fmt.Errorf("invalid watch interval '%s'", interval)
+		}
+		opts.interval = interval
+		return nil
+	}
+}
+
+// WithWatchDebounce sets the quiet period after the last change before the package is run again.
+func WithWatchDebounce(debounce time.Duration) WatchOption {
+	return func(opts *WatchOptions) error {
+		if debounce < 0 {
+			return fmt.Errorf("invalid watch debounce '%s'", debounce)
+		}
+		opts.debounce = debounce
+		return nil
+	}
+}
+
+// WithWatchCacheLock sets whether to acquire the lock of the package cache during each run,
+// so that the other kpm processes can use the package cache between the runs.
+func WithWatchCacheLock(cacheLock bool) WatchOption {
+	return func(opts *WatchOptions) error {
+		opts.cacheLock = cacheLock
+		return nil
+	}
+}
+
+// Watch runs the local kcl package, and runs it again when its '*.k' files, 'kcl.mod', 'kcl.yaml' or the settings files are changed.
+// The dependencies are resolved again only if the 'kcl.mod' is changed, otherwise only the compilation is run again.
+// It returns when the context of the client is done or the handler returns an error.
+func (c *KpmClient) Watch(options ...WatchOption) error {
+	return c.WatchWithContext(context.Background(), options...)
+}
+
+// WatchWithContext is the same as Watch, and the watching is stopped when the context 'ctx' is done.
+func (c *KpmClient) WatchWithContext(ctx context.Context, options ...WatchOption) error {
+	opts := &WatchOptions{
+		interval: DEFAULT_WATCH_INTERVAL,
+		debounce: DEFAULT_WATCH_DEBOUNCE,
+	}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return err
+		}
+	}
+	if opts.handler == nil {
+		return errors.New("watch handler is not set")
+	}
+
+	runOpts := &RunOptions{}
+	for _, option := range opts.runOptions {
+		if err := option(runOpts); err != nil {
+			return err
+		}
+	}
+	for _, source := range runOpts.Sources {
+		if !source.IsLocalPath() {
+			sourceStr, _ := source.ToString()
+			return fmt.Errorf("'%s' is not a local path, only the local packages can be watched", sourceStr)
+		}
+	}
+	workDir := ""
+	if runOpts.Option != nil {
+		workDir = runOpts.WorkDir
+	}
+	if workDir == "" {
+		var err error
+		workDir, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	}
+
+	w := &watcher{
+		client:   c,
+		opts:     opts,
+		cache:    &runCache{},
+		workDir:  workDir,
+		settings: runOpts.settingYamlFiles,
+	}
+	return w.watch(ctx)
+}
+
+// fileState is the state of a watched file to detect the changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// watcher polls the files of the package and runs the package on the changes.
+type watcher struct {
+	client   *KpmClient
+	opts     *WatchOptions
+	cache    *runCache
+	workDir  string
+	settings []string
+	// lastOutput and lastErr are the output and the error of the last run reported to the handler.
+	lastOutput *string
+	lastErr    string
+}
+
+func (w *watcher) watch(ctx context.Context) error {
+	if err := w.run(ctx); err != nil {
+		return err
+	}
+	snapshot := w.snapshot()
+	reporter.ReportMsgTo(fmt.Sprintf("watching %d files for changes", len(snapshot)), w.client.GetLogWriter())
+
+	ticker := time.NewTicker(w.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		changed := diffSnapshots(snapshot, w.snapshot())
+		if len(changed) == 0 {
+			continue
+		}
+		// Wait until the files are not changed for the debounce period, e.g. the editor saves several files at once.
+		for {
+			latest := w.snapshot()
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(w.opts.debounce):
+			}
+			more := diffSnapshots(latest, w.snapshot())
+			if len(more) == 0 {
+				break
+			}
+			changed = append(changed, more...)
+		}
+
+		if needsResolve(changed) {
+			// The dependencies are resolved again by the next run.
+			w.cache.pkgMap = nil
+		}
+		reporter.ReportMsgTo(fmt.Sprintf("'%s' changed, running again", changed[0]), w.client.GetLogWriter())
+		if err := w.run(ctx); err != nil {
+			return err
+		}
+		// The snapshot is taken after the run, so the 'kcl.mod.lock' updated by the run does not trigger the next run.
+		snapshot = w.snapshot()
+	}
+}
+
+// run runs the package by the 'Run' pipeline with the cache of the resolved dependencies,
+// and reports the result to the handler if the output or the error is changed.
+func (w *watcher) run(ctx context.Context) error {
+	if w.opts.cacheLock {
+		if err := w.client.AcquirePackageCacheLock(); err != nil {
+			return err
+		}
+	}
+	res, err := w.client.RunWithContext(ctx, append(append([]RunOption{}, w.opts.runOptions...), withRunCache(w.cache))...)
+	if w.opts.cacheLock {
+		if releaseErr := w.client.ReleasePackageCacheLock(); releaseErr != nil {
+			return releaseErr
+		}
+	}
+	// The watching is stopped without reporting the error of the canceled run.
+	if err != nil && ctx.Err() != nil {
+		return nil
+	}
+
+	if err != nil {
+		if err.Error() == w.lastErr {
+			return nil
+		}
+		w.lastErr = err.Error()
+		w.lastOutput = nil
+		return w.opts.handler(nil, err)
+	}
+
+	output := res.GetRawYamlResult()
+	if w.lastErr == "" && w.lastOutput != nil && *w.lastOutput == output {
+		return nil
+	}
+	w.lastErr = ""
+	w.lastOutput = &output
+	return w.opts.handler(res, nil)
+}
+
+// watchedFiles returns the files to watch, including the '*.k' files and the 'kcl.mod' of the package,
+// the '*.k' files in the work directory, the 'kcl.yaml' files and the settings files.
+func (w *watcher) watchedFiles() []string {
+	files := map[string]struct{}{}
+	dirs := []string{w.workDir}
+	if w.cache.homePath != "" {
+		dirs = append(dirs, w.cache.homePath)
+	}
+	for _, dir := range dirs {
+		for _, name := range []string{constants.KCL_MOD, constants.KCL_YAML} {
+			files[filepath.Join(dir, name)] = struct{}{}
+		}
+		_ = utils.WalkPkgDir(dir, nil, nil, func(path, relPath string, info os.FileInfo) error {
+			if info.IsDir() && w.skipDir(path, info.Name()) {
+				return filepath.SkipDir
+			}
+			if !info.IsDir() && filepath.Ext(path) == constants.KFilePathSuffix {
+				files[path] = struct{}{}
+			}
+			return nil
+		})
+	}
+	for _, setting := range w.settings {
+		if !filepath.IsAbs(setting) {
+			setting = filepath.Join(w.workDir, setting)
+		}
+		files[setting] = struct{}{}
+	}
+
+	var res []string
+	for file := range files {
+		res = append(res, file)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// skipDir returns true if the directory is not watched, including the hidden directories, the vendored dependencies
+// and the package cache of the external dependencies. The '.git' and the directories ignored by the '.gitignore'
+// and '.kclignore' files are already skipped by the walk.
+func (w *watcher) skipDir(path, name string) bool {
+	if path == w.workDir || path == w.cache.homePath {
+		return false
+	}
+	if strings.HasPrefix(name, ".") || name == "vendor" {
+		return true
+	}
+	return w.client != nil && w.client.homePath != "" && filepath.Clean(path) == filepath.Clean(w.client.homePath)
+}
+
+// snapshot returns the states of the watched files, the files not found are not in the snapshot.
+func (w *watcher) snapshot() map[string]fileState {
+	snapshot := map[string]fileState{}
+	for _, file := range w.watchedFiles() {
+		fileInfo, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		snapshot[file] = fileState{modTime: fileInfo.ModTime(), size: fileInfo.Size()}
+	}
+	return snapshot
+}
+
+// diffSnapshots returns the files created, removed or modified between the snapshots.
+func diffSnapshots(old, cur map[string]fileState) []string {
+	var changed []string
+	for file, state := range cur {
+		if oldState, ok := old[file]; !ok || oldState != state {
+			changed = append(changed, file)
+		}
+	}
+	for file := range old {
+		if _, ok := cur[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// needsResolve returns true if the dependencies should be resolved again for the changed files.
+func needsResolve(changed []string) bool {
+	for _, file := range changed {
+		if filepath.Base(file) == constants.KCL_MOD {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kcl-go/pkg/kcl"
+)
+
+func TestWatchedFiles(t *testing.T) {
+	pkgPath := t.TempDir()
+	for name, content := range map[string]string{
+		"kcl.mod":           "[package]\nname = \"test_watch\"\nversion = \"0.0.1\"\n",
+		"main.k":            "a = 1\n",
+		"sub/sub.k":         "b = 2\n",
+		"sub/README.md":     "# sub\n",
+		"vendor/dep/dep.k":  "c = 3\n",
+		".hidden/hidden.k":  "d = 4\n",
+		".gitignore":        "ignored/\n",
+		"ignored/ignored.k": "e = 5\n",
+		"kpm_home/dep/k.k":  "f = 6\n",
+		"settings/prod.yml": "kcl_cli_configs:\n  files:\n    - main.k\n",
+	} {
+		path := filepath.Join(pkgPath, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	w := &watcher{
+		client:   &KpmClient{homePath: filepath.Join(pkgPath, "kpm_home")},
+		cache:    &runCache{homePath: pkgPath},
+		workDir:  pkgPath,
+		settings: []string{filepath.Join("settings", "prod.yml")},
+	}
+	assert.Equal(t, []string{
+		filepath.Join(pkgPath, "kcl.mod"),
+		filepath.Join(pkgPath, "kcl.yaml"),
+		filepath.Join(pkgPath, "main.k"),
+		filepath.Join(pkgPath, "settings", "prod.yml"),
+		filepath.Join(pkgPath, "sub", "sub.k"),
+	}, w.watchedFiles())
+
+	// The 'kcl.yaml' not found is not in the snapshot, and it is a change when it is created.
+	snapshot := w.snapshot()
+	assert.Equal(t, 4, len(snapshot))
+	assert.Empty(t, diffSnapshots(snapshot, w.snapshot()))
+
+	later := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(filepath.Join(pkgPath, "main.k"), later, later))
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgPath, "kcl.yaml"), []byte("kcl_cli_configs:\n"), 0644))
+	changed := diffSnapshots(snapshot, w.snapshot())
+	assert.Equal(t, []string{filepath.Join(pkgPath, "kcl.yaml"), filepath.Join(pkgPath, "main.k")}, changed)
+	assert.False(t, needsResolve(changed))
+
+	snapshot = w.snapshot()
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgPath, "kcl.mod"), []byte("[package]\nname = \"test_watch\"\nversion = \"0.0.2\"\n"), 0644))
+	assert.NoError(t, os.Remove(filepath.Join(pkgPath, "sub", "sub.k")))
+	changed = diffSnapshots(snapshot, w.snapshot())
+	assert.Equal(t, []string{filepath.Join(pkgPath, "kcl.mod"), filepath.Join(pkgPath, "sub", "sub.k")}, changed)
+	assert.True(t, needsResolve(changed))
+}
+
+func TestWatchWithRemoteSource(t *testing.T) {
+	kpmcli, err := NewKpmClient()
+	assert.NoError(t, err)
+
+	err = kpmcli.Watch(
+		WithWatchRunOptions(WithRunSourceUrl("oci://localhost:5101/kcl-lang/helloworld?tag=0.1.0")),
+		WithWatchHandler(func(res *kcl.KCLResultList, err error) error { return nil }),
+	)
+	assert.ErrorContains(t, err, "only the local packages can be watched")
+
+	err = kpmcli.Watch(WithWatchRunOptions(WithWorkDir(t.TempDir())))
+	assert.ErrorContains(t, err, "watch handler is not set")
+}
+
+func TestWatch(t *testing.T) {
+	pkgPath := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgPath, "kcl.mod"), []byte("[package]\nname = \"test_watch\"\nversion = \"0.0.1\"\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgPath, "main.k"), []byte("a = 1\n"), 0644))
+
+	kpmcli, err := NewKpmClient()
+	assert.NoError(t, err)
+	kpmcli.SetLogWriter(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	outputs := make(chan string, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- kpmcli.WatchWithContext(
+			ctx,
+			WithWatchRunOptions(WithWorkDir(pkgPath)),
+			WithWatchInterval(20*time.Millisecond),
+			WithWatchDebounce(10*time.Millisecond),
+			WithWatchHandler(func(res *kcl.KCLResultList, err error) error {
+				if err != nil {
+					outputs <- err.Error()
+				} else {
+					outputs <- res.GetRawYamlResult()
+				}
+				return nil
+			}),
+		)
+	}()
+
+	waitOutput := func() string {
+		select {
+		case output := <-outputs:
+			return output
+		case <-time.After(30 * time.Second):
+			t.Fatal("timeout waiting for the output")
+			return ""
+		}
+	}
+	assert.Equal(t, "a: 1", waitOutput())
+
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgPath, "main.k"), []byte("a = 2\n"), 0644))
+	assert.Equal(t, "a: 2", waitOutput())
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
+
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/oci"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// YankOptions contains the options for the Yank method.
+type YankOptions struct {
+	// Source is the OCI source of the version to yank.
+	Source *downloader.Source
+	// Reason is the reason why the version is yanked.
+	Reason string
+	// Registries are the named registries in kcl.mod to resolve the source with only the package spec.
+	Registries []settings.OciRegistry
+}
+
+type YankOption func(*YankOptions) error
+
+// WithYankSource sets the OCI source of the version to yank for the Yank method.
+func WithYankSource(source *downloader.Source) YankOption {
+	return func(opts *YankOptions) error {
+		if source == nil {
+			return errors.New("source cannot be nil")
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithYankSourceUrl sets the OCI url of the version to yank for the Yank method, e.g. 'oci://ghcr.io/kcl-lang/helloworld?tag=0.1.0'.
+func WithYankSourceUrl(sourceUrl string) YankOption {
+	return func(opts *YankOptions) error {
+		source, err := downloader.NewSourceFromStr(sourceUrl)
+		if err != nil {
+			return err
+		}
+		opts.Source = source
+		return nil
+	}
+}
+
+// WithYankReason sets the reason why the version is yanked for the Yank method.
+func WithYankReason(reason string) YankOption {
+	return func(opts *YankOptions) error {
+		opts.Reason = reason
+		return nil
+	}
+}
+
+// WithYankRegistries sets the named registries in kcl.mod to resolve the source with only the package spec for the Yank method.
+func WithYankRegistries(registries ...settings.OciRegistry) YankOption {
+	return func(opts *YankOptions) error {
+		opts.Registries = registries
+		return nil
+	}
+}
+
+// Yank marks the published version of the package as yanked.
+// The yanked version is skipped when selecting the latest version of the package,
+// and the packages that have locked the yanked version are warned during 'run' and 'update'.
+func (c *KpmClient) Yank(options ...YankOption) error {
+	return c.YankWithContext(context.Background(), options...)
+}
+
+// YankWithContext is the same as Yank, and the operation is aborted when the context 'ctx' is canceled.
+func (c *KpmClient) YankWithContext(ctx context.Context, options ...YankOption) error {
+	opts := &YankOptions{}
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.Source == nil {
+		return errors.New("the version to yank is required")
+	}
+
+	c.resolveSpecOnlySource(opts.Source, opts.Registries...)
+
+	if opts.Source.Oci == nil {
+		sourceStr, _ := opts.Source.ToString()
+		return fmt.Errorf("'%s' is not an oci source, only support oci source", sourceStr)
+	}
+	if opts.Source.Oci.Tag == "" {
+		return fmt.Errorf("the version of '%s' to yank is required", utils.JoinPath(opts.Source.Oci.Reg, opts.Source.Oci.Repo))
+	}
+
+	ociCli, err := c.newOciClient(ctx, opts.Source.Oci)
+	if err != nil {
+		return err
+	}
+
+	return ociCli.Yank(opts.Source.Oci.Tag, opts.Reason)
+}
+
+// newOciClient returns the OciClient of the OCI source with the credential and settings of the client,
+// the credential is not required by the local OCI image layout.
+func (c *KpmClient) newOciClient(ctx context.Context, ociSource *downloader.Oci) (*oci.OciClient, error) {
+	cred := &remoteauth.Credential{}
+	if !ociSource.Layout {
+		var err error
+		cred, err = c.GetCredentials(ociSource.Reg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ociCli, err := oci.NewOciClientWithOpts(
+		oci.WithCredential(cred),
+		ociSource.RepoOption(),
+		oci.WithSettings(c.GetSettings()),
+		oci.WithInsecureSkipTLSverify(c.insecureSkipTLSverify),
+		oci.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ociCli.SetLogWriter(c.GetLogWriter())
+
+	return ociCli, nil
+}
+
+// warnYankedDeps warns the yanked versions of the OCI dependencies locked in the kcl.mod.lock of the package.
+// It is best-effort, the dependencies that fail to be checked are ignored.
+func (c *KpmClient) warnYankedDeps(ctx context.Context, kclPkg *pkg.KclPkg) {
+	if kclPkg == nil || kclPkg.Dependencies.Deps == nil {
+		return
+	}
+
+	for _, name := range kclPkg.Dependencies.Deps.Keys() {
+		dep, ok := kclPkg.Dependencies.Deps.Get(name)
+		if !ok || dep.Source.Oci == nil || !dep.Source.Oci.HasRepo() || dep.Source.Oci.Tag == "" {
+			continue
+		}
+
+		ociCli, err := c.newOciClient(ctx, dep.Source.Oci)
+		if err != nil {
+			continue
+		}
+		yanked, reason, err := ociCli.IsYanked(dep.Source.Oci.Tag)
+		if err != nil || !yanked {
+			continue
+		}
+
+		msg := fmt.Sprintf("warning: the locked version '%s' of '%s' is yanked", dep.Source.Oci.Tag, name)
+		if reason != "" {
+			msg = fmt.Sprintf("%s (%s)", msg, reason)
+		}
+		reporter.ReportEventTo(reporter.NewEvent(reporter.YankedVersion, msg), c.GetLogWriter())
+	}
+}
+
+// SelectUnyankedRelease selects the release from the 'releases' of the source 'uri' by 'selector',
+// and the yanked versions are skipped for the OCI source.
+func (c *KpmClient) SelectUnyankedRelease(sourceType, uri string, releases []string, selector func([]string) (string, error)) (string, error) {
+	if sourceType != pkg.OCI {
+		return selector(releases)
+	}
+
+	ociSource := &downloader.Oci{}
+	if err := ociSource.FromString(uri); err != nil {
+		return "", err
+	}
+	ociCli, err := c.newOciClient(context.Background(), ociSource)
+	if err != nil {
+		return "", err
+	}
+
+	return ociCli.SelectUnyankedVersion(releases, selector)
+}
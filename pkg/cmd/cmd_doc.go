@@ -0,0 +1,71 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+// Deprecated: The entire contents of this file will be deprecated.
+// Please use the kcl cli - https://github.com/kcl-lang/cli.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/api"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewDocCmd new a Command for `kpm doc`.
+func NewDocCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "doc",
+		Usage:     "generate the docs of a kcl package",
+		ArgsUsage: "[package_path]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FLAG_FORMAT,
+				Value: api.DOC_FORMAT_MARKDOWN,
+				Usage: fmt.Sprintf("the format of the docs, '%s' or '%s'", api.DOC_FORMAT_MARKDOWN, api.DOC_FORMAT_HTML),
+			},
+			&cli.StringFlag{
+				Name:    FLAG_OUTPUT,
+				Aliases: []string{"o"},
+				Value:   "docs",
+				Usage:   "the directory to write the docs",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_DEPS_DOC_URL,
+				Usage: "the base url of the docs of the dependencies, the schemas from the dependencies are linked to the docs under it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmDoc(c, kpmcli)
+		},
+	}
+}
+
+func KpmDoc(c *cli.Context, kpmcli *client.KpmClient) error {
+	pkgPath, err := os.Getwd()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, failed to load working directory.")
+	}
+	if c.NArg() != 0 {
+		pkgPath = c.Args().First()
+	}
+
+	kclPkg, err := api.GetKclPackage(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	files, err := kclPkg.GenDocs(api.DocOptions{
+		Format:     c.String(FLAG_FORMAT),
+		OutputDir:  c.String(FLAG_OUTPUT),
+		DepsDocUrl: c.String(FLAG_DEPS_DOC_URL),
+	})
+	if err != nil {
+		return err
+	}
+	reporter.ReportMsgTo(fmt.Sprintf("the docs of '%s' are generated in '%s', %d files", kclPkg.GetPkgName(), c.String(FLAG_OUTPUT), len(files)), kpmcli.GetLogWriter())
+	return nil
+}
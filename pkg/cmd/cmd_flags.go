@@ -11,6 +11,11 @@ const FLAG_VENDOR = "vendor"
 const FLAG_UPDATE = "update"
 const FLAG_TAG = "tag"
 const FLAG_TAR_PATH = "tar_path"
+const FLAG_GZIP = "gzip"
+const FLAG_PATH = "path"
+const FLAG_GIT = "git"
+const FLAG_COMMIT = "commit"
+const FLAG_BRANCH = "branch"
 
 const FLAG_SETTING = "setting"
 const FLAG_DISABLE_NONE = "disable_none"
@@ -20,3 +25,18 @@ const FLAG_SORT_KEYS = "sort_keys"
 
 const FLAG_QUIET = "quiet"
 const FLAG_NO_SUM_CHECK = "no_sum_check"
+
+const FLAG_REGISTRY = "registry"
+const FLAG_REPO_PREFIX = "repo_prefix"
+const FLAG_INDEX = "index"
+const FLAG_REASON = "reason"
+const FLAG_TEMPLATE = "template"
+const FLAG_EXTERNAL = "external"
+const FLAG_RESOLVE_OCI = "resolve_oci"
+const FLAG_YES = "yes"
+const FLAG_FORMAT = "format"
+const FLAG_OUTPUT = "output"
+const FLAG_DOCS = "docs"
+const FLAG_DEPS_DOC_URL = "deps_doc_url"
+const FLAG_SOCKET = "socket"
+const FLAG_WATCH = "watch"
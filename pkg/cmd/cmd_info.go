@@ -0,0 +1,74 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+// Deprecated: The entire contents of this file will be deprecated.
+// Please use the kcl cli - https://github.com/kcl-lang/cli.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewInfoCmd new a Command for `kpm info`.
+func NewInfoCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "info",
+		Usage:     "show the information of a kcl package in the OCI registry or the git repository without downloading it",
+		ArgsUsage: "<package_url>",
+		Action: func(c *cli.Context) error {
+			return KpmInfo(c, kpmcli)
+		},
+	}
+}
+
+func KpmInfo(c *cli.Context, kpmcli *client.KpmClient) error {
+	if c.NArg() == 0 {
+		return reporter.NewErrorEvent(reporter.InvalidCmd, fmt.Errorf("the url of the package is required"))
+	}
+
+	registries, err := workDirRegistries(kpmcli)
+	if err != nil {
+		return err
+	}
+
+	info, err := kpmcli.Info(client.WithInfoSourceUrl(c.Args().First()), client.WithInfoRegistries(registries...))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "name:\t%s\n", info.Name)
+	fmt.Fprintf(w, "version:\t%s\n", info.Version)
+	fmt.Fprintf(w, "description:\t%s\n", info.Description)
+	fmt.Fprintf(w, "source:\t%s\n", info.Source)
+	fmt.Fprintf(w, "versions:\t%s\n", strings.Join(info.Versions, ", "))
+	if info.Sum != "" {
+		fmt.Fprintf(w, "sum:\t%s\n", info.Sum)
+	}
+	if info.Created != "" {
+		fmt.Fprintf(w, "created:\t%s\n", info.Created)
+	}
+	if info.Size != 0 {
+		fmt.Fprintf(w, "size:\t%d bytes\n", info.Size)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(info.Dependencies) == 0 {
+		return nil
+	}
+	fmt.Println("dependencies:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, dep := range info.Dependencies {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", dep.Name, dep.Version, dep.Source)
+	}
+	return w.Flush()
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 	"kcl-lang.io/kpm/pkg/client"
@@ -15,6 +16,8 @@ import (
 	"kcl-lang.io/kpm/pkg/opt"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	reporter "kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/template"
+	"kcl-lang.io/kpm/pkg/utils"
 )
 
 // NewInitCmd new a Command for `kpm init`.
@@ -23,6 +26,26 @@ func NewInitCmd(kpmcli *client.KpmClient) *cli.Command {
 		Hidden: false,
 		Name:   "init",
 		Usage:  "initialize new module in current directory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FLAG_TEMPLATE,
+				Usage: fmt.Sprintf("the built-in template (%s), or the git url or the oci reference of the template package", strings.Join(template.BuiltinNames(), ", ")),
+			},
+			&cli.StringSliceFlag{
+				Name:    FLAG_EXTERNAL,
+				Aliases: []string{"E"},
+				Usage:   "the external packages '<name>=<path>' used by the existing kcl project, added as the dependencies",
+			},
+			&cli.BoolFlag{
+				Name:  FLAG_RESOLVE_OCI,
+				Usage: "resolve the external packages into the registry dependencies if they are published with the same versions",
+			},
+			&cli.BoolFlag{
+				Name:    FLAG_YES,
+				Aliases: []string{"y"},
+				Usage:   "write the proposed kcl.mod of the existing kcl project without confirmation on the terminal",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			pwd, err := os.Getwd()
 
@@ -70,7 +93,23 @@ func NewInitCmd(kpmcli *client.KpmClient) *cli.Command {
 				return err
 			}
 
-			err = kpmcli.InitEmptyPkg(&kclPkg)
+			if c.IsSet(FLAG_TEMPLATE) {
+				err = kpmcli.InitPkgFromTemplate(&kclPkg, c.String(FLAG_TEMPLATE))
+			} else {
+				// The existing kcl project in the directory is migrated into the package.
+				initOpts := []client.InitOption{
+					client.WithInitModPath(pkgRootPath),
+					client.WithInitExternalPkgs(c.StringSlice(FLAG_EXTERNAL)),
+					client.WithInitResolveOci(c.Bool(FLAG_RESOLVE_OCI)),
+				}
+				// The proposed kcl.mod is only confirmed on the terminal, so the scripts still init without the prompt.
+				if !c.Bool(FLAG_YES) && utils.IsStdinTerminal() {
+					initOpts = append(initOpts, client.WithInitConfirm(func(string) (bool, error) {
+						return utils.Confirm("write the proposed kcl.mod?")
+					}))
+				}
+				err = kpmcli.Init(initOpts...)
+			}
 			if err != nil {
 				return err
 			}
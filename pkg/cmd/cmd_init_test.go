@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+func TestInitExistingProjectWithoutTerminal(t *testing.T) {
+	if utils.IsStdinTerminal() {
+		t.Skip("stdin is a terminal")
+	}
+
+	projectPath := filepath.Join(t.TempDir(), "existing")
+	assert.Nil(t, os.MkdirAll(projectPath, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(projectPath, "main.k"), []byte("a = 1\n"), 0644))
+	pwd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(projectPath))
+	defer func() { _ = os.Chdir(pwd) }()
+
+	kpmcli, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(nil)
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{NewInitCmd(kpmcli)}
+
+	// The proposed kcl.mod is written without the prompt if stdin is not a terminal.
+	assert.Nil(t, app.Run([]string{"kpm", "init"}))
+	assert.True(t, utils.FileExists(filepath.Join(projectPath, "kcl.mod")))
+	mainK, err := os.ReadFile(filepath.Join(projectPath, "main.k"))
+	assert.Nil(t, err)
+	assert.Equal(t, "a = 1\n", string(mainK))
+}
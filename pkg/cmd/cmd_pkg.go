@@ -19,9 +19,13 @@ import (
 // NewPkgCmd new a Command for `kpm pkg`.
 func NewPkgCmd(kpmcli *client.KpmClient) *cli.Command {
 	return &cli.Command{
-		Hidden: false,
-		Name:   "pkg",
-		Usage:  "package a kcl package into tar",
+		Hidden:  false,
+		Name:    "pkg",
+		Aliases: []string{"package"},
+		Usage:   "package a kcl package into tar",
+		Subcommands: []*cli.Command{
+			NewPkgVerifyCmd(kpmcli),
+		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "target",
@@ -34,6 +38,11 @@ func NewPkgCmd(kpmcli *client.KpmClient) *cli.Command {
 				Name:  FLAG_VENDOR,
 				Usage: "push in vendor mode",
 			},
+			// '--gzip' will compress the tar by gzip into '<package_name>_<package_version>.tgz'.
+			&cli.BoolFlag{
+				Name:  FLAG_GZIP,
+				Usage: "compress the package tar by gzip",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			tarPath := c.String("target")
@@ -66,7 +75,12 @@ func NewPkgCmd(kpmcli *client.KpmClient) *cli.Command {
 				}
 			}
 
-			return kpmcli.Package(kclPkg, filepath.Join(tarPath, kclPkg.GetPkgTarName()), c.Bool(FLAG_VENDOR))
+			tarName := kclPkg.GetPkgTarName()
+			if c.Bool(FLAG_GZIP) {
+				tarName = kclPkg.GetPkgTgzName()
+			}
+
+			return kpmcli.Package(kclPkg, filepath.Join(tarPath, tarName), c.Bool(FLAG_VENDOR))
 		},
 	}
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/urfave/cli/v2"
 	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/errors"
 	"kcl-lang.io/kpm/pkg/oci"
@@ -38,6 +39,10 @@ func NewPushCmd(kpmcli *client.KpmClient) *cli.Command {
 				Name:  FLAG_VENDOR,
 				Usage: "push in vendor mode",
 			},
+			&cli.StringFlag{
+				Name:  FLAG_DOCS,
+				Usage: "the directory of the docs generated by 'kpm doc', which is pushed with the package",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			return KpmPush(c, kpmcli)
@@ -54,10 +59,10 @@ func KpmPush(c *cli.Context, kpmcli *client.KpmClient) error {
 	if len(localTarPath) == 0 {
 		// If the tar package to be pushed is not specified,
 		// the current kcl package is packaged into tar and pushed.
-		err = pushCurrentPackage(ociUrl, c.Bool(FLAG_VENDOR), kpmcli)
+		err = pushCurrentPackage(ociUrl, c.Bool(FLAG_VENDOR), c.String(FLAG_DOCS), kpmcli)
 	} else {
 		// Else push the tar package specified.
-		err = pushTarPackage(ociUrl, localTarPath, c.Bool(FLAG_VENDOR), kpmcli)
+		err = pushTarPackage(ociUrl, localTarPath, c.Bool(FLAG_VENDOR), c.String(FLAG_DOCS), kpmcli)
 	}
 
 	if err != nil {
@@ -82,7 +87,7 @@ func genDefaultOciUrlForKclPkg(pkg *pkg.KclPkg, kpmcli *client.KpmClient) (strin
 }
 
 // pushCurrentPackage will push the current package to the oci registry.
-func pushCurrentPackage(ociUrl string, vendorMode bool, kpmcli *client.KpmClient) error {
+func pushCurrentPackage(ociUrl string, vendorMode bool, docsPath string, kpmcli *client.KpmClient) error {
 	pwd, err := os.Getwd()
 
 	if err != nil {
@@ -103,12 +108,12 @@ func pushCurrentPackage(ociUrl string, vendorMode bool, kpmcli *client.KpmClient
 	}
 
 	// 2. push the package
-	return pushPackage(ociUrl, kclPkg, vendorMode, kpmcli)
+	return pushPackage(ociUrl, kclPkg, vendorMode, docsPath, kpmcli)
 }
 
 // pushTarPackage will push the kcl package in tarPath to the oci registry.
 // If the tar in 'tarPath' is not a kcl package tar, pushTarPackage will return an error.
-func pushTarPackage(ociUrl, localTarPath string, vendorMode bool, kpmcli *client.KpmClient) error {
+func pushTarPackage(ociUrl, localTarPath string, vendorMode bool, docsPath string, kpmcli *client.KpmClient) error {
 	var kclPkg *pkg.KclPkg
 	var err error
 
@@ -134,7 +139,7 @@ func pushTarPackage(ociUrl, localTarPath string, vendorMode bool, kpmcli *client
 	}
 
 	// 2. push the package
-	return pushPackage(ociUrl, kclPkg, vendorMode, kpmcli)
+	return pushPackage(ociUrl, kclPkg, vendorMode, docsPath, kpmcli)
 }
 
 // pushPackage will push the kcl package to the oci registry.
@@ -142,7 +147,7 @@ func pushTarPackage(ociUrl, localTarPath string, vendorMode bool, kpmcli *client
 // 2. If the oci url is not specified, generate the default oci url from the current package.
 // 3. Generate the OCI options from oci url and the version of current kcl package.
 // 4. Push the package to the oci registry.
-func pushPackage(ociUrl string, kclPkg *pkg.KclPkg, vendorMode bool, kpmcli *client.KpmClient) error {
+func pushPackage(ociUrl string, kclPkg *pkg.KclPkg, vendorMode bool, docsPath string, kpmcli *client.KpmClient) error {
 	// If the oci url is not specified, generate the default oci url from the current package.
 	var err error
 	if len(ociUrl) == 0 {
@@ -156,6 +161,23 @@ func pushPackage(ociUrl string, kclPkg *pkg.KclPkg, vendorMode bool, kpmcli *cli
 		}
 	}
 
+	// The package is pushed into the local OCI image layout for the 'oci+file://' url.
+	if u, parseErr := url.Parse(ociUrl); parseErr == nil && u.Scheme == constants.OciFileScheme {
+		layoutSource := &downloader.Oci{}
+		if err := layoutSource.FromString(ociUrl); err != nil {
+			return err
+		}
+		if len(layoutSource.Tag) == 0 {
+			layoutSource.Tag = kclPkg.GetPkgTag()
+		}
+		return kpmcli.Push(
+			client.WithPushModPath(kclPkg.HomePath),
+			client.WithPushSource(downloader.Source{Oci: layoutSource}),
+			client.WithPushVendorMode(vendorMode),
+			client.WithPushDocs(docsPath),
+		)
+	}
+
 	// Generate the OCI options from oci url and the version of current kcl package.
 	ociOpts, err := opt.ParseOciOptionFromOciUrl(ociUrl, kclPkg.GetPkgTag())
 	if err != (*reporter.KpmEvent)(nil) {
@@ -179,6 +201,7 @@ func pushPackage(ociUrl string, kclPkg *pkg.KclPkg, vendorMode bool, kpmcli *cli
 			},
 		),
 		client.WithPushVendorMode(vendorMode),
+		client.WithPushDocs(docsPath),
 	)
 	if err != (*reporter.KpmEvent)(nil) {
 		return err
@@ -7,15 +7,21 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
 	"github.com/urfave/cli/v2"
 	"kcl-lang.io/kcl-go/pkg/kcl"
 	"kcl-lang.io/kpm/pkg/api"
 	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/git"
 	"kcl-lang.io/kpm/pkg/opt"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/runner"
+	"kcl-lang.io/kpm/pkg/utils"
 )
 
 // NewRunCmd new a Command for `kpm run`.
@@ -81,6 +87,26 @@ func NewRunCmd(kpmcli *client.KpmClient) *cli.Command {
 				Aliases: []string{"k"},
 				Usage:   "sort result keys",
 			},
+
+			// --watch
+			&cli.BoolFlag{
+				Name:  FLAG_WATCH,
+				Usage: "run the local package again when its kcl files, kcl.mod, kcl.yaml or settings files are changed",
+			},
+
+			// --output, -o
+			&cli.StringFlag{
+				Name:    FLAG_OUTPUT,
+				Aliases: []string{"o"},
+				Usage:   "the file to write the output to, the output is printed if it is not set",
+			},
+
+			// --format
+			&cli.StringFlag{
+				Name:  FLAG_FORMAT,
+				Value: client.RUN_FORMAT_YAML,
+				Usage: fmt.Sprintf("the output format when multiple packages are run, '%s' for a multi-document stream or '%s' for a map keyed by the sources", client.RUN_FORMAT_YAML, client.RUN_FORMAT_JSON),
+			},
 		},
 		Action: func(c *cli.Context) error {
 			return KpmRun(c, kpmcli)
@@ -89,6 +115,10 @@ func NewRunCmd(kpmcli *client.KpmClient) *cli.Command {
 }
 
 func KpmRun(c *cli.Context, kpmcli *client.KpmClient) error {
+	if c.Bool(FLAG_WATCH) {
+		return kpmRunWatch(c, kpmcli)
+	}
+
 	// acquire the lock of the package cache.
 	err := kpmcli.AcquirePackageCacheLock()
 	if err != nil {
@@ -103,6 +133,14 @@ func KpmRun(c *cli.Context, kpmcli *client.KpmClient) error {
 		}
 	}()
 
+	multiPkgRun, err := isMultiPkgRun(runSourcesFromCli(c))
+	if err != nil {
+		return err
+	}
+	if multiPkgRun {
+		return kpmRunPkgs(c, kpmcli)
+	}
+
 	kclOpts := CompileOptionFromCli(c)
 	kclOpts.SetNoSumCheck(c.Bool(FLAG_NO_SUM_CHECK))
 	runEntry, errEvent := runner.FindRunEntryFrom(c.Args().Slice())
@@ -124,7 +162,7 @@ func KpmRun(c *cli.Context, kpmcli *client.KpmClient) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(compileResult.GetRawYamlResult())
+		return writeRunOutput(c, compileResult.GetRawYamlResult())
 	} else {
 		var compileResult *kcl.KCLResultList
 		var err error
@@ -154,9 +192,130 @@ func KpmRun(c *cli.Context, kpmcli *client.KpmClient) error {
 		if err != nil {
 			return err
 		}
-		fmt.Println(compileResult.GetRawYamlResult())
+		return writeRunOutput(c, compileResult.GetRawYamlResult())
+	}
+}
+
+// writeRunOutput writes the output to the file of '--output', or prints it if the file is not set.
+func writeRunOutput(c *cli.Context, output string) error {
+	if c.String(FLAG_OUTPUT) == "" {
+		fmt.Println(output)
+		return nil
+	}
+	return os.WriteFile(c.String(FLAG_OUTPUT), []byte(output), 0644)
+}
+
+// kpmRunWatch runs the package under '$pwd' or in the args, and runs it again when the package is changed.
+// The lock of the package cache is only held during each run, so that the other kpm commands are not blocked.
+func kpmRunWatch(c *cli.Context, kpmcli *client.KpmClient) error {
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	kpmcli.SetNoSumCheck(c.Bool(FLAG_NO_SUM_CHECK))
+	runOpts, err := runOptionsFromCli(c)
+	if err != nil {
+		return err
+	}
+
+	output := c.String(FLAG_OUTPUT)
+	return kpmcli.WatchWithContext(
+		ctx,
+		client.WithWatchRunOptions(runOpts...),
+		client.WithWatchCacheLock(true),
+		client.WithWatchHandler(func(res *kcl.KCLResultList, err error) error {
+			// The errors are reported and the package is watched until it is fixed.
+			if err != nil {
+				reporter.ReportMsgTo(err.Error(), kpmcli.GetLogWriter())
+				return nil
+			}
+			if output == "" {
+				fmt.Println(res.GetRawYamlResult())
+				return nil
+			}
+			if err := os.WriteFile(output, []byte(res.GetRawYamlResult()), 0644); err != nil {
+				return err
+			}
+			reporter.ReportMsgTo(fmt.Sprintf("the output is written to '%s'", output), kpmcli.GetLogWriter())
+			return nil
+		}),
+	)
+}
+
+// isMultiPkgRun returns true if the sources are more than one package,
+// each packaged source is a package, and the local sources are the same package if they have the same kcl.mod root.
+// The local kcl files without kcl.mod are not packages, they are compiled together as the entries.
+func isMultiPkgRun(sources []string) (bool, error) {
+	pkgs := map[string]struct{}{}
+	for _, sourceStr := range sources {
+		source, err := downloader.NewSourceFromStr(sourceStr)
+		if err != nil {
+			return false, err
+		}
+		if !source.IsPackaged() {
+			rootPath, err := source.FindRootPath()
+			if err != nil || !utils.FileExists(filepath.Join(rootPath, constants.KCL_MOD)) {
+				continue
+			}
+			sourceStr = rootPath
+		}
+		pkgs[sourceStr] = struct{}{}
+	}
+	return len(pkgs) > 1, nil
+}
+
+// runSourcesFromCli returns the sources to run from the cli context, they are the args and the entries of '--input'.
+func runSourcesFromCli(c *cli.Context) []string {
+	return append(c.Args().Slice(), c.StringSlice(FLAG_INPUT)...)
+}
+
+// kpmRunPkgs runs the packages in the args in parallel, and outputs the results
+// as a multi-document YAML stream or a JSON map keyed by the sources.
+func kpmRunPkgs(c *cli.Context, kpmcli *client.KpmClient) error {
+	format := c.String(FLAG_FORMAT)
+	if format != client.RUN_FORMAT_YAML && format != client.RUN_FORMAT_JSON {
+		return fmt.Errorf("invalid format '%s', only '%s' and '%s' are supported", format, client.RUN_FORMAT_YAML, client.RUN_FORMAT_JSON)
+	}
+
+	kpmcli.SetNoSumCheck(c.Bool(FLAG_NO_SUM_CHECK))
+	runOpts, err := runOptionsFromCli(c)
+	if err != nil {
+		return err
+	}
+	res, err := kpmcli.RunPkgsWithContext(c.Context, runOpts...)
+	if err != nil {
+		return err
+	}
+
+	output := res.YamlStream()
+	if format == client.RUN_FORMAT_JSON {
+		output, err = res.JsonMap()
+		if err != nil {
+			return err
+		}
+	}
+	return writeRunOutput(c, output)
+}
+
+// runOptionsFromCli will parse the options of the 'Run' pipeline from the cli context,
+// the sources are the args and the entries of '--input'.
+// '--tag' is only supported to run a single package, because it is not clear which package it belongs to.
+func runOptionsFromCli(c *cli.Context) ([]client.RunOption, error) {
+	if c.IsSet(FLAG_TAG) {
+		return nil, fmt.Errorf("'--%s' is not supported in the watch mode or when multiple packages are run, set the tag in the source, e.g. 'oci://ghcr.io/kcl-lang/helloworld?tag=0.1.0'", FLAG_TAG)
+	}
+
+	runOpts := []client.RunOption{
+		client.WithSettingFiles(c.StringSlice(FLAG_SETTING)),
+		client.WithArguments(c.StringSlice(FLAG_ARGUMENT)),
+		client.WithOverrides(c.StringSlice(FLAG_OVERRIDES), false),
+		client.WithDisableNone(c.Bool(FLAG_DISABLE_NONE)),
+		client.WithSortKeys(c.Bool(FLAG_SORT_KEYS)),
+		client.WithVendor(c.Bool(FLAG_VENDOR)),
+	}
+	if sources := runSourcesFromCli(c); len(sources) != 0 {
+		runOpts = append(runOpts, client.WithRunSourceUrls(sources))
 	}
-	return nil
+	return runOpts, nil
 }
 
 // CompileOptionFromCli will parse the kcl options from the cli context.
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+)
+
+func TestIsMultiPkgRun(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a/x.k":          "a = 1",
+		"b/y.k":          "b = 1",
+		"pkg1/kcl.mod":   "[package]\nname = \"pkg1\"\n",
+		"pkg1/main.k":    "c = 1",
+		"pkg1/sub/sub.k": "d = 1",
+		"pkg2/kcl.mod":   "[package]\nname = \"pkg2\"\n",
+		"pkg2/main.k":    "e = 1",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	p := func(name string) string { return filepath.Join(root, name) }
+
+	isMulti := func(sources ...string) bool {
+		multi, err := isMultiPkgRun(sources)
+		assert.Nil(t, err)
+		return multi
+	}
+
+	// The kcl files without kcl.mod are compiled together.
+	assert.False(t, isMulti(p("a/x.k"), p("b/y.k")))
+	// The sources in the same package.
+	assert.False(t, isMulti(p("pkg1/main.k"), p("pkg1/sub/sub.k")))
+	assert.False(t, isMulti(p("pkg1")))
+	// The sources in the different packages.
+	assert.True(t, isMulti(p("pkg1"), p("pkg2/main.k")))
+	assert.True(t, isMulti(p("pkg1"), "oci://ghcr.io/kcl-lang/helloworld?tag=0.1.0"))
+
+	// The invalid sources are reported.
+	_, err := isMultiPkgRun([]string{p("pkg1"), "oci://ghcr.io/%zz"})
+	assert.NotNil(t, err)
+}
+
+func TestRunMultiPkgsWithInput(t *testing.T) {
+	t.Setenv("KCL_PKG_PATH", t.TempDir())
+	root := t.TempDir()
+	for _, name := range []string{"pkg1", "pkg2"} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(root, name), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(root, name, "kcl.mod"), []byte("[package]\nname = \""+name+"\"\n"), 0644))
+		assert.Nil(t, os.WriteFile(filepath.Join(root, name, "main.k"), []byte("a = 1"), 0644))
+	}
+
+	kpmcli, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(nil)
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{NewRunCmd(kpmcli)}
+
+	// The packages in '--input' are run as multiple packages, so the format of their outputs is checked.
+	err = app.Run([]string{"kpm", "run", "--input", filepath.Join(root, "pkg1"), "--input", filepath.Join(root, "pkg2"), "--format", "xml"})
+	assert.ErrorContains(t, err, "invalid format 'xml'")
+}
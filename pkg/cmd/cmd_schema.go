@@ -0,0 +1,100 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+// Deprecated: The entire contents of this file will be deprecated.
+// Please use the kcl cli - https://github.com/kcl-lang/cli.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/api"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+const (
+	SCHEMA_FORMAT_OPENAPI    = "openapi"
+	SCHEMA_FORMAT_JSONSCHEMA = "jsonschema"
+	SCHEMA_FORMAT_SWAGGER    = "swagger"
+)
+
+// NewSchemaCmd new a Command for `kpm schema`.
+func NewSchemaCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden: false,
+		Name:   "schema",
+		Usage:  "export the schemas of a kcl package",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "export",
+				Usage:     "export the schemas of a kcl package and its dependencies into OpenAPI 3.1, JSON Schema draft 2020-12 or Swagger 2.0",
+				ArgsUsage: "[package_path]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  FLAG_FORMAT,
+						Value: SCHEMA_FORMAT_OPENAPI,
+						Usage: fmt.Sprintf("the format of the exported schemas, '%s', '%s' or '%s'", SCHEMA_FORMAT_OPENAPI, SCHEMA_FORMAT_JSONSCHEMA, SCHEMA_FORMAT_SWAGGER),
+					},
+					&cli.StringFlag{
+						Name:    FLAG_OUTPUT,
+						Aliases: []string{"o"},
+						Usage:   "the file to write the exported schemas, the schemas are printed if it is not set",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return KpmSchemaExport(c, kpmcli)
+				},
+			},
+		},
+	}
+}
+
+func KpmSchemaExport(c *cli.Context, kpmcli *client.KpmClient) error {
+	pkgPath, err := os.Getwd()
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, failed to load working directory.")
+	}
+	if c.NArg() != 0 {
+		pkgPath = c.Args().First()
+	}
+
+	kclPkg, err := api.GetKclPackage(pkgPath)
+	if err != nil {
+		return err
+	}
+
+	var exported interface{}
+	switch c.String(FLAG_FORMAT) {
+	case SCHEMA_FORMAT_OPENAPI:
+		exported, err = kclPkg.ExportOpenAPIV3Spec()
+	case SCHEMA_FORMAT_JSONSCHEMA:
+		exported, err = kclPkg.ExportJsonSchema()
+	case SCHEMA_FORMAT_SWAGGER:
+		exported, err = kclPkg.ExportSwaggerV2Spec()
+	default:
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("invalid format '%s', only support '%s', '%s' and '%s'", c.String(FLAG_FORMAT), SCHEMA_FORMAT_OPENAPI, SCHEMA_FORMAT_JSONSCHEMA, SCHEMA_FORMAT_SWAGGER),
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(exported, "", "  ")
+	if err != nil {
+		return err
+	}
+	if !c.IsSet(FLAG_OUTPUT) {
+		fmt.Println(string(content))
+		return nil
+	}
+	if err := os.WriteFile(c.String(FLAG_OUTPUT), append(content, '\n'), 0644); err != nil {
+		return err
+	}
+	reporter.ReportMsgTo(fmt.Sprintf("the schemas of '%s' are exported to '%s'", kclPkg.GetPkgName(), c.String(FLAG_OUTPUT)), kpmcli.GetLogWriter())
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+// Deprecated: The entire contents of this file will be deprecated.
+// Please use the kcl cli - https://github.com/kcl-lang/cli.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+)
+
+// NewSearchCmd new a Command for `kpm search`.
+func NewSearchCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "search",
+		Usage:     "search the kcl packages in the OCI registries, git repositories and search indexes",
+		ArgsUsage: "<term>",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  FLAG_REGISTRY,
+				Usage: "the OCI registry to search by the catalog API, default is the default registry in the settings",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_REPO_PREFIX,
+				Usage: "the prefix of the repositories of the packages in the OCI registries, default is the default repo in the settings",
+			},
+			&cli.StringSliceFlag{
+				Name:  FLAG_GIT,
+				Usage: "the git repository to search",
+			},
+			&cli.StringSliceFlag{
+				Name:  FLAG_INDEX,
+				Usage: "the local path or the url of the json search index, for the registries without the catalog API",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmSearch(c, kpmcli)
+		},
+	}
+}
+
+func KpmSearch(c *cli.Context, kpmcli *client.KpmClient) error {
+	opts := []client.SearchOption{
+		client.WithSearchTerm(c.Args().First()),
+	}
+
+	registries := c.StringSlice(FLAG_REGISTRY)
+	gitRepos := c.StringSlice(FLAG_GIT)
+	indexes := c.StringSlice(FLAG_INDEX)
+	// The default registry is searched if no registry, git repository or search index is specified.
+	if len(registries) == 0 && len(gitRepos) == 0 && len(indexes) == 0 {
+		registries = append(registries, kpmcli.GetSettings().DefaultOciRegistry())
+	}
+	repoPrefix := kpmcli.GetSettings().DefaultOciRepo()
+	if c.IsSet(FLAG_REPO_PREFIX) {
+		repoPrefix = c.String(FLAG_REPO_PREFIX)
+	}
+	for _, reg := range registries {
+		opts = append(opts, client.WithSearchIndex(kpmcli.NewOciCatalogIndex(reg, repoPrefix)))
+	}
+	if len(gitRepos) != 0 {
+		opts = append(opts, client.WithSearchIndex(kpmcli.NewGitIndex(gitRepos)))
+	}
+	for _, index := range indexes {
+		opts = append(opts, client.WithSearchIndex(kpmcli.NewJsonIndex(index)))
+	}
+
+	results, err := kpmcli.Search(opts...)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tDESCRIPTION")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Name, result.Version, result.Description)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+// Deprecated: The entire contents of this file will be deprecated.
+// Please use the kcl cli - https://github.com/kcl-lang/cli.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/server"
+)
+
+// NewServeCmd new a Command for `kpm serve`.
+func NewServeCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden: false,
+		Name:   "serve",
+		Usage:  "serve the kpm operations over JSON-RPC on stdio or a unix socket",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FLAG_SOCKET,
+				Usage: "the path of the unix socket to listen on, the requests are read from stdin if it is not set",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmServe(c, kpmcli)
+		},
+	}
+}
+
+func KpmServe(c *cli.Context, kpmcli *client.KpmClient) error {
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := server.NewServer(kpmcli)
+	socketPath := c.String(FLAG_SOCKET)
+	if socketPath == "" {
+		// The stdout is used by the responses, the logs outside the requests are written to the stderr.
+		kpmcli.SetLogWriter(os.Stderr)
+		return s.Serve(ctx, os.Stdin, os.Stdout)
+	}
+
+	reporter.ReportMsgTo(fmt.Sprintf("kpm is serving on '%s'", socketPath), kpmcli.GetLogWriter())
+	return s.ServeUnix(ctx, socketPath)
+}
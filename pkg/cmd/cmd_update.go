@@ -109,7 +109,14 @@ func GetModulesToUpdate(kclPkg *pkg.KclPkg, modulesToUpgrade []module.Version, m
 				fmt.Sprintf("failed to get releases for %s", pkgName),
 			)
 		}
-		pkgVersion, err = semver.LatestCompatibleVersion(releases, dep.Version)
+		kpmcli, err := client.NewKpmClient()
+		if err != nil {
+			return err
+		}
+		// The yanked versions are not selected to update.
+		pkgVersion, err = kpmcli.SelectUnyankedRelease(dep.GetSourceType(), dep.GetDownloadPath(), releases, func(versions []string) (string, error) {
+			return semver.LatestCompatibleVersion(versions, dep.Version)
+		})
 		if err != nil {
 			return reporter.NewErrorEvent(
 				reporter.FailedSelectLatestCompatibleVersion,
@@ -0,0 +1,112 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+// Deprecated: The entire contents of this file will be deprecated.
+// Please use the kcl cli - https://github.com/kcl-lang/cli.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// NewPkgVerifyCmd new a Command for `kpm pkg verify`.
+func NewPkgVerifyCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "verify",
+		Usage:     "verify that a kcl package published to OCI registry matches the package sources",
+		ArgsUsage: "<oci_url>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FLAG_PATH,
+				Usage: "the local checkout of the package sources, default is the current directory",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_GIT,
+				Usage: "the git url of the package sources",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_TAG,
+				Usage: "the git tag of the package sources",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_COMMIT,
+				Usage: "the git commit of the package sources",
+			},
+			&cli.StringFlag{
+				Name:  FLAG_BRANCH,
+				Usage: "the git branch of the package sources",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmPkgVerify(c, kpmcli)
+		},
+	}
+}
+
+func KpmPkgVerify(c *cli.Context, kpmcli *client.KpmClient) error {
+	ociUrl := c.Args().First()
+	if len(ociUrl) == 0 {
+		return reporter.NewErrorEvent(
+			reporter.InvalidCmd,
+			fmt.Errorf("the oci url of the published package is required"),
+			"run 'kpm pkg verify help' for more information",
+		)
+	}
+
+	opts := []client.VerifyOption{
+		client.WithVerifySourceUrl(ociUrl),
+	}
+
+	if gitUrl := c.String(FLAG_GIT); len(gitUrl) != 0 {
+		opts = append(opts, client.WithVerifyGit(&downloader.Git{
+			Url:    gitUrl,
+			Tag:    c.String(FLAG_TAG),
+			Commit: c.String(FLAG_COMMIT),
+			Branch: c.String(FLAG_BRANCH),
+		}))
+	} else {
+		localPath := c.String(FLAG_PATH)
+		if len(localPath) == 0 {
+			pwd, err := os.Getwd()
+			if err != nil {
+				return reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, failed to load working directory.")
+			}
+			localPath = pwd
+		}
+		opts = append(opts, client.WithVerifyLocalPath(localPath))
+	}
+
+	result, err := kpmcli.Verify(opts...)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("package: %s\n", result.Ref)
+	fmt.Printf("published sum: %s\n", result.Sum)
+	fmt.Printf("local sum: %s\n", result.LocalSum)
+	for _, file := range result.Added {
+		fmt.Printf("added: %s\n", file)
+	}
+	for _, file := range result.Removed {
+		fmt.Printf("removed: %s\n", file)
+	}
+	for _, file := range result.Changed {
+		fmt.Printf("changed: %s\n", file)
+	}
+
+	if !result.Matched() {
+		return reporter.NewErrorEvent(
+			reporter.FailedVerify,
+			fmt.Errorf("the published package '%s' does not match the package sources", result.Ref),
+		)
+	}
+
+	fmt.Println("the published package matches the package sources")
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 The KCL Authors. All rights reserved.
+// Deprecated: The entire contents of this file will be deprecated.
+// Please use the kcl cli - https://github.com/kcl-lang/cli.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/opt"
+	pkg "kcl-lang.io/kpm/pkg/package"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// NewYankCmd new a Command for `kpm yank`.
+func NewYankCmd(kpmcli *client.KpmClient) *cli.Command {
+	return &cli.Command{
+		Hidden:    false,
+		Name:      "yank",
+		Usage:     "mark a published version of the kcl package as yanked, it will not be selected as the latest version",
+		ArgsUsage: "<package_name>:<version> | <oci_url>?tag=<version>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  FLAG_REASON,
+				Usage: "the reason why the version is yanked",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return KpmYank(c, kpmcli)
+		},
+	}
+}
+
+func KpmYank(c *cli.Context, kpmcli *client.KpmClient) error {
+	if c.NArg() == 0 {
+		return reporter.NewErrorEvent(reporter.InvalidCmd, fmt.Errorf("the version of the package to yank is required"))
+	}
+
+	pkgRef := c.Args().First()
+	sourceOpt := client.WithYankSourceUrl(pkgRef)
+	if !strings.Contains(pkgRef, "://") {
+		pkgName, pkgVersion, err := opt.ParseOciPkgNameAndVersion(pkgRef)
+		if err != nil {
+			return err
+		}
+		sourceOpt = client.WithYankSource(&downloader.Source{
+			ModSpec: &downloader.ModSpec{
+				Name:    pkgName,
+				Version: pkgVersion,
+			},
+		})
+	}
+
+	registries, err := workDirRegistries(kpmcli)
+	if err != nil {
+		return err
+	}
+
+	return kpmcli.Yank(sourceOpt, client.WithYankReason(c.String(FLAG_REASON)), client.WithYankRegistries(registries...))
+}
+
+// workDirRegistries returns the named registries in the kcl.mod of the working directory,
+// nil is returned if the working directory is not a kcl package.
+func workDirRegistries(kpmcli *client.KpmClient) ([]settings.OciRegistry, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, reporter.NewErrorEvent(reporter.Bug, err, "internal bugs, failed to load working directory.")
+	}
+	if !utils.DirExists(filepath.Join(pwd, constants.KCL_MOD)) {
+		return nil, nil
+	}
+
+	modFile, err := pkg.LoadAndFillModFileWithOpts(
+		pkg.WithPath(pwd),
+		pkg.WithSettings(kpmcli.GetSettings()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return modFile.Registries, nil
+}
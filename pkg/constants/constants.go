@@ -6,8 +6,10 @@ const (
 	KFilePathSuffix     = ".k"
 	TarPathSuffix       = ".tar"
 	TgzPathSuffix       = ".tgz"
+	TarGzPathSuffix     = ".tar.gz"
 	GitPathSuffix       = ".git"
 	OciScheme           = "oci"
+	OciFileScheme       = "oci+file"
 	GitScheme           = "git"
 	HttpScheme          = "http"
 	HttpsScheme         = "https"
@@ -22,12 +24,15 @@ const (
 	GitBranch = "branch"
 	GitCommit = "commit"
 
-	Tag = "tag"
-	Mod = "mod"
+	Tag    = "tag"
+	Mod    = "mod"
+	Sha256 = "sha256"
 
 	KCL_MOD                              = "kcl.mod"
 	KCL_MOD_LOCK                         = "kcl.mod.lock"
 	KCL_YAML                             = "kcl.yaml"
+	KCL_IGNORE                           = ".kclignore"
+	GIT_IGNORE                           = ".gitignore"
 	OCI_SEPARATOR                        = ":"
 	KCL_PKG_TAR                          = "*.tar"
 	DEFAULT_KCL_FILE_NAME                = "main.k"
@@ -40,6 +45,11 @@ const (
 	URL_PATH_SEPARATOR                   = "/"
 	LATEST                               = "latest"
 
+	// The annotation of the reason why the version of the package is yanked.
+	DEFAULT_KCL_OCI_MANIFEST_YANKED_REASON = "org.kcllang.package.yanked.reason"
+	// The artifact type of the referrer marking the version of the package as yanked.
+	KCL_OCI_YANK_ARTIFACT_TYPE = "application/vnd.kcl.package.yank.v1"
+
 	// The pattern of the external package argument.
 	EXTERNAL_PKGS_ARG_PATTERN = "%s=%s"
 
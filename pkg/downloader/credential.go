@@ -1,15 +1,42 @@
 package downloader
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
 
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/settings"
 	dockerauth "oras.land/oras-go/pkg/auth/docker"
 	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
 )
 
+// DOCKER_CREDENTIAL_HELPER_PREFIX is the prefix of the docker credential helper executables.
+const DOCKER_CREDENTIAL_HELPER_PREFIX = "docker-credential-"
+
+// dockerCredentialsNotFound is the error message returned by the docker credential helpers if the credential is not found.
+const dockerCredentialsNotFound = "credentials not found in native keychain"
+
+// ErrDockerCredentialsNotFound is returned by DockerCredentialHelperGet if the helper has no credential of the host.
+var ErrDockerCredentialsNotFound = errors.New(dockerCredentialsNotFound)
+
+// dockerIdentityToken is the username returned by the docker credential helpers for the identity token.
+const dockerIdentityToken = "<token>"
+
 // CredClient is the client to get the credentials.
 type CredClient struct {
 	credsClient *dockerauth.Client
+	// credHelpers maps the registry host to the suffix of the docker credential helper.
+	credHelpers map[string]string
+	// credsStore is the suffix of the docker credential helper for all the registries.
+	credsStore string
+	// gitCredentials maps the git host to the credential used for the git repositories.
+	gitCredentials map[string]settings.GitCredential
 }
 
 // LoadCredentialFile loads the credential file and return the CredClient.
@@ -28,16 +55,43 @@ func LoadCredentialFile(filepath string) (*CredClient, error) {
 	}, nil
 }
 
+// LoadCredentialFileWithSettings loads the credential file in the settings,
+// and return the CredClient with the credential helpers configured in the settings.
+func LoadCredentialFileWithSettings(settings *settings.Settings) (*CredClient, error) {
+	credCli, err := LoadCredentialFile(settings.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	credCli.credHelpers = settings.Conf.CredHelpers
+	credCli.credsStore = settings.Conf.CredsStore
+	credCli.gitCredentials = settings.Conf.GitCredentials
+	return credCli, nil
+}
+
 // GetAuthClient returns the auth client.
 func (cred *CredClient) GetAuthClient() *dockerauth.Client {
 	return cred.credsClient
 }
 
 // Credential will reture the credential info cache in CredClient
+// The credential helper configured for the host is used first,
+// and the static auths in the credential file are used if the helper has no credential of the host.
 func (cred *CredClient) Credential(hostName string) (*remoteauth.Credential, error) {
 	if len(hostName) == 0 {
 		return nil, fmt.Errorf("hostName is empty")
 	}
+
+	helper, ok := cred.credHelpers[hostName]
+	if !ok {
+		helper = cred.credsStore
+	}
+	if len(helper) != 0 {
+		helperCred, err := DockerCredentialHelperGet(helper, hostName)
+		if !errors.Is(err, ErrDockerCredentialsNotFound) {
+			return helperCred, err
+		}
+	}
+
 	username, password, err := cred.credsClient.Credential(hostName)
 	if err != nil {
 		return nil, err
@@ -48,3 +102,100 @@ func (cred *CredClient) Credential(hostName string) (*remoteauth.Credential, err
 		Password: password,
 	}, nil
 }
+
+// GitCredential will return the credential of the git repository 'repoUrl' configured in the settings.
+// The token, the git credential helper and the CA file are used over https, and the ssh options are used over ssh.
+// If no credential is configured for the host of 'repoUrl', it returns nil,
+// and the git repository is accessed anonymously or by the global git configuration.
+func (cred *CredClient) GitCredential(repoUrl string) (*git.Credential, error) {
+	u, err := url.Parse(git.NormalizeScpLikeUrl(repoUrl))
+	if err != nil || len(u.Host) == 0 {
+		return nil, nil
+	}
+
+	gitCred, ok := cred.gitCredentials[u.Host]
+	if !ok {
+		gitCred, ok = cred.gitCredentials[u.Hostname()]
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	if u.Scheme == constants.SshScheme {
+		return &git.Credential{
+			SSHKeyFile:        gitCred.SSHKeyFile,
+			SSHKnownHostsFile: gitCred.SSHKnownHosts,
+			SSHAgent:          gitCred.SSHAgent,
+		}, nil
+	}
+
+	var httpsCred *git.Credential
+	if len(gitCred.BearerToken) != 0 {
+		httpsCred = &git.Credential{
+			BearerToken: gitCred.BearerToken,
+		}
+	} else if len(gitCred.Token) != 0 {
+		httpsCred = &git.Credential{
+			Username: gitCred.Username,
+			Password: gitCred.Token,
+		}
+	} else if len(gitCred.Helper) != 0 {
+		httpsCred, err = git.CredentialFill(gitCred.Helper, u.String())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(gitCred.CAFile) != 0 {
+		if httpsCred == nil {
+			httpsCred = &git.Credential{}
+		}
+		httpsCred.CAFile = gitCred.CAFile
+	}
+	return httpsCred, nil
+}
+
+// dockerCredential is the output of the 'get' command of the docker credential helpers.
+type dockerCredential struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// DockerCredentialHelperGet gets the credential of 'hostName' from the docker credential helper 'docker-credential-<helper>',
+// see https://github.com/docker/docker-credential-helpers for the protocol.
+// It returns ErrDockerCredentialsNotFound if the helper has no credential of 'hostName'.
+func DockerCredentialHelperGet(helper, hostName string) (*remoteauth.Credential, error) {
+	cmd := exec.Command(DOCKER_CREDENTIAL_HELPER_PREFIX+helper, "get")
+	cmd.Stdin = strings.NewReader(hostName)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// The docker credential helpers report the error message in stdout.
+		if strings.Contains(stdout.String(), dockerCredentialsNotFound) {
+			return nil, ErrDockerCredentialsNotFound
+		}
+		return nil, fmt.Errorf(
+			"failed to get the credential of '%s' from '%s%s': %w, %s",
+			hostName, DOCKER_CREDENTIAL_HELPER_PREFIX, helper, err,
+			strings.TrimSpace(stdout.String()+stderr.String()),
+		)
+	}
+
+	var dockerCred dockerCredential
+	if err := json.Unmarshal(stdout.Bytes(), &dockerCred); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of '%s%s': %w", DOCKER_CREDENTIAL_HELPER_PREFIX, helper, err)
+	}
+
+	if dockerCred.Username == dockerIdentityToken {
+		return &remoteauth.Credential{
+			RefreshToken: dockerCred.Secret,
+		}, nil
+	}
+	return &remoteauth.Credential{
+		Username: dockerCred.Username,
+		Password: dockerCred.Secret,
+	}, nil
+}
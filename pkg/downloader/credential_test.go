@@ -0,0 +1,76 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/test"
+	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
+)
+
+func TestCredentialWithHelpers(t *testing.T) {
+	helperDir := test.SetupFakeCredentialHelpers(t)
+
+	// The static auths in the credential file are used if the credential helper has no credential of the host.
+	credentialsFile := filepath.Join(t.TempDir(), "config.json")
+	assert.NilError(t, os.WriteFile(credentialsFile, []byte(`{"auths":{"ghcr.io":{"auth":"a3BtOmtwbS1naGNyLXNlY3JldA=="}}}`), 0600))
+
+	credCli, err := LoadCredentialFileWithSettings(&settings.Settings{
+		CredentialsFile: credentialsFile,
+		Conf: settings.KpmConf{
+			CredHelpers: map[string]string{
+				"example.com": "kpmtest",
+			},
+			CredsStore: "kpmtest",
+			GitCredentials: map[string]settings.GitCredential{
+				"github.com":  {Token: "kpm-token", SSHKeyFile: "/home/kpm/.ssh/id_ed25519", SSHKnownHosts: "/home/kpm/.ssh/known_hosts"},
+				"example.com": {Helper: filepath.Join(helperDir, "git-credential-kpmtest")},
+				"gitea.com":   {BearerToken: "kpm-bearer-token", Token: "ignored"},
+			},
+		},
+	})
+	assert.NilError(t, err)
+
+	cred, err := credCli.Credential("example.com")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cred, &remoteauth.Credential{Username: "kpm", Password: "kpm-secret"})
+
+	cred, err = credCli.Credential("token.example.com")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cred, &remoteauth.Credential{RefreshToken: "kpm-identity-token"})
+
+	cred, err = credCli.Credential("ghcr.io")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cred, &remoteauth.Credential{Username: "kpm", Password: "kpm-ghcr-secret"})
+
+	cred, err = credCli.Credential("docker.io")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, cred, &remoteauth.Credential{})
+
+	_, err = DockerCredentialHelperGet("kpmtest", "ghcr.io")
+	assert.ErrorIs(t, err, ErrDockerCredentialsNotFound)
+
+	gitCred, err := credCli.GitCredential("https://github.com/kcl-lang/kcl.git")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gitCred, &git.Credential{Password: "kpm-token"})
+
+	gitCred, err = credCli.GitCredential("https://example.com/kcl-lang/kcl.git")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gitCred, &git.Credential{Username: "kpm", Password: "kpm-token"})
+
+	gitCred, err = credCli.GitCredential("git@github.com:kcl-lang/kcl.git")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gitCred, &git.Credential{SSHKeyFile: "/home/kpm/.ssh/id_ed25519", SSHKnownHostsFile: "/home/kpm/.ssh/known_hosts"})
+
+	gitCred, err = credCli.GitCredential("https://gitea.com/kcl-lang/kcl.git")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, gitCred, &git.Credential{BearerToken: "kpm-bearer-token"})
+
+	gitCred, err = credCli.GitCredential("https://gitlab.com/kcl-lang/kcl.git")
+	assert.NilError(t, err)
+	assert.Assert(t, gitCred == nil)
+}
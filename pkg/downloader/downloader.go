@@ -1,6 +1,7 @@
 package downloader
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -99,6 +100,15 @@ func WithSource(source Source) Option {
 	}
 }
 
+// gitCredential returns the credential of the git repository 'repoUrl',
+// it returns nil if no credential client is set or no credential is configured for the repository.
+func (do *DownloadOptions) gitCredential(repoUrl string) (*git.Credential, error) {
+	if do.credsClient == nil {
+		return nil, nil
+	}
+	return do.credsClient.GitCredential(repoUrl)
+}
+
 func NewDownloadOptions(opts ...Option) *DownloadOptions {
 	do := &DownloadOptions{}
 	for _, opt := range opts {
@@ -108,43 +118,62 @@ func NewDownloadOptions(opts ...Option) *DownloadOptions {
 }
 
 // Downloader is the interface for downloading a package.
+// The context of the download and the latest version lookup is 'ctx',
+// the implementations should abort their network I/O when it is canceled.
 type Downloader interface {
-	Download(opts *DownloadOptions) error
+	Download(ctx context.Context, opts *DownloadOptions) error
 	// Get the latest version of the remote source
 	// For the git source, it will return the latest commit
 	// For the OCI source, it will return the latest tag
-	LatestVersion(opts *DownloadOptions) (string, error)
+	LatestVersion(ctx context.Context, opts *DownloadOptions) (string, error)
 }
 
-func (d *DepDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
+func (d *DepDownloader) LatestVersion(ctx context.Context, opts *DownloadOptions) (string, error) {
 	if opts.Source.Oci != nil {
 		if d.OciDownloader == nil {
 			d.OciDownloader = &OciDownloader{}
 		}
-		return d.OciDownloader.LatestVersion(opts)
+		return d.OciDownloader.LatestVersion(ctx, opts)
 	}
 
 	if opts.Source.Git != nil {
 		if d.GitDownloader == nil {
 			d.GitDownloader = &GitDownloader{}
 		}
-		return d.GitDownloader.LatestVersion(opts)
+		return d.GitDownloader.LatestVersion(ctx, opts)
+	}
+
+	if opts.Source.Http != nil {
+		if d.HttpDownloader == nil {
+			d.HttpDownloader = &HttpDownloader{}
+		}
+		return d.HttpDownloader.LatestVersion(ctx, opts)
+	}
+
+	if opts.Source.Custom != nil {
+		customDownloader, err := customDownloader(opts.Source.Custom)
+		if err != nil {
+			return "", err
+		}
+		return customDownloader.LatestVersion(ctx, opts)
 	}
 
 	return "", errors.New("source is nil")
 }
 
 // DepDownloader is the downloader for the package.
-// Only support the OCI and git source.
+// The OCI, git and http source are supported by kpm,
+// and the custom source is dispatched to the downloader registered for its scheme by 'RegisterDownloader'.
 type DepDownloader struct {
 	*OciDownloader
 	*GitDownloader
+	*HttpDownloader
 }
 
 // GitDownloader is the downloader for the git source.
 type GitDownloader struct{}
 
-func (d *GitDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
+func (d *GitDownloader) LatestVersion(ctx context.Context, opts *DownloadOptions) (string, error) {
 	if opts.Offline {
 		return "", errors.New("offline mode is enabled, the latest version is not supported")
 	}
@@ -152,6 +181,10 @@ func (d *GitDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	gitCred, err := opts.gitCredential(gitUrl)
+	if err != nil {
+		return "", err
+	}
 	// TODO：supports fetch the latest commit from the git bare repo,
 	// after totally transfer to the new storage.
 	// refer to cargo: https://github.com/rust-lang/cargo/blob/3dedb85a25604bdbbb8d3bf4b03162961a4facd0/crates/cargo-util-schemas/src/core/source_kind.rs#L133
@@ -174,6 +207,8 @@ func (d *GitDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 			git.WithTag(opts.Source.Git.Tag),
 			git.WithRepoURL(gitUrl),
 			git.WithLocalPath(tmp),
+			git.WithCredential(gitCred),
+			git.WithContext(ctx),
 		)
 
 		if err != nil {
@@ -184,7 +219,7 @@ func (d *GitDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 		cacheFullPath := opts.CachePath
 		// If the cache bare git repository exists, fetch the latest commit from the cache.
 		if git.IsGitBareRepo(cacheFullPath) {
-			err := git.Fetch(cacheFullPath)
+			err := git.FetchWithContext(ctx, cacheFullPath, gitCred)
 			if err != nil {
 				return "", err
 			}
@@ -198,6 +233,7 @@ func (d *GitDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 				git.WithCommit(opts.Source.Git.Commit),
 				git.WithBranch(opts.Source.Git.Branch),
 				git.WithTag(opts.Source.Git.Tag),
+				git.WithContext(ctx),
 			}
 
 			repo, err = git.CloneWithOpts(
@@ -206,6 +242,7 @@ func (d *GitDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 					git.WithRepoURL(gitUrl),
 					git.WithLocalPath(cacheFullPath),
 					git.WithBare(true),
+					git.WithCredential(gitCred),
 				)...,
 			)
 			if err != nil {
@@ -230,7 +267,7 @@ type OciDownloader struct {
 	Platform string
 }
 
-func (d *OciDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
+func (d *OciDownloader) LatestVersion(ctx context.Context, opts *DownloadOptions) (string, error) {
 	if opts.Offline {
 		return "", errors.New("offline mode is enabled, the latest version is not supported")
 	}
@@ -241,14 +278,23 @@ func (d *OciDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 		return "", errors.New("oci source is nil")
 	}
 
-	repoPath := utils.JoinPath(ociSource.Reg, ociSource.Repo)
+	ociCli, err := d.newOciClient(ctx, ociSource, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return ociCli.TheLatestTag()
+}
 
+// newOciClient returns the OciClient of the OCI source with the credential in the download options,
+// the credential is not required by the local OCI image layout.
+func (d *OciDownloader) newOciClient(ctx context.Context, ociSource *Oci, opts *DownloadOptions) (*oci.OciClient, error) {
 	var cred *remoteauth.Credential
 	var err error
-	if opts.credsClient != nil {
+	if opts.credsClient != nil && !ociSource.Layout {
 		cred, err = opts.credsClient.Credential(ociSource.Reg)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	} else {
 		cred = &remoteauth.Credential{}
@@ -256,18 +302,17 @@ func (d *OciDownloader) LatestVersion(opts *DownloadOptions) (string, error) {
 
 	ociCli, err := oci.NewOciClientWithOpts(
 		oci.WithCredential(cred),
-		oci.WithRepoPath(repoPath),
+		ociSource.RepoOption(),
 		oci.WithSettings(&opts.Settings),
 		oci.WithInsecureSkipTLSverify(opts.InsecureSkipTLSverify),
+		oci.WithContext(ctx),
 	)
-
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	ociCli.PullOciOptions.Platform = d.Platform
-
-	return ociCli.TheLatestTag()
+	return ociCli, nil
 }
 
 func NewOciDownloader(platform string) *DepDownloader {
@@ -278,7 +323,10 @@ func NewOciDownloader(platform string) *DepDownloader {
 	}
 }
 
-func (d *DepDownloader) Download(opts *DownloadOptions) error {
+func (d *DepDownloader) Download(ctx context.Context, opts *DownloadOptions) (retErr error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	// create a tmp dir to download the oci package.
 	tmpDir, err := os.MkdirTemp("", "")
 	if err != nil {
@@ -306,10 +354,16 @@ func (d *DepDownloader) Download(opts *DownloadOptions) error {
 			}
 			return nil
 		} else {
-			err := os.MkdirAll(cacheFullPath, 0755)
-			if err != nil {
+			newCache := !utils.DirExists(cacheFullPath)
+			if err := os.MkdirAll(cacheFullPath, 0755); err != nil {
 				return err
 			}
+			// The cache created for the download is removed if the download is failed or canceled.
+			defer func() {
+				if retErr != nil && newCache {
+					_ = os.RemoveAll(cacheFullPath)
+				}
+			}()
 		}
 	}
 
@@ -325,7 +379,7 @@ func (d *DepDownloader) Download(opts *DownloadOptions) error {
 			if d.OciDownloader == nil {
 				d.OciDownloader = &OciDownloader{}
 			}
-			err := d.OciDownloader.Download(opts)
+			err := d.OciDownloader.Download(ctx, opts)
 			if err != nil {
 				return err
 			}
@@ -335,7 +389,28 @@ func (d *DepDownloader) Download(opts *DownloadOptions) error {
 			if d.GitDownloader == nil {
 				d.GitDownloader = &GitDownloader{}
 			}
-			err := d.GitDownloader.Download(opts)
+			err := d.GitDownloader.Download(ctx, opts)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.Source.Http != nil {
+			if d.HttpDownloader == nil {
+				d.HttpDownloader = &HttpDownloader{}
+			}
+			err := d.HttpDownloader.Download(ctx, opts)
+			if err != nil {
+				return err
+			}
+		}
+
+		if opts.Source.Custom != nil {
+			customDownloader, err := customDownloader(opts.Source.Custom)
+			if err != nil {
+				return err
+			}
+			err = customDownloader.Download(ctx, opts)
 			if err != nil {
 				return err
 			}
@@ -376,7 +451,7 @@ type Platform struct {
 	Platform     *v1.Platform
 }
 
-func (d *OciDownloader) Download(opts *DownloadOptions) error {
+func (d *OciDownloader) Download(ctx context.Context, opts *DownloadOptions) error {
 	// download the package from the OCI registry
 	ociSource := opts.Source.Oci
 	if ociSource == nil {
@@ -385,32 +460,11 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 
 	localPath := opts.LocalPath
 
-	repoPath := utils.JoinPath(ociSource.Reg, ociSource.Repo)
-
-	var cred *remoteauth.Credential
-	var err error
-	if opts.credsClient != nil {
-		cred, err = opts.credsClient.Credential(ociSource.Reg)
-		if err != nil {
-			return err
-		}
-	} else {
-		cred = &remoteauth.Credential{}
-	}
-
-	ociCli, err := oci.NewOciClientWithOpts(
-		oci.WithCredential(cred),
-		oci.WithRepoPath(repoPath),
-		oci.WithSettings(&opts.Settings),
-		oci.WithInsecureSkipTLSverify(opts.InsecureSkipTLSverify),
-	)
-
+	ociCli, err := d.newOciClient(ctx, ociSource, opts)
 	if err != nil {
 		return err
 	}
 
-	ociCli.PullOciOptions.Platform = d.Platform
-
 	if len(ociSource.Tag) == 0 {
 		tagSelected, err := ociCli.TheLatestTag()
 		if err != nil {
@@ -438,14 +492,19 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 				if err != nil && errors.Is(err, utils.PkgArchiveNotFound) {
 					reporter.ReportMsgTo(
 						fmt.Sprintf(
-							"downloading '%s:%s' from '%s/%s:%s'",
-							ociSource.Repo, ociSource.Tag, ociSource.Reg, ociSource.Repo, ociSource.Tag,
+							"downloading '%s:%s' from '%s:%s'",
+							ociSource.Repo, ociSource.Tag, ociCli.GetReference(), ociSource.Tag,
 						),
 						opts.LogWriter,
 					)
 
+					newCache := !utils.DirExists(cacheFullPath)
 					err = ociCli.Pull(cacheFullPath, ociSource.Tag)
 					if err != nil {
+						// The package pulled partially into the cache is removed.
+						if newCache {
+							_ = os.RemoveAll(cacheFullPath)
+						}
 						return err
 					}
 					cacheTarPath, err = utils.FindPkgArchive(cacheFullPath)
@@ -468,8 +527,8 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 		} else if !opts.Offline {
 			reporter.ReportMsgTo(
 				fmt.Sprintf(
-					"downloading '%s:%s' from '%s/%s:%s'",
-					ociSource.Repo, ociSource.Tag, ociSource.Reg, ociSource.Repo, ociSource.Tag,
+					"downloading '%s:%s' from '%s:%s'",
+					ociSource.Repo, ociSource.Tag, ociCli.GetReference(), ociSource.Tag,
 				),
 				opts.LogWriter,
 			)
@@ -502,8 +561,8 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 	} else if !opts.Offline {
 		reporter.ReportMsgTo(
 			fmt.Sprintf(
-				"downloading '%s:%s' from '%s/%s:%s'",
-				ociSource.Repo, ociSource.Tag, ociSource.Reg, ociSource.Repo, ociSource.Tag,
+				"downloading '%s:%s' from '%s:%s'",
+				ociSource.Repo, ociSource.Tag, ociCli.GetReference(), ociSource.Tag,
 			),
 			opts.LogWriter,
 		)
@@ -542,7 +601,7 @@ func (d *OciDownloader) Download(opts *DownloadOptions) error {
 	return err
 }
 
-func (d *GitDownloader) Download(opts *DownloadOptions) error {
+func (d *GitDownloader) Download(ctx context.Context, opts *DownloadOptions) error {
 	gitSource := opts.Source.Git
 	if gitSource == nil {
 		return errors.New("git source is nil")
@@ -552,10 +611,33 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 	if err != nil {
 		return err
 	}
+	gitCred, err := opts.gitCredential(gitUrl)
+	if err != nil {
+		return err
+	}
 	cloneOpts := []git.CloneOption{
 		git.WithCommit(gitSource.Commit),
 		git.WithBranch(gitSource.Branch),
 		git.WithTag(gitSource.Tag),
+		git.WithContext(ctx),
+	}
+	// Only the directory of the package is checked out in the working copy,
+	// the bare repository in the cache is always a full clone.
+	// The submodules and Git LFS objects are only fetched into the working copy.
+	workTreeOpts := []git.CloneOption{
+		git.WithPackage(gitSource.Package),
+		git.WithSparsePath(gitSource.Subdir),
+		git.WithSubmodules(gitSource.Submodules),
+		git.WithLfs(gitSource.Lfs),
+	}
+	// The working copy cloned from the bare repository in the cache
+	// fetches the submodules and Git LFS objects from the remote git repository.
+	var fromCacheOpts []git.CloneOption
+	if gitSource.Submodules || gitSource.Lfs {
+		fromCacheOpts = []git.CloneOption{
+			git.WithOriginURL(gitUrl),
+			git.WithCredential(gitCred),
+		}
 	}
 
 	var msg string
@@ -583,7 +665,7 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 				// Try to clone the bare repository from the cache path.
 				_, err := git.CloneWithOpts(
 					append(
-						cloneOpts,
+						append(append(cloneOpts, workTreeOpts...), fromCacheOpts...),
 						git.WithRepoURL(cacheFullPath),
 						git.WithLocalPath(localFullPath),
 					)...,
@@ -593,7 +675,7 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 				if err != nil && !opts.Offline {
 					// If the bare repository cache exists, fetch the latest commit from the cache.
 					if utils.DirExists(cacheFullPath) && git.IsGitBareRepo(cacheFullPath) {
-						err := git.Fetch(cacheFullPath)
+						err := git.FetchWithContext(ctx, cacheFullPath, gitCred)
 						if err != nil {
 							return err
 						}
@@ -615,6 +697,7 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 								git.WithRepoURL(gitUrl),
 								git.WithLocalPath(cacheFullPath),
 								git.WithBare(true),
+								git.WithCredential(gitCred),
 							)...,
 						)
 						if err != nil {
@@ -625,7 +708,7 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 					// Clone the repository from the cache path to the local path.
 					_, err = git.CloneWithOpts(
 						append(
-							cloneOpts,
+							append(append(cloneOpts, workTreeOpts...), fromCacheOpts...),
 							git.WithRepoURL(cacheFullPath),
 							git.WithLocalPath(localFullPath),
 						)...,
@@ -643,9 +726,10 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 			// If the cache is disabled, clone the repository from the remote git repository.
 			_, err = git.CloneWithOpts(
 				append(
-					cloneOpts,
+					append(cloneOpts, workTreeOpts...),
 					git.WithRepoURL(gitUrl),
 					git.WithLocalPath(opts.LocalPath),
+					git.WithCredential(gitCred),
 				)...,
 			)
 			if err != nil {
@@ -669,6 +753,12 @@ func (d *GitDownloader) Download(opts *DownloadOptions) error {
 			git.WithTag(gitSource.Tag),
 			git.WithRepoURL(gitUrl),
 			git.WithLocalPath(opts.LocalPath),
+			git.WithCredential(gitCred),
+			git.WithPackage(gitSource.Package),
+			git.WithSparsePath(gitSource.Subdir),
+			git.WithSubmodules(gitSource.Submodules),
+			git.WithLfs(gitSource.Lfs),
+			git.WithContext(ctx),
 		)
 
 		if err != nil {
@@ -1,14 +1,17 @@
 package downloader
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
 	"kcl-lang.io/kpm/pkg/features"
 	"kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/mock"
 	"kcl-lang.io/kpm/pkg/test"
 	"kcl-lang.io/kpm/pkg/utils"
 )
@@ -33,22 +36,29 @@ func testOciDownloader(t *testing.T) {
 		_ = os.RemoveAll(path_oci)
 	}()
 
+	reg, err := mock.NewOciRegistry()
+	assert.Equal(t, err, nil)
+	defer reg.Close()
+	_, err = reg.PushPkg(filepath.Join("..", "mock", "test_pkg", "helloworld_0.1.0"), "test/helloworld")
+	assert.Equal(t, err, nil)
+
 	ociDownloader := OciDownloader{
 		Platform: "linux/amd64",
 	}
 
-	err := ociDownloader.Download(NewDownloadOptions(
+	err = ociDownloader.Download(context.Background(), NewDownloadOptions(
 		WithSource(Source{
 			Oci: &Oci{
-				Reg:  "ghcr.io",
-				Repo: "zong-zhe/helloworld",
-				Tag:  "0.0.3",
+				Reg:  reg.Host,
+				Repo: "test/helloworld",
+				Tag:  "0.1.0",
 			},
 		}),
 		WithLocalPath(path_oci),
 	))
 
 	assert.Equal(t, err, nil)
+	assert.Equal(t, utils.DirExists(filepath.Join(path_oci, "kcl.mod")), true)
 }
 
 func testGitDownloader(t *testing.T) {
@@ -62,17 +72,25 @@ func testGitDownloader(t *testing.T) {
 		_ = os.RemoveAll(path_git)
 	}()
 
+	server, err := mock.NewGitServer()
+	assert.Equal(t, err, nil)
+	defer server.Close()
+	commit, err := server.Seed("helloworld", filepath.Join("..", "mock", "test_pkg", "helloworld_0.1.0"))
+	assert.Equal(t, err, nil)
+	_, err = server.Seed("helloworld", filepath.Join("..", "mock", "test_pkg", "helloworld_0.2.0"))
+	assert.Equal(t, err, nil)
+
 	gitDownloader := GitDownloader{}
 	gitSource := Source{
 		Git: &Git{
-			Url:    "https://github.com/kcl-lang/flask-demo-kcl-manifests.git",
-			Commit: "ade147b",
+			Url:    server.RepoUrl("helloworld"),
+			Commit: commit,
 		},
 	}
 	gitHash, err := gitSource.Hash()
 	assert.Equal(t, err, nil)
 
-	err = gitDownloader.Download(NewDownloadOptions(
+	err = gitDownloader.Download(context.Background(), NewDownloadOptions(
 		WithSource(gitSource),
 		WithLocalPath(filepath.Join(path_git, "git", "src", gitHash)),
 		WithCachePath(filepath.Join(path_git, "git", "cache", gitHash)),
@@ -84,6 +102,9 @@ func testGitDownloader(t *testing.T) {
 	assert.Equal(t, git.IsGitBareRepo(filepath.Join(path_git, "git", "cache", gitHash)), true)
 	assert.Equal(t, utils.DirExists(filepath.Join(path_git, "git", "src", gitHash)), true)
 	assert.Equal(t, utils.DirExists(filepath.Join(path_git, "git", "src", gitHash, "kcl.mod")), true)
+	modContent, err := os.ReadFile(filepath.Join(path_git, "git", "src", gitHash, "kcl.mod"))
+	assert.Equal(t, err, nil)
+	assert.Assert(t, strings.Contains(string(modContent), `version = "0.1.0"`))
 }
 
 func TestWithGlobalLock(t *testing.T) {
@@ -0,0 +1,201 @@
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/features"
+	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// HttpDownloader is the downloader for the tarball on a http(s) server.
+type HttpDownloader struct{}
+
+// LatestVersion is not supported by the http source, because the tarball is pinned by its sha256 checksum.
+func (d *HttpDownloader) LatestVersion(ctx context.Context, opts *DownloadOptions) (string, error) {
+	return "", errors.New("the latest version is not supported for the http source")
+}
+
+func (d *HttpDownloader) Download(ctx context.Context, opts *DownloadOptions) error {
+	httpSource := opts.Source.Http
+	if httpSource == nil {
+		return errors.New("http source is nil")
+	}
+	if err := httpSource.Validate(); err != nil {
+		return err
+	}
+
+	if ok, err := features.Enabled(features.SupportNewStorage); err == nil && ok && opts.EnableCache {
+		localFullPath := opts.LocalPath
+		if utils.DirExists(filepath.Join(localFullPath, constants.KCL_MOD)) {
+			return nil
+		}
+
+		// The tarball is kept in the cache, and it is verified again before each extraction.
+		cacheTarPath := filepath.Join(opts.CachePath, httpSource.FileName())
+		if !utils.DirExists(cacheTarPath) {
+			if opts.Offline {
+				return ErrNotFoundAndOffline
+			}
+			newCache := !utils.DirExists(opts.CachePath)
+			if err := downloadHttpTarball(ctx, httpSource, cacheTarPath, opts); err != nil {
+				// The cache created for the download is removed if the download is failed or canceled.
+				if newCache {
+					_ = os.RemoveAll(opts.CachePath)
+				}
+				return err
+			}
+		} else if err := verifySha256(cacheTarPath, httpSource.Sha256); err != nil {
+			return err
+		}
+
+		return extractHttpTarball(cacheTarPath, localFullPath)
+	} else if !opts.Offline {
+		tmpDir, err := os.MkdirTemp("", "")
+		if err != nil {
+			return fmt.Errorf("failed to create a temp dir: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		tarPath := filepath.Join(tmpDir, httpSource.FileName())
+		if err := downloadHttpTarball(ctx, httpSource, tarPath, opts); err != nil {
+			return err
+		}
+
+		if err := extractHttpTarball(tarPath, opts.LocalPath); err != nil {
+			return err
+		}
+	}
+
+	if opts.Offline && !utils.DirExists(filepath.Join(opts.LocalPath, constants.KCL_MOD)) {
+		return ErrNotFoundAndOffline
+	}
+
+	return nil
+}
+
+// downloadHttpTarball downloads the tarball of the http source to 'tarPath'.
+// The tarball is written to 'tarPath' only after its sha256 checksum is verified.
+func downloadHttpTarball(ctx context.Context, httpSource *Http, tarPath string, opts *DownloadOptions) error {
+	reporter.ReportMsgTo(
+		fmt.Sprintf("downloading '%s'", httpSource.HttpUrl),
+		opts.LogWriter,
+	)
+
+	client := &http.Client{}
+	if opts.InsecureSkipTLSverify {
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpSource.HttpUrl, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download '%s': %w", httpSource.HttpUrl, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download '%s': %s", httpSource.HttpUrl, resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(tarPath), 0755); err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(tarPath), filepath.Base(tarPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmpFile, hasher), resp.Body)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download '%s': %w", httpSource.HttpUrl, err)
+	}
+
+	if sum := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(sum, httpSource.Sha256) {
+		return fmt.Errorf("checksum mismatch for '%s': expected sha256 '%s', got '%s'", httpSource.HttpUrl, httpSource.Sha256, sum)
+	}
+
+	return os.Rename(tmpFile.Name(), tarPath)
+}
+
+// verifySha256 checks the sha256 checksum of the file 'path'.
+func verifySha256(path, expected string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return err
+	}
+
+	if sum := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(sum, expected) {
+		return fmt.Errorf("checksum mismatch for '%s': expected sha256 '%s', got '%s'", path, expected, sum)
+	}
+	return nil
+}
+
+// extractHttpTarball extracts the tarball 'tarPath' into 'destDir'.
+// If the tarball wraps the package in a single top-level directory, e.g. the release archives of GitHub,
+// the content of the directory is moved up into 'destDir'.
+func extractHttpTarball(tarPath, destDir string) error {
+	var err error
+	if utils.IsTar(tarPath) {
+		err = utils.UnTarDir(tarPath, destDir)
+	} else {
+		err = utils.ExtractTarball(tarPath, destDir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to untar the kcl package tar from '%s' into '%s': %w", tarPath, destDir, err)
+	}
+
+	if utils.DirExists(filepath.Join(destDir, constants.KCL_MOD)) {
+		return nil
+	}
+
+	entries, err := os.ReadDir(destDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil
+	}
+
+	rootDir := filepath.Join(destDir, entries[0].Name())
+	children, err := os.ReadDir(rootDir)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := os.Rename(filepath.Join(rootDir, child.Name()), filepath.Join(destDir, child.Name())); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(rootDir)
+}
@@ -0,0 +1,92 @@
+package downloader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// serveTestTarball packs the package 'pkgDir' into a '.tgz' tarball wrapped in a top-level directory,
+// serves it by a http server and returns the url and the sha256 checksum of the tarball.
+func serveTestTarball(t *testing.T, pkgDir string) (string, string) {
+	wrapDir := t.TempDir()
+	assert.NilError(t, os.CopyFS(filepath.Join(wrapDir, "helloworld-0.1.0"), os.DirFS(pkgDir)))
+	tgzPath := filepath.Join(t.TempDir(), "helloworld-0.1.0.tgz")
+	assert.NilError(t, utils.TarGzDir(wrapDir, tgzPath, nil, nil))
+
+	content, err := os.ReadFile(tgzPath)
+	assert.NilError(t, err)
+	sum := sha256.Sum256(content)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/helloworld-0.1.0.tgz", hex.EncodeToString(sum[:])
+}
+
+func TestHttpDownloader(t *testing.T) {
+	pkgDir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(pkgDir, "kcl.mod"), []byte("[package]\nname = \"helloworld\"\nversion = \"0.1.0\"\n"), 0644))
+	assert.NilError(t, os.WriteFile(filepath.Join(pkgDir, "main.k"), []byte("a = 1\n"), 0644))
+	tarballUrl, sum := serveTestTarball(t, pkgDir)
+
+	localPath := t.TempDir()
+	err := (&DepDownloader{}).Download(context.Background(), NewDownloadOptions(
+		WithSource(Source{Http: &Http{HttpUrl: tarballUrl, Sha256: sum}}),
+		WithLocalPath(localPath),
+	))
+	assert.NilError(t, err)
+	assert.Assert(t, utils.DirExists(filepath.Join(localPath, "kcl.mod")))
+	assert.Assert(t, utils.DirExists(filepath.Join(localPath, "main.k")))
+
+	err = (&DepDownloader{}).Download(context.Background(), NewDownloadOptions(
+		WithSource(Source{Http: &Http{HttpUrl: tarballUrl, Sha256: strings.Repeat("0", 64)}}),
+		WithLocalPath(filepath.Join(t.TempDir(), "mismatch")),
+	))
+	assert.ErrorContains(t, err, "checksum mismatch for '"+tarballUrl+"'")
+}
+
+func TestHttpDownloaderWithContext(t *testing.T) {
+	// The server does not respond until the request is aborted.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	source := Source{Http: &Http{HttpUrl: server.URL + "/helloworld-0.1.0.tgz", Sha256: strings.Repeat("0", 64)}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	localPath := filepath.Join(t.TempDir(), "canceled")
+	err := (&DepDownloader{}).Download(ctx, NewDownloadOptions(
+		WithSource(source),
+		WithLocalPath(localPath),
+	))
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	assert.Assert(t, !utils.DirExists(localPath))
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	localPath = filepath.Join(t.TempDir(), "timeout")
+	cachePath := filepath.Join(t.TempDir(), "cache")
+	err = (&DepDownloader{}).Download(ctx, NewDownloadOptions(
+		WithSource(source),
+		WithLocalPath(localPath),
+		WithCachePath(cachePath),
+		WithEnableCache(true),
+	))
+	assert.Assert(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Assert(t, !utils.DirExists(localPath))
+	assert.Assert(t, !utils.DirExists(cachePath))
+}
@@ -0,0 +1,107 @@
+package downloader
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"kcl-lang.io/kpm/pkg/constants"
+)
+
+// CustomTOMLMarshaler is implemented by the Downloader registered for a custom scheme to customize the dependency of the scheme in kcl.mod.
+// By default, the dependency is marshaled as `{ <scheme> = "<url>", tag = "<tag>" }`.
+type CustomTOMLMarshaler interface {
+	// MarshalTOML returns the fields of the source in the inline table of the dependency.
+	MarshalTOML(source *Custom) string
+	// UnmarshalModTOML parses the source from the inline table of the dependency,
+	// it is only called if the table contains the key of the scheme.
+	UnmarshalModTOML(meta map[string]interface{}) (*Custom, error)
+}
+
+// CustomSourceHasher is implemented by the Downloader registered for a custom scheme to customize the path of the package
+// relative to the root of the cache and the local storage.
+// By default, the path is '<short hash of the host and the parent path>/<base name>/<tag>'.
+type CustomSourceHasher interface {
+	Hash(source *Custom) (string, error)
+}
+
+// builtinSchemes are the source schemes supported by kpm itself, they cannot be registered.
+var builtinSchemes = map[string]bool{
+	constants.GitScheme:        true,
+	constants.OciScheme:        true,
+	constants.HttpScheme:       true,
+	constants.HttpsScheme:      true,
+	constants.SshScheme:        true,
+	constants.DefaultOciScheme: true,
+	constants.FileEntry:        true,
+}
+
+var (
+	customDownloadersMu sync.RWMutex
+	customDownloaders   = map[string]Downloader{}
+)
+
+// RegisterDownloader registers the downloader for the packages from the custom source 'scheme', e.g. 's3'.
+// The dependencies whose url has the scheme are parsed into the 'Custom' source and downloaded by 'd',
+// and the scheme is also the key of the url of the dependency in kcl.mod,
+// e.g. `helloworld = { s3 = "s3://bucket/helloworld", tag = "0.1.0" }`.
+// The context of the client is passed to the methods of 'd' as the first argument.
+func RegisterDownloader(scheme string, d Downloader) error {
+	if scheme == "" {
+		return fmt.Errorf("the scheme of the downloader is empty")
+	}
+	if d == nil {
+		return fmt.Errorf("the downloader of scheme '%s' is nil", scheme)
+	}
+	if builtinSchemes[scheme] {
+		return fmt.Errorf("the scheme '%s' is supported by kpm and cannot be registered", scheme)
+	}
+
+	customDownloadersMu.Lock()
+	defer customDownloadersMu.Unlock()
+	if _, ok := customDownloaders[scheme]; ok {
+		return fmt.Errorf("the downloader of scheme '%s' is already registered", scheme)
+	}
+	customDownloaders[scheme] = d
+	return nil
+}
+
+// UnregisterDownloader removes the downloader registered for the custom source 'scheme'.
+func UnregisterDownloader(scheme string) {
+	customDownloadersMu.Lock()
+	defer customDownloadersMu.Unlock()
+	delete(customDownloaders, scheme)
+}
+
+// LookupDownloader returns the downloader registered for the custom source 'scheme'.
+func LookupDownloader(scheme string) (Downloader, bool) {
+	customDownloadersMu.RLock()
+	defer customDownloadersMu.RUnlock()
+	d, ok := customDownloaders[scheme]
+	return d, ok
+}
+
+// registeredSchemes returns the sorted schemes of the registered downloaders.
+func registeredSchemes() []string {
+	customDownloadersMu.RLock()
+	defer customDownloadersMu.RUnlock()
+	schemes := make([]string, 0, len(customDownloaders))
+	for scheme := range customDownloaders {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// customDownloader returns the downloader registered for the scheme of the custom source.
+func customDownloader(source *Custom) (Downloader, error) {
+	if source == nil {
+		return nil, fmt.Errorf("custom source is nil")
+	}
+	scheme := source.Scheme()
+	d, ok := LookupDownloader(scheme)
+	if !ok {
+		return nil, fmt.Errorf("no downloader is registered for the scheme '%s'", scheme)
+	}
+	return d, nil
+}
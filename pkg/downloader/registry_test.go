@@ -0,0 +1,70 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/otiai10/copy"
+	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// fakeDownloader downloads the packages of the 'fake://' scheme from the local directory 'root',
+// the package 'fake://host/<name>?tag=<tag>' is in '<root>/<name>/<tag>'.
+type fakeDownloader struct {
+	root string
+}
+
+func (d *fakeDownloader) Download(ctx context.Context, opts *DownloadOptions) error {
+	return copy.Copy(filepath.Join(d.root, filepath.Base(opts.Source.Custom.CustomUrl), opts.Source.Custom.Tag), opts.LocalPath)
+}
+
+func (d *fakeDownloader) LatestVersion(ctx context.Context, opts *DownloadOptions) (string, error) {
+	return "0.2.0", nil
+}
+
+func TestRegisterDownloader(t *testing.T) {
+	root := t.TempDir()
+	pkgDir := filepath.Join(root, "helloworld", "0.1.0")
+	assert.NilError(t, os.MkdirAll(pkgDir, 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(pkgDir, "kcl.mod"), []byte("[package]\nname = \"helloworld\"\n"), 0644))
+
+	assert.ErrorContains(t, RegisterDownloader("git", &fakeDownloader{}), "the scheme 'git' is supported by kpm and cannot be registered")
+	assert.NilError(t, RegisterDownloader("fake", &fakeDownloader{root: root}))
+	defer UnregisterDownloader("fake")
+	assert.ErrorContains(t, RegisterDownloader("fake", &fakeDownloader{}), "the downloader of scheme 'fake' is already registered")
+
+	source, err := NewSourceFromStr("fake://example.com/helloworld?tag=0.1.0")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, source.Custom, &Custom{CustomUrl: "fake://example.com/helloworld", Tag: "0.1.0"})
+	assert.Equal(t, source.Type(), "fake")
+	assert.Assert(t, source.IsRemote())
+
+	sourceStr, err := source.ToString()
+	assert.NilError(t, err)
+	assert.Equal(t, sourceStr, "fake://example.com/helloworld?tag=0.1.0")
+
+	assert.Equal(t, source.MarshalTOML(), `{ fake = "fake://example.com/helloworld", tag = "0.1.0" }`)
+	var meta map[string]interface{}
+	_, err = toml.Decode(fmt.Sprintf("dep = %s", source.MarshalTOML()), &meta)
+	assert.NilError(t, err)
+	var got Source
+	assert.NilError(t, got.UnmarshalModTOML(meta["dep"]))
+	assert.DeepEqual(t, got.Custom, source.Custom)
+
+	latest, err := (&DepDownloader{}).LatestVersion(context.Background(), NewDownloadOptions(WithSource(Source{Custom: &Custom{CustomUrl: "fake://example.com/helloworld"}})))
+	assert.NilError(t, err)
+	assert.Equal(t, latest, "0.2.0")
+
+	localPath := filepath.Join(t.TempDir(), "helloworld")
+	assert.NilError(t, (&DepDownloader{}).Download(context.Background(), NewDownloadOptions(WithSource(*source), WithLocalPath(localPath))))
+	assert.Assert(t, utils.DirExists(filepath.Join(localPath, "kcl.mod")))
+
+	UnregisterDownloader("fake")
+	err = (&DepDownloader{}).Download(context.Background(), NewDownloadOptions(WithSource(*source), WithLocalPath(filepath.Join(t.TempDir(), "unregistered"))))
+	assert.ErrorContains(t, err, "no downloader is registered for the scheme 'fake'")
+}
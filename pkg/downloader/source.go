@@ -1,16 +1,21 @@
 package downloader
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/go-version"
 	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/features"
+	gitpkg "kcl-lang.io/kpm/pkg/git"
+	"kcl-lang.io/kpm/pkg/oci"
 	"kcl-lang.io/kpm/pkg/opt"
 	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/utils"
@@ -29,7 +34,7 @@ func (p *ModSpec) IsNil() bool {
 }
 
 // Source is the module source.
-// It can be from git, oci, local path.
+// It can be from git, oci, http(s) tarball, local path, or a custom scheme registered by 'RegisterDownloader'.
 // `ModSpec` is used to represent the module in the source.
 // If there are more than one module from the source, use `ModSpec` to specify the module.
 // If the `ModSpec` is nil, it means the source is one module.
@@ -37,11 +42,23 @@ type Source struct {
 	ModSpec *ModSpec `toml:"-"`
 	*Git
 	*Oci
+	*Http
+	*Custom
 	*Local `toml:"-"`
 }
 
 func (s *Source) SpecOnly() bool {
-	return !s.ModSpec.IsNil() && s.Git == nil && s.Oci == nil && s.Local == nil
+	return !s.ModSpec.IsNil() && s.Git == nil && s.Oci == nil && s.Http == nil && s.Custom == nil && s.Local == nil
+}
+
+// ResolveSpecOnly sets the OCI source of the source with only the package spec, e.g. 'helloworld:0.1.0',
+// the registry and repo are resolved by the named 'registries' in kcl.mod and the settings 'conf'.
+func (s *Source) ResolveSpecOnly(conf *settings.Settings, registries ...settings.OciRegistry) {
+	if !s.SpecOnly() {
+		return
+	}
+	s.Oci = &Oci{Tag: s.ModSpec.Version}
+	s.Oci.FillRepo(s.ModSpec.Name, conf, registries...)
 }
 
 type Local struct {
@@ -52,6 +69,21 @@ type Oci struct {
 	Reg  string `toml:"reg,omitempty"`
 	Repo string `toml:"repo,omitempty"`
 	Tag  string `toml:"oci_tag,omitempty"`
+	// Layout is true if 'Repo' is the path of a local OCI image layout directory instead of a repository in the registry 'Reg',
+	// e.g. 'oci+file:///srv/kcl-registry/k8s?tag=1.28'.
+	Layout bool `toml:"oci_layout,omitempty"`
+	// LayoutFullPath is the absolute path of the local OCI image layout,
+	// the relative 'Repo' in kcl.mod is resolved against the directory of kcl.mod.
+	LayoutFullPath string `toml:"-"`
+}
+
+// GetLayoutPath returns the path of the local OCI image layout,
+// the relative 'Repo' of the source parsed from a url is relative to the working directory.
+func (o *Oci) GetLayoutPath() string {
+	if len(o.LayoutFullPath) != 0 {
+		return o.LayoutFullPath
+	}
+	return o.Repo
 }
 
 // If the OCI source has no reference, return true.
@@ -64,6 +96,117 @@ func (o *Oci) GetRef() string {
 	return o.Tag
 }
 
+// RepoOption returns the option to set the repository of the OciClient, the registry or the local OCI image layout of the source.
+func (o *Oci) RepoOption() oci.OciClientOption {
+	if o.Layout {
+		return oci.WithLayoutPath(o.GetLayoutPath())
+	}
+	return oci.WithRepoPath(utils.JoinPath(o.Reg, o.Repo))
+}
+
+// FillRepo fills the missing registry and repo of the OCI source of the package 'name',
+// they are resolved by the named 'registries' in kcl.mod and the settings 'conf'.
+func (o *Oci) FillRepo(name string, conf *settings.Settings, registries ...settings.OciRegistry) {
+	// The local OCI image layout has no registry.
+	if o.Layout {
+		return
+	}
+	reg, repo := conf.ResolveOciRepo(name, registries...)
+	if len(o.Reg) == 0 {
+		o.Reg = reg
+	}
+	if len(o.Repo) == 0 {
+		o.Repo = repo
+	}
+}
+
+// HasRepo returns true if the repository of the OCI source is specified.
+func (o *Oci) HasRepo() bool {
+	return (len(o.Reg) != 0 || o.Layout) && len(o.Repo) != 0
+}
+
+// Http is the package source from a tarball on a http(s) server.
+type Http struct {
+	// HttpUrl is the url of the '.tar', '.tgz' or '.tar.gz' tarball of the package.
+	HttpUrl string `toml:"url,omitempty"`
+	// Sha256 is the hex encoded sha256 checksum of the tarball, the downloaded tarball is verified by it.
+	Sha256 string `toml:"sha256,omitempty"`
+}
+
+// IsHttpArchiveUrl returns true if the url is a http(s) url of a '.tar', '.tgz' or '.tar.gz' tarball.
+func IsHttpArchiveUrl(u *url.URL) bool {
+	if u == nil || (u.Scheme != constants.HttpScheme && u.Scheme != constants.HttpsScheme) {
+		return false
+	}
+	return httpArchiveSuffix(u.Path) != ""
+}
+
+// httpArchiveSuffix returns the tarball suffix of the path, or an empty string if the path is not a tarball.
+func httpArchiveSuffix(path string) string {
+	for _, suffix := range []string{constants.TarGzPathSuffix, constants.TgzPathSuffix, constants.TarPathSuffix} {
+		if strings.HasSuffix(path, suffix) {
+			return suffix
+		}
+	}
+	return ""
+}
+
+// Validate checks the url and the sha256 checksum of the http source.
+func (h *Http) Validate() error {
+	if h == nil {
+		return fmt.Errorf("http source is nil")
+	}
+	u, err := url.Parse(h.HttpUrl)
+	if err != nil {
+		return err
+	}
+	if !IsHttpArchiveUrl(u) {
+		return fmt.Errorf("invalid http source '%s', only the '.tar', '.tgz' and '.tar.gz' tarballs from http(s) are supported", h.HttpUrl)
+	}
+	if h.Sha256 == "" {
+		return fmt.Errorf("the 'sha256' of the http source '%s' is required", h.HttpUrl)
+	}
+	if sum, err := hex.DecodeString(h.Sha256); err != nil || len(sum) != sha256.Size {
+		return fmt.Errorf("invalid sha256 '%s' of the http source '%s'", h.Sha256, h.HttpUrl)
+	}
+	return nil
+}
+
+// FileName returns the file name of the tarball.
+func (h *Http) FileName() string {
+	u, err := url.Parse(h.HttpUrl)
+	if err != nil {
+		return path.Base(h.HttpUrl)
+	}
+	return path.Base(u.Path)
+}
+
+// Custom is the package source from a custom scheme, it is downloaded by the Downloader registered for the scheme.
+type Custom struct {
+	// CustomUrl is the url of the package, e.g. 's3://bucket/helloworld'.
+	CustomUrl string `toml:"custom_url,omitempty"`
+	// Tag is the version of the package, it is the latest version if it is empty.
+	Tag string `toml:"custom_tag,omitempty"`
+}
+
+// Scheme returns the scheme of the custom source.
+func (c *Custom) Scheme() string {
+	u, err := url.Parse(c.CustomUrl)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme
+}
+
+// If the custom source has no reference, return true.
+func (c *Custom) NoRef() bool {
+	return c.Tag == ""
+}
+
+func (c *Custom) GetRef() string {
+	return c.Tag
+}
+
 // Git is the package source from git registry.
 type Git struct {
 	Url     string `toml:"url,omitempty"`
@@ -72,11 +215,19 @@ type Git struct {
 	Tag     string `toml:"git_tag,omitempty"`
 	Version string `toml:"version,omitempty"`
 	Package string `toml:"package,omitempty"`
+	// Subdir is the directory of the package relative to the repository root,
+	// it is resolved from 'Package' and recorded in the kcl.mod.lock to check out the package directly.
+	Subdir string `toml:"subdir,omitempty"`
+	// Submodules enables the clone of the submodules of the repository.
+	Submodules bool `toml:"submodules,omitempty"`
+	// Lfs enables the download of the Git LFS objects of the repository, 'git-lfs' is required.
+	Lfs bool `toml:"lfs,omitempty"`
 }
 
 // Transform the git url to the canonicalized url.
+// The 'git://' url is transformed into 'https://', and the scp-like url 'user@host:path' into 'ssh://user@host/path'.
 func (git *Git) GetCanonicalizedUrl() (string, error) {
-	url, err := url.Parse(git.Url)
+	url, err := url.Parse(gitpkg.NormalizeScpLikeUrl(git.Url))
 	if err != nil {
 		return "", err
 	}
@@ -117,7 +268,7 @@ func NewSourceFromStr(sourceStr string) (*Source, error) {
 }
 
 func (source *Source) IsNilSource() bool {
-	return source == nil || (source.Git == nil && source.Oci == nil && source.Local == nil && source.ModSpec.IsNil())
+	return source == nil || (source.Git == nil && source.Oci == nil && source.Http == nil && source.Custom == nil && source.Local == nil && source.ModSpec.IsNil())
 }
 
 func (source *Source) IsLocalPath() bool {
@@ -133,11 +284,11 @@ func (source *Source) IsLocalTgzPath() bool {
 }
 
 func (source *Source) IsRemote() bool {
-	return source.Local == nil && (source.Git != nil || source.Oci != nil || !source.ModSpec.IsNil())
+	return source.Local == nil && (source.Git != nil || source.Oci != nil || source.Http != nil || source.Custom != nil || !source.ModSpec.IsNil())
 }
 
 func (source *Source) IsPackaged() bool {
-	return source.IsLocalTarPath() || source.Git != nil || source.Oci != nil || !source.ModSpec.IsNil()
+	return source.IsLocalTarPath() || source.Git != nil || source.Oci != nil || source.Http != nil || source.Custom != nil || !source.ModSpec.IsNil()
 }
 
 // If the source is a local path, check if it is a real local package(a directory with kcl.mod file).
@@ -158,6 +309,12 @@ func (source *Source) FindRootPath() (string, error) {
 	if source.Oci != nil {
 		return source.Oci.ToFilePath()
 	}
+	if source.Http != nil {
+		return source.Http.ToFilePath()
+	}
+	if source.Custom != nil {
+		return source.Custom.ToFilePath()
+	}
 	if source.Local != nil {
 		return source.Local.FindRootPath()
 	}
@@ -259,6 +416,18 @@ func (source *Source) ToFilePath() (string, error) {
 			return "", err
 		}
 	}
+	if source.Http != nil {
+		path, err = source.Http.ToFilePath()
+		if err != nil {
+			return "", err
+		}
+	}
+	if source.Custom != nil {
+		path, err = source.Custom.ToFilePath()
+		if err != nil {
+			return "", err
+		}
+	}
 	if source.Local != nil {
 		path, err = source.Local.ToFilePath()
 		if err != nil {
@@ -281,7 +450,7 @@ func (git *Git) ToFilePath() (string, error) {
 		return "", fmt.Errorf("git source is nil")
 	}
 
-	gitUrl, err := url.Parse(git.Url)
+	gitUrl, err := url.Parse(gitpkg.NormalizeScpLikeUrl(git.Url))
 	if err != nil {
 		return "", err
 	}
@@ -303,6 +472,50 @@ func (git *Git) GetPackage() string {
 	return git.Package
 }
 
+// FindPackage finds the package 'git.Package' in the git repository cloned in 'root',
+// and records the directory of the package relative to 'root' in 'git.Subdir'.
+// If the repository is a sparse checkout without the package, the directory of the package is added into the sparse checkout,
+// and the missing files are fetched from the remote with the credential 'cred'.
+func (git *Git) FindPackage(root string, cred *gitpkg.Credential) (string, error) {
+	if git.Subdir != "" {
+		pkgPath := filepath.Join(root, filepath.FromSlash(git.Subdir))
+		if !utils.DirExists(filepath.Join(pkgPath, constants.KCL_MOD)) && gitpkg.IsSparseCheckout(root) {
+			if err := gitpkg.AddSparsePath(root, git.Subdir, cred); err != nil {
+				return "", err
+			}
+		}
+		if utils.MatchesPackageName(filepath.Join(pkgPath, constants.KCL_MOD), git.Package) {
+			return pkgPath, nil
+		}
+		// 'root' is the directory of the package found before, the directory recorded is kept.
+		if utils.MatchesPackageName(filepath.Join(root, constants.KCL_MOD), git.Package) {
+			return root, nil
+		}
+	}
+
+	pkgPath, err := utils.FindPackage(root, git.Package)
+	if err != nil && gitpkg.IsSparseCheckout(root) {
+		subdir, findErr := gitpkg.FindPackagePath(root, "HEAD", git.Package, cred)
+		if findErr != nil {
+			return "", findErr
+		}
+		if err := gitpkg.AddSparsePath(root, subdir, cred); err != nil {
+			return "", err
+		}
+		pkgPath, err = filepath.Join(root, filepath.FromSlash(subdir)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	subdir, err := filepath.Rel(root, pkgPath)
+	if err != nil {
+		return "", err
+	}
+	git.Subdir = filepath.ToSlash(subdir)
+	return pkgPath, nil
+}
+
 func (oci *Oci) ToFilePath() (string, error) {
 	if oci == nil {
 		return "", fmt.Errorf("oci source is nil")
@@ -316,6 +529,32 @@ func (oci *Oci) ToFilePath() (string, error) {
 	return filepath.Join(constants.OciScheme, ociUrl.Host, ociUrl.Path, oci.Tag), nil
 }
 
+func (h *Http) ToFilePath() (string, error) {
+	if h == nil {
+		return "", fmt.Errorf("http source is nil")
+	}
+
+	httpUrl, err := url.Parse(h.HttpUrl)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(constants.HttpScheme, httpUrl.Host, httpUrl.Path, h.Sha256), nil
+}
+
+func (c *Custom) ToFilePath() (string, error) {
+	if c == nil {
+		return "", fmt.Errorf("custom source is nil")
+	}
+
+	customUrl, err := url.Parse(c.CustomUrl)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(customUrl.Scheme, customUrl.Host, customUrl.Path, c.Tag), nil
+}
+
 func (local *Local) ToFilePath() (string, error) {
 	if local == nil {
 		return "", fmt.Errorf("local source is nil")
@@ -340,6 +579,16 @@ func (source *Source) ToString() (string, error) {
 		if err != nil {
 			return "", err
 		}
+	} else if source.Http != nil {
+		sourceStr, err = source.Http.ToString()
+		if err != nil {
+			return "", err
+		}
+	} else if source.Custom != nil {
+		sourceStr, err = source.Custom.ToString()
+		if err != nil {
+			return "", err
+		}
 	} else if source.Local != nil {
 		sourceStr, err = source.Local.ToString()
 		if err != nil {
@@ -355,7 +604,7 @@ func (git *Git) ToString() (string, error) {
 		return "", fmt.Errorf("git source is nil")
 	}
 
-	gitUrl, err := url.Parse(git.Url)
+	gitUrl, err := url.Parse(gitpkg.NormalizeScpLikeUrl(git.Url))
 	if err != nil {
 		return "", err
 	}
@@ -383,11 +632,7 @@ func (oci *Oci) ToString() (string, error) {
 		return "", fmt.Errorf("oci source is nil")
 	}
 
-	ociUrl := &url.URL{
-		Scheme: constants.OciScheme,
-		Host:   oci.Reg,
-		Path:   oci.Repo,
-	}
+	ociUrl := oci.url()
 	q := ociUrl.Query()
 	if oci.Tag != "" {
 		q.Set(constants.Tag, oci.Tag)
@@ -397,6 +642,42 @@ func (oci *Oci) ToString() (string, error) {
 	return ociUrl.String(), nil
 }
 
+func (h *Http) ToString() (string, error) {
+	if h == nil {
+		return "", fmt.Errorf("http source is nil")
+	}
+
+	httpUrl, err := url.Parse(h.HttpUrl)
+	if err != nil {
+		return "", err
+	}
+	q := httpUrl.Query()
+	if h.Sha256 != "" {
+		q.Set(constants.Sha256, h.Sha256)
+	}
+	httpUrl.RawQuery = q.Encode()
+
+	return httpUrl.String(), nil
+}
+
+func (c *Custom) ToString() (string, error) {
+	if c == nil {
+		return "", fmt.Errorf("custom source is nil")
+	}
+
+	customUrl, err := url.Parse(c.CustomUrl)
+	if err != nil {
+		return "", err
+	}
+	q := customUrl.Query()
+	if c.Tag != "" {
+		q.Set(constants.Tag, c.Tag)
+	}
+	customUrl.RawQuery = q.Encode()
+
+	return customUrl.String(), nil
+}
+
 func (local *Local) ToString() (string, error) {
 	if local == nil {
 		return "", fmt.Errorf("local source is nil")
@@ -442,9 +723,17 @@ func (source *Source) FromString(sourceStr string) error {
 	if sourceUrl.Scheme == constants.GitScheme || sourceUrl.Scheme == constants.SshScheme {
 		source.Git = &Git{}
 		source.Git.FromString(sourceUrl.String())
-	} else if sourceUrl.Scheme == constants.OciScheme {
+	} else if sourceUrl.Scheme == constants.OciScheme || sourceUrl.Scheme == constants.OciFileScheme {
 		source.Oci = &Oci{}
-		source.Oci.FromString(sourceUrl.String())
+		if err := source.Oci.FromString(sourceUrl.String()); err != nil {
+			return err
+		}
+	} else if IsHttpArchiveUrl(sourceUrl) {
+		source.Http = &Http{}
+		source.Http.FromString(sourceUrl.String())
+	} else if _, ok := LookupDownloader(sourceUrl.Scheme); ok {
+		source.Custom = &Custom{}
+		source.Custom.FromString(sourceUrl.String())
 	} else if sourceUrl.Scheme == constants.DefaultOciScheme {
 		source.ModSpec = &ModSpec{}
 		source.ModSpec.FromString(sourceUrl.String())
@@ -495,6 +784,9 @@ func (oci *Oci) FromString(ociStr string) error {
 		return err
 	}
 
+	if u.Scheme == constants.OciFileScheme {
+		return oci.fromLayoutUrl(u)
+	}
 	if u.Scheme != constants.OciScheme {
 		return fmt.Errorf("invalid oci url with schema: %s", u.Scheme)
 	}
@@ -506,6 +798,84 @@ func (oci *Oci) FromString(ociStr string) error {
 	return nil
 }
 
+// fromLayoutUrl parses the url of the local OCI image layout, the tag is specified by the 'tag' query or after the last ':' of the path,
+// e.g. 'oci+file:///srv/kcl-registry/k8s?tag=1.28' or 'oci+file:///srv/kcl-registry/k8s:1.28'.
+func (oci *Oci) fromLayoutUrl(u *url.URL) error {
+	layoutPath := u.Path
+	if len(u.Opaque) != 0 {
+		// The relative path, e.g. 'oci+file:k8s:1.28'.
+		layoutPath = u.Opaque
+	}
+	if len(u.Host) != 0 {
+		return fmt.Errorf("invalid oci layout url '%s', the path should be absolute, e.g. 'oci+file:///path/to/layout'", u.String())
+	}
+
+	tag := u.Query().Get(constants.Tag)
+	if len(tag) == 0 {
+		dir, name := path.Split(layoutPath)
+		if i := strings.LastIndex(name, ":"); i > 0 {
+			layoutPath = dir + name[:i]
+			tag = name[i+1:]
+		}
+	}
+	if len(layoutPath) == 0 {
+		return fmt.Errorf("invalid oci layout url '%s', the path of the layout is required", u.String())
+	}
+
+	oci.Reg = ""
+	oci.Repo = filepath.FromSlash(layoutPath)
+	oci.Tag = tag
+	oci.Layout = true
+
+	return nil
+}
+
+func (h *Http) FromString(httpStr string) error {
+	if h == nil {
+		return fmt.Errorf("http source is nil")
+	}
+
+	u, err := url.Parse(httpStr)
+	if err != nil {
+		return err
+	}
+
+	if !IsHttpArchiveUrl(u) {
+		return fmt.Errorf("invalid http url of tarball: %s", httpStr)
+	}
+
+	q := u.Query()
+	h.Sha256 = q.Get(constants.Sha256)
+	q.Del(constants.Sha256)
+	u.RawQuery = q.Encode()
+	h.HttpUrl = u.String()
+
+	return nil
+}
+
+func (c *Custom) FromString(customStr string) error {
+	if c == nil {
+		return fmt.Errorf("custom source is nil")
+	}
+
+	u, err := url.Parse(customStr)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := LookupDownloader(u.Scheme); !ok {
+		return fmt.Errorf("no downloader is registered for the scheme '%s'", u.Scheme)
+	}
+
+	q := u.Query()
+	c.Tag = q.Get(constants.Tag)
+	q.Del(constants.Tag)
+	u.RawQuery = q.Encode()
+	c.CustomUrl = u.String()
+
+	return nil
+}
+
 func (local *Local) FromString(localStr string) error {
 	if local == nil {
 		return fmt.Errorf("local source is nil")
@@ -575,18 +945,54 @@ func (git *Git) GetValidGitReference() (string, error) {
 // Deprecated: Use ToString instead
 func (oci *Oci) IntoOciUrl() string {
 	if oci != nil {
-		u := &url.URL{
-			Scheme: constants.OciScheme,
-			Host:   oci.Reg,
-			Path:   oci.Repo,
-		}
-
-		return u.String()
+		return oci.url().String()
 	}
 	return ""
 }
 
+// url returns the url of the OCI source without the tag,
+// e.g. 'oci://ghcr.io/kcl-lang/k8s' or 'oci+file:///srv/kcl-registry/k8s' for the local OCI image layout.
+func (oci *Oci) url() *url.URL {
+	if oci.Layout {
+		if !filepath.IsAbs(oci.Repo) && !path.IsAbs(filepath.ToSlash(oci.Repo)) {
+			// The relative path is kept opaque, e.g. 'oci+file:k8s'.
+			return &url.URL{
+				Scheme: constants.OciFileScheme,
+				Opaque: filepath.ToSlash(oci.Repo),
+			}
+		}
+		return &url.URL{
+			Scheme: constants.OciFileScheme,
+			Path:   filepath.ToSlash(oci.Repo),
+		}
+	}
+	return &url.URL{
+		Scheme: constants.OciScheme,
+		Host:   oci.Reg,
+		Path:   oci.Repo,
+	}
+}
+
 func ParseSourceUrlFrom(sourceStr string, settings *settings.Settings) (*url.URL, error) {
+	// The url of the custom scheme is passed to the registered downloader as it is.
+	if sourceUrl, err := url.Parse(sourceStr); err == nil {
+		if _, ok := LookupDownloader(sourceUrl.Scheme); ok {
+			return sourceUrl, nil
+		}
+		// The url of the local OCI image layout is normalized into the 'tag' query.
+		if sourceUrl.Scheme == constants.OciFileScheme {
+			ociSource := &Oci{}
+			if err := ociSource.FromString(sourceStr); err != nil {
+				return nil, err
+			}
+			ociUrl, err := ociSource.ToString()
+			if err != nil {
+				return nil, err
+			}
+			return url.Parse(ociUrl)
+		}
+	}
+
 	regOpts, err := opt.NewRegistryOptionsFrom(sourceStr, settings)
 	if err != nil {
 		return nil, err
@@ -638,6 +1044,12 @@ func (s *Source) Hash() (string, error) {
 	if s.Oci != nil {
 		return s.Oci.Hash()
 	}
+	if s.Http != nil {
+		return s.Http.Hash()
+	}
+	if s.Custom != nil {
+		return s.Custom.Hash()
+	}
 	if s.Local != nil {
 		return s.Local.Hash()
 	}
@@ -651,11 +1063,28 @@ func (g *Git) Hash() (string, error) {
 		return "", err
 	}
 
-	return filepath.Join(hash, filepath.Base(gitURL), g.GetRef()), nil
+	return filepath.Join(hash, filepath.Base(gitURL), g.cacheRef(g.GetRef())), nil
+}
+
+// cacheRef returns the 'ref' in the cache paths of the source,
+// the working copies with the submodules or the Git LFS objects are cached apart from the ones without them.
+func (g *Git) cacheRef(ref string) string {
+	if g.Submodules {
+		ref += "_submodules"
+	}
+	if g.Lfs {
+		ref += "_lfs"
+	}
+	return ref
 }
 
 func (o *Oci) Hash() (string, error) {
-	hash, err := utils.ShortHash(utils.JoinPath(o.Reg, filepath.Dir(o.Repo)))
+	repo := o.Repo
+	if o.Layout {
+		// The layouts with the same relative path in different packages are different.
+		repo = o.GetLayoutPath()
+	}
+	hash, err := utils.ShortHash(utils.JoinPath(o.Reg, filepath.Dir(repo)))
 	if err != nil {
 		return "", err
 	}
@@ -663,6 +1092,39 @@ func (o *Oci) Hash() (string, error) {
 	return filepath.Join(hash, filepath.Base(o.Repo), o.GetRef()), nil
 }
 
+func (h *Http) Hash() (string, error) {
+	u, err := url.Parse(h.HttpUrl)
+	if err != nil {
+		return "", err
+	}
+	hash, err := utils.ShortHash(utils.JoinPath(u.Host, path.Dir(u.Path)))
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(path.Base(u.Path), httpArchiveSuffix(u.Path))
+
+	return filepath.Join(hash, name, h.Sha256), nil
+}
+
+func (c *Custom) Hash() (string, error) {
+	if d, ok := LookupDownloader(c.Scheme()); ok {
+		if hasher, ok := d.(CustomSourceHasher); ok {
+			return hasher.Hash(c)
+		}
+	}
+
+	u, err := url.Parse(c.CustomUrl)
+	if err != nil {
+		return "", err
+	}
+	hash, err := utils.ShortHash(utils.JoinPath(u.Host, path.Dir(u.Path)))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(hash, path.Base(u.Path), c.Tag), nil
+}
+
 func (l *Local) Hash() (string, error) {
 	return utils.ShortHash(l.Path)
 }
@@ -680,15 +1142,22 @@ func (s *Source) LocalPath(root string) string {
 
 		if s.Git != nil && len(s.Git.Tag) != 0 {
 			gitUrl := strings.TrimSuffix(s.Git.Url, filepath.Ext(s.Git.Url))
-			path = fmt.Sprintf("%s_%s", filepath.Base(gitUrl), s.Git.Tag)
+			path = fmt.Sprintf("%s_%s", filepath.Base(gitUrl), s.Git.cacheRef(s.Git.Tag))
 		}
 		if s.Git != nil && len(s.Git.Branch) != 0 {
 			gitUrl := strings.TrimSuffix(s.Git.Url, filepath.Ext(s.Git.Url))
-			path = fmt.Sprintf("%s_%s", filepath.Base(gitUrl), s.Git.Branch)
+			path = fmt.Sprintf("%s_%s", filepath.Base(gitUrl), s.Git.cacheRef(s.Git.Branch))
 		}
 		if s.Git != nil && len(s.Git.Commit) != 0 {
 			gitUrl := strings.TrimSuffix(s.Git.Url, filepath.Ext(s.Git.Url))
-			path = fmt.Sprintf("%s_%s", filepath.Base(gitUrl), s.Git.Commit)
+			path = fmt.Sprintf("%s_%s", filepath.Base(gitUrl), s.Git.cacheRef(s.Git.Commit))
+		}
+		if s.Http != nil && len(s.Http.Sha256) != 0 {
+			name := strings.TrimSuffix(s.Http.FileName(), httpArchiveSuffix(s.Http.FileName()))
+			path = fmt.Sprintf("%s_%s", name, s.Http.Sha256[:min(len(s.Http.Sha256), 12)])
+		}
+		if s.Custom != nil && len(s.Custom.Tag) != 0 {
+			path = fmt.Sprintf("%s_%s", filepath.Base(s.Custom.CustomUrl), s.Custom.Tag)
 		}
 	} else {
 		path, err = s.Hash()
@@ -721,6 +1190,12 @@ func (s *Source) Type() string {
 	if s.Oci != nil {
 		return "oci"
 	}
+	if s.Http != nil {
+		return "http"
+	}
+	if s.Custom != nil {
+		return s.Custom.Scheme()
+	}
 	if s.Local != nil {
 		return "local"
 	}
@@ -1,9 +1,16 @@
 package downloader
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/BurntSushi/toml"
 	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/settings"
 )
 
 func TestParseModSpecFromStr(t *testing.T) {
@@ -27,3 +34,176 @@ func TestParseModSpecFromStr(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCanonicalizedUrl(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"git://github.com/kcl-lang/kcl.git", "https://github.com/kcl-lang/kcl.git"},
+		{"https://github.com/kcl-lang/kcl.git", "https://github.com/kcl-lang/kcl.git"},
+		{"ssh://git@github.com/kcl-lang/kcl.git", "ssh://git@github.com/kcl-lang/kcl.git"},
+		{"git@github.com:kcl-lang/kcl.git", "ssh://git@github.com/kcl-lang/kcl.git"},
+	}
+
+	for _, tt := range tests {
+		git := &Git{Url: tt.input}
+		url, err := git.GetCanonicalizedUrl()
+		assert.Equal(t, err, nil)
+		assert.Equal(t, url, tt.expected)
+	}
+}
+
+func TestGitFindPackage(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		pkgPath := filepath.Join(root, "pkgs", name)
+		assert.Equal(t, os.MkdirAll(pkgPath, 0755), nil)
+		assert.Equal(t, os.WriteFile(filepath.Join(pkgPath, "kcl.mod"), []byte(fmt.Sprintf("[package]\nname = \"%s\"\n", name)), 0644), nil)
+	}
+
+	git := &Git{Url: "https://github.com/kcl-lang/modules.git", Package: "b"}
+	pkgPath, err := git.FindPackage(root, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pkgPath, filepath.Join(root, "pkgs", "b"))
+	assert.Equal(t, git.Subdir, "pkgs/b")
+
+	// The subdirectory is recorded in the kcl.mod.lock.
+	var buf bytes.Buffer
+	assert.Equal(t, toml.NewEncoder(&buf).Encode(git), nil)
+	assert.Assert(t, strings.Contains(buf.String(), `subdir = "pkgs/b"`))
+
+	// The recorded subdirectory is checked out directly.
+	git = &Git{Url: "https://github.com/kcl-lang/modules.git", Package: "a", Subdir: "pkgs/a"}
+	pkgPath, err = git.FindPackage(root, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pkgPath, filepath.Join(root, "pkgs", "a"))
+
+	// The package is searched again if the recorded subdirectory is stale.
+	git = &Git{Url: "https://github.com/kcl-lang/modules.git", Package: "a", Subdir: "pkgs/b"}
+	pkgPath, err = git.FindPackage(root, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pkgPath, filepath.Join(root, "pkgs", "a"))
+	assert.Equal(t, git.Subdir, "pkgs/a")
+
+	// The directory of the package found before keeps the recorded subdirectory.
+	pkgPath, err = git.FindPackage(pkgPath, nil)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, pkgPath, filepath.Join(root, "pkgs", "a"))
+	assert.Equal(t, git.Subdir, "pkgs/a")
+}
+
+func TestGitSubmodulesAndLfsTOML(t *testing.T) {
+	git := Git{Url: "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", Tag: "v0.1.0", Submodules: true, Lfs: true}
+	assert.Equal(t, git.MarshalTOML(), `git = "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", tag = "v0.1.0", submodules = true, lfs = true`)
+
+	var meta map[string]interface{}
+	_, err := toml.Decode(fmt.Sprintf("dep = { %s }", git.MarshalTOML()), &meta)
+	assert.NilError(t, err)
+	var got Git
+	assert.NilError(t, got.UnmarshalModTOML(meta["dep"]))
+	assert.DeepEqual(t, got, git)
+}
+
+func TestGitSubmodulesAndLfsCachePath(t *testing.T) {
+	git := Git{Url: "https://github.com/kcl-lang/flask-demo-kcl-manifests.git", Tag: "v0.1.0"}
+	plain := (&Source{Git: &git}).LocalPath("cache")
+
+	withSubmodules := git
+	withSubmodules.Submodules = true
+	withLfs := git
+	withLfs.Lfs = true
+
+	// The working copies with the submodules or the Git LFS objects are not shared with the plain one.
+	assert.Assert(t, (&Source{Git: &withSubmodules}).LocalPath("cache") != plain)
+	assert.Assert(t, (&Source{Git: &withLfs}).LocalPath("cache") != plain)
+	assert.Assert(t, (&Source{Git: &withSubmodules}).LocalPath("cache") != (&Source{Git: &withLfs}).LocalPath("cache"))
+}
+
+func TestHttpSourceTOML(t *testing.T) {
+	httpSource := Http{HttpUrl: "https://example.com/kcl/helloworld-0.1.0.tgz", Sha256: strings.Repeat("a", 64)}
+	source := Source{Http: &httpSource}
+	assert.Equal(t, source.MarshalTOML(), fmt.Sprintf(`{ url = "https://example.com/kcl/helloworld-0.1.0.tgz", sha256 = "%s" }`, httpSource.Sha256))
+
+	var meta map[string]interface{}
+	_, err := toml.Decode(fmt.Sprintf("dep = %s", source.MarshalTOML()), &meta)
+	assert.NilError(t, err)
+	var got Source
+	assert.NilError(t, got.UnmarshalModTOML(meta["dep"]))
+	assert.DeepEqual(t, got.Http, &httpSource)
+	assert.Equal(t, got.Type(), "http")
+
+	sourceStr, err := got.ToString()
+	assert.NilError(t, err)
+	fromStr, err := NewSourceFromStr(sourceStr)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, fromStr.Http, &httpSource)
+
+	_, err = toml.Decode(`dep = { url = "https://example.com/kcl/helloworld-0.1.0.tgz" }`, &meta)
+	assert.NilError(t, err)
+	assert.ErrorContains(t, (&Source{}).UnmarshalModTOML(meta["dep"]), "the 'sha256' of the http source 'https://example.com/kcl/helloworld-0.1.0.tgz' is required")
+}
+
+func TestOciLayoutSourceFromString(t *testing.T) {
+	tests := []struct {
+		input    string
+		repo     string
+		tag      string
+		expected string
+	}{
+		{"oci+file:///srv/kcl-registry/k8s:1.28", "/srv/kcl-registry/k8s", "1.28", "oci+file:///srv/kcl-registry/k8s?tag=1.28"},
+		{"oci+file:///srv/kcl-registry/k8s?tag=1.28", "/srv/kcl-registry/k8s", "1.28", "oci+file:///srv/kcl-registry/k8s?tag=1.28"},
+		{"oci+file:///srv/kcl-registry/k8s", "/srv/kcl-registry/k8s", "", "oci+file:///srv/kcl-registry/k8s"},
+		{"oci+file:layouts/k8s:1.28", "layouts/k8s", "1.28", "oci+file:layouts/k8s?tag=1.28"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			source, err := NewSourceFromStr(tt.input)
+			assert.NilError(t, err)
+			assert.Assert(t, source.Oci != nil)
+			assert.Equal(t, source.Oci.Layout, true)
+			assert.Equal(t, source.Oci.Reg, "")
+			assert.Equal(t, source.Oci.Repo, filepath.FromSlash(tt.repo))
+			assert.Equal(t, source.Oci.Tag, tt.tag)
+
+			sourceStr, err := source.ToString()
+			assert.NilError(t, err)
+			assert.Equal(t, sourceStr, tt.expected)
+
+			parsed, err := ParseSourceUrlFrom(tt.input, nil)
+			assert.NilError(t, err)
+			assert.Equal(t, parsed.String(), tt.expected)
+		})
+	}
+
+	_, err := NewSourceFromStr("oci+file://localhost/srv/kcl-registry/k8s")
+	assert.ErrorContains(t, err, "the path should be absolute")
+}
+
+func TestResolveSpecOnly(t *testing.T) {
+	conf := &settings.Settings{
+		Conf: settings.KpmConf{
+			DefaultOciRegistry: "ghcr.io",
+			DefaultOciRepo:     "kcl-lang",
+		},
+	}
+	acme := settings.OciRegistry{Name: "acme", Registry: "registry.acme.com", Repo: "kcl", Scopes: []string{"acme-*"}}
+
+	source := &Source{ModSpec: &ModSpec{Name: "acme-base", Version: "0.1.0"}}
+	source.ResolveSpecOnly(conf, acme)
+	assert.DeepEqual(t, source.Oci, &Oci{Reg: "registry.acme.com", Repo: "kcl/acme-base", Tag: "0.1.0"})
+
+	source = &Source{ModSpec: &ModSpec{Name: "helloworld", Version: "0.1.4"}}
+	source.ResolveSpecOnly(conf, acme)
+	assert.DeepEqual(t, source.Oci, &Oci{Reg: "ghcr.io", Repo: "kcl-lang/helloworld", Tag: "0.1.4"})
+
+	// The registry and repo already set are kept.
+	ociSource := &Oci{Reg: "localhost:5001", Tag: "0.1.0"}
+	ociSource.FillRepo("acme-base", conf, acme)
+	assert.DeepEqual(t, ociSource, &Oci{Reg: "localhost:5001", Repo: "kcl/acme-base", Tag: "0.1.0"})
+
+	layout := &Oci{Repo: "layouts/acme-base", Layout: true}
+	layout.FillRepo("acme-base", conf, acme)
+	assert.DeepEqual(t, layout, &Oci{Repo: "layouts/acme-base", Layout: true})
+}
@@ -53,12 +53,26 @@ func (source *Source) MarshalTOML() string {
 		if source.Oci != nil {
 			tomlStr = source.Oci.MarshalTOML()
 			if len(tomlStr) != 0 {
-				if len(source.Oci.Reg) != 0 && len(source.Oci.Repo) != 0 {
+				if source.Oci.HasRepo() {
 					tomlStr = fmt.Sprintf(SOURCE_PATTERN, tomlStr+pkgSpec)
 				}
 			}
 		}
 
+		if source.Http != nil {
+			tomlStr = source.Http.MarshalTOML()
+			if len(tomlStr) != 0 {
+				tomlStr = fmt.Sprintf(SOURCE_PATTERN, tomlStr+pkgSpec)
+			}
+		}
+
+		if source.Custom != nil {
+			tomlStr = source.Custom.MarshalTOML()
+			if len(tomlStr) != 0 {
+				tomlStr = fmt.Sprintf(SOURCE_PATTERN, tomlStr+pkgSpec)
+			}
+		}
+
 		if source.Local != nil {
 			tomlStr = source.Local.MarshalTOML()
 			if len(tomlStr) != 0 {
@@ -78,6 +92,8 @@ const GIT_COMMIT_PATTERN = "commit = \"%s\""
 const GIT_BRANCH_PATTERN = "branch = \"%s\""
 const VERSION_PATTERN = "version = \"%s\""
 const GIT_PACKAGE = "package = \"%s\""
+const GIT_SUBMODULES = "submodules = true"
+const GIT_LFS = "lfs = true"
 const SEPARATOR = ", "
 
 func (git *Git) MarshalTOML() string {
@@ -109,6 +125,16 @@ func (git *Git) MarshalTOML() string {
 		sb.WriteString(fmt.Sprintf(GIT_PACKAGE, git.Package))
 	}
 
+	if git.Submodules {
+		sb.WriteString(SEPARATOR)
+		sb.WriteString(GIT_SUBMODULES)
+	}
+
+	if git.Lfs {
+		sb.WriteString(SEPARATOR)
+		sb.WriteString(GIT_LFS)
+	}
+
 	return sb.String()
 }
 
@@ -116,19 +142,54 @@ const OCI_URL_PATTERN = "oci = \"%s\""
 
 func (oci *Oci) MarshalTOML() string {
 	var sb strings.Builder
-	if len(oci.Reg) != 0 && len(oci.Repo) != 0 {
+	if oci.HasRepo() {
 		sb.WriteString(fmt.Sprintf(OCI_URL_PATTERN, oci.IntoOciUrl()))
 		if len(oci.Tag) != 0 {
 			sb.WriteString(SEPARATOR)
 			sb.WriteString(fmt.Sprintf(TAG_PATTERN, oci.Tag))
 		}
-	} else if len(oci.Reg) == 0 && len(oci.Repo) == 0 && len(oci.Tag) != 0 {
+	} else if len(oci.Reg) == 0 && len(oci.Repo) == 0 && !oci.Layout && len(oci.Tag) != 0 {
 		sb.WriteString(fmt.Sprintf(`"%s"`, oci.Tag))
 	}
 
 	return sb.String()
 }
 
+const HTTP_URL_PATTERN = "url = \"%s\""
+const HTTP_SHA256_PATTERN = "sha256 = \"%s\""
+
+func (h *Http) MarshalTOML() string {
+	var sb strings.Builder
+	if len(h.HttpUrl) != 0 {
+		sb.WriteString(fmt.Sprintf(HTTP_URL_PATTERN, h.HttpUrl))
+		if len(h.Sha256) != 0 {
+			sb.WriteString(SEPARATOR)
+			sb.WriteString(fmt.Sprintf(HTTP_SHA256_PATTERN, h.Sha256))
+		}
+	}
+	return sb.String()
+}
+
+// MarshalTOML marshals the custom source as `<scheme> = "<url>", tag = "<tag>"`,
+// unless the registered downloader of the scheme implements 'CustomTOMLMarshaler'.
+func (c *Custom) MarshalTOML() string {
+	if d, ok := LookupDownloader(c.Scheme()); ok {
+		if marshaler, ok := d.(CustomTOMLMarshaler); ok {
+			return marshaler.MarshalTOML(c)
+		}
+	}
+
+	var sb strings.Builder
+	if len(c.CustomUrl) != 0 {
+		sb.WriteString(fmt.Sprintf("%s = %q", c.Scheme(), c.CustomUrl))
+		if len(c.Tag) != 0 {
+			sb.WriteString(SEPARATOR)
+			sb.WriteString(fmt.Sprintf(TAG_PATTERN, c.Tag))
+		}
+	}
+	return sb.String()
+}
+
 const LOCAL_PATH_PATTERN = "path = %s"
 
 func (local *Local) MarshalTOML() string {
@@ -163,6 +224,25 @@ func (source *Source) UnmarshalModTOML(data interface{}) error {
 				return err
 			}
 			source.Oci = &oci
+		} else if _, ok := meta[HTTP_URL_FLAG]; ok {
+			http := Http{}
+			err := http.UnmarshalModTOML(data)
+			if err != nil {
+				return err
+			}
+			source.Http = &http
+		} else {
+			for _, scheme := range registeredSchemes() {
+				if _, ok := meta[scheme]; !ok {
+					continue
+				}
+				custom, err := unmarshalCustomModTOML(scheme, meta)
+				if err != nil {
+					return err
+				}
+				source.Custom = custom
+				break
+			}
 		}
 
 		pSpec := ModSpec{}
@@ -207,6 +287,8 @@ const TAG_FLAG = "tag"
 const GIT_COMMIT_FLAG = "commit"
 const GIT_BRANCH_FLAG = "branch"
 const GIT_PACKAGE_FLAG = "package"
+const GIT_SUBMODULES_FLAG = "submodules"
+const GIT_LFS_FLAG = "lfs"
 
 func (git *Git) UnmarshalModTOML(data interface{}) error {
 	meta, ok := data.(map[string]interface{})
@@ -234,6 +316,14 @@ func (git *Git) UnmarshalModTOML(data interface{}) error {
 		git.Package = v
 	}
 
+	if v, ok := meta[GIT_SUBMODULES_FLAG].(bool); ok {
+		git.Submodules = v
+	}
+
+	if v, ok := meta[GIT_LFS_FLAG].(bool); ok {
+		git.Lfs = v
+	}
+
 	return nil
 }
 
@@ -254,6 +344,48 @@ func (oci *Oci) UnmarshalModTOML(data interface{}) error {
 	return nil
 }
 
+const HTTP_URL_FLAG = "url"
+const HTTP_SHA256_FLAG = "sha256"
+
+func (h *Http) UnmarshalModTOML(data interface{}) error {
+	meta, ok := data.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected map[string]interface{}, got %T", data)
+	}
+
+	if v, ok := meta[HTTP_URL_FLAG].(string); ok {
+		h.HttpUrl = v
+	}
+
+	if v, ok := meta[HTTP_SHA256_FLAG].(string); ok {
+		h.Sha256 = v
+	}
+
+	return h.Validate()
+}
+
+// unmarshalCustomModTOML parses the custom source of 'scheme' from the dependency `{ <scheme> = "<url>", tag = "<tag>" }`,
+// unless the registered downloader of the scheme implements 'CustomTOMLMarshaler'.
+func unmarshalCustomModTOML(scheme string, meta map[string]interface{}) (*Custom, error) {
+	if d, ok := LookupDownloader(scheme); ok {
+		if marshaler, ok := d.(CustomTOMLMarshaler); ok {
+			return marshaler.UnmarshalModTOML(meta)
+		}
+	}
+
+	custom := &Custom{}
+	if v, ok := meta[scheme].(string); ok {
+		custom.CustomUrl = v
+	}
+	if v, ok := meta[TAG_FLAG].(string); ok {
+		custom.Tag = v
+	}
+	if custom.Scheme() != scheme {
+		return nil, fmt.Errorf("invalid url '%s' of the '%s' source", custom.CustomUrl, scheme)
+	}
+	return custom, nil
+}
+
 const LOCAL_PATH_FLAG = "path"
 
 func (local *Local) UnmarshalModTOML(data interface{}) error {
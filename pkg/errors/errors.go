@@ -50,6 +50,7 @@ var IsOciRef = errors.New("oci ref is not an url.")
 
 // Invalid Version
 var InvalidVersionFormat = errors.New("failed to parse version.")
+var AllVersionsYanked = errors.New("all the versions are yanked.")
 var PathNotFound = errors.New("path not found.")
 var PathIsEmpty = errors.New("path is empty.")
 var InvalidPkg = errors.New("invalid kcl package.")
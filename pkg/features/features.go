@@ -15,6 +15,9 @@ const (
 	SupportNewStorage = "SupportNewStorage"
 	// SupportModCheck is the feature gate for enabling the support for the checksum verification.
 	SupportModCheck = "SupportModCheck"
+	// SupportSystemGit is the feature gate for using the local git command instead of go-git,
+	// it enables the partial clone and sparse checkout of the git packages.
+	SupportSystemGit = "SupportSystemGit"
 )
 
 var (
@@ -22,6 +25,7 @@ var (
 		SupportMVS:        false,
 		SupportNewStorage: false,
 		SupportModCheck:   false,
+		SupportSystemGit:  false,
 	}
 	mu sync.Mutex
 )
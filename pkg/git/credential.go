@@ -0,0 +1,237 @@
+// This file mainly provides the authentication of the git repositories,
+// including the git credential helper protocol, see https://git-scm.com/docs/git-credential for more details.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GIT_CREDENTIAL_HELPER_PREFIX is the prefix of the git credential helper executables.
+const GIT_CREDENTIAL_HELPER_PREFIX = "git-credential-"
+
+// SYSTEM_GIT_CREDENTIAL_HELPER means to use the credential helpers configured in the system git by 'git credential fill'.
+const SYSTEM_GIT_CREDENTIAL_HELPER = "git"
+
+// Credential is the authentication used to access a git repository.
+// The username and password or the bearer token are used over https,
+// and the ssh key file, known_hosts file and agent are used over ssh.
+type Credential struct {
+	Username string
+	Password string
+	// BearerToken is sent in the 'Authorization: Bearer' header over https, it takes precedence over the password.
+	BearerToken string
+	// SSHKeyFile is the path of the private key used over ssh.
+	SSHKeyFile string
+	// SSHKnownHostsFile is the path of the known_hosts file used to verify the host key over ssh,
+	// the host key is checked strictly if it is set.
+	SSHKnownHostsFile string
+	// SSHAgent means to use the ssh agent in $SSH_AUTH_SOCK together with 'SSHKeyFile',
+	// if 'SSHKeyFile' is set and 'SSHAgent' is false, only the key file is used.
+	SSHAgent bool
+	// CAFile is the path of the PEM encoded certificates of the CAs trusted over https in addition to the system ones.
+	CAFile string
+}
+
+// IsEmpty returns true if the credential has no authentication and no CA file.
+func (c *Credential) IsEmpty() bool {
+	return c == nil || (c.Password == "" && c.BearerToken == "" && c.SSHKeyFile == "" && c.SSHKnownHostsFile == "" && !c.SSHAgent && c.CAFile == "")
+}
+
+// caBundle returns the certificates in the CA file of the credential 'cred', it is nil if the CA file is not set.
+func caBundle(cred *Credential) ([]byte, error) {
+	if cred == nil || cred.CAFile == "" {
+		return nil, nil
+	}
+	bundle, err := os.ReadFile(cred.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the CA file '%s': %w", cred.CAFile, err)
+	}
+	return bundle, nil
+}
+
+// CredentialFill gets the credential of 'repoUrl' from the git credential helper 'helper'.
+// The 'helper' can be:
+//   - 'git', the credential helpers configured in the system git are used by 'git credential fill'.
+//   - a command starting with '!', which is executed by the shell.
+//   - an absolute path of the helper executable.
+//   - a helper name 'xxx', the executable 'git-credential-xxx' in $PATH is used.
+//
+// If the helper returns no credential, CredentialFill returns nil.
+func CredentialFill(helper, repoUrl string) (*Credential, error) {
+	u, err := url.Parse(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var input bytes.Buffer
+	fmt.Fprintf(&input, "protocol=%s\n", u.Scheme)
+	fmt.Fprintf(&input, "host=%s\n", u.Host)
+	if path := strings.TrimPrefix(u.Path, "/"); path != "" {
+		fmt.Fprintf(&input, "path=%s\n", path)
+	}
+	if u.User != nil && u.User.Username() != "" {
+		fmt.Fprintf(&input, "username=%s\n", u.User.Username())
+	}
+	input.WriteString("\n")
+
+	var cmd *exec.Cmd
+	switch {
+	case helper == SYSTEM_GIT_CREDENTIAL_HELPER:
+		cmd = exec.Command("git", "credential", "fill")
+	case strings.HasPrefix(helper, "!"):
+		cmd = exec.Command("sh", "-c", strings.TrimPrefix(helper, "!")+" get")
+	case filepath.IsAbs(helper):
+		cmd = exec.Command(helper, "get")
+	default:
+		cmd = exec.Command(GIT_CREDENTIAL_HELPER_PREFIX+helper, "get")
+	}
+	// Never prompt the user in the terminal, kpm may run in the CI without a terminal.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdin = &input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the credential of '%s' from the git credential helper '%s': %w, %s", u.Host, helper, err, stderr.String())
+	}
+
+	cred := &Credential{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "username":
+			cred.Username = value
+		case "password":
+			cred.Password = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if cred.IsEmpty() {
+		return nil, nil
+	}
+	return cred, nil
+}
+
+// scpLikeUrlRegex matches the scp-like syntax 'user@host:path' of the ssh git url.
+var scpLikeUrlRegex = regexp.MustCompile(`^([^@/:]+)@([^/:]+):(.+)$`)
+
+// NormalizeScpLikeUrl converts the scp-like ssh git url 'user@host:path' into 'ssh://user@host/path',
+// which can be parsed by url.Parse, other urls are returned unchanged.
+func NormalizeScpLikeUrl(repoUrl string) string {
+	if strings.Contains(repoUrl, "://") {
+		return repoUrl
+	}
+	matches := scpLikeUrlRegex.FindStringSubmatch(repoUrl)
+	if matches == nil {
+		return repoUrl
+	}
+	return fmt.Sprintf("ssh://%s@%s/%s", matches[1], matches[2], strings.TrimPrefix(matches[3], "/"))
+}
+
+// gitEnv returns the environment of the git command to access the repository with the credential 'cred'.
+func gitEnv(cred *Credential) []string {
+	return scopedGitEnv(cred, "")
+}
+
+// scopedGitEnv returns the environment of the git command to access the repository with the credential 'cred',
+// and the https authentication is only sent to the urls prefixed by 'repoUrl' if it is not empty.
+// The credential is passed by the environment variables 'GIT_CONFIG_COUNT', 'GIT_CONFIG_KEY_<n>' and 'GIT_CONFIG_VALUE_<n>',
+// so it is not persisted in the config of the repository, and not visible in the command line of the process.
+func scopedGitEnv(cred *Credential, repoUrl string) []string {
+	// Never prompt the user in the terminal, kpm may run in the CI without a terminal.
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	configs := credentialConfigs(cred, repoUrl)
+	if len(configs) == 0 {
+		return env
+	}
+	// The configs in the environment of kpm are kept, and the configs of the credential are appended after them.
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	for i, config := range configs {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", count+i, config[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", count+i, config[1]),
+		)
+	}
+	return append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", count+len(configs)))
+}
+
+// credentialConfigs returns the git configs as the key-value pairs to access the repository with the credential 'cred',
+// and the https authentication is only sent to the urls prefixed by 'repoUrl' if it is not empty.
+func credentialConfigs(cred *Credential, repoUrl string) [][2]string {
+	if cred.IsEmpty() {
+		return nil
+	}
+
+	headerKey := "http.extraHeader"
+	if repoUrl != "" {
+		headerKey = "http." + repoUrl + ".extraHeader"
+	}
+
+	var configs [][2]string
+	if cred.BearerToken != "" {
+		configs = append(configs, [2]string{headerKey, "Authorization: Bearer " + cred.BearerToken})
+	} else if cred.Password != "" {
+		username := cred.Username
+		if username == "" {
+			// The token is used as the password, and most of the git hosts accept any non-empty username.
+			username = "oauth2"
+		}
+		auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + cred.Password))
+		configs = append(configs, [2]string{headerKey, "Authorization: Basic " + auth})
+	}
+
+	if cred.CAFile != "" {
+		configs = append(configs, [2]string{"http.sslCAInfo", cred.CAFile})
+	}
+	if sshCommand := sshCommand(cred); sshCommand != "" {
+		configs = append(configs, [2]string{"core.sshCommand", sshCommand})
+	}
+	return configs
+}
+
+// sshCommand returns the ssh command used by git with the ssh options in 'cred'.
+func sshCommand(cred *Credential) string {
+	if cred.SSHKeyFile == "" && cred.SSHKnownHostsFile == "" && !cred.SSHAgent {
+		return ""
+	}
+
+	// Never prompt the user for the passphrase or the unknown host key.
+	command := []string{"ssh", "-o", "BatchMode=yes"}
+	if cred.SSHKeyFile != "" {
+		command = append(command, "-i", shellQuote(cred.SSHKeyFile), "-o", "IdentitiesOnly=yes")
+		if !cred.SSHAgent {
+			command = append(command, "-o", "IdentityAgent=none")
+		}
+	}
+	if cred.SSHKnownHostsFile != "" {
+		command = append(command,
+			"-o", "UserKnownHostsFile="+shellQuote(cred.SSHKnownHostsFile),
+			"-o", "StrictHostKeyChecking=yes",
+		)
+	}
+	return strings.Join(command, " ")
+}
+
+// shellQuote quotes 's' by single quotes for the shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
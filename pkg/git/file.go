@@ -0,0 +1,168 @@
+package git
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"kcl-lang.io/kpm/pkg/constants"
+)
+
+// ReadModFile reads the kcl.mod file of the package in the branch, tag or commit of the repository without checking out the files,
+// and returns the content of the kcl.mod file and the slash separated directory of the package relative to the repository root.
+// The remote repository is cloned into 'LocalPath' as a bare repository. Only the blobs of the kcl.mod files are downloaded
+// by the partial clone if the local git command is used, and the tag or branch is cloned shallowly by go-git.
+// The local repository is read in place by go-git without the local git command.
+// The kcl.mod file in the repository root is read if 'Package' is empty.
+func (cloneOpts *CloneOptions) ReadModFile() (string, string, error) {
+	if err := cloneOpts.Validate(); err != nil {
+		return "", "", err
+	}
+
+	if !UseSystemGit() {
+		return cloneOpts.readModFileWithGoGit()
+	}
+
+	cmdArgs := []string{"clone", "--bare", "--filter=" + PARTIAL_CLONE_FILTER}
+	if ref := cloneOpts.refName(); ref != "" {
+		cmdArgs = append(cmdArgs, "--branch", ref)
+		// The commit may not be the head of the branch or tag, so the history is cloned to find the commit.
+		if cloneOpts.Commit == "" {
+			cmdArgs = append(cmdArgs, "--depth", "1")
+		}
+	}
+	cmdArgs = append(cmdArgs, cloneOpts.RepoURL, cloneOpts.LocalPath)
+	if _, err := runGit(cloneOpts.Credential, cmdArgs...); err != nil {
+		return "", "", fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	ref := "HEAD"
+	if cloneOpts.Commit != "" {
+		ref = cloneOpts.Commit
+	}
+
+	subdir := "."
+	if cloneOpts.Package != "" {
+		var err error
+		subdir, err = FindPackagePath(cloneOpts.LocalPath, ref, cloneOpts.Package, cloneOpts.Credential)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	kclModPath := path.Join(subdir, constants.KCL_MOD)
+	content, err := runGit(cloneOpts.Credential, "-C", cloneOpts.LocalPath, "cat-file", "blob", ref+":"+kclModPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read '%s': %w", kclModPath, err)
+	}
+
+	return content, subdir, nil
+}
+
+// readModFileWithGoGit reads the kcl.mod file of the package by go-git.
+func (cloneOpts *CloneOptions) readModFileWithGoGit() (string, string, error) {
+	var referenceName plumbing.ReferenceName
+	if cloneOpts.Branch != "" {
+		referenceName = plumbing.NewBranchReferenceName(cloneOpts.Branch)
+	} else if cloneOpts.Tag != "" {
+		referenceName = plumbing.NewTagReferenceName(cloneOpts.Tag)
+	}
+
+	var repo *git.Repository
+	var err error
+	revision := "HEAD"
+	if localPath, ok := localRepoPath(cloneOpts.RepoURL); ok {
+		// The file transport of go-git runs 'git-upload-pack' of the local git command,
+		// so the local repository is read in place.
+		repo, err = git.PlainOpen(localPath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to open repository '%s': %w", cloneOpts.RepoURL, err)
+		}
+		if referenceName != "" {
+			revision = referenceName.String()
+		}
+	} else {
+		auth, err := authMethod(cloneOpts.Credential, cloneOpts.RepoURL)
+		if err != nil {
+			return "", "", err
+		}
+		ca, err := caBundle(cloneOpts.Credential)
+		if err != nil {
+			return "", "", err
+		}
+
+		opts := &git.CloneOptions{
+			URL:           cloneOpts.RepoURL,
+			Auth:          auth,
+			CABundle:      ca,
+			Progress:      cloneOpts.Writer,
+			Tags:          git.NoTags,
+			ReferenceName: referenceName,
+		}
+		if referenceName != "" {
+			opts.SingleBranch = true
+			// The commit may not be the head of the branch or tag.
+			if cloneOpts.Commit == "" {
+				opts.Depth = 1
+			}
+		}
+
+		repo, err = git.PlainCloneContext(cloneOpts.ctx(), cloneOpts.LocalPath, true, opts)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, err)
+		}
+	}
+
+	if cloneOpts.Commit != "" {
+		revision = cloneOpts.Commit
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve '%s': %w", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return "", "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", "", err
+	}
+
+	subdir := "."
+	if cloneOpts.Package != "" {
+		subdir, err = findPackagePathWithGoGit(repo, *hash, cloneOpts.Package)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	content, err := readTreeFile(tree, path.Join(subdir, constants.KCL_MOD))
+	if err != nil {
+		return "", "", err
+	}
+	return content, subdir, nil
+}
+
+// refName returns the name of the branch or tag to clone, the branch takes precedence.
+func (cloneOpts *CloneOptions) refName() string {
+	if cloneOpts.Branch != "" {
+		return cloneOpts.Branch
+	}
+	return cloneOpts.Tag
+}
+
+// readTreeFile reads the file 'name' in the git tree.
+func readTreeFile(tree *object.Tree, name string) (string, error) {
+	f, err := tree.File(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s': %w", name, err)
+	}
+	content, err := f.Contents()
+	if err != nil {
+		return "", fmt.Errorf("failed to read '%s': %w", name, err)
+	}
+	return content, nil
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestReadModFile(t *testing.T) {
+	for _, systemGit := range []bool{false, true} {
+		t.Run(fmt.Sprintf("systemGit=%v", systemGit), func(t *testing.T) {
+			if systemGit {
+				enableSystemGit(t)
+			}
+			repoUrl := initTestMonorepo(t)
+			if !systemGit {
+				// The local repository is read without the local git command.
+				t.Setenv("PATH", t.TempDir())
+			}
+
+			content, subdir, err := (&CloneOptions{
+				RepoURL:   repoUrl,
+				Tag:       "v0.0.1",
+				Package:   "b",
+				LocalPath: filepath.Join(t.TempDir(), "b"),
+			}).ReadModFile()
+			assert.NilError(t, err)
+			assert.Equal(t, subdir, "pkgs/b")
+			assert.Equal(t, content, "[package]\nname = \"b\"\nversion = \"0.0.1\"\n")
+
+			_, _, err = (&CloneOptions{
+				RepoURL:   repoUrl,
+				Branch:    "main",
+				Package:   "c",
+				LocalPath: filepath.Join(t.TempDir(), "c"),
+			}).ReadModFile()
+			assert.ErrorContains(t, err, "package 'c' not found")
+
+			_, _, err = (&CloneOptions{
+				RepoURL:   repoUrl,
+				LocalPath: filepath.Join(t.TempDir(), "root"),
+			}).ReadModFile()
+			assert.ErrorContains(t, err, "failed to read 'kcl.mod'")
+		})
+	}
+}
+
+func TestReadModFileWithCommit(t *testing.T) {
+	for _, systemGit := range []bool{false, true} {
+		t.Run(fmt.Sprintf("systemGit=%v", systemGit), func(t *testing.T) {
+			if systemGit {
+				enableSystemGit(t)
+			}
+			repoUrl := initTestMonorepo(t)
+			bareDir := filepath.FromSlash(strings.TrimPrefix(repoUrl, "file://"))
+			workDir := filepath.Join(t.TempDir(), "work")
+			runGit := func(args ...string) string {
+				cmd := exec.Command("git", args...)
+				cmd.Env = append(os.Environ(),
+					"GIT_AUTHOR_NAME=kpm", "GIT_AUTHOR_EMAIL=kpm@kcl-lang.io",
+					"GIT_COMMITTER_NAME=kpm", "GIT_COMMITTER_EMAIL=kpm@kcl-lang.io",
+				)
+				output, err := cmd.CombinedOutput()
+				assert.NilError(t, err, string(output))
+				return strings.TrimSpace(string(output))
+			}
+
+			// The commit to read is not the head of the branch.
+			commit := runGit("-C", bareDir, "rev-parse", "v0.0.1")
+			runGit("clone", repoUrl, workDir)
+			assert.NilError(t, os.WriteFile(filepath.Join(workDir, "pkgs", "b", "kcl.mod"), []byte("[package]\nname = \"b\"\nversion = \"0.0.2\"\n"), 0644))
+			runGit("-C", workDir, "commit", "-am", "bump b")
+			runGit("-C", workDir, "push", "origin", "main")
+			if !systemGit {
+				t.Setenv("PATH", t.TempDir())
+			}
+
+			content, subdir, err := (&CloneOptions{
+				RepoURL:   repoUrl,
+				Commit:    commit,
+				Package:   "b",
+				LocalPath: filepath.Join(t.TempDir(), "b"),
+			}).ReadModFile()
+			assert.NilError(t, err)
+			assert.Equal(t, subdir, "pkgs/b")
+			assert.Equal(t, content, "[package]\nname = \"b\"\nversion = \"0.0.1\"\n")
+		})
+	}
+}
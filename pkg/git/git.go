@@ -2,20 +2,19 @@ package git
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"os"
 	"os/exec"
 	"regexp"
-	"strings"
-	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/hashicorp/go-getter"
 	giturl "github.com/kubescape/go-git-url"
+	"kcl-lang.io/kpm/pkg/features"
 )
 
 // CloneOptions is a struct for specifying options for cloning a git repository
@@ -27,6 +26,21 @@ type CloneOptions struct {
 	LocalPath string
 	Writer    io.Writer
 	Bare      bool // New field to indicate if the clone should be bare
+	// Credential is the authentication used to access the repository.
+	Credential *Credential
+	// Package is the name of the package in the repository, only its directory is checked out if it is set.
+	Package string
+	// SparsePath is the directory of the package relative to the repository root, the package is not searched if it is set.
+	SparsePath string
+	// Submodules means to clone the submodules of the repository recursively.
+	Submodules bool
+	// Lfs means to download the Git LFS objects of the repository, it requires the local git command and 'git-lfs'.
+	Lfs bool
+	// OriginURL is the url of the upstream repository when cloning from the local cache,
+	// it is set as the url of the remote 'origin' to resolve the relative submodule urls and download the Git LFS objects.
+	OriginURL string
+	// Context is the context of the clone, the network I/O is aborted when it is canceled.
+	Context context.Context
 }
 
 // CloneOption is a function that modifies CloneOptions
@@ -92,6 +106,28 @@ func WithWriter(writer io.Writer) CloneOption {
 	}
 }
 
+// WithContext sets the context for CloneOptions
+func WithContext(ctx context.Context) CloneOption {
+	return func(o *CloneOptions) {
+		o.Context = ctx
+	}
+}
+
+// ctx returns the context of the clone, it is 'context.Background()' if the context is not set.
+func (cloneOpts *CloneOptions) ctx() context.Context {
+	if cloneOpts.Context == nil {
+		return context.Background()
+	}
+	return cloneOpts.Context
+}
+
+// WithCredential sets the credential for CloneOptions
+func WithCredential(cred *Credential) CloneOption {
+	return func(o *CloneOptions) {
+		o.Credential = cred
+	}
+}
+
 // Validate checks if the CloneOptions are valid
 func (cloneOpts *CloneOptions) Validate() error {
 	onlyOneAllowed := 0
@@ -109,6 +145,12 @@ func (cloneOpts *CloneOptions) Validate() error {
 		return errors.New("only one of branch, tag or commit is allowed")
 	}
 
+	// go-git can not download the Git LFS objects.
+	if cloneOpts.Lfs && !UseSystemGit() {
+		return fmt.Errorf("the Git LFS objects of '%s' can only be downloaded by the local git command, "+
+			"enable it by 'KPM_FEATURE_GATES=\"%s=true\"'", cloneOpts.RepoURL, features.SupportSystemGit)
+	}
+
 	return nil
 }
 
@@ -130,9 +172,13 @@ func (cloneOpts *CloneOptions) CheckoutFromBare() error {
 		return errors.New("no reference specified for checkout")
 	}
 
-	cmd := exec.Command("git", "-C", cloneOpts.LocalPath, "symbolic-ref", "HEAD", reference)
+	if !UseSystemGit() {
+		return cloneOpts.checkoutFromBareWithGoGit(reference)
+	}
+
+	cmd := exec.CommandContext(cloneOpts.ctx(), "git", "-C", cloneOpts.LocalPath, "symbolic-ref", "HEAD", reference)
 	if cloneOpts.Commit != "" {
-		cmd = exec.Command("git", "-C", cloneOpts.LocalPath, "update-ref", "HEAD", reference)
+		cmd = exec.CommandContext(cloneOpts.ctx(), "git", "-C", cloneOpts.LocalPath, "update-ref", "HEAD", reference)
 	}
 	cmd.Stdout = cloneOpts.Writer
 	cmd.Stderr = cloneOpts.Writer
@@ -144,16 +190,33 @@ func (cloneOpts *CloneOptions) CheckoutFromBare() error {
 	return nil
 }
 
-// Clone clones a git repository, handling both bare and non-bare options
+// Clone clones a git repository, handling both bare and non-bare options.
+// If the clone is canceled by the context, the repository cloned partially into a new directory is removed.
 func (cloneOpts *CloneOptions) Clone() (*git.Repository, error) {
 	if err := cloneOpts.Validate(); err != nil {
 		return nil, err
 	}
 
+	_, statErr := os.Stat(cloneOpts.LocalPath)
+	repo, err := cloneOpts.clone()
+	if err != nil && cloneOpts.ctx().Err() != nil {
+		if os.IsNotExist(statErr) {
+			_ = os.RemoveAll(cloneOpts.LocalPath)
+		}
+		return nil, fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, cloneOpts.ctx().Err())
+	}
+	return repo, err
+}
+
+func (cloneOpts *CloneOptions) clone() (*git.Repository, error) {
 	if cloneOpts.Bare {
+		if !UseSystemGit() {
+			return cloneOpts.cloneBareWithGoGit()
+		}
+
 		// Use local git command to clone as bare repository
-		cmdArgs := []string{"clone", "--bare", cloneOpts.RepoURL, cloneOpts.LocalPath}
-		cmd := exec.Command("git", cmdArgs...)
+		cmd := exec.CommandContext(cloneOpts.ctx(), "git", "clone", "--bare", cloneOpts.RepoURL, cloneOpts.LocalPath)
+		cmd.Env = gitEnv(cloneOpts.Credential)
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
@@ -168,6 +231,34 @@ func (cloneOpts *CloneOptions) Clone() (*git.Repository, error) {
 		return repo, nil
 	}
 
+	repo, err := cloneOpts.cloneWorkTree()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cloneOpts.updateSubmodulesAndLfs(); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// cloneWorkTree clones a non-bare git repository and checks out the working tree.
+func (cloneOpts *CloneOptions) cloneWorkTree() (*git.Repository, error) {
+	if !UseSystemGit() {
+		return cloneOpts.cloneWorkTreeWithGoGit()
+	}
+
+	if cloneOpts.Package != "" || cloneOpts.SparsePath != "" {
+		return cloneOpts.cloneSparse()
+	}
+
+	// The authenticated clone uses the local git command,
+	// because the credential cannot be passed to go-getter without persisting it in the url of the remote.
+	if !cloneOpts.Credential.IsEmpty() {
+		return cloneOpts.cloneWithCredential()
+	}
+
 	// Default non-bare clone using go-getter
 	url, err := cloneOpts.ForceGitUrl()
 	if err != nil {
@@ -175,6 +266,7 @@ func (cloneOpts *CloneOptions) Clone() (*git.Repository, error) {
 	}
 
 	client := &getter.Client{
+		Ctx:       cloneOpts.ctx(),
 		Src:       url,
 		Dst:       cloneOpts.LocalPath,
 		Pwd:       cloneOpts.LocalPath,
@@ -195,6 +287,35 @@ func (cloneOpts *CloneOptions) Clone() (*git.Repository, error) {
 	return repo, nil
 }
 
+// cloneWithCredential clones a non-bare git repository with the credential by the local git command,
+// and checks out the branch, tag or commit.
+func (cloneOpts *CloneOptions) cloneWithCredential() (*git.Repository, error) {
+	cmdArgs := []string{"clone"}
+	if cloneOpts.Branch != "" {
+		cmdArgs = append(cmdArgs, "--branch", cloneOpts.Branch)
+	} else if cloneOpts.Tag != "" {
+		cmdArgs = append(cmdArgs, "--branch", cloneOpts.Tag)
+	}
+	cmdArgs = append(cmdArgs, cloneOpts.RepoURL, cloneOpts.LocalPath)
+	cmd := exec.CommandContext(cloneOpts.ctx(), "git", cmdArgs...)
+	cmd.Env = gitEnv(cloneOpts.Credential)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %s, error: %w", string(output), err)
+	}
+
+	if cloneOpts.Commit != "" {
+		cmd := exec.CommandContext(cloneOpts.ctx(), "git", "-C", cloneOpts.LocalPath, "checkout", cloneOpts.Commit)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("failed to checkout commit '%s': %s, error: %w", cloneOpts.Commit, string(output), err)
+		}
+	}
+
+	return git.PlainOpen(cloneOpts.LocalPath)
+}
+
 // CloneWithOpts will clone from `repoURL` to `localPath` via git by using CloneOptions
 func CloneWithOpts(opts ...CloneOption) (*git.Repository, error) {
 	cloneOpts := &CloneOptions{}
@@ -238,6 +359,7 @@ func parseNextPageURL(linkHeader string) (string, error) {
 }
 
 // GetAllGithubReleases fetches all releases from a GitHub repository
+// Deprecated: This function will be removed in a future version. Use GetAllReleases instead.
 func GetAllGithubReleases(url string) ([]string, error) {
 	// Initialize and parse the URL to extract owner and repo names
 	gitURL, err := giturl.NewGitURL(url)
@@ -249,79 +371,56 @@ func GetAllGithubReleases(url string) ([]string, error) {
 		return nil, errors.New("only GitHub repositories are currently supported")
 	}
 
-	// Construct initial API URL for the first page
-	apiBase := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", gitURL.GetOwnerName(), gitURL.GetRepoName())
-	apiURL := fmt.Sprintf("%s?per_page=100&page=1", apiBase)
-
-	client := http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	var releaseTags []string
-
-	for apiURL != "" {
-		req, err := http.NewRequest("GET", apiURL, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("failed to fetch tags, status code: %d", resp.StatusCode)
-		}
-
-		// Decode the JSON response into a slice of releases
-		var releases []GitHubRelease
-		if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-			return nil, err
-		}
-
-		// Extract tag names from the releases
-		for _, release := range releases {
-			releaseTags = append(releaseTags, release.TagName)
-		}
-
-		// Read the `Link` header to get the next page URL, if available
-		linkHeader := resp.Header.Get("Link")
-		if linkHeader != "" {
-			nextURL, err := parseNextPageURL(linkHeader)
-			if err != nil {
-				apiURL = ""
-			} else {
-				apiURL = nextURL
-			}
-		} else {
-			apiURL = ""
-		}
-		fmt.Println(apiURL)
-	}
-
-	return releaseTags, nil
+	return (&GithubReleaseLister{}).ListReleases(url)
 }
 
 // IsGitBareRepo checks if a directory is a bare git repository
 func IsGitBareRepo(dir string) bool {
-	cmd := exec.Command("git", "-C", dir, "rev-parse", "--is-bare-repository")
-	output, err := cmd.Output()
+	repo, err := git.PlainOpen(dir)
 	if err != nil {
 		return false
 	}
-	return strings.TrimSpace(string(output)) == "true"
+	cfg, err := repo.Config()
+	return err == nil && cfg.Core.IsBare
 }
 
 // Fetch fetches the latest changes from a remote repository
 func Fetch(dir string, args ...string) error {
-	cmdArgs := append([]string{"-C", dir, "fetch", "origin"}, args...)
-	cmd := exec.Command("git", cmdArgs...)
+	return FetchWithOpts(dir, nil, args...)
+}
+
+// FetchWithOpts fetches the latest changes from a remote repository with the credential 'cred'.
+// The 'args' are the refspecs to fetch from the remote 'origin', by go-git or by the local git command,
+// all the branches and tags are fetched into the bare repository if no refspecs are specified.
+func FetchWithOpts(dir string, cred *Credential, args ...string) error {
+	return FetchWithContext(context.Background(), dir, cred, args...)
+}
+
+// FetchWithContext is the same as FetchWithOpts, and the fetch is aborted when the context 'ctx' is canceled.
+func FetchWithContext(ctx context.Context, dir string, cred *Credential, args ...string) error {
+	refSpecs, err := parseRefSpecs(args)
+	if err != nil {
+		return err
+	}
+	// The bare repository cloned by the local git command has no refspecs of the remote.
+	if len(refSpecs) == 0 && IsGitBareRepo(dir) {
+		refSpecs = bareFetchRefSpecs
+	}
+
+	if !UseSystemGit() {
+		return fetchWithGoGit(ctx, dir, cred, refSpecs)
+	}
+
+	cmdArgs := []string{"-C", dir, "fetch", "--tags", "origin"}
+	for _, refSpec := range refSpecs {
+		cmdArgs = append(cmdArgs, refSpec.String())
+	}
+	cmd := exec.CommandContext(ctx, "git", cmdArgs...)
+	cmd.Env = gitEnv(cred)
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &out
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
 		return fmt.Errorf("failed to fetch latest changes: %v, output: %s", err, out.String())
 	}
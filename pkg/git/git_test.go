@@ -2,6 +2,8 @@ package git
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/go-git/go-git/v5/plumbing"
 	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/mock"
 )
 
 func TestWithGitOptions(t *testing.T) {
@@ -49,8 +52,38 @@ func TestValidateGitOptions(t *testing.T) {
 	assert.Equal(t, err.Error(), "only one of branch, tag or commit is allowed")
 }
 
+func TestCloneWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	localPath := filepath.Join(t.TempDir(), "flask-demo-kcl-manifests")
+	_, err := CloneWithOpts(
+		WithRepoURL("https://github.com/kcl-lang/flask-demo-kcl-manifests.git"),
+		WithLocalPath(localPath),
+		WithContext(ctx),
+	)
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	_, statErr := os.Stat(localPath)
+	assert.Assert(t, os.IsNotExist(statErr))
+}
+
+// seedTestRepo starts a local git server with the repository 'helloworld' of two commits,
+// and returns the url of the repository and the hash of the first commit.
+func seedTestRepo(t *testing.T) (string, string) {
+	server, err := mock.NewGitServer()
+	assert.NilError(t, err)
+	t.Cleanup(server.Close)
+
+	commit, err := server.Seed("helloworld", filepath.Join("..", "mock", "test_pkg", "helloworld_0.1.0"), "v0.1.0")
+	assert.NilError(t, err)
+	_, err = server.Seed("helloworld", filepath.Join("..", "mock", "test_pkg", "helloworld_0.2.0"), "v0.2.0")
+	assert.NilError(t, err)
+	return server.RepoUrl("helloworld"), commit
+}
+
 func TestCloneWithOptions(t *testing.T) {
 	var buf bytes.Buffer
+	repoURL, commitSHA := seedTestRepo(t)
 
 	// Test cloning a remote repo as a bare repo
 	t.Run("RemoteBareClone", func(t *testing.T) {
@@ -62,7 +95,7 @@ func TestCloneWithOptions(t *testing.T) {
 		}()
 
 		_, err = CloneWithOpts(
-			WithRepoURL("https://github.com/KusionStack/catalog.git"),
+			WithRepoURL(repoURL),
 			WithLocalPath(tmpdir),
 			WithBare(true), // Set the Bare flag to true
 		)
@@ -92,8 +125,8 @@ func TestCloneWithOptions(t *testing.T) {
 		}()
 
 		repo, err := CloneWithOpts(
-			WithRepoURL("https://github.com/KusionStack/catalog.git"),
-			WithCommit("4e59d5852cd76542f9f0ec65e5773ca9f4e02462"),
+			WithRepoURL(repoURL),
+			WithCommit(commitSHA),
 			WithWriter(&buf),
 			WithLocalPath(tmpdir),
 			WithBare(false), // Ensure the Bare flag is false
@@ -102,7 +135,7 @@ func TestCloneWithOptions(t *testing.T) {
 
 		head, err := repo.Head()
 		assert.Equal(t, err, nil)
-		assert.Equal(t, head.Hash().String(), "4e59d5852cd76542f9f0ec65e5773ca9f4e02462")
+		assert.Equal(t, head.Hash().String(), commitSHA)
 	})
 
 	// Test cloning a bare repo as a bare repo
@@ -114,7 +147,7 @@ func TestCloneWithOptions(t *testing.T) {
 			rErr := os.RemoveAll(bareRepoPath)
 			assert.Equal(t, rErr, nil)
 		}()
-		cmd := exec.Command("git", "clone", "--bare", "https://github.com/KusionStack/catalog.git", bareRepoPath)
+		cmd := exec.Command("git", "clone", "--bare", repoURL, bareRepoPath)
 		err = cmd.Run()
 		assert.Equal(t, err, nil)
 
@@ -156,7 +189,7 @@ func TestCloneWithOptions(t *testing.T) {
 			rErr := os.RemoveAll(bareRepoPath)
 			assert.Equal(t, rErr, nil)
 		}()
-		cmd := exec.Command("git", "clone", "--bare", "https://github.com/KusionStack/catalog.git", bareRepoPath)
+		cmd := exec.Command("git", "clone", "--bare", repoURL, bareRepoPath)
 		err = cmd.Run()
 		assert.Equal(t, err, nil)
 
@@ -170,7 +203,7 @@ func TestCloneWithOptions(t *testing.T) {
 
 		repo, err := CloneWithOpts(
 			WithRepoURL(bareRepoPath),
-			WithCommit("4e59d5852cd76542f9f0ec65e5773ca9f4e02462"),
+			WithCommit(commitSHA),
 			WithWriter(&buf),
 			WithLocalPath(tmpdir),
 			WithBare(false), // Ensure the Bare flag is false
@@ -179,7 +212,7 @@ func TestCloneWithOptions(t *testing.T) {
 
 		head, err := repo.Head()
 		assert.Equal(t, err, nil)
-		assert.Equal(t, head.Hash().String(), "4e59d5852cd76542f9f0ec65e5773ca9f4e02462")
+		assert.Equal(t, head.Hash().String(), commitSHA)
 	})
 }
 
@@ -193,8 +226,7 @@ func TestCheckoutFromBare(t *testing.T) {
 	}()
 
 	// First, clone a bare repository
-	repoURL := "https://github.com/KusionStack/catalog.git"
-	commitSHA := "4e59d5852cd76542f9f0ec65e5773ca9f4e02462"
+	repoURL, commitSHA := seedTestRepo(t)
 
 	repo, err := CloneWithOpts(
 		WithRepoURL(repoURL),
@@ -0,0 +1,890 @@
+// This file mainly provides the git operations implemented by go-git, so that kpm works without the local git command.
+// The local git command is used instead only if the feature gate 'SupportSystemGit' is enabled.
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/features"
+)
+
+// bareFetchRefSpecs are the refspecs to fetch all the branches and tags into the bare repository, like 'git clone --bare'.
+var bareFetchRefSpecs = []config.RefSpec{
+	"+refs/heads/*:refs/heads/*",
+	"+refs/tags/*:refs/tags/*",
+}
+
+// UseSystemGit returns true if the local git command is used instead of go-git.
+func UseSystemGit() bool {
+	ok, err := features.Enabled(features.SupportSystemGit)
+	return err == nil && ok
+}
+
+// authMethod returns the go-git authentication of the credential 'cred' to access the repository 'repoUrl'.
+func authMethod(cred *Credential, repoUrl string) (transport.AuthMethod, error) {
+	if cred.IsEmpty() {
+		return nil, nil
+	}
+
+	ep, err := transport.NewEndpoint(NormalizeScpLikeUrl(repoUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	switch ep.Protocol {
+	case "http", "https":
+		if cred.BearerToken != "" {
+			return &githttp.TokenAuth{Token: cred.BearerToken}, nil
+		}
+		if cred.Password != "" {
+			username := cred.Username
+			if username == "" {
+				// The token is used as the password, and most of the git hosts accept any non-empty username.
+				username = "oauth2"
+			}
+			return &githttp.BasicAuth{Username: username, Password: cred.Password}, nil
+		}
+	case "ssh":
+		user := ep.User
+		if user == "" {
+			user = "git"
+		}
+
+		var auth transport.AuthMethod
+		var helper *gitssh.HostKeyCallbackHelper
+		if cred.SSHKeyFile != "" {
+			keys, err := gitssh.NewPublicKeysFromFile(user, cred.SSHKeyFile, "")
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the ssh key '%s': %w", cred.SSHKeyFile, err)
+			}
+			auth, helper = keys, &keys.HostKeyCallbackHelper
+		} else {
+			agent, err := gitssh.NewSSHAgentAuth(user)
+			if err != nil {
+				return nil, fmt.Errorf("failed to connect to the ssh agent: %w", err)
+			}
+			auth, helper = agent, &agent.HostKeyCallbackHelper
+		}
+
+		if cred.SSHKnownHostsFile != "" {
+			callback, err := gitssh.NewKnownHostsCallback(cred.SSHKnownHostsFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the known_hosts file '%s': %w", cred.SSHKnownHostsFile, err)
+			}
+			helper.HostKeyCallback = callback
+		}
+		return auth, nil
+	}
+
+	return nil, nil
+}
+
+// cloneBareWithGoGit clones the bare repository with all the branches and tags by go-git,
+// and the HEAD of the bare repository points to the default branch of the remote.
+func (cloneOpts *CloneOptions) cloneBareWithGoGit() (repo *git.Repository, err error) {
+	auth, err := authMethod(cloneOpts.Credential, cloneOpts.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := caBundle(cloneOpts.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err = git.PlainInit(cloneOpts.LocalPath, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(cloneOpts.LocalPath)
+		}
+	}()
+
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name:  git.DefaultRemoteName,
+		URLs:  []string{cloneOpts.RepoURL},
+		Fetch: bareFetchRefSpecs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if localPath, ok := localRepoPath(cloneOpts.RepoURL); ok {
+		if err = fetchLocal(cloneOpts.LocalPath, localPath, bareFetchRefSpecs); err != nil {
+			return nil, fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, err)
+		}
+		src, err := git.PlainOpen(localPath)
+		if err != nil {
+			return nil, err
+		}
+		if head, err := src.Reference(plumbing.HEAD, false); err == nil && head.Type() == plumbing.SymbolicReference {
+			if err = repo.Storer.SetReference(head); err != nil {
+				return nil, err
+			}
+		}
+		return git.PlainOpen(cloneOpts.LocalPath)
+	}
+
+	refs, err := remote.ListContext(cloneOpts.ctx(), &git.ListOptions{Auth: auth, CABundle: ca})
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, err)
+	}
+
+	err = remote.FetchContext(cloneOpts.ctx(), &git.FetchOptions{
+		RefSpecs: bareFetchRefSpecs,
+		Auth:     auth,
+		CABundle: ca,
+		Progress: cloneOpts.Writer,
+		Tags:     git.NoTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, err)
+	}
+
+	if head := remoteHead(refs); head != "" {
+		if err = repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, head)); err != nil {
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// remoteHead returns the branch pointed by the HEAD in the references 'refs' listed from the remote.
+func remoteHead(refs []*plumbing.Reference) plumbing.ReferenceName {
+	var head *plumbing.Reference
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD {
+			head = ref
+			break
+		}
+	}
+	if head == nil {
+		return ""
+	}
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target()
+	}
+
+	// The remote does not advertise the symbolic HEAD, so the branch is guessed by the commit.
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			return ref.Name()
+		}
+	}
+	return ""
+}
+
+// cloneWorkTreeWithGoGit clones the non-bare repository by go-git, and checks out the branch, tag or commit.
+// Only the latest commit of the branch or tag is fetched from the remote, and only the directory of the package
+// is checked out in the sparse checkout if the package is specified, like the partial clone by the local git command.
+func (cloneOpts *CloneOptions) cloneWorkTreeWithGoGit() (repo *git.Repository, err error) {
+	if localPath, ok := localRepoPath(cloneOpts.RepoURL); ok {
+		repo, err = cloneOpts.cloneLocalWithGoGit(localPath)
+	} else {
+		repo, err = cloneOpts.cloneRemoteWithGoGit()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hash plumbing.Hash
+	if cloneOpts.Commit != "" {
+		commit, err := repo.ResolveRevision(plumbing.Revision(cloneOpts.Commit))
+		if err != nil {
+			return nil, fmt.Errorf("failed to checkout commit '%s': %w", cloneOpts.Commit, err)
+		}
+		hash = *commit
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.HEAD, hash)); err != nil {
+			return nil, err
+		}
+	} else {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, err
+		}
+		hash = head.Hash()
+	}
+
+	sparsePath := cloneOpts.SparsePath
+	if sparsePath == "" && cloneOpts.Package != "" {
+		sparsePath, err = findPackagePathWithGoGit(repo, hash, cloneOpts.Package)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The package in the repository root needs all the files, so the sparse checkout is not used.
+	if sparsePath == "" || sparsePath == "." {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return nil, err
+		}
+		if err := worktree.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset}); err != nil {
+			return nil, fmt.Errorf("failed to checkout '%s': %w", hash, err)
+		}
+		return repo, nil
+	}
+
+	if err := checkoutSparse(repo, hash, []string{sparsePath}); err != nil {
+		return nil, fmt.Errorf("failed to checkout '%s': %w", hash, err)
+	}
+	if err := setSparsePaths(repo, []string{sparsePath}); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// cloneRemoteWithGoGit clones the remote repository without checking out the files.
+// Only the latest commit of the branch, tag or default branch is fetched,
+// and the history of all the branches is fetched to find the commit if the commit is specified.
+func (cloneOpts *CloneOptions) cloneRemoteWithGoGit() (*git.Repository, error) {
+	auth, err := authMethod(cloneOpts.Credential, cloneOpts.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := caBundle(cloneOpts.Credential)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &git.CloneOptions{
+		URL:        cloneOpts.RepoURL,
+		Auth:       auth,
+		CABundle:   ca,
+		Progress:   cloneOpts.Writer,
+		NoCheckout: true,
+		Tags:       git.NoTags,
+	}
+	if cloneOpts.Commit == "" {
+		opts.Depth = 1
+		opts.SingleBranch = true
+	}
+	if cloneOpts.Branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(cloneOpts.Branch)
+	} else if cloneOpts.Tag != "" {
+		opts.ReferenceName = plumbing.NewTagReferenceName(cloneOpts.Tag)
+	}
+
+	repo, err := git.PlainCloneContext(cloneOpts.ctx(), cloneOpts.LocalPath, false, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, err)
+	}
+	return repo, nil
+}
+
+// cloneLocalWithGoGit clones the local repository 'localPath', e.g. the bare repository in the cache,
+// without checking out the files. The objects and references are copied in process,
+// because the file transport of go-git runs 'git-upload-pack' of the local git command.
+func (cloneOpts *CloneOptions) cloneLocalWithGoGit(localPath string) (repo *git.Repository, err error) {
+	repo, err = git.PlainInit(cloneOpts.LocalPath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(cloneOpts.LocalPath)
+		}
+	}()
+
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{cloneOpts.RepoURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = fetchLocal(cloneOpts.LocalPath, localPath, remote.Config().Fetch); err != nil {
+		return nil, fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, err)
+	}
+	if repo, err = git.PlainOpen(cloneOpts.LocalPath); err != nil {
+		return nil, err
+	}
+
+	src, err := git.PlainOpen(localPath)
+	if err != nil {
+		return nil, err
+	}
+	var head *plumbing.Reference
+	if cloneOpts.Tag != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(plumbing.NewTagReferenceName(cloneOpts.Tag)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone repository '%s': %w", cloneOpts.RepoURL, err)
+		}
+		head = plumbing.NewHashReference(plumbing.HEAD, *hash)
+	} else {
+		branch := plumbing.NewBranchReferenceName(cloneOpts.Branch)
+		if cloneOpts.Branch == "" {
+			srcHead, err := src.Reference(plumbing.HEAD, false)
+			if err != nil {
+				return nil, err
+			}
+			branch = srcHead.Target()
+		}
+		ref, err := src.Reference(branch, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to clone repository '%s': reference '%s' %w", cloneOpts.RepoURL, branch, err)
+		}
+		if err = repo.Storer.SetReference(plumbing.NewHashReference(branch, ref.Hash())); err != nil {
+			return nil, err
+		}
+		head = plumbing.NewSymbolicReference(plumbing.HEAD, branch)
+	}
+	if err = repo.Storer.SetReference(head); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// localRepoPath returns the path of the repository 'repoUrl' if it is on the local file system.
+func localRepoPath(repoUrl string) (string, bool) {
+	ep, err := transport.NewEndpoint(NormalizeScpLikeUrl(repoUrl))
+	if err != nil || ep.Protocol != "file" {
+		return "", false
+	}
+	return ep.Path, true
+}
+
+// fetchLocal fetches the local repository 'srcPath' into the repository 'dstPath' in process,
+// the objects are copied, the references matching the refspecs 'refSpecs' are updated and all the tags are fetched.
+func fetchLocal(dstPath, srcPath string, refSpecs []config.RefSpec) error {
+	src, err := git.PlainOpen(srcPath)
+	if err != nil {
+		return err
+	}
+	srcDir, err := gitDir(src)
+	if err != nil {
+		return err
+	}
+	dst, err := git.PlainOpen(dstPath)
+	if err != nil {
+		return err
+	}
+	dstDir, err := gitDir(dst)
+	if err != nil {
+		return err
+	}
+	if err := copyObjects(filepath.Join(srcDir, "objects"), filepath.Join(dstDir, "objects")); err != nil {
+		return err
+	}
+
+	// The repository is opened again to load the copied packfiles.
+	if dst, err = git.PlainOpen(dstPath); err != nil {
+		return err
+	}
+	refs, err := src.References()
+	if err != nil {
+		return err
+	}
+	defer refs.Close()
+	return refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if ref.Name().IsTag() {
+			return dst.Storer.SetReference(ref)
+		}
+		for _, refSpec := range refSpecs {
+			if refSpec.Match(ref.Name()) {
+				return dst.Storer.SetReference(plumbing.NewHashReference(refSpec.Dst(ref.Name()), ref.Hash()))
+			}
+		}
+		return nil
+	})
+}
+
+// gitDir returns the git directory of the repository, it is the repository itself for the bare repository.
+func gitDir(repo *git.Repository) (string, error) {
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", errors.New("the repository is not stored in the file system")
+	}
+	return storage.Filesystem().Root(), nil
+}
+
+// copyObjects copies the loose objects and the packfiles in 'srcDir' into 'dstDir', the existing files are skipped.
+func copyObjects(srcDir, dstDir string) error {
+	return filepath.WalkDir(srcDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		// The alternates of the source repository are not valid in the destination.
+		if relPath == "info" && d.IsDir() {
+			return filepath.SkipDir
+		}
+		dstPath := filepath.Join(dstDir, relPath)
+		if d.IsDir() {
+			return os.MkdirAll(dstPath, 0755)
+		}
+		if _, err := os.Stat(dstPath); err == nil {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(dstPath, content, 0444)
+	})
+}
+
+// findPackagePathWithGoGit finds the package 'pkgName' in the tree of the commit 'hash' by go-git,
+// and returns the slash separated directory of the package relative to the repository root.
+// Only the blobs of the 'kcl.mod' files are read.
+func findPackagePathWithGoGit(repo *git.Repository, hash plumbing.Hash, pkgName string) (string, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", err
+	}
+
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if path.Base(name) != constants.KCL_MOD || !entry.Mode.IsFile() {
+			continue
+		}
+		file, err := tree.File(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read '%s': %w", name, err)
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return "", fmt.Errorf("failed to read '%s': %w", name, err)
+		}
+		if matchesPackageName(content, pkgName) {
+			return path.Dir(name), nil
+		}
+	}
+
+	return "", fmt.Errorf("package '%s' not found", pkgName)
+}
+
+// sparseDir returns the directory 'sparsePath' with the trailing slash,
+// so that the sparse checkout of go-git matching the prefixes does not check out the sibling directories with the same prefix.
+func sparseDir(sparsePath string) string {
+	return strings.TrimSuffix(sparsePath, "/") + "/"
+}
+
+// sparsePaths returns the directories checked out in the sparse checkout of the repository.
+func sparsePaths(repo *git.Repository) ([]string, error) {
+	dir, err := gitDir(repo)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "info", "sparse-checkout"))
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.Trim(strings.TrimSpace(line), "/"); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// setSparsePaths records the directories 'paths' checked out in the sparse checkout of the repository,
+// in the same config and file as the non-cone mode of the local git command.
+func setSparsePaths(repo *git.Repository, paths []string) error {
+	dir, err := gitDir(repo)
+	if err != nil {
+		return err
+	}
+	var content strings.Builder
+	for _, sparsePath := range paths {
+		content.WriteString("/" + sparseDir(sparsePath) + "\n")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "info"), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "info", "sparse-checkout"), []byte(content.String()), 0644); err != nil {
+		return err
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	cfg.Raw.Section("core").SetOption("sparseCheckout", "true")
+	return repo.SetConfig(cfg)
+}
+
+// addSparsePathWithGoGit adds the directory 'sparsePath' into the sparse checkout of the repository 'repoPath' by go-git,
+// the sparse checkout is disabled if 'sparsePath' is the repository root.
+func addSparsePathWithGoGit(repoPath, sparsePath string) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	if sparsePath == "." {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return err
+		}
+		if err := worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.HardReset}); err != nil {
+			return err
+		}
+		dir, err := gitDir(repo)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(dir, "info", "sparse-checkout")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		cfg, err := repo.Config()
+		if err != nil {
+			return err
+		}
+		cfg.Raw.Section("core").RemoveOption("sparseCheckout")
+		return repo.SetConfig(cfg)
+	}
+
+	paths, err := sparsePaths(repo)
+	if err != nil {
+		return err
+	}
+	paths = append(paths, sparsePath)
+	if err := checkoutSparse(repo, head.Hash(), paths); err != nil {
+		return err
+	}
+	return setSparsePaths(repo, paths)
+}
+
+// checkoutSparse checks out the files in the directories 'paths' of the commit 'hash' into the working tree,
+// and the other files are marked as skip-worktree in the index like the sparse checkout of the local git command.
+// The sparse checkout of go-git is not used, because it also skips the parent directories shared with the skipped files.
+func checkoutSparse(repo *git.Repository, hash plumbing.Hash, paths []string) error {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	// The index is reset to the commit without updating the working tree.
+	if err := worktree.Reset(&git.ResetOptions{Commit: hash, Mode: git.MixedReset}); err != nil {
+		return err
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	var dirs []string
+	for _, p := range paths {
+		dirs = append(dirs, sparseDir(p))
+	}
+	idx.SkipUnless(dirs)
+	if err := repo.Storer.SetIndex(idx); err != nil {
+		return err
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	root := worktree.Filesystem.Root()
+	for _, p := range paths {
+		dirTree, err := tree.Tree(p)
+		if err != nil {
+			return fmt.Errorf("failed to find '%s': %w", p, err)
+		}
+		err = dirTree.Files().ForEach(func(file *object.File) error {
+			return writeFile(filepath.Join(root, filepath.FromSlash(p), filepath.FromSlash(file.Name)), file)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes the file 'file' in the git tree into 'filePath', the symbolic links are created as the links.
+func writeFile(filePath string, file *object.File) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return err
+	}
+	_ = os.Remove(filePath)
+	if file.Mode == filemode.Symlink {
+		return os.Symlink(content, filePath)
+	}
+	perm := os.FileMode(0644)
+	if file.Mode == filemode.Executable {
+		perm = 0755
+	}
+	return os.WriteFile(filePath, []byte(content), perm)
+}
+
+// checkoutFromBareWithGoGit points the HEAD of the bare repository to the branch or tag 'reference',
+// or detaches the HEAD at the commit by go-git.
+func (cloneOpts *CloneOptions) checkoutFromBareWithGoGit(reference string) error {
+	repo, err := git.PlainOpen(cloneOpts.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	head := plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.ReferenceName(reference))
+	if cloneOpts.Commit != "" {
+		hash, err := repo.ResolveRevision(plumbing.Revision(reference))
+		if err != nil {
+			return fmt.Errorf("failed to update HEAD in bare repository: %w", err)
+		}
+		head = plumbing.NewHashReference(plumbing.HEAD, *hash)
+	} else if _, err := repo.Reference(head.Target(), false); err != nil {
+		return fmt.Errorf("failed to update HEAD in bare repository: %w", err)
+	}
+
+	return repo.Storer.SetReference(head)
+}
+
+// fetchWithGoGit fetches the latest changes from the remote 'origin' of the repository 'dir' by go-git,
+// the refspecs of the remote are used if no refspecs are specified.
+func fetchWithGoGit(ctx context.Context, dir string, cred *Credential, refSpecs []config.RefSpec) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+
+	if localPath, ok := localRepoPath(remote.Config().URLs[0]); ok {
+		if len(refSpecs) == 0 {
+			refSpecs = remote.Config().Fetch
+		}
+		if err := fetchLocal(dir, localPath, refSpecs); err != nil {
+			return fmt.Errorf("failed to fetch latest changes: %w", err)
+		}
+		return nil
+	}
+
+	auth, err := authMethod(cred, remote.Config().URLs[0])
+	if err != nil {
+		return err
+	}
+	ca, err := caBundle(cred)
+	if err != nil {
+		return err
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		RefSpecs: refSpecs,
+		Auth:     auth,
+		CABundle: ca,
+		Tags:     git.AllTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch latest changes: %w", err)
+	}
+	return nil
+}
+
+// parseRefSpecs parses the fetch arguments 'args' as the refspecs.
+func parseRefSpecs(args []string) ([]config.RefSpec, error) {
+	var refSpecs []config.RefSpec
+	for _, arg := range args {
+		refSpec := config.RefSpec(arg)
+		if strings.HasPrefix(arg, "-") || refSpec.Validate() != nil {
+			return nil, fmt.Errorf("unsupported fetch argument '%s', only the refspecs are supported", arg)
+		}
+		refSpecs = append(refSpecs, refSpec)
+	}
+	return refSpecs, nil
+}
+
+// listRemoteTagsWithGoGit lists the names of the tags in the remote repository 'repoUrl' by go-git.
+func listRemoteTagsWithGoGit(ctx context.Context, repoUrl string, cred *Credential) ([]string, error) {
+	// The file transport of go-git runs 'git-upload-pack' of the local git command,
+	// so the tags of the local repository are read in place.
+	if localPath, ok := localRepoPath(repoUrl); ok {
+		return listLocalTags(localPath)
+	}
+
+	repoUrl = NormalizeScpLikeUrl(repoUrl)
+	auth, err := authMethod(cred, repoUrl)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := caBundle(cred)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{repoUrl},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth, CABundle: ca, PeelingOption: git.IgnorePeeled})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of '%s': %w", repoUrl, err)
+	}
+
+	var tags []string
+	for _, ref := range refs {
+		if ref.Name().IsTag() {
+			tags = append(tags, ref.Name().Short())
+		}
+	}
+	return tags, nil
+}
+
+// listLocalTags lists the names of the tags in the local repository 'repoPath'.
+func listLocalTags(repoPath string) ([]string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of '%s': %w", repoPath, err)
+	}
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of '%s': %w", repoPath, err)
+	}
+	defer refs.Close()
+
+	var tags []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of '%s': %w", repoPath, err)
+	}
+	return tags, nil
+}
+
+// setOriginURL sets the url of the remote 'origin' of the repository 'repoPath' to 'originURL'.
+func setOriginURL(repoPath, originURL string) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return err
+	}
+	remote, ok := cfg.Remotes[git.DefaultRemoteName]
+	if !ok {
+		return fmt.Errorf("remote '%s' not found", git.DefaultRemoteName)
+	}
+	remote.URLs = []string{originURL}
+	return repo.SetConfig(cfg)
+}
+
+// updateSubmodulesWithGoGit clones the submodules of the repository 'repoPath' recursively by go-git.
+// The authentication 'auth' is only sent to the submodules on the same host as 'upstreamURL'.
+func updateSubmodulesWithGoGit(repoPath, upstreamURL string, auth transport.AuthMethod) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return err
+	}
+	return updateSubmodules(repo, hostOf(upstreamURL), auth, git.DefaultSubmoduleRecursionDepth)
+}
+
+// updateSubmodules initializes and updates the submodules of 'repo' and their submodules until the 'depth'.
+func updateSubmodules(repo *git.Repository, upstreamHost string, auth transport.AuthMethod, depth git.SubmoduleRescursivity) error {
+	if depth == 0 {
+		return nil
+	}
+
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return err
+	}
+
+	for _, submodule := range submodules {
+		submoduleConfig := submodule.Config()
+		submoduleConfig.URL = resolveSubmoduleURL(remote.Config().URLs[0], submoduleConfig.URL)
+
+		var submoduleAuth transport.AuthMethod
+		if hostOf(submoduleConfig.URL) == upstreamHost {
+			submoduleAuth = auth
+		}
+		if err := submodule.Update(&git.SubmoduleUpdateOptions{Init: true, Auth: submoduleAuth}); err != nil {
+			return fmt.Errorf("failed to update the submodule '%s': %w", submoduleConfig.Name, err)
+		}
+
+		submoduleRepo, err := submodule.Repository()
+		if err != nil {
+			return err
+		}
+		if err := updateSubmodules(submoduleRepo, upstreamHost, auth, depth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveSubmoduleURL resolves the relative url 'submoduleURL' by the url 'parentURL' of the superproject like git,
+// e.g. '../lib.git' in 'https://github.com/kcl-lang/repo.git' is resolved to 'https://github.com/kcl-lang/lib.git'.
+func resolveSubmoduleURL(parentURL, submoduleURL string) string {
+	if !strings.HasPrefix(submoduleURL, "./") && !strings.HasPrefix(submoduleURL, "../") {
+		return submoduleURL
+	}
+
+	// The single letter scheme is the drive of the windows path.
+	if u, err := url.Parse(NormalizeScpLikeUrl(parentURL)); err == nil && len(u.Scheme) > 1 {
+		u.Path = path.Join(u.Path, submoduleURL)
+		return u.String()
+	}
+	return filepath.Join(parentURL, filepath.FromSlash(submoduleURL))
+}
+
+// hostOf returns the host of the git url 'repoUrl', it is empty for the local paths.
+func hostOf(repoUrl string) string {
+	ep, err := transport.NewEndpoint(NormalizeScpLikeUrl(repoUrl))
+	if err != nil {
+		return ""
+	}
+	return ep.Host
+}
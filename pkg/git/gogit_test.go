@@ -0,0 +1,95 @@
+package git
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"gotest.tools/v3/assert"
+	"kcl-lang.io/kpm/pkg/features"
+)
+
+// enableSystemGit uses the local git command instead of go-git in the test.
+func enableSystemGit(t *testing.T) {
+	features.Enable(features.SupportSystemGit)
+	t.Cleanup(func() {
+		features.Disable(features.SupportSystemGit)
+	})
+}
+
+func TestCloneBareWithGoGit(t *testing.T) {
+	bareDir, commits := initTestRepo(t)
+	output, err := exec.Command("git", "-C", bareDir, "branch", "dev", commits[0]).CombinedOutput()
+	assert.NilError(t, err, string(output))
+
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	repo, err := CloneWithOpts(WithRepoURL(bareDir), WithLocalPath(cacheDir), WithBare(true))
+	assert.NilError(t, err)
+	assert.Assert(t, IsGitBareRepo(cacheDir))
+	assert.Assert(t, !IsGitBareRepo(t.TempDir()))
+
+	head, err := repo.Reference(plumbing.HEAD, false)
+	assert.NilError(t, err)
+	assert.Equal(t, head.Target(), plumbing.NewBranchReferenceName("main"))
+	dev, err := repo.Reference(plumbing.NewBranchReferenceName("dev"), true)
+	assert.NilError(t, err)
+	assert.Equal(t, dev.Hash().String(), commits[0])
+	_, err = repo.Reference(plumbing.NewTagReferenceName("v0.0.1"), true)
+	assert.NilError(t, err)
+
+	// The working copy is cloned from the bare repository in the cache.
+	repo, err = CloneWithOpts(WithRepoURL(cacheDir), WithLocalPath(filepath.Join(t.TempDir(), "dev")), WithBranch("dev"))
+	assert.NilError(t, err)
+	localHead, err := repo.Head()
+	assert.NilError(t, err)
+	assert.Equal(t, localHead.Hash().String(), commits[0])
+}
+
+func TestFetchWithGoGit(t *testing.T) {
+	bareDir, commits := initTestRepo(t)
+
+	// The bare repository cloned by the local git command has no refspecs of the remote.
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	output, err := exec.Command("git", "clone", "--bare", bareDir, cacheDir).CombinedOutput()
+	assert.NilError(t, err, string(output))
+
+	output, err = exec.Command("git", "-C", bareDir, "tag", "v0.0.2", commits[1]).CombinedOutput()
+	assert.NilError(t, err, string(output))
+	output, err = exec.Command("git", "-C", bareDir, "branch", "dev", commits[0]).CombinedOutput()
+	assert.NilError(t, err, string(output))
+
+	assert.NilError(t, Fetch(cacheDir))
+	output, err = exec.Command("git", "-C", cacheDir, "rev-parse", "v0.0.2", "dev").CombinedOutput()
+	assert.NilError(t, err, string(output))
+	assert.Equal(t, string(output), commits[1]+"\n"+commits[0]+"\n")
+
+	assert.ErrorContains(t, Fetch(cacheDir, "--tags"), "unsupported fetch argument '--tags'")
+}
+
+func TestCheckoutFromBareWithGoGit(t *testing.T) {
+	bareDir, commits := initTestRepo(t)
+	cacheDir := filepath.Join(t.TempDir(), "cache")
+	repo, err := CloneWithOpts(WithRepoURL(bareDir), WithLocalPath(cacheDir), WithBare(true))
+	assert.NilError(t, err)
+
+	assert.NilError(t, (&CloneOptions{Bare: true, LocalPath: cacheDir, Tag: "v0.0.1"}).CheckoutFromBare())
+	head, err := repo.Reference(plumbing.HEAD, false)
+	assert.NilError(t, err)
+	assert.Equal(t, head.Target(), plumbing.NewTagReferenceName("v0.0.1"))
+
+	assert.NilError(t, (&CloneOptions{Bare: true, LocalPath: cacheDir, Commit: commits[0][:7]}).CheckoutFromBare())
+	head, err = repo.Reference(plumbing.HEAD, false)
+	assert.NilError(t, err)
+	assert.Equal(t, head.Hash().String(), commits[0])
+
+	err = (&CloneOptions{Bare: true, LocalPath: cacheDir, Branch: "notexist"}).CheckoutFromBare()
+	assert.ErrorContains(t, err, "failed to update HEAD in bare repository")
+}
+
+func TestResolveSubmoduleURL(t *testing.T) {
+	assert.Equal(t, resolveSubmoduleURL("https://github.com/kcl-lang/repo.git", "../lib.git"), "https://github.com/kcl-lang/lib.git")
+	assert.Equal(t, resolveSubmoduleURL("git@github.com:kcl-lang/repo.git", "./lib"), "ssh://git@github.com/kcl-lang/repo.git/lib")
+	assert.Equal(t, resolveSubmoduleURL(filepath.Join("repos", "repo.git"), "../lib.git"), filepath.Join("repos", "lib.git"))
+	assert.Equal(t, resolveSubmoduleURL("https://github.com/kcl-lang/repo.git", "https://gitlab.com/kcl-lang/lib.git"), "https://gitlab.com/kcl-lang/lib.git")
+}
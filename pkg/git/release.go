@@ -0,0 +1,351 @@
+// This file mainly provides the release discovery of the git repositories hosted on different platforms.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"kcl-lang.io/kpm/pkg/constants"
+)
+
+// ReleaseLister lists the release tags of a git repository.
+type ReleaseLister interface {
+	ListReleases(repoUrl string) ([]string, error)
+}
+
+// ContextReleaseLister is implemented by the ReleaseLister whose listing can be aborted by a context.
+type ContextReleaseLister interface {
+	ListReleasesWithContext(ctx context.Context, repoUrl string) ([]string, error)
+}
+
+// GithubReleaseLister lists the releases by the GitHub REST API.
+type GithubReleaseLister struct {
+	// ApiUrl is the url of the GitHub REST API, 'https://api.github.com' by default.
+	ApiUrl string
+	// Credential is sent to the REST API to access the private repositories and raise the rate limit.
+	Credential *Credential
+}
+
+// GitlabReleaseLister lists the releases by the GitLab REST API '<scheme>://<host>/api/v4'.
+type GitlabReleaseLister struct {
+	// Credential is sent to the REST API, the password is used as the personal access token.
+	Credential *Credential
+}
+
+// GiteaReleaseLister lists the releases by the Gitea REST API '<scheme>://<host>/api/v1'.
+type GiteaReleaseLister struct {
+	// Credential is sent to the REST API to access the private repositories.
+	Credential *Credential
+}
+
+// TagReleaseLister lists the semver tags of the remote repository, it works for any git repository.
+type TagReleaseLister struct {
+	// Credential is used to access the repository.
+	Credential *Credential
+}
+
+// GITHUB_API_URL is the default url of the GitHub REST API.
+const GITHUB_API_URL = "https://api.github.com"
+
+// releaseListers are the release listers of the git hosts,
+// the repositories on the other hosts are listed by the TagReleaseLister.
+var releaseListers = map[string]ReleaseLister{
+	"github.com":   &GithubReleaseLister{},
+	"gitlab.com":   &GitlabReleaseLister{},
+	"gitea.com":    &GiteaReleaseLister{},
+	"codeberg.org": &GiteaReleaseLister{},
+}
+
+var releaseListersMu sync.RWMutex
+
+// RegisterReleaseLister registers the release lister for the git host, e.g. a self-hosted GitLab.
+func RegisterReleaseLister(host string, lister ReleaseLister) {
+	releaseListersMu.Lock()
+	defer releaseListersMu.Unlock()
+	releaseListers[host] = lister
+}
+
+// GetReleaseLister returns the release lister for the host of 'repoUrl',
+// and the TagReleaseLister is returned if no release lister is registered for the host.
+func GetReleaseLister(repoUrl string) ReleaseLister {
+	u, err := url.Parse(NormalizeScpLikeUrl(repoUrl))
+	if err == nil {
+		releaseListersMu.RLock()
+		defer releaseListersMu.RUnlock()
+		if lister, ok := releaseListers[u.Host]; ok {
+			return lister
+		}
+		if lister, ok := releaseListers[u.Hostname()]; ok {
+			return lister
+		}
+	}
+	return &TagReleaseLister{}
+}
+
+// ReleaseListerWithCredential returns a copy of the built-in release lister 'lister' with the credential 'cred' of the git host,
+// the registered listers are shared, so they are not modified. The other listers are returned as they are.
+func ReleaseListerWithCredential(lister ReleaseLister, cred *Credential) ReleaseLister {
+	switch l := lister.(type) {
+	case *GithubReleaseLister:
+		withCred := *l
+		withCred.Credential = cred
+		return &withCred
+	case *GitlabReleaseLister:
+		withCred := *l
+		withCred.Credential = cred
+		return &withCred
+	case *GiteaReleaseLister:
+		withCred := *l
+		withCred.Credential = cred
+		return &withCred
+	case *TagReleaseLister:
+		withCred := *l
+		withCred.Credential = cred
+		return &withCred
+	}
+	return lister
+}
+
+// GetAllReleases fetches all releases of the git repository 'repoUrl' by the release lister selected by the host.
+func GetAllReleases(repoUrl string) ([]string, error) {
+	return GetAllReleasesWithContext(context.Background(), repoUrl)
+}
+
+// GetAllReleasesWithContext is the same as GetAllReleases, and the listing is aborted when the context 'ctx' is canceled.
+func GetAllReleasesWithContext(ctx context.Context, repoUrl string) ([]string, error) {
+	return ListReleasesWithContext(ctx, GetReleaseLister(repoUrl), repoUrl)
+}
+
+// ListReleasesWithContext lists the releases of 'repoUrl' by 'lister' with the context 'ctx',
+// the listers not implementing ContextReleaseLister are not aborted by the context.
+func ListReleasesWithContext(ctx context.Context, lister ReleaseLister, repoUrl string) ([]string, error) {
+	if contextLister, ok := lister.(ContextReleaseLister); ok {
+		return contextLister.ListReleasesWithContext(ctx, repoUrl)
+	}
+	return lister.ListReleases(repoUrl)
+}
+
+// ListReleases fetches all releases from a GitHub repository.
+func (l *GithubReleaseLister) ListReleases(repoUrl string) ([]string, error) {
+	return l.ListReleasesWithContext(context.Background(), repoUrl)
+}
+
+// ListReleasesWithContext is the same as ListReleases, and the requests are aborted when the context 'ctx' is canceled.
+func (l *GithubReleaseLister) ListReleasesWithContext(ctx context.Context, repoUrl string) ([]string, error) {
+	_, repoPath, err := parseRepoUrl(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	apiUrl := l.ApiUrl
+	if apiUrl == "" {
+		apiUrl = GITHUB_API_URL
+	}
+
+	return listApiReleases(ctx, fmt.Sprintf("%s/repos/%s/releases?per_page=100&page=1", strings.TrimSuffix(apiUrl, "/"), repoPath), apiAuthorization(l.Credential, false))
+}
+
+// ListReleases fetches all releases from a GitLab repository.
+func (l *GitlabReleaseLister) ListReleases(repoUrl string) ([]string, error) {
+	return l.ListReleasesWithContext(context.Background(), repoUrl)
+}
+
+// ListReleasesWithContext is the same as ListReleases, and the requests are aborted when the context 'ctx' is canceled.
+func (l *GitlabReleaseLister) ListReleasesWithContext(ctx context.Context, repoUrl string) ([]string, error) {
+	base, repoPath, err := parseRepoUrl(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	// The GitLab API uses the url-encoded path of the project with the namespaces as the project id.
+	return listApiReleases(ctx, fmt.Sprintf("%s/api/v4/projects/%s/releases?per_page=100&page=1", base, url.PathEscape(repoPath)), apiAuthorization(l.Credential, true))
+}
+
+// ListReleases fetches all releases from a Gitea repository.
+func (l *GiteaReleaseLister) ListReleases(repoUrl string) ([]string, error) {
+	return l.ListReleasesWithContext(context.Background(), repoUrl)
+}
+
+// ListReleasesWithContext is the same as ListReleases, and the requests are aborted when the context 'ctx' is canceled.
+func (l *GiteaReleaseLister) ListReleasesWithContext(ctx context.Context, repoUrl string) ([]string, error) {
+	base, repoPath, err := parseRepoUrl(repoUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	return listApiReleases(ctx, fmt.Sprintf("%s/api/v1/repos/%s/releases?limit=50&page=1", base, repoPath), apiAuthorization(l.Credential, false))
+}
+
+// ListReleases lists the semver tags of a git repository by 'git ls-remote'.
+func (l *TagReleaseLister) ListReleases(repoUrl string) ([]string, error) {
+	return l.ListReleasesWithContext(context.Background(), repoUrl)
+}
+
+// ListReleasesWithContext is the same as ListReleases, and the listing is aborted when the context 'ctx' is canceled.
+func (l *TagReleaseLister) ListReleasesWithContext(ctx context.Context, repoUrl string) ([]string, error) {
+	var tags []string
+	var err error
+	if UseSystemGit() {
+		tags, err = listRemoteTags(ctx, repoUrl, l.Credential)
+	} else {
+		tags, err = listRemoteTagsWithGoGit(ctx, repoUrl, l.Credential)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var releaseTags []string
+	for _, tag := range tags {
+		// Only the semver tags are releases, e.g. 'v0.1.0' or '0.1.0'.
+		if _, err := version.NewSemver(tag); err != nil {
+			continue
+		}
+		releaseTags = append(releaseTags, tag)
+	}
+
+	return releaseTags, nil
+}
+
+// listRemoteTags lists the names of the tags in the remote repository 'repoUrl' by 'git ls-remote'.
+func listRemoteTags(ctx context.Context, repoUrl string, cred *Credential) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--tags", "--refs", NormalizeScpLikeUrl(repoUrl))
+	cmd.Env = gitEnv(cred)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of '%s': %w, %s", repoUrl, err, stderr.String())
+	}
+
+	var tags []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		_, ref, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		tags = append(tags, strings.TrimPrefix(ref, "refs/tags/"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// parseRepoUrl returns the base url '<scheme>://<host>' of the git host and the path '<owner>/<repo>' of the repository.
+// The base url of the ssh repository is 'https://<host>'.
+func parseRepoUrl(repoUrl string) (string, string, error) {
+	u, err := url.Parse(NormalizeScpLikeUrl(repoUrl))
+	if err != nil {
+		return "", "", err
+	}
+
+	repoPath := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if u.Host == "" || !strings.Contains(repoPath, "/") {
+		return "", "", fmt.Errorf("invalid git repository url '%s'", repoUrl)
+	}
+
+	scheme := u.Scheme
+	if scheme != constants.HttpsScheme && scheme != "http" {
+		scheme = constants.HttpsScheme
+	}
+	host := u.Host
+	if u.Scheme == constants.SshScheme {
+		host = u.Hostname()
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, host), repoPath, nil
+}
+
+// apiAuthorization returns the 'Authorization' header of the REST API requests with the credential 'cred',
+// the password is sent as the bearer token instead of the basic authentication if 'passwordAsToken' is true.
+func apiAuthorization(cred *Credential, passwordAsToken bool) string {
+	if cred == nil {
+		return ""
+	}
+	if cred.BearerToken != "" {
+		return "Bearer " + cred.BearerToken
+	}
+	if cred.Password == "" {
+		return ""
+	}
+	if passwordAsToken {
+		return "Bearer " + cred.Password
+	}
+	username := cred.Username
+	if username == "" {
+		// The token is used as the password, and most of the git hosts accept any non-empty username.
+		username = "oauth2"
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+cred.Password))
+}
+
+// listApiReleases fetches all the pages of the releases from the REST API 'apiUrl' with the 'Authorization' header 'authorization',
+// the next page is read from the 'Link' header, which is supported by GitHub, GitLab and Gitea.
+func listApiReleases(ctx context.Context, apiUrl, authorization string) ([]string, error) {
+	client := http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	// The credential is only sent to the host of the REST API, not to the other hosts in the 'Link' header.
+	var apiHost string
+	if u, err := url.Parse(apiUrl); err == nil {
+		apiHost = u.Host
+	}
+
+	var releaseTags []string
+
+	for apiUrl != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+		if authorization != "" && req.URL.Host == apiHost {
+			req.Header.Set("Authorization", authorization)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch releases from '%s', status code: %d", apiUrl, resp.StatusCode)
+		}
+
+		// Decode the JSON response into a slice of releases
+		var releases []GitHubRelease
+		err = json.NewDecoder(resp.Body).Decode(&releases)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		// Extract tag names from the releases
+		for _, release := range releases {
+			releaseTags = append(releaseTags, release.TagName)
+		}
+
+		// Read the `Link` header to get the next page URL, if available
+		apiUrl, err = parseNextPageURL(resp.Header.Get("Link"))
+		if err != nil {
+			apiUrl = ""
+		}
+	}
+
+	return releaseTags, nil
+}
@@ -0,0 +1,143 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"sort"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// newTestReleaseServer returns a server responding the releases in two pages at 'path'.
+func newTestReleaseServer(t *testing.T, path string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != path {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"tag_name": "v0.0.1"}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2>; rel="next", <%s%s?page=2>; rel="last"`, server.URL, path, server.URL, path))
+		fmt.Fprint(w, `[{"tag_name": "v0.0.3"}, {"tag_name": "v0.0.2"}]`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGithubReleaseLister(t *testing.T) {
+	server := newTestReleaseServer(t, "/repos/kcl-lang/kpm/releases")
+	releases, err := (&GithubReleaseLister{ApiUrl: server.URL}).ListReleases("https://github.com/kcl-lang/kpm.git")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, releases, []string{"v0.0.3", "v0.0.2", "v0.0.1"})
+}
+
+func TestGitlabReleaseLister(t *testing.T) {
+	server := newTestReleaseServer(t, "/api/v4/projects/kcl-lang%2Fsub%2Fkpm/releases")
+	releases, err := (&GitlabReleaseLister{}).ListReleases(server.URL + "/kcl-lang/sub/kpm.git")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, releases, []string{"v0.0.3", "v0.0.2", "v0.0.1"})
+}
+
+func TestGiteaReleaseLister(t *testing.T) {
+	server := newTestReleaseServer(t, "/api/v1/repos/kcl-lang/kpm/releases")
+	releases, err := (&GiteaReleaseLister{}).ListReleases(server.URL + "/kcl-lang/kpm")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, releases, []string{"v0.0.3", "v0.0.2", "v0.0.1"})
+
+	_, err = (&GiteaReleaseLister{}).ListReleases(server.URL + "/kcl-lang/notexist")
+	assert.ErrorContains(t, err, "status code: 404")
+}
+
+func TestTagReleaseLister(t *testing.T) {
+	bareDir, commits := initTestRepo(t)
+	for _, tag := range []string{"0.0.2", "latest"} {
+		output, err := exec.Command("git", "-C", bareDir, "tag", tag, commits[1]).CombinedOutput()
+		assert.NilError(t, err, string(output))
+	}
+
+	t.Run("withoutSystemGit", func(t *testing.T) {
+		// The tags of the local repository are listed without the local git command.
+		t.Setenv("PATH", t.TempDir())
+		releases, err := (&TagReleaseLister{}).ListReleases(bareDir)
+		assert.NilError(t, err)
+		sort.Strings(releases)
+		assert.DeepEqual(t, releases, []string{"0.0.2", "v0.0.1"})
+	})
+
+	enableSystemGit(t)
+	releases, err := (&TagReleaseLister{}).ListReleases(bareDir)
+	assert.NilError(t, err)
+	sort.Strings(releases)
+	assert.DeepEqual(t, releases, []string{"0.0.2", "v0.0.1"})
+}
+
+func TestGetReleaseLister(t *testing.T) {
+	assert.DeepEqual(t, GetReleaseLister("https://github.com/kcl-lang/kpm"), ReleaseLister(&GithubReleaseLister{}))
+	assert.DeepEqual(t, GetReleaseLister("git@gitlab.com:kcl-lang/kpm.git"), ReleaseLister(&GitlabReleaseLister{}))
+	assert.DeepEqual(t, GetReleaseLister("https://codeberg.org/kcl-lang/kpm"), ReleaseLister(&GiteaReleaseLister{}))
+	assert.DeepEqual(t, GetReleaseLister("https://git.example.com/kcl-lang/kpm"), ReleaseLister(&TagReleaseLister{}))
+
+	RegisterReleaseLister("git.example.com", &GitlabReleaseLister{})
+	defer func() {
+		releaseListersMu.Lock()
+		delete(releaseListers, "git.example.com")
+		releaseListersMu.Unlock()
+	}()
+	assert.DeepEqual(t, GetReleaseLister("https://git.example.com/kcl-lang/kpm"), ReleaseLister(&GitlabReleaseLister{}))
+}
+
+// staticReleaseLister is a ReleaseLister without the context.
+type staticReleaseLister struct{}
+
+func (l *staticReleaseLister) ListReleases(repoUrl string) ([]string, error) {
+	return []string{"v0.0.1"}, nil
+}
+
+func TestListReleasesWithContext(t *testing.T) {
+	server := newTestReleaseServer(t, "/api/v1/repos/kcl-lang/kpm/releases")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ListReleasesWithContext(ctx, &GiteaReleaseLister{}, server.URL+"/kcl-lang/kpm")
+	assert.Assert(t, errors.Is(err, context.Canceled))
+
+	// The listers without the context are called ignoring the context.
+	releases, err := ListReleasesWithContext(ctx, &staticReleaseLister{}, server.URL+"/kcl-lang/kpm")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, releases, []string{"v0.0.1"})
+}
+
+func TestReleaseListerWithCredential(t *testing.T) {
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `[{"tag_name": "v0.0.1"}]`)
+	}))
+	t.Cleanup(server.Close)
+
+	cred := &Credential{Username: "kpm", Password: "token"}
+	lister := ReleaseListerWithCredential(GetReleaseLister("https://github.com/kcl-lang/kpm"), cred)
+	lister.(*GithubReleaseLister).ApiUrl = server.URL
+	_, err := lister.ListReleases("https://github.com/kcl-lang/kpm")
+	assert.NilError(t, err)
+	// The registered lister is not modified.
+	assert.DeepEqual(t, GetReleaseLister("https://github.com/kcl-lang/kpm"), ReleaseLister(&GithubReleaseLister{}))
+
+	// The password is sent as the personal access token of GitLab.
+	_, err = ReleaseListerWithCredential(&GitlabReleaseLister{}, cred).ListReleases(server.URL + "/kcl-lang/kpm")
+	assert.NilError(t, err)
+	_, err = ReleaseListerWithCredential(&GiteaReleaseLister{}, &Credential{BearerToken: "bearer"}).ListReleases(server.URL + "/kcl-lang/kpm")
+	assert.NilError(t, err)
+	_, err = (&GiteaReleaseLister{}).ListReleases(server.URL + "/kcl-lang/kpm")
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, authorizations, []string{"Basic a3BtOnRva2Vu", "Bearer token", "Bearer bearer", ""})
+}
@@ -0,0 +1,208 @@
+// This file mainly provides the partial clone and sparse checkout of the packages in the git repositories,
+// only the directory of the package is checked out, and the blobs out of it are not downloaded from the remote.
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+	"kcl-lang.io/kpm/pkg/constants"
+)
+
+// PARTIAL_CLONE_FILTER is the filter of the partial clone, the blobs are downloaded on demand.
+const PARTIAL_CLONE_FILTER = "blob:none"
+
+// WithPackage sets the package name for CloneOptions,
+// only the directory of the package is checked out if it is set.
+func WithPackage(pkgName string) CloneOption {
+	return func(o *CloneOptions) {
+		o.Package = pkgName
+	}
+}
+
+// WithSparsePath sets the directory checked out in the sparse checkout for CloneOptions,
+// it is the path of the package relative to the repository root, the package is not searched if it is set.
+func WithSparsePath(sparsePath string) CloneOption {
+	return func(o *CloneOptions) {
+		o.SparsePath = sparsePath
+	}
+}
+
+// cloneSparse clones the git repository with the partial clone,
+// and checks out the directory of the package in the sparse checkout.
+func (cloneOpts *CloneOptions) cloneSparse() (*git.Repository, error) {
+	cmdArgs := []string{"clone", "--filter=" + PARTIAL_CLONE_FILTER, "--no-checkout"}
+	if cloneOpts.Branch != "" {
+		cmdArgs = append(cmdArgs, "--branch", cloneOpts.Branch)
+	} else if cloneOpts.Tag != "" {
+		cmdArgs = append(cmdArgs, "--branch", cloneOpts.Tag)
+	}
+	cmdArgs = append(cmdArgs, cloneOpts.RepoURL, cloneOpts.LocalPath)
+	if _, err := runGitContext(cloneOpts.ctx(), cloneOpts.Credential, cmdArgs...); err != nil {
+		return nil, fmt.Errorf("failed to clone repository: %w", err)
+	}
+
+	ref := "HEAD"
+	if cloneOpts.Commit != "" {
+		ref = cloneOpts.Commit
+	}
+
+	sparsePath := cloneOpts.SparsePath
+	if sparsePath == "" {
+		var err error
+		sparsePath, err = FindPackagePath(cloneOpts.LocalPath, ref, cloneOpts.Package, cloneOpts.Credential)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The package in the repository root needs all the files, so the sparse checkout is not used.
+	if sparsePath != "." {
+		if _, err := runGit(nil, "-C", cloneOpts.LocalPath, "sparse-checkout", "set", "--", sparsePath); err != nil {
+			return nil, fmt.Errorf("failed to set sparse checkout '%s': %w", sparsePath, err)
+		}
+	}
+
+	checkoutArgs := []string{"-C", cloneOpts.LocalPath, "checkout", "--quiet"}
+	if cloneOpts.Commit != "" {
+		checkoutArgs = append(checkoutArgs, cloneOpts.Commit)
+	}
+	// The missing blobs are fetched from the remote during the checkout.
+	if _, err := runGitContext(cloneOpts.ctx(), cloneOpts.Credential, checkoutArgs...); err != nil {
+		return nil, fmt.Errorf("failed to checkout '%s': %w", ref, err)
+	}
+
+	return git.PlainOpen(cloneOpts.LocalPath)
+}
+
+// FindPackagePath finds the package 'pkgName' in the tree of 'ref' in the git repository 'repoPath',
+// and returns the slash separated directory of the package relative to the repository root.
+// Only the blobs of the 'kcl.mod' files are read, so it works in a partial clone without checking out the files,
+// and the missing blobs are fetched from the remote with the credential 'cred'.
+func FindPackagePath(repoPath, ref, pkgName string, cred *Credential) (string, error) {
+	if !UseSystemGit() {
+		repo, err := git.PlainOpen(repoPath)
+		if err != nil {
+			return "", err
+		}
+		hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+		if err != nil {
+			return "", fmt.Errorf("failed to list the files of '%s': %w", ref, err)
+		}
+		return findPackagePathWithGoGit(repo, *hash, pkgName)
+	}
+
+	output, err := runGit(nil, "-C", repoPath, "ls-tree", "-r", "-z", "--name-only", ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the files of '%s': %w", ref, err)
+	}
+
+	var kclModPaths []string
+	for _, file := range strings.Split(output, "\x00") {
+		if path.Base(file) == constants.KCL_MOD {
+			kclModPaths = append(kclModPaths, file)
+		}
+	}
+
+	for _, kclModPath := range kclModPaths {
+		content, err := runGit(cred, "-C", repoPath, "cat-file", "blob", ref+":"+kclModPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read '%s': %w", kclModPath, err)
+		}
+		if matchesPackageName(content, pkgName) {
+			return path.Dir(kclModPath), nil
+		}
+	}
+
+	return "", fmt.Errorf("package '%s' not found", pkgName)
+}
+
+// matchesPackageName checks whether the package name in the kcl.mod file content 'kclMod' is equal to 'pkgName'.
+func matchesPackageName(kclMod, pkgName string) bool {
+	var modFile struct {
+		Package struct {
+			Name string `toml:"name"`
+		} `toml:"package"`
+	}
+
+	if _, err := toml.Decode(kclMod, &modFile); err != nil {
+		return false
+	}
+
+	return modFile.Package.Name == pkgName
+}
+
+// IsSparseCheckout checks if the sparse checkout is enabled in the git repository 'repoPath'.
+func IsSparseCheckout(repoPath string) bool {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return false
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(cfg.Raw.Section("core").Option("sparseCheckout"), "true") {
+		return true
+	}
+
+	// 'git sparse-checkout' writes the config into 'config.worktree', which is not read by go-git.
+	file, err := os.Open(filepath.Join(repoPath, git.GitDirName, "config.worktree"))
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	worktreeCfg := gitconfig.New()
+	if err := gitconfig.NewDecoder(file).Decode(worktreeCfg); err != nil {
+		return false
+	}
+	return strings.EqualFold(worktreeCfg.Section("core").Option("sparseCheckout"), "true")
+}
+
+// AddSparsePath adds the directory 'sparsePath' into the sparse checkout of the git repository 'repoPath',
+// the missing blobs are fetched from the remote with the credential 'cred'.
+func AddSparsePath(repoPath, sparsePath string, cred *Credential) error {
+	if !UseSystemGit() {
+		return addSparsePathWithGoGit(repoPath, sparsePath)
+	}
+	// The package in the repository root needs all the files.
+	if sparsePath == "." {
+		_, err := runGit(cred, "-C", repoPath, "sparse-checkout", "disable")
+		return err
+	}
+	_, err := runGit(cred, "-C", repoPath, "sparse-checkout", "add", "--", sparsePath)
+	return err
+}
+
+// runGit runs the local git command with the credential 'cred' and returns the stdout.
+func runGit(cred *Credential, args ...string) (string, error) {
+	return runGitContext(context.Background(), cred, args...)
+}
+
+// runGitContext is the same as runGit, and the command is killed when the context 'ctx' is canceled.
+func runGitContext(ctx context.Context, cred *Credential, args ...string) (string, error) {
+	return runGitWithEnv(ctx, gitEnv(cred), args...)
+}
+
+// runGitWithEnv runs the local git command with the environment 'env' and returns the stdout.
+func runGitWithEnv(ctx context.Context, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w, %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
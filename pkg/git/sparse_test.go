@@ -0,0 +1,146 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// initTestMonorepo creates a bare git repository with the packages 'a' in 'pkgs/a' and 'b' in 'pkgs/b',
+// and returns the 'file://' url of the repository, which supports the partial clone.
+func initTestMonorepo(t *testing.T) string {
+	workDir := filepath.Join(t.TempDir(), "work")
+	bareDir := filepath.Join(t.TempDir(), "bare.git")
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=kpm", "GIT_AUTHOR_EMAIL=kpm@kcl-lang.io",
+			"GIT_COMMITTER_NAME=kpm", "GIT_COMMITTER_EMAIL=kpm@kcl-lang.io",
+		)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, string(output))
+	}
+
+	files := map[string]string{
+		"README.md":        "monorepo",
+		"pkgs/a/kcl.mod":   "[package]\nname = \"a\"\nversion = \"0.0.1\"\n",
+		"pkgs/a/main.k":    "a = 1",
+		"pkgs/b/kcl.mod":   "[package]\nname = \"b\"\nversion = \"0.0.1\"\n",
+		"pkgs/b/main.k":    "b = 1",
+		"pkgs/b/sub/sub.k": "sub = 1",
+	}
+	for name, content := range files {
+		path := filepath.Join(workDir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	runGit("init", "-b", "main", workDir)
+	runGit("-C", workDir, "add", ".")
+	runGit("-C", workDir, "commit", "-m", "init")
+	runGit("-C", workDir, "tag", "v0.0.1")
+	runGit("clone", "--bare", workDir, bareDir)
+	runGit("-C", bareDir, "config", "uploadpack.allowFilter", "true")
+
+	return "file://" + filepath.ToSlash(bareDir)
+}
+
+func TestCloneSparse(t *testing.T) {
+	enableSystemGit(t)
+	repoUrl := initTestMonorepo(t)
+
+	localPath := filepath.Join(t.TempDir(), "b")
+	_, err := CloneWithOpts(
+		WithRepoURL(repoUrl),
+		WithTag("v0.0.1"),
+		WithLocalPath(localPath),
+		WithPackage("b"),
+	)
+	assert.NilError(t, err)
+	assert.Assert(t, IsSparseCheckout(localPath))
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "b", "sub", "sub.k"))
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "a"))
+	assert.Assert(t, os.IsNotExist(err))
+	// The files in the repository root are always checked out in the cone mode.
+	_, err = os.Stat(filepath.Join(localPath, "README.md"))
+	assert.NilError(t, err)
+
+	// The blobs of the other packages are not downloaded in the partial clone.
+	output, err := exec.Command("git", "-C", localPath, "rev-list", "--objects", "--missing=print", "HEAD").Output()
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(output), "?"))
+
+	subdir, err := FindPackagePath(localPath, "HEAD", "a", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, subdir, "pkgs/a")
+	assert.NilError(t, AddSparsePath(localPath, subdir, nil))
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "a", "main.k"))
+	assert.NilError(t, err)
+
+	_, err = FindPackagePath(localPath, "HEAD", "notexist", nil)
+	assert.ErrorContains(t, err, "package 'notexist' not found")
+}
+
+func TestCloneSparseWithSparsePath(t *testing.T) {
+	enableSystemGit(t)
+	repoUrl := initTestMonorepo(t)
+
+	localPath := filepath.Join(t.TempDir(), "a")
+	repo, err := CloneWithOpts(
+		WithRepoURL(repoUrl),
+		WithBranch("main"),
+		WithLocalPath(localPath),
+		WithSparsePath("pkgs/a"),
+	)
+	assert.NilError(t, err)
+	head, err := repo.Head()
+	assert.NilError(t, err)
+	assert.Equal(t, head.Name().Short(), "main")
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "a", "main.k"))
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "b"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestCloneSparseWithGoGit(t *testing.T) {
+	repoUrl := initTestMonorepo(t)
+
+	localPath := filepath.Join(t.TempDir(), "b")
+	repo, err := CloneWithOpts(
+		WithRepoURL(repoUrl),
+		WithBranch("main"),
+		WithLocalPath(localPath),
+		WithPackage("b"),
+	)
+	assert.NilError(t, err)
+	head, err := repo.Head()
+	assert.NilError(t, err)
+	assert.Equal(t, head.Name().Short(), "main")
+	assert.Assert(t, IsSparseCheckout(localPath))
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "b", "sub", "sub.k"))
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "a"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	subdir, err := FindPackagePath(localPath, "HEAD", "a", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, subdir, "pkgs/a")
+	assert.NilError(t, AddSparsePath(localPath, subdir, nil))
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "a", "main.k"))
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(localPath, "pkgs", "b", "main.k"))
+	assert.NilError(t, err)
+
+	// The package in the repository root disables the sparse checkout.
+	assert.NilError(t, AddSparsePath(localPath, ".", nil))
+	assert.Assert(t, !IsSparseCheckout(localPath))
+	_, err = os.Stat(filepath.Join(localPath, "README.md"))
+	assert.NilError(t, err)
+
+	_, err = FindPackagePath(localPath, "HEAD", "notexist", nil)
+	assert.ErrorContains(t, err, "package 'notexist' not found")
+}
@@ -0,0 +1,74 @@
+// This file mainly provides the submodules and Git LFS objects of the cloned git repositories.
+package git
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// WithSubmodules sets the submodules flag for CloneOptions
+func WithSubmodules(submodules bool) CloneOption {
+	return func(o *CloneOptions) {
+		o.Submodules = submodules
+	}
+}
+
+// WithLfs sets the Git LFS flag for CloneOptions
+func WithLfs(lfs bool) CloneOption {
+	return func(o *CloneOptions) {
+		o.Lfs = lfs
+	}
+}
+
+// WithOriginURL sets the url of the upstream repository for CloneOptions
+func WithOriginURL(originURL string) CloneOption {
+	return func(o *CloneOptions) {
+		o.OriginURL = originURL
+	}
+}
+
+// updateSubmodulesAndLfs clones the submodules and downloads the Git LFS objects in the cloned working tree.
+// The https authentication of the credential is only sent to the upstream repository,
+// not to the other hosts of the submodules or the storage of the Git LFS objects.
+func (cloneOpts *CloneOptions) updateSubmodulesAndLfs() error {
+	if !cloneOpts.Submodules && !cloneOpts.Lfs {
+		return nil
+	}
+
+	upstreamURL := cloneOpts.RepoURL
+	if cloneOpts.OriginURL != "" {
+		upstreamURL = cloneOpts.OriginURL
+		if err := setOriginURL(cloneOpts.LocalPath, cloneOpts.OriginURL); err != nil {
+			return fmt.Errorf("failed to set the url of the remote 'origin' to '%s': %w", cloneOpts.OriginURL, err)
+		}
+	}
+	env := scopedGitEnv(cloneOpts.Credential, upstreamURL)
+
+	if cloneOpts.Lfs {
+		if _, err := exec.LookPath("git-lfs"); err != nil {
+			return fmt.Errorf("'git-lfs' is required to download the Git LFS objects of '%s': %w", cloneOpts.RepoURL, err)
+		}
+		if _, err := runGit(nil, "-C", cloneOpts.LocalPath, "lfs", "install", "--local"); err != nil {
+			return fmt.Errorf("failed to install Git LFS: %w", err)
+		}
+		if _, err := runGitWithEnv(cloneOpts.ctx(), env, "-C", cloneOpts.LocalPath, "lfs", "pull"); err != nil {
+			return fmt.Errorf("failed to download the Git LFS objects: %w", err)
+		}
+	}
+
+	if cloneOpts.Submodules && !UseSystemGit() {
+		auth, err := authMethod(cloneOpts.Credential, upstreamURL)
+		if err != nil {
+			return err
+		}
+		if err := updateSubmodulesWithGoGit(cloneOpts.LocalPath, upstreamURL, auth); err != nil {
+			return fmt.Errorf("failed to update the submodules: %w", err)
+		}
+	} else if cloneOpts.Submodules {
+		if _, err := runGitWithEnv(cloneOpts.ctx(), env, "-C", cloneOpts.LocalPath, "submodule", "update", "--init", "--recursive"); err != nil {
+			return fmt.Errorf("failed to update the submodules: %w", err)
+		}
+	}
+
+	return nil
+}
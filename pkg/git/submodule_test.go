@@ -0,0 +1,118 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// initTestSuperRepo creates the bare repositories 'super.git' and 'sub.git' in the same directory,
+// 'super.git' contains the package 'super' and the submodule 'lib' with the relative url '../sub.git'.
+func initTestSuperRepo(t *testing.T) string {
+	// The submodules are cloned from the local file system in the test.
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	reposDir := t.TempDir()
+	runGit := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=kpm", "GIT_AUTHOR_EMAIL=kpm@kcl-lang.io",
+			"GIT_COMMITTER_NAME=kpm", "GIT_COMMITTER_EMAIL=kpm@kcl-lang.io",
+		)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, string(output))
+	}
+
+	subWorkDir := filepath.Join(t.TempDir(), "sub")
+	assert.NilError(t, os.MkdirAll(subWorkDir, 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(subWorkDir, "lib.k"), []byte("lib = 1"), 0644))
+	runGit("init", "-b", "main", subWorkDir)
+	runGit("-C", subWorkDir, "add", ".")
+	runGit("-C", subWorkDir, "commit", "-m", "init")
+	runGit("clone", "--bare", subWorkDir, filepath.Join(reposDir, "sub.git"))
+
+	superWorkDir := filepath.Join(t.TempDir(), "super")
+	assert.NilError(t, os.MkdirAll(superWorkDir, 0755))
+	assert.NilError(t, os.WriteFile(filepath.Join(superWorkDir, "kcl.mod"), []byte("[package]\nname = \"super\"\nversion = \"0.0.1\"\n"), 0644))
+	runGit("init", "-b", "main", superWorkDir)
+	runGit("-C", superWorkDir, "submodule", "add", filepath.Join(reposDir, "sub.git"), "lib")
+	runGit("-C", superWorkDir, "config", "-f", ".gitmodules", "submodule.lib.url", "../sub.git")
+	runGit("-C", superWorkDir, "add", ".")
+	runGit("-C", superWorkDir, "commit", "-m", "init")
+	runGit("clone", "--bare", superWorkDir, filepath.Join(reposDir, "super.git"))
+
+	return filepath.Join(reposDir, "super.git")
+}
+
+func TestCloneWithSubmodules(t *testing.T) {
+	for _, systemGit := range []bool{false, true} {
+		t.Run(fmt.Sprintf("systemGit=%t", systemGit), func(t *testing.T) {
+			if systemGit {
+				enableSystemGit(t)
+			}
+			superRepo := initTestSuperRepo(t)
+
+			localPath := filepath.Join(t.TempDir(), "super")
+			_, err := CloneWithOpts(
+				WithRepoURL(superRepo),
+				WithLocalPath(localPath),
+				WithPackage("super"),
+				WithSubmodules(true),
+			)
+			assert.NilError(t, err)
+			_, err = os.Stat(filepath.Join(localPath, "lib", "lib.k"))
+			assert.NilError(t, err)
+
+			// The relative url of the submodule is resolved by the url of the upstream repository, not the cache.
+			cachePath := filepath.Join(t.TempDir(), "cache.git")
+			_, err = CloneWithOpts(WithRepoURL(superRepo), WithLocalPath(cachePath), WithBare(true))
+			assert.NilError(t, err)
+
+			localPath = filepath.Join(t.TempDir(), "super")
+			_, err = CloneWithOpts(
+				WithRepoURL(cachePath),
+				WithLocalPath(localPath),
+				WithPackage("super"),
+				WithSubmodules(true),
+				WithOriginURL(superRepo),
+			)
+			assert.NilError(t, err)
+			_, err = os.Stat(filepath.Join(localPath, "lib", "lib.k"))
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestCloneWithLfsWithoutGitLfs(t *testing.T) {
+	if _, err := exec.LookPath("git-lfs"); err == nil {
+		t.Skip("git-lfs is installed")
+	}
+	enableSystemGit(t)
+	repoUrl := initTestMonorepo(t)
+
+	_, err := CloneWithOpts(
+		WithRepoURL(repoUrl),
+		WithLocalPath(filepath.Join(t.TempDir(), "a")),
+		WithPackage("a"),
+		WithLfs(true),
+	)
+	assert.ErrorContains(t, err, "'git-lfs' is required")
+}
+
+func TestCloneWithLfsWithoutSystemGit(t *testing.T) {
+	repoUrl := initTestMonorepo(t)
+
+	_, err := CloneWithOpts(
+		WithRepoURL(repoUrl),
+		WithLocalPath(filepath.Join(t.TempDir(), "a")),
+		WithPackage("a"),
+		WithLfs(true),
+	)
+	assert.ErrorContains(t, err, "can only be downloaded by the local git command")
+}
@@ -0,0 +1,496 @@
+package mock
+
+import (
+	"compress/gzip"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/format/pktline"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+
+	"kcl-lang.io/kpm/pkg/settings"
+)
+
+// GitServer is an in-process git server serving the repositories seeded from the fixture directories
+// by the smart http protocol, the repositories are created and served by go-git without the git command.
+// Only fetching is supported, including the shallow fetches and the fetches of the commits by their hashes.
+type GitServer struct {
+	// Host is the host of the server, e.g. '127.0.0.1:41235'.
+	Host string
+
+	username string
+	token    string
+	// addr is the address listened by the server, a random port of the loopback address if empty.
+	addr string
+	// tls serves the repositories by https instead of http.
+	tls bool
+	// root contains the repositories served by the server.
+	root string
+	// caFile is the certificate of the server in PEM format if it serves by https.
+	caFile string
+	server *httptest.Server
+}
+
+type GitServerOption func(*GitServer) error
+
+// WithGitAuth requires the basic authentication with 'username' and 'token' to access the repositories.
+func WithGitAuth(username, token string) GitServerOption {
+	return func(s *GitServer) error {
+		if username == "" {
+			return fmt.Errorf("username cannot be empty")
+		}
+		s.username = username
+		s.token = token
+		return nil
+	}
+}
+
+// WithGitAddr makes the server listen on 'addr', e.g. '127.0.0.1:5002',
+// so the urls of the repositories are the same in every run of the tests.
+func WithGitAddr(addr string) GitServerOption {
+	return func(s *GitServer) error {
+		if addr == "" {
+			return fmt.Errorf("addr cannot be empty")
+		}
+		s.addr = addr
+		return nil
+	}
+}
+
+// WithGitTLS serves the repositories by https with the certificate of 'httptest',
+// the certificate is trusted by the http client returned by Client and the KpmClient configured by Configure.
+func WithGitTLS() GitServerOption {
+	return func(s *GitServer) error {
+		s.tls = true
+		return nil
+	}
+}
+
+// NewGitServer starts a local git server without repositories, the repositories are created by Seed.
+// The server should be closed by Close after use.
+func NewGitServer(opts ...GitServerOption) (*GitServer, error) {
+	s := &GitServer{}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	root, err := os.MkdirTemp("", "git_server_mock")
+	if err != nil {
+		return nil, err
+	}
+	s.root = root
+
+	s.server = httptest.NewUnstartedServer(s)
+	if s.addr != "" {
+		listener, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			_ = os.RemoveAll(root)
+			return nil, err
+		}
+		s.server.Listener.Close()
+		s.server.Listener = listener
+	}
+	if s.tls {
+		s.server.StartTLS()
+		s.caFile = filepath.Join(root, "ca.pem")
+		if err := os.WriteFile(s.caFile, s.Certificate(), 0644); err != nil {
+			s.Close()
+			return nil, err
+		}
+	} else {
+		s.server.Start()
+	}
+	s.Host = strings.TrimPrefix(strings.TrimPrefix(s.server.URL, "https://"), "http://")
+	return s, nil
+}
+
+// Close stops the server and removes its repositories.
+func (s *GitServer) Close() {
+	s.server.Close()
+	_ = os.RemoveAll(s.root)
+}
+
+// Client returns the http client trusting the certificate of the server if it serves by https.
+func (s *GitServer) Client() *http.Client {
+	return s.server.Client()
+}
+
+// Certificate returns the certificate of the server in PEM format if it serves by https.
+func (s *GitServer) Certificate() []byte {
+	if !s.tls {
+		return nil
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.server.Certificate().Raw})
+}
+
+// RepoUrl returns the url of the repository 'name', e.g. 'http://127.0.0.1:41235/flask-demo.git'.
+func (s *GitServer) RepoUrl(name string) string {
+	return fmt.Sprintf("%s/%s.git", s.server.URL, name)
+}
+
+// Configure adds the credential and the certificate of the server to the git credentials of 'settings',
+// so the KpmClient with the settings accesses the repositories of the server if the authentication or https is required.
+// It should be called before the credentials are loaded by the KpmClient.
+func (s *GitServer) Configure(kpmSettings *settings.Settings) {
+	if s.username == "" && s.caFile == "" {
+		return
+	}
+	if kpmSettings.Conf.GitCredentials == nil {
+		kpmSettings.Conf.GitCredentials = map[string]settings.GitCredential{}
+	}
+	kpmSettings.Conf.GitCredentials[s.Host] = settings.GitCredential{
+		Username: s.username,
+		Token:    s.token,
+		CAFile:   s.caFile,
+	}
+}
+
+// seedTime is the time of the seeded commits, so the hashes of the commits seeded from the same fixtures are the same in every run.
+var seedTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Seed commits the files in 'fixtureDir' to the branch 'main' of the repository 'name' and tags the commit with 'tags', it returns the hash of the commit.
+// The repository is created if it does not exist. Seed can be called several times to create the history of the repository,
+// the files of the previous commit are replaced by the files in 'fixtureDir'.
+func (s *GitServer) Seed(name, fixtureDir string, tags ...string) (string, error) {
+	return s.SeedBranch(name, "main", fixtureDir, tags...)
+}
+
+// SeedBranch is the same as Seed, but commits to the branch 'branch'.
+// The branch is created from the latest commit of 'main' if it does not exist, and 'main' is still the default branch of the repository.
+func (s *GitServer) SeedBranch(name, branch, fixtureDir string, tags ...string) (string, error) {
+	repoPath := filepath.Join(s.root, name+".git")
+	mainRef := plumbing.NewBranchReferenceName("main")
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	repo, err := git.PlainOpen(repoPath)
+	if err == git.ErrRepositoryNotExists {
+		repo, err = git.PlainInitWithOptions(repoPath, &git.PlainInitOptions{
+			InitOptions: git.InitOptions{DefaultBranch: branchRef},
+		})
+	}
+	if err != nil {
+		return "", err
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	if head, err := repo.Head(); err == nil && head.Name() != branchRef {
+		_, err := repo.Reference(branchRef, false)
+		if err := worktree.Checkout(&git.CheckoutOptions{
+			Branch: branchRef,
+			Create: err == plumbing.ErrReferenceNotFound,
+			Force:  true,
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if entry.Name() == git.GitDirName {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(repoPath, entry.Name())); err != nil {
+			return "", err
+		}
+	}
+	if err := copyFixture(fixtureDir, repoPath); err != nil {
+		return "", err
+	}
+
+	if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return "", err
+	}
+	commit, err := worktree.Commit(fmt.Sprintf("seed from '%s'", filepath.Base(fixtureDir)), &git.CommitOptions{
+		All:               true,
+		AllowEmptyCommits: true,
+		Author: &object.Signature{
+			Name:  "kpm",
+			Email: "kpm@kcl-lang.io",
+			When:  seedTime,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range tags {
+		if _, err := repo.CreateTag(tag, commit, nil); err != nil {
+			return "", err
+		}
+	}
+
+	// The HEAD advertised to the clients is always 'main' if it exists.
+	if branchRef != mainRef {
+		if _, err := repo.Reference(mainRef, false); err == nil {
+			if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, mainRef)); err != nil {
+				return "", err
+			}
+		}
+	}
+	return commit.String(), nil
+}
+
+// ServeHTTP serves the 'git-upload-pack' service of the smart http protocol,
+// see https://git-scm.com/docs/http-protocol.
+func (s *GitServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.username != "" {
+		username, token, ok := req.BasicAuth()
+		if !ok || username != s.username || token != s.token {
+			w.Header().Set("WWW-Authenticate", `Basic realm="kpm mock git server"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var name, service string
+	switch {
+	case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/info/refs"):
+		name = strings.TrimSuffix(req.URL.Path, "/info/refs")
+		service = req.URL.Query().Get("service")
+	case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/"+transport.UploadPackServiceName):
+		name = strings.TrimSuffix(req.URL.Path, "/"+transport.UploadPackServiceName)
+		service = transport.UploadPackServiceName
+	}
+	if service != transport.UploadPackServiceName {
+		http.Error(w, fmt.Sprintf("'%s %s' is not supported", req.Method, req.URL.Path), http.StatusForbidden)
+		return
+	}
+
+	// The same as the git hosting services, the repository can be accessed without the suffix '.git'.
+	if !strings.HasSuffix(name, ".git") {
+		name += ".git"
+	}
+	repo, err := git.PlainOpen(filepath.Join(s.root, filepath.FromSlash(strings.TrimPrefix(name, "/"))))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if req.Method == http.MethodGet {
+		err = s.advertiseRefs(w, req, repo.Storer)
+	} else {
+		err = s.uploadPack(w, req, repo.Storer)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// advertiseRefs writes the references of the repository with the capabilities of the server.
+func (s *GitServer) advertiseRefs(w http.ResponseWriter, req *http.Request, repoStorer storer.Storer) error {
+	ep, err := transport.NewEndpoint(s.server.URL + req.URL.Path)
+	if err != nil {
+		return err
+	}
+	session, err := server.NewServer(server.MapLoader{ep.String(): repoStorer}).NewUploadPackSession(ep, nil)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	advRefs, err := session.AdvertisedReferencesContext(req.Context())
+	if err != nil {
+		return err
+	}
+	for _, c := range []capability.Capability{capability.Shallow, capability.AllowTipSHA1InWant, capability.AllowReachableSHA1InWant} {
+		if err := advRefs.Capabilities.Set(c); err != nil {
+			return err
+		}
+	}
+	advRefs.Prefix = [][]byte{[]byte("# service=" + transport.UploadPackServiceName), pktline.Flush}
+
+	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-advertisement", transport.UploadPackServiceName))
+	w.Header().Set("Cache-Control", "no-cache")
+	return advRefs.Encode(w)
+}
+
+// uploadPack writes the packfile of the objects wanted by the client, the history is cut at the requested depth.
+// The objects of the client are only used to end the negotiation, and the packfile contains all the wanted objects.
+func (s *GitServer) uploadPack(w http.ResponseWriter, req *http.Request, repoStorer storer.Storer) error {
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(req.Body)
+		if err != nil {
+			return err
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+
+	upReq := packp.NewUploadPackRequest()
+	if err := upReq.UploadRequest.Decode(body); err != nil {
+		return err
+	}
+	haves, done := false, false
+	scanner := pktline.NewScanner(body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(string(scanner.Bytes()))
+		if line == "done" {
+			done = true
+			break
+		}
+		haves = haves || strings.HasPrefix(line, "have ")
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	depth := 0
+	if commits, ok := upReq.Depth.(packp.DepthCommits); ok {
+		depth = int(commits)
+	}
+	objs, shallows, err := wantedObjects(repoStorer, upReq.Wants, depth)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", fmt.Sprintf("application/x-%s-result", transport.UploadPackServiceName))
+	w.Header().Set("Cache-Control", "no-cache")
+	if depth > 0 {
+		if err := (&packp.ShallowUpdate{Shallows: shallows}).Encode(w); err != nil {
+			return err
+		}
+	}
+	if !haves && !done {
+		// The first request of the shallow fetch only gets the shallow commits.
+		return nil
+	}
+	if err := (&packp.ServerResponse{}).Encode(w, false); err != nil {
+		return err
+	}
+	if !done {
+		// The client goes on to send its objects until it is done.
+		return nil
+	}
+	_, err = packfile.NewEncoder(w, repoStorer, false).Encode(objs, 10)
+	return err
+}
+
+// wantedObjects returns the objects reachable from 'wants' and the shallow commits where the history is cut,
+// only the commits within 'depth' from the wanted commits are returned if 'depth' is not zero.
+func wantedObjects(repoStorer storer.EncodedObjectStorer, wants []plumbing.Hash, depth int) ([]plumbing.Hash, []plumbing.Hash, error) {
+	if depth == 0 {
+		objs, err := revlist.Objects(repoStorer, wants, nil)
+		return objs, nil, err
+	}
+
+	var objs, shallows, trees []plumbing.Hash
+	seen := map[plumbing.Hash]bool{}
+	level := map[plumbing.Hash]int{}
+	var queue []plumbing.Hash
+	for _, want := range wants {
+		// The annotated tags are sent with the commits they point to.
+		obj, err := repoStorer.EncodedObject(plumbing.AnyObject, want)
+		if err != nil {
+			return nil, nil, err
+		}
+		for obj.Type() == plumbing.TagObject {
+			tag, err := object.DecodeTag(repoStorer, obj)
+			if err != nil {
+				return nil, nil, err
+			}
+			objs = append(objs, tag.Hash)
+			if obj, err = repoStorer.EncodedObject(plumbing.AnyObject, tag.Target); err != nil {
+				return nil, nil, err
+			}
+		}
+		if obj.Type() != plumbing.CommitObject {
+			return nil, nil, fmt.Errorf("the shallow fetch of the %s '%s' is not supported", obj.Type(), obj.Hash())
+		}
+		if _, ok := level[obj.Hash()]; !ok {
+			level[obj.Hash()] = 1
+			queue = append(queue, obj.Hash())
+		}
+	}
+
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		commit, err := object.GetCommit(repoStorer, hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		objs = append(objs, hash)
+		trees = append(trees, commit.TreeHash)
+		if level[hash] >= depth {
+			if commit.NumParents() > 0 {
+				shallows = append(shallows, hash)
+			}
+			continue
+		}
+		for _, parent := range commit.ParentHashes {
+			if _, ok := level[parent]; !ok {
+				level[parent] = level[hash] + 1
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	treeObjs, err := revlist.Objects(repoStorer, trees, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(objs, treeObjs...), shallows, nil
+}
+
+// copyFixture copies the files in 'src' to 'dest' except the '.git' directories.
+func copyFixture(src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		srcFile, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer srcFile.Close()
+		destFile, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer destFile.Close()
+		_, err = io.Copy(destFile, srcFile)
+		return err
+	})
+}
@@ -0,0 +1,130 @@
+package mock
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/downloader"
+)
+
+func TestGitServer(t *testing.T) {
+	server, err := NewGitServer(WithGitAuth("test", "1234"))
+	assert.Nil(t, err)
+	defer server.Close()
+
+	_, err = server.Seed("helloworld", filepath.Join("test_pkg", "helloworld_0.1.0"), "v0.1.0")
+	assert.Nil(t, err)
+	commit, err := server.Seed("helloworld", filepath.Join("test_pkg", "helloworld_0.2.0"), "v0.2.0")
+	assert.Nil(t, err)
+	assert.Len(t, commit, 40)
+
+	source := &downloader.Source{
+		Git: &downloader.Git{
+			Url: server.RepoUrl("helloworld"),
+		},
+	}
+
+	// The repositories are not accessible without the credential.
+	anonymous, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	anonymous.SetLogWriter(io.Discard)
+	_, err = anonymous.Info(client.WithInfoSource(source))
+	assert.NotNil(t, err)
+
+	kpmcli, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(io.Discard)
+	server.Configure(kpmcli.GetSettings())
+
+	info, err := kpmcli.Info(client.WithInfoSource(source))
+	assert.Nil(t, err)
+	assert.Equal(t, "helloworld", info.Name)
+	assert.Equal(t, "v0.2.0", info.Version)
+	assert.Equal(t, []string{"v0.1.0", "v0.2.0"}, info.Versions)
+
+	pulledPath := t.TempDir()
+	kPkg, err := kpmcli.Pull(
+		client.WithPullSource(&downloader.Source{
+			Git: &downloader.Git{
+				Url: server.RepoUrl("helloworld"),
+				Tag: "v0.1.0",
+			},
+		}),
+		client.WithLocalPath(pulledPath),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1.0", kPkg.GetPkgVersion())
+
+	content, err := os.ReadFile(filepath.Join(kPkg.HomePath, "main.k"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "Hello World 0.1.0!")
+}
+
+func TestGitServerWithBranchAndTLS(t *testing.T) {
+	server, err := NewGitServer(WithGitTLS())
+	assert.Nil(t, err)
+	defer server.Close()
+	assert.True(t, strings.HasPrefix(server.RepoUrl("helloworld"), "https://"))
+
+	mainCommit, err := server.Seed("helloworld", filepath.Join("test_pkg", "helloworld_0.1.0"))
+	assert.Nil(t, err)
+	branchCommit, err := server.SeedBranch("helloworld", "dev", filepath.Join("test_pkg", "helloworld_0.2.0"))
+	assert.Nil(t, err)
+
+	// The commits seeded from the same fixtures are the same.
+	another, err := NewGitServer()
+	assert.Nil(t, err)
+	defer another.Close()
+	commit, err := another.Seed("helloworld", filepath.Join("test_pkg", "helloworld_0.1.0"))
+	assert.Nil(t, err)
+	assert.Equal(t, mainCommit, commit)
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{server.RepoUrl("helloworld")},
+	})
+	refs, err := remote.List(&git.ListOptions{CABundle: server.Certificate()})
+	assert.Nil(t, err)
+
+	heads := map[string]string{}
+	for _, ref := range refs {
+		if ref.Type() == plumbing.SymbolicReference {
+			heads[ref.Name().String()] = ref.Target().String()
+		} else {
+			heads[ref.Name().String()] = ref.Hash().String()
+		}
+	}
+	assert.Equal(t, "refs/heads/main", heads["HEAD"])
+	assert.Equal(t, mainCommit, heads["refs/heads/main"])
+	assert.Equal(t, branchCommit, heads["refs/heads/dev"])
+
+	// The certificate of the server is trusted by the KpmClient configured by the server.
+	source := &downloader.Source{
+		Git: &downloader.Git{
+			Url:    server.RepoUrl("helloworld"),
+			Branch: "dev",
+		},
+	}
+	anonymous, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	anonymous.SetLogWriter(io.Discard)
+	_, err = anonymous.Pull(client.WithPullSource(source), client.WithLocalPath(t.TempDir()))
+	assert.ErrorContains(t, err, "certificate")
+
+	kpmcli, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(io.Discard)
+	server.Configure(kpmcli.GetSettings())
+	kPkg, err := kpmcli.Pull(client.WithPullSource(source), client.WithLocalPath(t.TempDir()))
+	assert.Nil(t, err)
+	assert.Equal(t, "0.2.0", kPkg.GetPkgVersion())
+}
@@ -0,0 +1,562 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry/remote"
+	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/utils"
+)
+
+// DEFAULT_OCI_REPO is the default repo of the packages set by OciRegistry.Configure.
+const DEFAULT_OCI_REPO = "test"
+
+// ociArtifactType is the media type of the packages pushed by kpm, the same as 'oci.DEFAULT_OCI_ARTIFACT_TYPE'.
+const ociArtifactType = "application/vnd.oci.image.layer.v1.tar"
+
+// OciRegistry is an in-process OCI distribution registry storing the repositories in memory.
+// It supports the push, the pull, the tag listing, the catalog and the referrers API,
+// and the basic authentication if the credential is set by WithOciAuth.
+type OciRegistry struct {
+	// Host is the host of the registry, e.g. 'localhost:41235', the registry is accessed by plain http.
+	Host string
+
+	username string
+	password string
+	// addr is the address listened by the registry, a random port of the loopback address if empty.
+	addr   string
+	server *httptest.Server
+	// credentialsDir contains the credentials file used by the settings configured by the registry.
+	credentialsDir string
+
+	mu      sync.Mutex
+	blobs   map[string][]byte
+	repos   map[string]*ociRepository
+	uploads map[string]*bytes.Buffer
+	upload  int
+}
+
+// ociRepository is a repository in the OciRegistry.
+type ociRepository struct {
+	// manifests maps the digest to the manifest.
+	manifests map[string]ociManifest
+	// tags maps the tag to the digest of the manifest.
+	tags map[string]string
+}
+
+// ociManifest is a manifest stored with its media type.
+type ociManifest struct {
+	mediaType string
+	content   []byte
+}
+
+type OciRegistryOption func(*OciRegistry) error
+
+// WithOciAuth requires the basic authentication with 'username' and 'password' to access the registry.
+func WithOciAuth(username, password string) OciRegistryOption {
+	return func(r *OciRegistry) error {
+		if username == "" {
+			return fmt.Errorf("username cannot be empty")
+		}
+		r.username = username
+		r.password = password
+		return nil
+	}
+}
+
+// WithOciAddr makes the registry listen on 'addr', e.g. '127.0.0.1:5001',
+// so the registry can be accessed by a fixed host from outside of the test process.
+func WithOciAddr(addr string) OciRegistryOption {
+	return func(r *OciRegistry) error {
+		if addr == "" {
+			return fmt.Errorf("addr cannot be empty")
+		}
+		r.addr = addr
+		return nil
+	}
+}
+
+// NewOciRegistry starts an in-process OCI registry, the registry should be closed by Close after use.
+func NewOciRegistry(opts ...OciRegistryOption) (*OciRegistry, error) {
+	r := &OciRegistry{
+		blobs:   map[string][]byte{},
+		repos:   map[string]*ociRepository{},
+		uploads: map[string]*bytes.Buffer{},
+	}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	credentialsDir, err := os.MkdirTemp("", "oci_registry_mock")
+	if err != nil {
+		return nil, err
+	}
+	r.credentialsDir = credentialsDir
+
+	r.server = httptest.NewUnstartedServer(r)
+	if r.addr != "" {
+		listener, err := net.Listen("tcp", r.addr)
+		if err != nil {
+			_ = os.RemoveAll(credentialsDir)
+			return nil, err
+		}
+		r.server.Listener.Close()
+		r.server.Listener = listener
+	}
+	r.server.Start()
+	// kpm accesses the 'localhost' registries by plain http.
+	r.Host = strings.Replace(strings.TrimPrefix(r.server.URL, "http://"), "127.0.0.1", "localhost", 1)
+
+	if err := r.writeCredentialsFile(); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close stops the registry and removes its credentials file.
+func (r *OciRegistry) Close() {
+	r.server.Close()
+	_ = os.RemoveAll(r.credentialsDir)
+}
+
+// CredentialsFile returns the path of the credentials file containing the credential of the registry.
+func (r *OciRegistry) CredentialsFile() string {
+	return filepath.Join(r.credentialsDir, "config.json")
+}
+
+// Configure makes the registry the default OCI registry of 'settings' with the repo 'test',
+// and uses the credentials file of the registry, so the KpmClient with the settings accesses the registry without login.
+// It should be called before the credentials are loaded by the KpmClient.
+func (r *OciRegistry) Configure(kpmSettings *settings.Settings) {
+	kpmSettings.Conf.DefaultOciRegistry = r.Host
+	kpmSettings.Conf.DefaultOciRepo = DEFAULT_OCI_REPO
+	kpmSettings.CredentialsFile = r.CredentialsFile()
+}
+
+// PushPkg pushes the kcl package in 'pkgPath' to the repository 'repo' of the registry with its version as the tag,
+// the package is packed and annotated the same as the package pushed by kpm. It returns the checksum of the package.
+// The package is pushed without loading it by the package 'pkg', so the registry can be used by the tests of the packages it depends on.
+func (r *OciRegistry) PushPkg(pkgPath, repo string) (string, error) {
+	var modFile struct {
+		Package struct {
+			Name        string   `toml:"name"`
+			Version     string   `toml:"version"`
+			Description string   `toml:"description"`
+			Include     []string `toml:"include"`
+			Exclude     []string `toml:"exclude"`
+		} `toml:"package"`
+	}
+	if _, err := toml.DecodeFile(filepath.Join(pkgPath, constants.KCL_MOD), &modFile); err != nil {
+		return "", err
+	}
+	sum, err := utils.HashDir(pkgPath)
+	if err != nil {
+		return "", err
+	}
+
+	tarDir, err := os.MkdirTemp("", "oci_registry_mock_pkg")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tarDir)
+	tarName := fmt.Sprintf("%s_%s%s", modFile.Package.Name, modFile.Package.Version, constants.TarPathSuffix)
+	if err := utils.TarDir(pkgPath, filepath.Join(tarDir, tarName), modFile.Package.Include, modFile.Package.Exclude); err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	store, err := file.New(tarDir)
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+	layer, err := store.Add(ctx, tarName, ociArtifactType, "")
+	if err != nil {
+		return "", err
+	}
+	manifest, err := oras.PackManifest(ctx, store, oras.PackManifestVersion1_1_RC4, ociArtifactType, oras.PackManifestOptions{
+		Layers: []ocispec.Descriptor{layer},
+		ManifestAnnotations: map[string]string{
+			constants.DEFAULT_KCL_OCI_MANIFEST_NAME:        modFile.Package.Name,
+			constants.DEFAULT_KCL_OCI_MANIFEST_VERSION:     modFile.Package.Version,
+			constants.DEFAULT_KCL_OCI_MANIFEST_DESCRIPTION: modFile.Package.Description,
+			constants.DEFAULT_KCL_OCI_MANIFEST_SUM:         sum,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := store.Tag(ctx, manifest, modFile.Package.Version); err != nil {
+		return "", err
+	}
+
+	repository, err := remote.NewRepository(utils.JoinPath(r.Host, repo))
+	if err != nil {
+		return "", err
+	}
+	repository.PlainHTTP = true
+	repository.Client = &remoteauth.Client{
+		Credential: remoteauth.StaticCredential(r.Host, remoteauth.Credential{Username: r.username, Password: r.password}),
+	}
+	if _, err := oras.Copy(ctx, store, modFile.Package.Version, repository, modFile.Package.Version, oras.DefaultCopyOptions); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// Tags returns the sorted tags of the repository 'repo'.
+func (r *OciRegistry) Tags(repo string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tags []string
+	if repository, ok := r.repos[repo]; ok {
+		for tag := range repository.tags {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// writeCredentialsFile writes the docker config file containing the credential of the registry.
+func (r *OciRegistry) writeCredentialsFile() error {
+	auths := map[string]interface{}{}
+	if r.username != "" {
+		auths[r.Host] = map[string]string{
+			"auth": base64.StdEncoding.EncodeToString([]byte(r.username + ":" + r.password)),
+		}
+	}
+	content, err := json.Marshal(map[string]interface{}{"auths": auths})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.CredentialsFile(), content, 0600)
+}
+
+// ServeHTTP serves the OCI distribution API, see https://github.com/opencontainers/distribution-spec/blob/main/spec.md.
+func (r *OciRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.username != "" {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != r.username || password != r.password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="kpm mock registry"`)
+			writeOciError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+			return
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case path == "" || path == "/v2":
+		w.WriteHeader(http.StatusOK)
+	case path == "_catalog":
+		repos := []string{}
+		for name := range r.repos {
+			repos = append(repos, name)
+		}
+		sort.Strings(repos)
+		writeOciJson(w, "application/json", map[string]interface{}{"repositories": repos})
+	case strings.HasSuffix(path, "/tags/list"):
+		r.serveTags(w, strings.TrimSuffix(path, "/tags/list"))
+	case strings.Contains(path, "/blobs/uploads/"):
+		name, id, _ := strings.Cut(path, "/blobs/uploads/")
+		r.serveUpload(w, req, name, id)
+	case strings.Contains(path, "/blobs/"):
+		name, digest, _ := strings.Cut(path, "/blobs/")
+		r.serveBlob(w, req, name, digest)
+	case strings.Contains(path, "/manifests/"):
+		name, ref, _ := strings.Cut(path, "/manifests/")
+		r.serveManifest(w, req, name, ref)
+	case strings.Contains(path, "/referrers/"):
+		name, digest, _ := strings.Cut(path, "/referrers/")
+		r.serveReferrers(w, req, name, digest)
+	default:
+		writeOciError(w, http.StatusNotFound, "UNSUPPORTED", fmt.Sprintf("'%s' is not supported", req.URL.Path))
+	}
+}
+
+func (r *OciRegistry) serveTags(w http.ResponseWriter, name string) {
+	repository, ok := r.repos[name]
+	if !ok {
+		writeOciError(w, http.StatusNotFound, "NAME_UNKNOWN", fmt.Sprintf("repository '%s' not found", name))
+		return
+	}
+	tags := []string{}
+	for tag := range repository.tags {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	writeOciJson(w, "application/json", map[string]interface{}{"name": name, "tags": tags})
+}
+
+// serveUpload serves the monolithic and the chunked blob uploads.
+func (r *OciRegistry) serveUpload(w http.ResponseWriter, req *http.Request, name, id string) {
+	content, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeOciError(w, http.StatusBadRequest, "BLOB_UPLOAD_INVALID", err.Error())
+		return
+	}
+	query := req.URL.Query()
+
+	switch req.Method {
+	case http.MethodPost:
+		if mount := query.Get("mount"); mount != "" {
+			if _, ok := r.blobs[mount]; ok {
+				w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/%s", name, mount))
+				w.Header().Set("Docker-Content-Digest", mount)
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+		}
+		if digest := query.Get("digest"); digest != "" {
+			r.putBlob(w, name, digest, content)
+			return
+		}
+		r.upload++
+		id = fmt.Sprint(r.upload)
+		r.uploads[id] = bytes.NewBuffer(content)
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", name, id))
+		w.Header().Set("Docker-Upload-UUID", id)
+		w.Header().Set("Range", "0-0")
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPatch, http.MethodPut:
+		upload, ok := r.uploads[id]
+		if !ok {
+			writeOciError(w, http.StatusNotFound, "BLOB_UPLOAD_UNKNOWN", fmt.Sprintf("upload '%s' not found", id))
+			return
+		}
+		upload.Write(content)
+		if req.Method == http.MethodPatch {
+			w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", name, id))
+			w.Header().Set("Range", fmt.Sprintf("0-%d", upload.Len()-1))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		delete(r.uploads, id)
+		r.putBlob(w, name, query.Get("digest"), upload.Bytes())
+	case http.MethodDelete:
+		delete(r.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// putBlob stores the blob 'content' after verifying its digest.
+func (r *OciRegistry) putBlob(w http.ResponseWriter, name, digest string, content []byte) {
+	if digest != sha256Digest(content) {
+		writeOciError(w, http.StatusBadRequest, "DIGEST_INVALID", fmt.Sprintf("digest '%s' does not match the content", digest))
+		return
+	}
+	r.blobs[digest] = content
+	w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/%s", name, digest))
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (r *OciRegistry) serveBlob(w http.ResponseWriter, req *http.Request, name, digest string) {
+	content, ok := r.blobs[digest]
+	if !ok {
+		writeOciError(w, http.StatusNotFound, "BLOB_UNKNOWN", fmt.Sprintf("blob '%s' not found in '%s'", digest, name))
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+	case http.MethodDelete:
+		delete(r.blobs, digest)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *OciRegistry) serveManifest(w http.ResponseWriter, req *http.Request, name, ref string) {
+	repository, ok := r.repos[name]
+	if !ok {
+		if req.Method != http.MethodPut {
+			writeOciError(w, http.StatusNotFound, "NAME_UNKNOWN", fmt.Sprintf("repository '%s' not found", name))
+			return
+		}
+		repository = &ociRepository{
+			manifests: map[string]ociManifest{},
+			tags:      map[string]string{},
+		}
+		r.repos[name] = repository
+	}
+
+	if req.Method == http.MethodPut {
+		content, err := io.ReadAll(req.Body)
+		if err != nil {
+			writeOciError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+		manifest := struct {
+			Subject *struct {
+				Digest string `json:"digest"`
+			} `json:"subject"`
+		}{}
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			writeOciError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+
+		digest := sha256Digest(content)
+		repository.manifests[digest] = ociManifest{
+			mediaType: req.Header.Get("Content-Type"),
+			content:   content,
+		}
+		if !strings.HasPrefix(ref, "sha256:") {
+			repository.tags[ref] = digest
+		}
+		if manifest.Subject != nil {
+			w.Header().Set("OCI-Subject", manifest.Subject.Digest)
+		}
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/manifests/%s", name, digest))
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	digest := ref
+	if tagged, ok := repository.tags[ref]; ok {
+		digest = tagged
+	}
+	manifest, ok := repository.manifests[digest]
+	if !ok {
+		writeOciError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", fmt.Sprintf("manifest '%s' not found in '%s'", ref, name))
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		w.Header().Set("Content-Type", manifest.mediaType)
+		w.Header().Set("Content-Length", fmt.Sprint(len(manifest.content)))
+		w.Header().Set("Docker-Content-Digest", digest)
+		w.WriteHeader(http.StatusOK)
+		if req.Method == http.MethodGet {
+			_, _ = w.Write(manifest.content)
+		}
+	case http.MethodDelete:
+		if digest != ref {
+			// Deleting by the tag only untags the manifest.
+			delete(repository.tags, ref)
+		} else {
+			delete(repository.manifests, digest)
+			for tag, tagged := range repository.tags {
+				if tagged == digest {
+					delete(repository.tags, tag)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveReferrers lists the manifests whose subject is 'subject', filtered by the 'artifactType' query.
+func (r *OciRegistry) serveReferrers(w http.ResponseWriter, req *http.Request, name, subject string) {
+	artifactType := req.URL.Query().Get("artifactType")
+	referrers := []map[string]interface{}{}
+
+	if repository, ok := r.repos[name]; ok {
+		digests := make([]string, 0, len(repository.manifests))
+		for digest := range repository.manifests {
+			digests = append(digests, digest)
+		}
+		sort.Strings(digests)
+
+		for _, digest := range digests {
+			manifest := repository.manifests[digest]
+			referrer := struct {
+				ArtifactType string `json:"artifactType"`
+				Config       struct {
+					MediaType string `json:"mediaType"`
+				} `json:"config"`
+				Subject *struct {
+					Digest string `json:"digest"`
+				} `json:"subject"`
+				Annotations map[string]string `json:"annotations"`
+			}{}
+			if err := json.Unmarshal(manifest.content, &referrer); err != nil || referrer.Subject == nil || referrer.Subject.Digest != subject {
+				continue
+			}
+			if referrer.ArtifactType == "" {
+				referrer.ArtifactType = referrer.Config.MediaType
+			}
+			if artifactType != "" && referrer.ArtifactType != artifactType {
+				continue
+			}
+			referrers = append(referrers, map[string]interface{}{
+				"mediaType":    manifest.mediaType,
+				"digest":       digest,
+				"size":         len(manifest.content),
+				"artifactType": referrer.ArtifactType,
+				"annotations":  referrer.Annotations,
+			})
+		}
+	}
+
+	if artifactType != "" {
+		w.Header().Set("OCI-Filters-Applied", "artifactType")
+	}
+	writeOciJson(w, "application/vnd.oci.image.index.v1+json", map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.index.v1+json",
+		"manifests":     referrers,
+	})
+}
+
+func sha256Digest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+func writeOciJson(w http.ResponseWriter, mediaType string, body interface{}) {
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeOciError writes the error in the format of the OCI distribution spec.
+func writeOciError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]string{{"code": code, "message": message}},
+	})
+}
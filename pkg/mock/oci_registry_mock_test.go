@@ -0,0 +1,85 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/downloader"
+)
+
+func TestOciRegistry(t *testing.T) {
+	reg, err := NewOciRegistry(WithOciAuth("test", "1234"))
+	assert.Nil(t, err)
+	defer reg.Close()
+
+	// The registry requires the authentication.
+	resp, err := http.Get("http://" + reg.Host + "/v2/")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	kpmcli, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetLogWriter(io.Discard)
+	reg.Configure(kpmcli.GetSettings())
+
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		err = kpmcli.Push(
+			client.WithPushModPath(filepath.Join("test_pkg", "helloworld_"+version)),
+			client.WithPushSource(downloader.Source{
+				Oci: &downloader.Oci{
+					Reg:  reg.Host,
+					Repo: "test/helloworld",
+				},
+			}),
+		)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, []string{"0.1.0", "0.2.0"}, reg.Tags("test/helloworld"))
+
+	// The shorthand package is resolved from the registry configured in the settings.
+	info, err := kpmcli.Info(client.WithInfoSource(&downloader.Source{
+		ModSpec: &downloader.ModSpec{Name: "helloworld"},
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, "helloworld", info.Name)
+	assert.Equal(t, "0.2.0", info.Version)
+	assert.Equal(t, []string{"0.1.0", "0.2.0"}, info.Versions)
+
+	// The referrers API is used to yank the version.
+	assert.Nil(t, kpmcli.Yank(
+		client.WithYankSourceUrl("oci://"+reg.Host+"/test/helloworld?tag=0.2.0"),
+		client.WithYankReason("broken"),
+	))
+
+	pulledPath := t.TempDir()
+	kPkg, err := kpmcli.Pull(
+		client.WithPullSourceUrl("oci://"+reg.Host+"/test/helloworld"),
+		client.WithLocalPath(pulledPath),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1.0", kPkg.GetPkgVersion())
+
+	content, err := os.ReadFile(filepath.Join(kPkg.HomePath, "main.k"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(content), "Hello World 0.1.0!")
+}
+
+func TestOciRegistryWithAddr(t *testing.T) {
+	_, err := NewOciRegistry(WithOciAddr(""))
+	assert.Equal(t, "addr cannot be empty", err.Error())
+
+	reg, err := NewOciRegistry(WithOciAddr("127.0.0.1:0"))
+	assert.Nil(t, err)
+	defer reg.Close()
+
+	resp, err := http.Get("http://" + reg.Host + "/v2/")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,5 @@
+[package]
+name = "helloworld"
+edition = "v0.9.0"
+version = "0.1.0"
+description = "The hello world package for the mock registry and git server"
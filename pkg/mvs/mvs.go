@@ -69,7 +69,8 @@ func (r ReqsGraph) Upgrade(m module.Version) (module.Version, error) {
 	}
 
 	var releases []string
-	for sourceType, uri := range properties.Attributes {
+	var sourceType, uri string
+	for sourceType, uri = range properties.Attributes {
 		releases, err = client.GetReleasesFromSource(sourceType, uri)
 		if err != nil {
 			return module.Version{}, err
@@ -80,10 +81,17 @@ func (r ReqsGraph) Upgrade(m module.Version) (module.Version, error) {
 		return m, nil
 	}
 
-	m.Version, err = semver.LatestCompatibleVersion(releases, m.Version)
+	// The yanked versions are not selected to upgrade, and the version is kept if all the compatible versions are yanked.
+	selected, err := r.KpmClient.SelectUnyankedRelease(sourceType, uri, releases, func(versions []string) (string, error) {
+		return semver.LatestCompatibleVersion(versions, m.Version)
+	})
+	if err == errInt.AllVersionsYanked {
+		return m, nil
+	}
 	if err != nil {
 		return module.Version{}, err
 	}
+	m.Version = selected
 	_, err = r.Vertex(m)
 	if err == graph.ErrVertexNotFound {
 		d := pkg.Dependency{
@@ -1,6 +1,7 @@
 package mvs
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -10,8 +11,7 @@ import (
 	"golang.org/x/mod/module"
 	"kcl-lang.io/kpm/pkg/3rdparty/mvs"
 	"kcl-lang.io/kpm/pkg/client"
-	"kcl-lang.io/kpm/pkg/oci"
-	"kcl-lang.io/kpm/pkg/settings"
+	"kcl-lang.io/kpm/pkg/mock"
 	"kcl-lang.io/kpm/pkg/test"
 	"kcl-lang.io/kpm/pkg/utils"
 )
@@ -26,6 +26,61 @@ func getTestDir(subDir string) string {
 	return testDir
 }
 
+// testPkgs are the packages in the test registry with the versions of the packages they depend on,
+// the dependencies of 'test_with_external_deps' are resolved from the registry.
+var testPkgs = []struct {
+	name    string
+	version string
+	deps    map[string]string
+}{
+	{name: "k8s", version: "1.14"},
+	{name: "k8s", version: "1.17"},
+	{name: "k8s", version: "1.27"},
+	{name: "k8s", version: "1.29"},
+	{name: "k8s", version: "1.31"},
+	{name: "helloworld", version: "0.1.0"},
+	{name: "helloworld", version: "0.1.1"},
+	{name: "helloworld", version: "0.1.2"},
+	{name: "helloworld", version: "0.1.3"},
+	{name: "helloworld", version: "0.1.4"},
+	{name: "json_merge_patch", version: "0.1.0"},
+	{name: "json_merge_patch", version: "0.1.1"},
+	{name: "argo-cd-order", version: "0.1.2", deps: map[string]string{"json_merge_patch": "0.1.0"}},
+	{name: "argo-cd-order", version: "0.2.0", deps: map[string]string{"json_merge_patch": "0.1.0"}},
+	{name: "podinfo", version: "0.1.1", deps: map[string]string{"k8s": "1.29"}},
+	{name: "podinfo", version: "0.2.1", deps: map[string]string{"k8s": "1.29"}},
+}
+
+// newTestKpmClient starts a test registry with the packages in 'testPkgs',
+// and returns the KpmClient resolving the packages from the registry into an empty package cache.
+func newTestKpmClient(t *testing.T) *client.KpmClient {
+	reg, err := mock.NewOciRegistry()
+	assert.Equal(t, err, nil)
+	t.Cleanup(reg.Close)
+
+	for _, p := range testPkgs {
+		pkgPath := filepath.Join(t.TempDir(), p.name)
+		assert.Equal(t, os.MkdirAll(pkgPath, 0755), nil)
+		modContent := fmt.Sprintf("[package]\nname = \"%s\"\nedition = \"v0.9.0\"\nversion = \"%s\"\n", p.name, p.version)
+		if len(p.deps) != 0 {
+			modContent += "\n[dependencies]\n"
+			for dep, version := range p.deps {
+				modContent += fmt.Sprintf("%s = \"%s\"\n", dep, version)
+			}
+		}
+		assert.Equal(t, os.WriteFile(filepath.Join(pkgPath, "kcl.mod"), []byte(modContent), 0644), nil)
+		assert.Equal(t, os.WriteFile(filepath.Join(pkgPath, "main.k"), []byte("a = 1\n"), 0644), nil)
+		_, err = reg.PushPkg(pkgPath, mock.DEFAULT_OCI_REPO+"/"+p.name)
+		assert.Equal(t, err, nil)
+	}
+
+	t.Setenv("KCL_PKG_PATH", t.TempDir())
+	kpmcli, err := client.NewKpmClient()
+	assert.Equal(t, err, nil)
+	reg.Configure(kpmcli.GetSettings())
+	return kpmcli
+}
+
 func testMax(t *testing.T) {
 	reqs := ReqsGraph{}
 	assert.Equal(t, reqs.Max("", "1.0.0", "2.0.0"), "2.0.0")
@@ -68,8 +123,7 @@ func testRequired(t *testing.T) {
 func testUpgrade(t *testing.T) {
 	pkg_path := getTestDir("test_with_external_deps")
 	assert.Equal(t, utils.DirExists(filepath.Join(pkg_path, "kcl.mod")), true)
-	kpmcli, err := client.NewKpmClient()
-	assert.Equal(t, err, nil)
+	kpmcli := newTestKpmClient(t)
 	kclPkg, err := kpmcli.LoadPkgFromPath(pkg_path)
 	assert.Equal(t, err, nil)
 
@@ -104,8 +158,7 @@ func testUpgrade(t *testing.T) {
 func testUpgradeToLatest(t *testing.T) {
 	pkg_path := getTestDir("test_with_external_deps")
 	assert.Equal(t, utils.DirExists(filepath.Join(pkg_path, "kcl.mod")), true)
-	kpmcli, err := client.NewKpmClient()
-	assert.Equal(t, err, nil)
+	kpmcli := newTestKpmClient(t)
 	kclPkg, err := kpmcli.LoadPkgFromPath(pkg_path)
 	assert.Equal(t, err, nil)
 
@@ -121,18 +174,13 @@ func testUpgradeToLatest(t *testing.T) {
 	upgrade, err := reqs.Upgrade(module.Version{Path: "k8s", Version: "1.27"})
 	assert.Equal(t, err, nil)
 
-	ociClient, err := oci.NewOciClient("ghcr.io", "kcl-lang/k8s", settings.GetSettings())
-	assert.Equal(t, err, nil)
-	tagSelected, err := ociClient.TheLatestTag()
-	assert.Equal(t, err, nil)
-	assert.Equal(t, upgrade, module.Version{Path: "k8s", Version: tagSelected})
+	assert.Equal(t, upgrade, module.Version{Path: "k8s", Version: "1.31"})
 }
 
 func testUpgradeAllToLatest(t *testing.T) {
 	pkg_path := getTestDir("test_with_external_deps")
 	assert.Equal(t, utils.DirExists(filepath.Join(pkg_path, "kcl.mod")), true)
-	kpmcli, err := client.NewKpmClient()
-	assert.Equal(t, err, nil)
+	kpmcli := newTestKpmClient(t)
 	kclPkg, err := kpmcli.LoadPkgFromPath(pkg_path)
 	assert.Equal(t, err, nil)
 
@@ -150,17 +198,12 @@ func testUpgradeAllToLatest(t *testing.T) {
 	upgrade, err := mvs.UpgradeAll(target, reqs)
 	assert.Equal(t, err, nil)
 
-	ociClient, err := oci.NewOciClient("ghcr.io", "kcl-lang/k8s", settings.GetSettings())
-	assert.Equal(t, err, nil)
-	tagSelected, err := ociClient.TheLatestTag()
-	assert.Equal(t, err, nil)
-
 	expectedReqs := []module.Version{
 		{Path: "test_with_external_deps", Version: "0.0.1"},
 		{Path: "argo-cd-order", Version: "0.2.0"},
 		{Path: "helloworld", Version: "0.1.4"},
 		{Path: "json_merge_patch", Version: "0.1.1"},
-		{Path: "k8s", Version: tagSelected},
+		{Path: "k8s", Version: "1.31"},
 		{Path: "podinfo", Version: "0.2.1"},
 	}
 	assert.Equal(t, expectedReqs, upgrade)
@@ -169,8 +212,7 @@ func testUpgradeAllToLatest(t *testing.T) {
 func testPrevious(t *testing.T) {
 	pkg_path := getTestDir("test_with_external_deps")
 	assert.Equal(t, utils.DirExists(filepath.Join(pkg_path, "kcl.mod")), true)
-	kpmcli, err := client.NewKpmClient()
-	assert.Equal(t, err, nil)
+	kpmcli := newTestKpmClient(t)
 	kclPkg, err := kpmcli.LoadPkgFromPath(pkg_path)
 	assert.Equal(t, err, nil)
 
@@ -217,8 +259,7 @@ func testUpgradePreviousOfLocalDependency(t *testing.T) {
 func testDowngrade(t *testing.T) {
 	pkg_path := getTestDir("test_with_external_deps")
 	assert.Equal(t, utils.DirExists(filepath.Join(pkg_path, "kcl.mod")), true)
-	kpmcli, err := client.NewKpmClient()
-	assert.Equal(t, err, nil)
+	kpmcli := newTestKpmClient(t)
 	kclPkg, err := kpmcli.LoadPkgFromPath(pkg_path)
 	assert.Equal(t, err, nil)
 
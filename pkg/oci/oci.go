@@ -1,6 +1,9 @@
 package oci
 
 import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
 	"context"
 	"crypto/tls"
 	"encoding/json"
@@ -10,6 +13,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -23,6 +27,7 @@ import (
 	dockerauth "oras.land/oras-go/pkg/auth/docker"
 	remoteauth "oras.land/oras-go/v2/registry/remote/auth"
 
+	"kcl-lang.io/kpm/pkg/constants"
 	"kcl-lang.io/kpm/pkg/opt"
 	"kcl-lang.io/kpm/pkg/reporter"
 	"kcl-lang.io/kpm/pkg/semver"
@@ -32,12 +37,17 @@ import (
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/content/file"
+	ocilayout "oras.land/oras-go/v2/content/oci"
+	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/errcode"
 )
 
 const OCI_SCHEME = "oci"
 const DEFAULT_OCI_ARTIFACT_TYPE = "application/vnd.oci.image.layer.v1.tar"
+
+// OCI_DOCS_MEDIA_TYPE is the media type of the layer with the package docs, which is skipped when pulling the package.
+const OCI_DOCS_MEDIA_TYPE = "application/vnd.kcl.package.docs.v1.tar+gzip"
 const (
 	OciErrorCodeNameUnknown  = "NAME_UNKNOWN"
 	OciErrorCodeRepoNotFound = "NOT_FOUND"
@@ -93,9 +103,19 @@ func Logout(hostname string, setting *settings.Settings) error {
 	return nil
 }
 
+// ociRepository is the storage of the kcl packages,
+// it is a repository in the OCI registry or a local OCI image layout directory.
+type ociRepository interface {
+	oras.GraphTarget
+	registry.TagLister
+}
+
 // OciClient is mainly responsible for interacting with OCI registry
 type OciClient struct {
-	repo                  *remote.Repository
+	repo ociRepository
+	// reference is the reference of the repository used in the messages,
+	// e.g. 'ghcr.io/kcl-lang/k8s' or 'oci+file:///srv/kcl-registry/k8s'.
+	reference             string
 	ctx                   *context.Context
 	logWriter             io.Writer
 	settings              *settings.Settings
@@ -127,11 +147,29 @@ func WithSettings(settings *settings.Settings) OciClientOption {
 // WithRepoPath sets the repo path of the OciClient
 func WithRepoPath(repoPath string) OciClientOption {
 	return func(c *OciClient) error {
-		var err error
-		c.repo, err = remote.NewRepository(repoPath)
+		repo, err := remote.NewRepository(repoPath)
 		if err != nil {
 			return fmt.Errorf("repository '%s' not found", repoPath)
 		}
+		c.repo = repo
+		c.reference = repo.Reference.String()
+		return nil
+	}
+}
+
+// WithLayoutPath sets the local OCI image layout directory 'layoutPath' as the repository of the OciClient,
+// the directory contains the 'oci-layout' and 'index.json' files, and the tags are the versions of the package in it.
+func WithLayoutPath(layoutPath string) OciClientOption {
+	return func(c *OciClient) error {
+		if !utils.DirExists(layoutPath) {
+			return fmt.Errorf("oci layout '%s' not found", layoutPath)
+		}
+		store, err := ocilayout.New(layoutPath)
+		if err != nil {
+			return fmt.Errorf("failed to open the oci layout '%s': %w", layoutPath, err)
+		}
+		c.repo = store
+		c.reference = fmt.Sprintf("%s://%s", constants.OciFileScheme, filepath.ToSlash(layoutPath))
 		return nil
 	}
 }
@@ -144,6 +182,14 @@ func WithCredential(credential *remoteauth.Credential) OciClientOption {
 	}
 }
 
+// WithContext sets the context of the OciClient, the requests to the registry are aborted when it is canceled.
+func WithContext(ctx context.Context) OciClientOption {
+	return func(c *OciClient) error {
+		c.ctx = &ctx
+		return nil
+	}
+}
+
 // WithPlainHttp sets the plain http of the OciClient
 func WithPlainHttp(plainHttp bool) OciClientOption {
 	return func(c *OciClient) error {
@@ -162,7 +208,7 @@ func (ociClient *OciClient) SetLogWriter(writer io.Writer) {
 }
 
 func (ociClient *OciClient) GetReference() string {
-	return ociClient.repo.Reference.String()
+	return ociClient.reference
 }
 
 // NewOciClientWithOpts will new an OciClient with options.
@@ -175,9 +221,35 @@ func NewOciClientWithOpts(opts ...OciClientOption) (*OciClient, error) {
 		}
 	}
 
+	if client.repo == nil {
+		return nil, fmt.Errorf("the repository of the oci client is required")
+	}
+
+	if repo, ok := client.repo.(*remote.Repository); ok {
+		repo.Client = client.authClient(repo.Reference.Host())
+		repo.PlainHTTP = client.plainHttp(repo.Reference.String())
+	}
+
+	if client.ctx == nil {
+		ctx := context.Background()
+		client.ctx = &ctx
+	}
+	client.PullOciOptions = &PullOciOptions{
+		CopyOpts: &oras.CopyOptions{
+			CopyGraphOptions: oras.CopyGraphOptions{
+				MaxMetadataBytes: DEFAULT_LIMIT_STORE_SIZE, // default is 64 MiB
+			},
+		},
+	}
+
+	return client, nil
+}
+
+// authClient returns the http client to access the registry 'host' with the credential of the OciClient.
+func (ociClient *OciClient) authClient(host string) *remoteauth.Client {
 	customTransport := &http.Transport{
 		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: client.insecureSkipTLSverify,
+			InsecureSkipVerify: ociClient.insecureSkipTLSverify,
 		},
 	}
 
@@ -185,43 +257,85 @@ func NewOciClientWithOpts(opts ...OciClientOption) (*OciClient, error) {
 		Transport: customTransport,
 	}
 
-	ctx := context.Background()
-	client.repo.Client = &remoteauth.Client{
+	cred := remoteauth.EmptyCredential
+	if ociClient.cred != nil {
+		cred = *ociClient.cred
+	}
+
+	return &remoteauth.Client{
 		Client:     customClient,
 		Cache:      remoteauth.DefaultCache,
-		Credential: remoteauth.StaticCredential(client.repo.Reference.Host(), *client.cred),
+		Credential: remoteauth.StaticCredential(host, cred),
 	}
+}
 
-	// If the plain http is not specified
-	if client.isPlainHttp == nil {
-		// Set the default value of the plain http
-		registry := client.repo.Reference.String()
-		host, _, _ := net.SplitHostPort(registry)
-		if host == "localhost" || registry == "localhost" {
-			// not specified, defaults to plain http for localhost
-			client.repo.PlainHTTP = true
+// plainHttp returns whether to access the 'registry' by plain http.
+func (ociClient *OciClient) plainHttp(registry string) bool {
+	if ociClient.isPlainHttp != nil {
+		return *ociClient.isPlainHttp
+	}
+	return isPlainHttpRegistry(registry, ociClient.settings)
+}
+
+// isPlainHttpRegistry returns whether to access the 'registry' by plain http by default,
+// the registry on localhost is accessed by plain http unless it is overridden by 'kpmSettings'.
+func isPlainHttpRegistry(registry string, kpmSettings *settings.Settings) bool {
+	var plainHttp bool
+	// Set the default value of the plain http
+	host, _, _ := net.SplitHostPort(registry)
+	if host == "localhost" || registry == "localhost" {
+		// not specified, defaults to plain http for localhost
+		plainHttp = true
+	}
+
+	// If the plain http is specified in the settings file
+	// Override the default value of the plain http
+	if kpmSettings != nil {
+		isPlainHttp, force := kpmSettings.ForceOciPlainHttp()
+		if force {
+			plainHttp = isPlainHttp
 		}
+	}
 
-		// If the plain http is specified in the settings file
-		// Override the default value of the plain http
-		if client.settings != nil {
-			isPlainHttp, force := client.settings.ForceOciPlainHttp()
-			if force {
-				client.repo.PlainHTTP = isPlainHttp
-			}
+	return plainHttp
+}
+
+// ListRepositories lists the repositories of the registry 'reg' by the OCI catalog API '/v2/_catalog'.
+// The repository path of the options is ignored, and some registries, e.g. 'ghcr.io', do not support the catalog API.
+func ListRepositories(reg string, opts ...OciClientOption) ([]string, error) {
+	client := &OciClient{}
+	for _, opt := range opts {
+		err := opt(client)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	client.ctx = &ctx
-	client.PullOciOptions = &PullOciOptions{
-		CopyOpts: &oras.CopyOptions{
-			CopyGraphOptions: oras.CopyGraphOptions{
-				MaxMetadataBytes: DEFAULT_LIMIT_STORE_SIZE, // default is 64 MiB
-			},
-		},
+	registry, err := remote.NewRegistry(reg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry '%s': %w", reg, err)
 	}
+	registry.Client = client.authClient(registry.Reference.Host())
+	registry.PlainHTTP = client.plainHttp(registry.Reference.Registry)
 
-	return client, nil
+	ctx := context.Background()
+	if client.ctx != nil {
+		ctx = *client.ctx
+	}
+	var repos []string
+	err = registry.Repositories(ctx, "", func(page []string) error {
+		repos = append(repos, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, reporter.NewErrorEvent(
+			reporter.FailedListRepositories,
+			err,
+			fmt.Sprintf("failed to list the repositories of '%s', the registry may not support the catalog API", reg),
+		)
+	}
+
+	return repos, nil
 }
 
 // NewOciClient will new an OciClient.
@@ -264,7 +378,7 @@ func (ociClient *OciClient) Pull(localPath, tag string) error {
 		return reporter.NewErrorEvent(
 			reporter.FailedGetPkg,
 			err,
-			fmt.Sprintf("failed to get package with '%s' from '%s'", tag, ociClient.repo.Reference.String()),
+			fmt.Sprintf("failed to get package with '%s' from '%s'", tag, ociClient.reference),
 		)
 	}
 
@@ -273,29 +387,76 @@ func (ociClient *OciClient) Pull(localPath, tag string) error {
 
 // TheLatestTag will return the latest tag of the kcl packages.
 func (ociClient *OciClient) TheLatestTag() (string, error) {
-	var tagSelected string
+	allTags, err := ociClient.ListTags()
 
-	err := ociClient.repo.Tags(*ociClient.ctx, "", func(tags []string) error {
-		var err error
-		tagSelected, err = semver.LatestVersion(tags)
-		if err != nil {
-			return err
-		}
-
-		return nil
-	})
+	var tagSelected string
+	if err == nil {
+		// The yanked versions are not selected as the latest version.
+		tagSelected, err = ociClient.SelectUnyankedVersion(allTags, semver.LatestVersion)
+	}
 
 	if err != nil {
 		return "", reporter.NewErrorEvent(
 			reporter.FailedSelectLatestVersion,
 			err,
-			fmt.Sprintf("failed to select latest version from '%s'", ociClient.repo.Reference.String()),
+			fmt.Sprintf("failed to select latest version from '%s'", ociClient.reference),
 		)
 	}
 
 	return tagSelected, nil
 }
 
+// ListTags will return all the tags of the repository.
+// The error of the registry is returned as it is, so that the callers can check whether the repo exists.
+func (ociClient *OciClient) ListTags() ([]string, error) {
+	var allTags []string
+	err := ociClient.repo.Tags(*ociClient.ctx, "", func(tags []string) error {
+		allTags = append(allTags, tags...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return allTags, nil
+}
+
+// ReadLayerFile will read the file 'name' in the tar or tgz layer of the kcl package.
+// The layer is streamed from the registry, and the download stops once the file is found.
+func (ociClient *OciClient) ReadLayerFile(layer v1.Descriptor, name string) ([]byte, error) {
+	rc, err := ociClient.repo.Fetch(*ociClient.ctx, layer)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	br := bufio.NewReader(rc)
+	var r io.Reader = br
+	// The gzip stream starts with the magic number '1f 8b'.
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("file '%s' not found in the layer '%s'", name, layer.Digest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && path.Clean(header.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
 // RepoIsNotExist will check if the error is caused by the repo not found.
 func RepoIsNotExist(err error) bool {
 	errRes, ok := err.(*errcode.ErrorResponse)
@@ -313,13 +474,7 @@ func RepoIsNotExist(err error) bool {
 
 // ContainsTag will check if the tag exists in the repo.
 func (ociClient *OciClient) ContainsTag(tag string) (bool, *reporter.KpmEvent) {
-	var exists bool
-
-	err := ociClient.repo.Tags(*ociClient.ctx, "", func(tags []string) error {
-		exists = funk.ContainsString(tags, tag)
-		return nil
-	})
-
+	tags, err := ociClient.ListTags()
 	if err != nil {
 		// If the repo with tag is not found, return false.
 		if RepoIsNotExist(err) {
@@ -329,11 +484,11 @@ func (ociClient *OciClient) ContainsTag(tag string) (bool, *reporter.KpmEvent) {
 		return false, reporter.NewErrorEvent(
 			reporter.FailedGetPackageVersions,
 			err,
-			fmt.Sprintf("failed to access '%s'", ociClient.repo.Reference.String()),
+			fmt.Sprintf("failed to access '%s'", ociClient.reference),
 		)
 	}
 
-	return exists, nil
+	return funk.ContainsString(tags, tag), nil
 }
 
 // Push will push the oci artifacts to oci registry from local path
@@ -349,6 +504,8 @@ func (ociClient *OciClient) PushWithOciManifest(localPath, tag string, opts *opt
 		return reporter.NewErrorEvent(reporter.FailedPush, err, "Failed to load store path ", localPath)
 	}
 	defer fs.Close()
+	// The docs directory is packed by the file store, the tar is reproducible to keep the digest of the same docs.
+	fs.TarReproducible = true
 
 	// 1. Add files to a file store
 
@@ -366,6 +523,19 @@ func (ociClient *OciClient) PushWithOciManifest(localPath, tag string, opts *opt
 		fileDescriptors = append(fileDescriptors, fileDescriptor)
 	}
 
+	// The directory of the docs is packed into a gzipped tar by the file store.
+	if opts.DocsPath != "" {
+		docsPath, err := filepath.Abs(opts.DocsPath)
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedPush, err, fmt.Sprintf("Failed to add docs '%s'", opts.DocsPath))
+		}
+		docsDescriptor, err := fs.Add(*ociClient.ctx, "docs", OCI_DOCS_MEDIA_TYPE, docsPath)
+		if err != nil {
+			return reporter.NewErrorEvent(reporter.FailedPush, err, fmt.Sprintf("Failed to add docs '%s'", opts.DocsPath))
+		}
+		fileDescriptors = append(fileDescriptors, docsDescriptor)
+	}
+
 	// 2. Pack the files, tag the packed manifest and add metadata as annotations
 	packOpts := oras.PackManifestOptions{
 		ManifestAnnotations: opts.Annotations,
@@ -385,10 +555,10 @@ func (ociClient *OciClient) PushWithOciManifest(localPath, tag string, opts *opt
 	desc, err := oras.Copy(*ociClient.ctx, fs, tag, ociClient.repo, tag, oras.DefaultCopyOptions)
 
 	if err != nil {
-		return reporter.NewErrorEvent(reporter.FailedPush, err, fmt.Sprintf("failed to push '%s'", ociClient.repo.Reference))
+		return reporter.NewErrorEvent(reporter.FailedPush, err, fmt.Sprintf("failed to push '%s'", ociClient.reference))
 	}
 
-	reporter.ReportMsgTo(fmt.Sprintf("pushed [registry] %s", ociClient.repo.Reference), ociClient.logWriter)
+	reporter.ReportMsgTo(fmt.Sprintf("pushed [registry] %s", ociClient.reference), ociClient.logWriter)
 	reporter.ReportMsgTo(fmt.Sprintf("digest: %s", desc.Digest), ociClient.logWriter)
 	return nil
 }
@@ -475,8 +645,15 @@ func Push(localPath, hostName, repoName, tag string, settings *settings.Settings
 	return ociClient.Push(localPath, tag)
 }
 
+// GetAllImageTags lists the tags of the image 'imageName', e.g. 'oci://ghcr.io/kcl-lang/helloworld'.
+// The registry is accessed by plain http the same as the OciClient with the global kpm settings.
 func GetAllImageTags(imageName string) ([]string, error) {
 	sysCtx := &types.SystemContext{}
+	registry, _, _ := strings.Cut(strings.TrimPrefix(imageName, "oci://"), "/")
+	if isPlainHttpRegistry(registry, settings.GetSettings()) {
+		// The docker client falls back to plain http only if the verification of TLS is skipped.
+		sysCtx.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	}
 	ref, err := docker.ParseReference("//" + strings.TrimPrefix(imageName, "oci://"))
 	if err != nil {
 		log.Fatalf("Error parsing reference: %v", err)
@@ -515,7 +692,14 @@ func (popts *PullOciOptions) Successors(ctx context.Context, fetcher content.Fet
 			nodes = append(nodes, *manifest.Subject)
 		}
 		nodes = append(nodes, manifest.Config)
-		return append(nodes, manifest.Layers...), nil
+		for _, layer := range manifest.Layers {
+			// The docs of the package are not pulled with the package.
+			if layer.MediaType == OCI_DOCS_MEDIA_TYPE {
+				continue
+			}
+			nodes = append(nodes, layer)
+		}
+		return nodes, nil
 	case v1.MediaTypeImageIndex:
 		content, err := content.FetchAll(ctx, fetcher, node)
 		if err != nil {
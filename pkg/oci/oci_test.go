@@ -1,78 +1,63 @@
 package oci
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"kcl-lang.io/kpm/pkg/mock"
 	"kcl-lang.io/kpm/pkg/settings"
 	"kcl-lang.io/kpm/pkg/utils"
 )
 
-const testDataDir = "test_data"
-
-func getTestDir(subDir string) string {
-	pwd, _ := os.Getwd()
-	testDir := filepath.Join(pwd, testDataDir)
-	testDir = filepath.Join(testDir, subDir)
-
-	return testDir
-}
-
 func TestLogin(t *testing.T) {
-	testPath := getTestDir("test_login")
-	testConfPath := filepath.Join(testPath, "config.json")
-
-	// clean the test dir
-	if utils.DirExists(testConfPath) {
-		os.Remove(testConfPath)
-	}
+	reg, err := mock.NewOciRegistry(mock.WithOciAuth("test", "1234"))
+	assert.Equal(t, err, nil)
+	defer reg.Close()
 
 	settings := settings.Settings{
-		CredentialsFile: testConfPath,
+		CredentialsFile: filepath.Join(t.TempDir(), "config.json"),
 	}
 
-	hostName := "ghcr.io"
-	userName := "invalid_username"
-	userPwd := "invalid_password"
+	err = Login(reg.Host, "invalid_username", "invalid_password", &settings)
+	assert.Contains(t, err.Error(), fmt.Sprintf("failed to login '%s', please check registry, username and password is valid", reg.Host))
 
-	err := Login(hostName, userName, userPwd, &settings)
-	assert.Equal(t, err.Error(), "failed to login 'ghcr.io', please check registry, username and password is valid\nGet \"https://ghcr.io/v2/\": denied: denied\n")
+	err = Login(reg.Host, "test", "1234", &settings)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, utils.DirExists(settings.CredentialsFile), true)
 }
 
 func TestPull(t *testing.T) {
-	type TestCase struct {
-		Registry string
-		Image    string
-		Tag      string
-	}
-
-	testCases := []TestCase{
-		{"ghcr.io", "kusionstack/opsrule", "0.0.9"},
-		{"ghcr.io", "kcl-lang/helloworld", "0.1.2"},
-	}
-
-	defer func() {
-		err := os.RemoveAll(getTestDir("test_pull"))
+	reg, err := mock.NewOciRegistry(mock.WithOciAuth("test", "1234"))
+	assert.Equal(t, err, nil)
+	defer reg.Close()
+	kpmSettings := settings.GetSettings()
+	regSettings := *kpmSettings
+	reg.Configure(&regSettings)
+
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		_, err = reg.PushPkg(filepath.Join("..", "mock", "test_pkg", "helloworld_"+version), "test/helloworld")
 		assert.Equal(t, err, nil)
-	}()
+	}
 
-	for _, tc := range testCases {
-		client, err := NewOciClient(tc.Registry, tc.Image, settings.GetSettings())
+	for _, tag := range []string{"0.1.0", "0.2.0"} {
+		client, err := NewOciClient(reg.Host, "test/helloworld", &regSettings)
 		if err != nil {
 			t.Fatalf(err.Error())
 		}
 
-		tmpPath := filepath.Join(getTestDir("test_pull"), tc.Tag)
+		tmpPath := filepath.Join(t.TempDir(), tag)
 
 		err = os.MkdirAll(tmpPath, 0755)
 		assert.Equal(t, err, nil)
 
-		err = client.Pull(tmpPath, tc.Tag)
+		err = client.Pull(tmpPath, tag)
 		if err != nil {
 			t.Errorf(err.Error())
 		}
+		assert.Equal(t, utils.DirExists(filepath.Join(tmpPath, "helloworld_"+tag+".tar")), true)
 	}
 }
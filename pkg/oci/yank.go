@@ -0,0 +1,127 @@
+package oci
+
+import (
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/thoas/go-funk"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/registry"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/reporter"
+)
+
+// Yank marks the version 'tag' of the package as yanked by pushing an artifact referring to the manifest of the version,
+// the 'reason' is recorded in the 'org.kcllang.package.yanked.reason' annotation of the artifact.
+// The yanked version is skipped when selecting the latest version, but it can still be pulled if it is pinned exactly.
+func (ociClient *OciClient) Yank(tag, reason string) error {
+	ref := fmt.Sprintf("%s:%s", ociClient.reference, tag)
+	desc, err := ociClient.repo.Resolve(*ociClient.ctx, tag)
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedYank, err, fmt.Sprintf("failed to resolve '%s'", ref))
+	}
+
+	yanked, _, err := ociClient.yankedReason(desc)
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedYank, err, fmt.Sprintf("failed to check whether '%s' is yanked", ref))
+	}
+	if yanked {
+		reporter.ReportMsgTo(fmt.Sprintf("'%s' has already been yanked", ref), ociClient.logWriter)
+		return nil
+	}
+
+	yankDesc, err := oras.PackManifest(
+		*ociClient.ctx,
+		ociClient.repo,
+		oras.PackManifestVersion1_1,
+		constants.KCL_OCI_YANK_ARTIFACT_TYPE,
+		oras.PackManifestOptions{
+			Subject: &desc,
+			ManifestAnnotations: map[string]string{
+				constants.DEFAULT_KCL_OCI_MANIFEST_YANKED_REASON: reason,
+			},
+		},
+	)
+	if err != nil {
+		return reporter.NewErrorEvent(reporter.FailedYank, err, fmt.Sprintf("failed to yank '%s'", ref))
+	}
+
+	reporter.ReportMsgTo(fmt.Sprintf("yanked '%s'", ref), ociClient.logWriter)
+	reporter.ReportMsgTo(fmt.Sprintf("digest: %s", yankDesc.Digest), ociClient.logWriter)
+	return nil
+}
+
+// IsYanked returns true and the reason if the version 'tag' of the package is yanked.
+func (ociClient *OciClient) IsYanked(tag string) (bool, string, error) {
+	desc, err := ociClient.repo.Resolve(*ociClient.ctx, tag)
+	if err != nil {
+		return false, "", err
+	}
+	return ociClient.yankedReason(desc)
+}
+
+// yankedReason looks up the yank artifacts referring to the manifest 'desc'.
+func (ociClient *OciClient) yankedReason(desc v1.Descriptor) (bool, string, error) {
+	// The referrers API is used for the registries, and the predecessors are looked up for the oci layouts.
+	referrers, err := registry.Referrers(*ociClient.ctx, ociClient.repo, desc, constants.KCL_OCI_YANK_ARTIFACT_TYPE)
+	if err != nil {
+		return false, "", err
+	}
+
+	var yanked bool
+	var reason string
+	for _, referrer := range referrers {
+		// The registry may ignore the artifact type filter.
+		if referrer.ArtifactType != constants.KCL_OCI_YANK_ARTIFACT_TYPE {
+			continue
+		}
+		yanked = true
+		reason = referrer.Annotations[constants.DEFAULT_KCL_OCI_MANIFEST_YANKED_REASON]
+	}
+
+	return yanked, reason, nil
+}
+
+// SelectUnyankedVersion selects the version from 'versions' by 'selector', and the yanked versions are skipped.
+// The selected version is returned without checking if the registry fails to list the referrers of it,
+// and 'errors.AllVersionsYanked' is returned if all the versions that can be selected are yanked.
+func (ociClient *OciClient) SelectUnyankedVersion(versions []string, selector func([]string) (string, error)) (string, error) {
+	candidates := versions
+	for {
+		selected, err := selector(candidates)
+		if err != nil {
+			if len(candidates) < len(versions) {
+				return "", errors.AllVersionsYanked
+			}
+			return "", err
+		}
+
+		yanked, reason, err := ociClient.IsYanked(selected)
+		if err != nil {
+			reporter.ReportMsgTo(
+				fmt.Sprintf("failed to check whether '%s:%s' is yanked: %v", ociClient.reference, selected, err),
+				ociClient.logWriter,
+			)
+			return selected, nil
+		}
+		if !yanked {
+			return selected, nil
+		}
+
+		reporter.ReportEventTo(
+			reporter.NewEvent(reporter.YankedVersion, fmt.Sprintf("skip the yanked version '%s:%s'%s", ociClient.reference, selected, yankedReasonSuffix(reason))),
+			ociClient.logWriter,
+		)
+		candidates = funk.FilterString(candidates, func(v string) bool { return v != selected })
+	}
+}
+
+// yankedReasonSuffix returns the suffix of the message about the yanked version with the 'reason'.
+func yankedReasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", reason)
+}
@@ -0,0 +1,178 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+
+	"kcl-lang.io/kpm/pkg/constants"
+	"kcl-lang.io/kpm/pkg/errors"
+	"kcl-lang.io/kpm/pkg/semver"
+)
+
+// testRegistry is an in-memory registry of a single repository supporting the push, the pull and the referrers API.
+type testRegistry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	tags      map[string]string
+}
+
+func newTestRegistry(t *testing.T) (*testRegistry, string) {
+	reg := &testRegistry{
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+		tags:      map[string]string{},
+	}
+	server := httptest.NewServer(reg)
+	t.Cleanup(server.Close)
+	// The plain http is used for the localhost registry.
+	return reg, strings.Replace(strings.TrimPrefix(server.URL, "http://"), "127.0.0.1", "localhost", 1)
+}
+
+// tag stores the manifest of the version 'tag' and tags it.
+func (reg *testRegistry) tag(tag string) {
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     v1.MediaTypeImageManifest,
+		"config":        v1.DescriptorEmptyJSON,
+		"layers":        []v1.Descriptor{},
+		"annotations":   map[string]string{constants.DEFAULT_KCL_OCI_MANIFEST_VERSION: tag},
+	})
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(manifest))
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.manifests[digest] = manifest
+	reg.tags[tag] = digest
+}
+
+func (reg *testRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/test/pkg/")
+	switch {
+	case path == "tags/list":
+		var tags []string
+		for tag := range reg.tags {
+			tags = append(tags, tag)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": "test/pkg", "tags": tags})
+	case path == "blobs/uploads/":
+		w.Header().Set("Location", "/v2/test/pkg/blobs/uploads/1")
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasPrefix(path, "blobs/uploads/"):
+		content, _ := io.ReadAll(r.Body)
+		reg.blobs[r.URL.Query().Get("digest")] = content
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "blobs/"):
+		content, ok := reg.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		_, _ = w.Write(content)
+	case strings.HasPrefix(path, "manifests/"):
+		ref := strings.TrimPrefix(path, "manifests/")
+		if r.Method == http.MethodPut {
+			content, _ := io.ReadAll(r.Body)
+			digest := fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+			reg.manifests[digest] = content
+			if !strings.HasPrefix(ref, "sha256:") {
+				reg.tags[ref] = digest
+			}
+			if strings.Contains(string(content), `"subject"`) {
+				w.Header().Set("OCI-Subject", digest)
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if digest, ok := reg.tags[ref]; ok {
+			ref = digest
+		}
+		content, ok := reg.manifests[ref]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", v1.MediaTypeImageManifest)
+		w.Header().Set("Docker-Content-Digest", ref)
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(content)
+		}
+	case strings.HasPrefix(path, "referrers/"):
+		subject := strings.TrimPrefix(path, "referrers/")
+		referrers := []map[string]interface{}{}
+		for digest, content := range reg.manifests {
+			manifest := v1.Manifest{}
+			if err := json.Unmarshal(content, &manifest); err != nil || manifest.Subject == nil || string(manifest.Subject.Digest) != subject {
+				continue
+			}
+			referrers = append(referrers, map[string]interface{}{
+				"mediaType":    v1.MediaTypeImageManifest,
+				"digest":       digest,
+				"size":         len(content),
+				"artifactType": manifest.ArtifactType,
+				"annotations":  manifest.Annotations,
+			})
+		}
+		w.Header().Set("Content-Type", v1.MediaTypeImageIndex)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"schemaVersion": 2,
+			"mediaType":     v1.MediaTypeImageIndex,
+			"manifests":     referrers,
+		})
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+func TestYank(t *testing.T) {
+	reg, host := newTestRegistry(t)
+	reg.tag("0.1.0")
+	reg.tag("0.1.1")
+	reg.tag("0.2.0")
+
+	ociCli, err := NewOciClientWithOpts(WithRepoPath(host + "/test/pkg"))
+	assert.Nil(t, err)
+
+	latest, err := ociCli.TheLatestTag()
+	assert.Nil(t, err)
+	assert.Equal(t, "0.2.0", latest)
+
+	assert.Nil(t, ociCli.Yank("0.2.0", "broken"))
+	// Yanking the yanked version again does nothing.
+	assert.Nil(t, ociCli.Yank("0.2.0", "broken again"))
+
+	yanked, reason, err := ociCli.IsYanked("0.2.0")
+	assert.Nil(t, err)
+	assert.True(t, yanked)
+	assert.Equal(t, "broken", reason)
+
+	yanked, _, err = ociCli.IsYanked("0.1.1")
+	assert.Nil(t, err)
+	assert.False(t, yanked)
+
+	latest, err = ociCli.TheLatestTag()
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1.1", latest)
+
+	assert.Nil(t, ociCli.Yank("0.1.1", ""))
+	assert.Nil(t, ociCli.Yank("0.1.0", ""))
+	_, err = ociCli.SelectUnyankedVersion([]string{"0.1.0", "0.1.1", "0.2.0"}, semver.LatestVersion)
+	assert.Equal(t, errors.AllVersionsYanked, err)
+
+	assert.NotNil(t, ociCli.Yank("0.3.0", ""))
+}
@@ -417,6 +417,9 @@ type OciOptions struct {
 	// Annotations denotes the additional annotation map for the OCI manifest.
 	// +optional
 	Annotations map[string]string
+	// DocsPath denotes the directory of the package docs pushed as an extra layer of the OCI manifest.
+	// +optional
+	DocsPath string
 	// InsecureSkipTLSverify denotes whether to skip the verification of the certificate.
 	// +optional
 	InsecureSkipTLSverify bool
@@ -506,6 +509,8 @@ func (oci *OciOptions) SanitizePathWithSuffix(pathPrefix string) string {
 
 type OciManifestOptions struct {
 	Annotations map[string]string
+	// DocsPath is the directory of the package docs, it is pushed as an extra layer if it is not empty.
+	DocsPath string
 }
 
 // OciFetchOptions is the input options of the api to fetch oci manifest.
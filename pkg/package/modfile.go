@@ -26,6 +26,7 @@ const (
 	MOD_LOCK_FILE = "kcl.mod.lock"
 	GIT           = "git"
 	OCI           = "oci"
+	HTTP          = "http"
 	LOCAL         = "local"
 )
 
@@ -54,6 +55,8 @@ type ModFile struct {
 	// in the current package directory.
 	VendorMode bool     `toml:"-"`
 	Profiles   *Profile `toml:"profile"`
+	// Registries are the named OCI registries to resolve the shorthand dependencies of the package by their scopes.
+	Registries []settings.OciRegistry `toml:"-"`
 	Dependencies
 }
 
@@ -245,12 +248,25 @@ func (d Dependency) Equals(other Dependency) bool {
 
 	sameOciSrc := true
 	if d.Source.Oci != nil && other.Source.Oci != nil {
-		sameOciSrc = d.Source.Oci.Reg == other.Source.Oci.Reg &&
+		sameOciSrc = d.Source.Oci.Layout == other.Source.Oci.Layout &&
+			d.Source.Oci.Reg == other.Source.Oci.Reg &&
 			d.Source.Oci.Repo == other.Source.Oci.Repo &&
 			d.Source.Oci.Tag == other.Source.Oci.Tag
 	}
 
-	return sameNameAndVersion && sameGitSrc && sameOciSrc
+	sameHttpSrc := true
+	if d.Source.Http != nil && other.Source.Http != nil {
+		sameHttpSrc = d.Source.Http.HttpUrl == other.Source.Http.HttpUrl &&
+			d.Source.Http.Sha256 == other.Source.Http.Sha256
+	}
+
+	sameCustomSrc := true
+	if d.Source.Custom != nil && other.Source.Custom != nil {
+		sameCustomSrc = d.Source.Custom.CustomUrl == other.Source.Custom.CustomUrl &&
+			d.Source.Custom.Tag == other.Source.Custom.Tag
+	}
+
+	return sameNameAndVersion && sameGitSrc && sameOciSrc && sameHttpSrc && sameCustomSrc
 }
 
 // GetLocalFullPath will get the local path of a dependency.
@@ -291,7 +307,7 @@ func (d *Dependency) GenPathSuffix() string {
 }
 
 func (dep *Dependency) IsFromLocal() bool {
-	return dep.Source.Oci == nil && dep.Source.Git == nil && dep.Source.Local != nil
+	return dep.Source.Oci == nil && dep.Source.Git == nil && dep.Source.Http == nil && dep.Source.Custom == nil && dep.Source.Local != nil
 }
 
 // GenDepFullName will generate the full name of a dependency by its name and version
@@ -321,6 +337,12 @@ func (dep *Dependency) GetDownloadPath() string {
 	if dep.Source.Oci != nil {
 		return dep.Source.Oci.IntoOciUrl()
 	}
+	if dep.Source.Http != nil {
+		return dep.Source.Http.HttpUrl
+	}
+	if dep.Source.Custom != nil {
+		return dep.Source.Custom.CustomUrl
+	}
 	return ""
 }
 
@@ -357,6 +379,12 @@ func (dep *Dependency) GetSourceType() string {
 	if dep.Source.Oci != nil {
 		return OCI
 	}
+	if dep.Source.Http != nil {
+		return HTTP
+	}
+	if dep.Source.Custom != nil {
+		return dep.Source.Custom.Scheme()
+	}
 	if dep.Source.Local != nil {
 		return LOCAL
 	}
@@ -116,8 +116,8 @@ func LoadKclPkgWithOpts(options ...LoadOption) (*KclPkg, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not load 'kcl.mod' in '%s'\n%w", pkgPath, err)
 	}
-	// 2. Fill the default oci registry, the default oci registry is in the settings.
-	err = fillDepsInfoWithSettings(&modFile.Dependencies, opts.Settings)
+	// 2. Fill the oci registry resolved by the named registries in kcl.mod and the settings.
+	err = fillDepsInfoWithSettings(&modFile.Dependencies, opts.Settings, modFile.Registries...)
 	if err != nil {
 		return nil, fmt.Errorf("could not load 'kcl.mod' in '%s'\n%w", pkgPath, err)
 	}
@@ -131,19 +131,15 @@ func LoadKclPkgWithOpts(options ...LoadOption) (*KclPkg, error) {
 			lockDep.Source = modDep.Source
 			lockDep.LocalFullPath = modDep.LocalFullPath
 		} else {
-			// If there is no source in the lock file, fill the default oci registry.
+			// If there is no source in the lock file, fill the resolved oci registry.
 			if lockDep.Source.IsNilSource() {
 				lockDep.Source = downloader.Source{
 					ModSpec: &downloader.ModSpec{
 						Name:    lockDep.Name,
 						Version: lockDep.Version,
 					},
-					Oci: &downloader.Oci{
-						Reg:  opts.Settings.DefaultOciRegistry(),
-						Repo: utils.JoinPath(opts.Settings.DefaultOciRepo(), lockDep.Name),
-						Tag:  lockDep.Version,
-					},
 				}
+				lockDep.Source.ResolveSpecOnly(opts.Settings, modFile.Registries...)
 			}
 		}
 		deps.Deps.Set(name, lockDep)
@@ -182,8 +178,8 @@ func LoadAndFillModFileWithOpts(options ...LoadOption) (*ModFile, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not load 'kcl.mod' in '%s'\n%w", path, err)
 	}
-	// 2. Fill the default oci registry, the default oci registry is in the settings.
-	err = fillDepsInfoWithSettings(&modFile.Dependencies, opts.Settings)
+	// 2. Fill the oci registry resolved by the named registries in kcl.mod and the settings.
+	err = fillDepsInfoWithSettings(&modFile.Dependencies, opts.Settings, modFile.Registries...)
 	if err != nil {
 		return nil, fmt.Errorf("could not load 'kcl.mod' in '%s'\n%w", path, err)
 	}
@@ -212,37 +208,37 @@ func convertDepsLocalPathToAbsPath(deps *Dependencies, rootPath string) error {
 			}
 			dep.LocalFullPath = localFullPath
 		}
+		// Transform the path of the local OCI image layout to the absolute path, the path in kcl.mod is kept.
+		if dep.Source.Oci != nil && dep.Source.Oci.Layout {
+			layoutFullPath := dep.Source.Oci.Repo
+			if !filepath.IsAbs(layoutFullPath) {
+				var err error
+				layoutFullPath, err = filepath.Abs(filepath.Join(rootPath, layoutFullPath))
+				if err != nil {
+					return fmt.Errorf("failed to get the absolute path of the oci layout dependency %s: %w", name, err)
+				}
+			}
+			dep.Source.Oci.LayoutFullPath = layoutFullPath
+		}
 		deps.Deps.Set(name, dep)
 	}
 
 	return nil
 }
 
-// `fillDepsInfoWithSettings` will fill the default oci registry info in dependencies.
-func fillDepsInfoWithSettings(deps *Dependencies, settings *settings.Settings) error {
+// `fillDepsInfoWithSettings` will fill the oci registry info in dependencies,
+// the oci registry is resolved by the named 'registries' in kcl.mod and the settings.
+func fillDepsInfoWithSettings(deps *Dependencies, settings *settings.Settings, registries ...settings.OciRegistry) error {
 	for _, name := range deps.Deps.Keys() {
 		dep, ok := deps.Deps.Get(name)
 		if !ok {
 			break
 		}
-		// Fill the default oci registry.
+		// Fill the resolved oci registry.
 		if dep.Source.Oci != nil {
-			if len(dep.Source.Oci.Reg) == 0 {
-				dep.Source.Oci.Reg = settings.DefaultOciRegistry()
-			}
-
-			if len(dep.Source.Oci.Repo) == 0 {
-				urlpath := utils.JoinPath(settings.DefaultOciRepo(), dep.Name)
-				dep.Source.Oci.Repo = urlpath
-			}
-		}
-		if dep.Source.SpecOnly() {
-			dep.Source.Oci = &downloader.Oci{
-				Reg:  settings.DefaultOciRegistry(),
-				Repo: utils.JoinPath(settings.DefaultOciRepo(), dep.ModSpec.Name),
-				Tag:  dep.ModSpec.Version,
-			}
+			dep.Source.Oci.FillRepo(dep.Name, settings, registries...)
 		}
+		dep.Source.ResolveSpecOnly(settings, registries...)
 		dep.FullName = dep.GenDepFullName()
 		deps.Deps.Set(name, dep)
 	}
@@ -527,6 +523,11 @@ func (kclPkg *KclPkg) GetPkgTarName() string {
 	return kclPkg.GetPkgFullName() + TAR_SUFFIX
 }
 
+// GetPkgTgzName returns the kcl package gzip compressed tar name "<package_name>-v<package_version>.tgz"
+func (kclPkg *KclPkg) GetPkgTgzName() string {
+	return kclPkg.GetPkgFullName() + constants.TgzPathSuffix
+}
+
 const LOCK_FILE_NAME = "kcl.mod.lock"
 
 // GetLockFilePath returns the abs path of kcl.mod.lock.
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/assert"
 	"kcl-lang.io/kpm/pkg/env"
 	"kcl-lang.io/kpm/pkg/opt"
@@ -218,3 +219,63 @@ func TestLockDepsVersionNoChange(t *testing.T) {
 	assert.Equal(t, initialModTime, updatedModTime, "kcl.mod.lock should not be modified")
 	assert.Equal(t, string(initialLockContent), string(updatedLockContent), "kcl.mod.lock content should remain the same")
 }
+
+func TestLoadKclPkgWithRegistries(t *testing.T) {
+	kMod, err := LoadKclPkgWithOpts(
+		WithPath(getTestDir("load_with_registries")),
+		WithSettings(settings.GetSettings()),
+	)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, kMod.ModFile.Registries, []settings.OciRegistry{{
+		Name:     "acme",
+		Registry: "registry.acme.com",
+		Repo:     "kcl",
+		Scopes:   []string{"acme-*"},
+		Priority: 10,
+	}})
+
+	acme := kMod.ModFile.Dependencies.Deps.GetOrDefault("acme-base", TestPkgDependency)
+	assert.Equal(t, acme.Source.Oci.Reg, "registry.acme.com")
+	assert.Equal(t, acme.Source.Oci.Repo, "kcl/acme-base")
+	assert.Equal(t, acme.Source.Oci.Tag, "0.1.0")
+
+	helloworld := kMod.ModFile.Dependencies.Deps.GetOrDefault("helloworld", TestPkgDependency)
+	assert.Equal(t, helloworld.Source.Oci.Reg, settings.GetSettings().DefaultOciRegistry())
+	assert.Equal(t, helloworld.Source.Oci.Repo, utils.JoinPath(settings.GetSettings().DefaultOciRepo(), "helloworld"))
+
+	modFile := ModFile{}
+	err = toml.Unmarshal([]byte(kMod.ModFile.MarshalTOML()), &modFile)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, modFile.Registries, kMod.ModFile.Registries)
+}
+
+func TestLoadKclPkgWithRelativeOciLayout(t *testing.T) {
+	pkgPath := t.TempDir()
+	err := os.WriteFile(filepath.Join(pkgPath, "kcl.mod"), []byte(`[package]
+name = "relative_layout"
+edition = "v0.9.0"
+version = "0.0.1"
+
+[dependencies]
+k8s = { oci = "oci+file:layouts/k8s", tag = "1.28" }
+`), 0644)
+	assert.Equal(t, err, nil)
+
+	// The path is resolved against the directory of kcl.mod instead of the working directory.
+	kMod, err := LoadKclPkgWithOpts(
+		WithPath(pkgPath),
+		WithSettings(settings.GetSettings()),
+	)
+	assert.Equal(t, err, nil)
+	k8s := kMod.ModFile.Dependencies.Deps.GetOrDefault("k8s", TestPkgDependency)
+	assert.Equal(t, k8s.Source.Oci.Layout, true)
+	assert.Equal(t, k8s.Source.Oci.GetLayoutPath(), filepath.Join(pkgPath, "layouts", "k8s"))
+
+	// The relative path is kept in kcl.mod and kcl.mod.lock.
+	assert.Contains(t, kMod.ModFile.MarshalTOML(), `oci = "oci+file:layouts/k8s"`)
+	kMod.Dependencies.Deps.Set("k8s", k8s)
+	lockContent, err := kMod.Dependencies.MarshalLockTOML()
+	assert.Equal(t, err, nil)
+	assert.Contains(t, lockContent, `repo = "layouts/k8s"`)
+	assert.NotContains(t, lockContent, pkgPath)
+}
@@ -0,0 +1,14 @@
+[package]
+name = "load_with_registries"
+edition = "v0.9.0"
+version = "0.0.1"
+
+[dependencies]
+acme-base = "0.1.0"
+helloworld = "0.1.4"
+
+[registries.acme]
+registry = "registry.acme.com"
+repo = "kcl"
+scopes = ["acme-*"]
+priority = 10
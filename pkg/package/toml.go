@@ -31,6 +31,7 @@ import (
 
 	"kcl-lang.io/kpm/pkg/downloader"
 	"kcl-lang.io/kpm/pkg/reporter"
+	"kcl-lang.io/kpm/pkg/settings"
 )
 
 const NEWLINE = "\n"
@@ -43,6 +44,11 @@ func (mod *ModFile) MarshalTOML() string {
 		sb.WriteString(NEWLINE)
 		sb.WriteString(dependencies)
 	}
+	registries := marshalRegistriesTOML(mod.Registries)
+	if registries != "" {
+		sb.WriteString(NEWLINE)
+		sb.WriteString(registries)
+	}
 	profiles := mod.Profiles.MarshalTOML()
 	if profiles != "" {
 		sb.WriteString(NEWLINE)
@@ -100,6 +106,32 @@ func (dep *Dependency) MarshalTOML() string {
 	return sb.String()
 }
 
+const REGISTRY_PATTERN = "[registries.%s]"
+
+// marshalRegistriesTOML marshals the named registries into the tables like:
+//
+// [registries.<registry_name>]
+// registry = "<registry_host>"
+// repo = "<registry_repo>"
+// scopes = ["<package_name_pattern>"]
+// priority = <priority>
+func marshalRegistriesTOML(registries []settings.OciRegistry) string {
+	var sb strings.Builder
+	for i, registry := range registries {
+		if i != 0 {
+			sb.WriteString(NEWLINE)
+		}
+		sb.WriteString(fmt.Sprintf(REGISTRY_PATTERN, registry.Name))
+		sb.WriteString(NEWLINE)
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(registry); err != nil {
+			return ""
+		}
+		sb.WriteString(buf.String())
+	}
+	return sb.String()
+}
+
 const PROFILE_PATTERN = "[profile]"
 
 func (p *Profile) MarshalTOML() string {
@@ -117,9 +149,10 @@ func (p *Profile) MarshalTOML() string {
 }
 
 const (
-	PACKAGE_FLAG  = "package"
-	DEPS_FLAG     = "dependencies"
-	PROFILES_FLAG = "profile"
+	PACKAGE_FLAG    = "package"
+	DEPS_FLAG       = "dependencies"
+	PROFILES_FLAG   = "profile"
+	REGISTRIES_FLAG = "registries"
 )
 
 func (mod *ModFile) UnmarshalTOML(data interface{}) error {
@@ -160,9 +193,50 @@ func (mod *ModFile) UnmarshalTOML(data interface{}) error {
 		}
 		mod.Profiles = &p
 	}
+
+	if v, ok := meta[REGISTRIES_FLAG]; ok {
+		registries, err := unmarshalRegistriesTOML(v)
+		if err != nil {
+			return err
+		}
+		mod.Registries = registries
+	}
 	return nil
 }
 
+// unmarshalRegistriesTOML unmarshals the named registries sorted by their names.
+func unmarshalRegistriesTOML(data interface{}) ([]settings.OciRegistry, error) {
+	meta, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected map[string]interface{}, got %T", data)
+	}
+
+	names := make([]string, 0, len(meta))
+	for name := range meta {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var registries []settings.OciRegistry
+	for _, name := range names {
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(meta[name]); err != nil {
+			return nil, err
+		}
+		registry := settings.OciRegistry{}
+		if err := toml.Unmarshal(buf.Bytes(), &registry); err != nil {
+			return nil, fmt.Errorf("invalid registry '%s': %w", name, err)
+		}
+		if registry.Registry == "" {
+			return nil, fmt.Errorf("invalid registry '%s': the 'registry' is required", name)
+		}
+		registry.Name = name
+		registries = append(registries, registry)
+	}
+
+	return registries, nil
+}
+
 const (
 	NAME_FLAG        = "name"
 	EDITION_FLAG     = "edition"
@@ -262,6 +336,9 @@ func (dep *Dependency) UnmarshalModTOML(data interface{}) error {
 	if source.Oci != nil {
 		version = source.Oci.Tag
 	}
+	if source.Custom != nil {
+		version = source.Custom.Tag
+	}
 
 	if source.ModSpec != nil {
 		version = source.ModSpec.Version
@@ -313,6 +390,17 @@ func (dep *Dependencies) UnmarshalLockTOML(data string) error {
 		return reporter.NewErrorEvent(reporter.FailedLoadKclModLock, err, "failed to load kcl.mod.lock")
 	}
 
+	// The git and the http sources share the 'url' key, which is skipped by the decoder as an ambiguous field,
+	// so it is decoded on its own and set to the http source if the dependency has the 'sha256' of a tarball.
+	lockUrlsUI := struct {
+		Deps map[string]struct {
+			Url string `toml:"url"`
+		} `toml:"dependencies"`
+	}{}
+	if _, err := toml.NewDecoder(strings.NewReader(data)).Decode(&lockUrlsUI); err != nil {
+		return reporter.NewErrorEvent(reporter.FailedLoadKclModLock, err, "failed to load kcl.mod.lock")
+	}
+
 	var keys []string
 	for k := range lockDepdenciesUI.Deps {
 		keys = append(keys, k)
@@ -320,7 +408,18 @@ func (dep *Dependencies) UnmarshalLockTOML(data string) error {
 	sort.Strings(keys)
 
 	for _, k := range keys {
-		dep.Deps.Set(k, lockDepdenciesUI.Deps[k])
+		d := lockDepdenciesUI.Deps[k]
+		if url := lockUrlsUI.Deps[k].Url; url != "" {
+			if d.Source.Http != nil {
+				d.Source.Http.HttpUrl = url
+			} else {
+				if d.Source.Git == nil {
+					d.Source.Git = &downloader.Git{}
+				}
+				d.Source.Git.Url = url
+			}
+		}
+		dep.Deps.Set(k, d)
 	}
 
 	return nil
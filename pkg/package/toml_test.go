@@ -168,6 +168,38 @@ func TestUnmarshalLockTOML(t *testing.T) {
 	assert.Equal(t, deps.Deps.GetOrDefault("MyOciKcl1", TestPkgDependency).Source.Oci.Tag, "0.0.1")
 }
 
+func TestLockTOMLWithHttpSource(t *testing.T) {
+	httpDep := Dependency{
+		Name:     "helloworld",
+		FullName: "helloworld_0.1.0",
+		Version:  "0.1.0",
+		Sum:      "hjkasdahjksdasdhjk",
+		Source: downloader.Source{
+			Http: &downloader.Http{
+				HttpUrl: "https://example.com/kcl/helloworld-0.1.0.tgz",
+				Sha256:  strings.Repeat("a", 64),
+			},
+		},
+	}
+
+	deps := Dependencies{
+		orderedmap.NewOrderedMap[string, Dependency](),
+	}
+	deps.Deps.Set(httpDep.Name, httpDep)
+	tomlStr, err := deps.MarshalLockTOML()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, strings.Contains(tomlStr, `url = "https://example.com/kcl/helloworld-0.1.0.tgz"`), true)
+
+	lockDeps := Dependencies{
+		orderedmap.NewOrderedMap[string, Dependency](),
+	}
+	assert.Equal(t, lockDeps.UnmarshalLockTOML(tomlStr), nil)
+	dep := lockDeps.Deps.GetOrDefault("helloworld", TestPkgDependency)
+	assert.Equal(t, dep.Source.Git == nil, true)
+	assert.Equal(t, dep.Source.Http.HttpUrl, "https://example.com/kcl/helloworld-0.1.0.tgz")
+	assert.Equal(t, dep.Source.Http.Sha256, strings.Repeat("a", 64))
+}
+
 func TestUnMarshalTOMLWithProfile(t *testing.T) {
 	modfile, err := LoadModFile(getTestDir("test_profile"))
 	assert.Equal(t, err, nil)
@@ -118,6 +118,11 @@ const (
 	CompileFailed
 	FailedParseVersion
 	FailedFetchOciManifest
+	FailedVerify
+	FailedListRepositories
+	FailedYank
+	YankedVersion
+	InitCanceled
 )
 
 // KpmEvent is the event used to show kpm logs to users.
@@ -145,6 +150,11 @@ func (e *KpmEvent) Error() string {
 	return result
 }
 
+// Unwrap returns the error of the event, so that the event can be checked by 'errors.Is' and 'errors.As'.
+func (e *KpmEvent) Unwrap() error {
+	return e.err
+}
+
 // Event returns the msg of the event without error message.
 func (e *KpmEvent) Event() string {
 	if e.msg != "" {
@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -74,6 +75,12 @@ type DepsResolver struct {
 
 // Resolve resolves the dependencies of the package.
 func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
+	return dr.ResolveWithContext(context.Background(), options...)
+}
+
+// ResolveWithContext resolves the dependencies of the package,
+// the resolving is stopped and the download of the remote dependencies is aborted when the context 'ctx' is canceled.
+func (dr *DepsResolver) ResolveWithContext(ctx context.Context, options ...ResolveOption) error {
 	opts := &ResolveOptions{}
 	for _, option := range options {
 		if err := option(opts); err != nil {
@@ -81,7 +88,7 @@ func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
 		}
 	}
 	// visitorSelectorFunc selects the visitor for the source.
-	// For remote source, it will use the RemoteVisitor and enable the cache.
+	// For remote source, including the custom scheme registered by downloader.RegisterDownloader, it will use the RemoteVisitor and enable the cache.
 	// For local source, it will use the PkgVisitor.
 	visitorSelectorFunc := func(source *downloader.Source) (visitor.Visitor, error) {
 		pkgVisitor := &visitor.PkgVisitor{
@@ -105,6 +112,7 @@ func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
 				CachePath:             cachePath,
 				VisitedSpace:          cachePath,
 				Offline:               opts.Offline,
+				Registries:            opts.kMod.ModFile.Registries,
 			}, nil
 		} else if source.IsLocalTarPath() || source.IsLocalTgzPath() {
 			return visitor.NewArchiveVisitor(pkgVisitor), nil
@@ -159,7 +167,7 @@ func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
 			return err
 		}
 
-		err = depVisitor.Visit(depSource, func(kclMod *pkg.KclPkg) error {
+		err = depVisitor.Visit(ctx, depSource, func(kclMod *pkg.KclPkg) error {
 			dep.FromKclPkg(kclMod)
 			for _, resolveFunc := range dr.ResolveFuncs {
 				err := resolveFunc(&dep, kMod)
@@ -167,7 +175,8 @@ func (dr *DepsResolver) Resolve(options ...ResolveOption) error {
 					return err
 				}
 			}
-			err = dr.Resolve(
+			err = dr.ResolveWithContext(
+				ctx,
 				WithResolveKclMod(kclMod),
 				WithEnableCache(opts.EnableCache),
 				WithCachePath(opts.CachePath),
@@ -10,7 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
-	"kcl-lang.io/kpm/pkg/env"
+	"kcl-lang.io/kpm/pkg/mock"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/settings"
 )
@@ -28,19 +28,29 @@ func getTestDir(subDir string) string {
 func TestResolver(t *testing.T) {
 	resolve_path := getTestDir("test_resolve_graph")
 	pkgPath := filepath.Join(resolve_path, "pkg")
-	defaultCachePath, err := env.GetAbsPkgPath()
+
+	reg, err := mock.NewOciRegistry()
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer reg.Close()
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		_, err = reg.PushPkg(filepath.Join("..", "mock", "test_pkg", "helloworld_"+version), "test/helloworld")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	regSettings := *settings.GetSettings()
+	reg.Configure(&regSettings)
 
 	var res []string
 	var buf bytes.Buffer
 
 	resolver := DepsResolver{
 		Downloader:       &downloader.DepDownloader{},
-		Settings:         settings.GetSettings(),
+		Settings:         &regSettings,
 		LogWriter:        &buf,
-		DefaultCachePath: defaultCachePath,
+		DefaultCachePath: t.TempDir(),
 		ResolveFuncs: []resolveFunc{func(dep *pkg.Dependency, parentPkg *pkg.KclPkg) error {
 			res = append(res, fmt.Sprintf("%s -> %s", parentPkg.GetPkgName(), dep.Name))
 			return nil
@@ -49,6 +59,7 @@ func TestResolver(t *testing.T) {
 
 	kMod, err := pkg.LoadKclPkgWithOpts(
 		pkg.WithPath(pkgPath),
+		pkg.WithSettings(&regSettings),
 	)
 
 	if err != nil {
@@ -4,4 +4,4 @@ edition = "v0.11.2"
 version = "0.0.1"
 
 [dependencies]
-helloworld = "0.1.1"
+helloworld = "0.1.0"
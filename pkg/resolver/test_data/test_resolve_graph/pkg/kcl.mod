@@ -5,4 +5,4 @@ version = "0.0.1"
 
 [dependencies]
 dep1 = { path = "../dep1" }
-helloworld = "0.1.2"
+helloworld = "0.2.0"
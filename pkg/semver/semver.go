@@ -2,6 +2,7 @@ package semver
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/hashicorp/go-version"
 	"kcl-lang.io/kpm/pkg/constants"
@@ -84,3 +85,24 @@ func LeastOldCompatibleVersion(versions []string, baseVersion string) (string, e
 	}
 	return OldestVersion(compatibleVersions)
 }
+
+// SortVersions sorts the versions in ascending order, the versions that are not semantic versions are kept at the end in their original order.
+func SortVersions(versions []string) []string {
+	var semvers []*version.Version
+	var others []string
+	for _, v := range versions {
+		ver, err := version.NewVersion(v)
+		if err != nil {
+			others = append(others, v)
+			continue
+		}
+		semvers = append(semvers, ver)
+	}
+	sort.Stable(version.Collection(semvers))
+
+	sorted := make([]string, 0, len(versions))
+	for _, ver := range semvers {
+		sorted = append(sorted, ver.Original())
+	}
+	return append(sorted, others...)
+}
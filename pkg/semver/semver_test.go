@@ -68,3 +68,10 @@ func TestFilterCompatibleVersions(t *testing.T) {
 		assert.Equal(t, v, expCompatible[i])
 	}
 }
+
+func TestSortVersions(t *testing.T) {
+	sorted := SortVersions([]string{"1.4.0", "latest", "0.1.0-beta", "1.2.3", "main", "1.10.0"})
+	assert.DeepEqual(t, sorted, []string{"0.1.0-beta", "1.2.3", "1.4.0", "1.10.0", "latest", "main"})
+
+	assert.DeepEqual(t, SortVersions(nil), []string{})
+}
@@ -0,0 +1,320 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/mod/module"
+	"kcl-lang.io/kpm/pkg/client"
+	"kcl-lang.io/kpm/pkg/downloader"
+	pkg "kcl-lang.io/kpm/pkg/package"
+)
+
+// The methods of the operations of the KpmClient.
+const (
+	METHOD_INIT     = "init"
+	METHOD_ADD      = "add"
+	METHOD_REMOVE   = "remove"
+	METHOD_UPDATE   = "update"
+	METHOD_GRAPH    = "graph"
+	METHOD_METADATA = "metadata"
+	METHOD_RUN      = "run"
+	METHOD_PULL     = "pull"
+	METHOD_PUSH     = "push"
+)
+
+// InitParams are the params of the method 'init'.
+type InitParams struct {
+	ModPath    string `json:"modPath"`
+	ModName    string `json:"modName,omitempty"`
+	ModVersion string `json:"modVersion,omitempty"`
+	Template   string `json:"template,omitempty"`
+}
+
+// AddParams are the params of the method 'add'.
+type AddParams struct {
+	ModPath string `json:"modPath"`
+	// Source is the url of the dependency, e.g. 'oci://ghcr.io/kcl-lang/k8s?tag=1.28' and 'k8s:1.28'.
+	Source string `json:"source"`
+	Alias  string `json:"alias,omitempty"`
+}
+
+// RemoveParams are the params of the method 'remove'.
+type RemoveParams struct {
+	ModPath string   `json:"modPath"`
+	Names   []string `json:"names"`
+}
+
+// UpdateParams are the params of the method 'update'.
+type UpdateParams struct {
+	ModPath string `json:"modPath"`
+	Offline bool   `json:"offline,omitempty"`
+}
+
+// GraphParams are the params of the method 'graph'.
+type GraphParams struct {
+	ModPath string `json:"modPath"`
+}
+
+// MetadataParams are the params of the method 'metadata'.
+type MetadataParams struct {
+	ModPath string `json:"modPath"`
+	Update  bool   `json:"update,omitempty"`
+	Vendor  bool   `json:"vendor,omitempty"`
+}
+
+// RunParams are the params of the method 'run'.
+type RunParams struct {
+	WorkDir       string   `json:"workDir,omitempty"`
+	Sources       []string `json:"sources"`
+	Settings      []string `json:"settings,omitempty"`
+	Arguments     []string `json:"arguments,omitempty"`
+	Overrides     []string `json:"overrides,omitempty"`
+	PathSelectors []string `json:"pathSelectors,omitempty"`
+	DisableNone   bool     `json:"disableNone,omitempty"`
+	SortKeys      bool     `json:"sortKeys,omitempty"`
+	ShowHidden    bool     `json:"showHidden,omitempty"`
+	Vendor        bool     `json:"vendor,omitempty"`
+}
+
+// PullParams are the params of the method 'pull'.
+type PullParams struct {
+	Source    string `json:"source"`
+	LocalPath string `json:"localPath"`
+}
+
+// PushParams are the params of the method 'push'.
+type PushParams struct {
+	ModPath string `json:"modPath"`
+	// Target is the oci url to push the package, e.g. 'oci://ghcr.io/kcl-lang/k8s?tag=1.28'.
+	Target string `json:"target"`
+	Vendor bool   `json:"vendor,omitempty"`
+	Docs   string `json:"docs,omitempty"`
+}
+
+// PkgResult is the result of the methods returning a kcl package.
+type PkgResult struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	HomePath string `json:"homePath"`
+}
+
+// GraphResult is the result of the method 'graph', the edges are the lines of '<parent>@<version> <child>@<version>'.
+type GraphResult struct {
+	Graph string `json:"graph"`
+}
+
+// RunResult is the result of the method 'run'.
+type RunResult struct {
+	Yaml string `json:"yaml"`
+	Json string `json:"json"`
+}
+
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return &Error{Code: CodeInvalidParams, Message: "params are required"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func intoPkgResult(kclPkg *pkg.KclPkg) *PkgResult {
+	return &PkgResult{
+		Name:     kclPkg.GetPkgName(),
+		Version:  kclPkg.GetPkgVersion(),
+		HomePath: kclPkg.HomePath,
+	}
+}
+
+func (s *Server) loadPkg(modPath string) (*pkg.KclPkg, error) {
+	if modPath == "" {
+		return nil, &Error{Code: CodeInvalidParams, Message: "modPath is required"}
+	}
+	return s.kpmcli.LoadPkgFromPath(modPath)
+}
+
+func (s *Server) init(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &InitParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	opts := []client.InitOption{
+		client.WithInitModPath(p.ModPath),
+		client.WithInitModName(p.ModName),
+		client.WithInitModVersion(p.ModVersion),
+	}
+	if p.Template != "" {
+		opts = append(opts, client.WithInitTemplate(p.Template))
+	}
+	if err := s.kpmcli.InitWithContext(ctx, opts...); err != nil {
+		return nil, err
+	}
+	// The package is initialized in the subdirectory with the name if the name is set.
+	modPath := p.ModPath
+	if p.ModName != "" {
+		modPath = filepath.Join(modPath, p.ModName)
+	}
+	kclPkg, err := s.loadPkg(modPath)
+	if err != nil {
+		return nil, err
+	}
+	return intoPkgResult(kclPkg), nil
+}
+
+func (s *Server) add(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &AddParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	kclPkg, err := s.loadPkg(p.ModPath)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.AddOption{
+		client.WithAddKclPkg(kclPkg),
+		client.WithAddSourceUrl(p.Source),
+	}
+	if p.Alias != "" {
+		opts = append(opts, client.WithAlias(p.Alias))
+	}
+	if err := s.kpmcli.AddWithContext(ctx, opts...); err != nil {
+		return nil, err
+	}
+	return intoPkgResult(kclPkg), nil
+}
+
+func (s *Server) remove(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &RemoveParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	kclPkg, err := s.loadPkg(p.ModPath)
+	if err != nil {
+		return nil, err
+	}
+	kclPkg, err = s.kpmcli.RemoveWithContext(ctx, client.WithRemoveKclPkg(kclPkg), client.WithRemoveDepNames(p.Names...))
+	if err != nil {
+		return nil, err
+	}
+	return intoPkgResult(kclPkg), nil
+}
+
+func (s *Server) update(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &UpdateParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	kclPkg, err := s.loadPkg(p.ModPath)
+	if err != nil {
+		return nil, err
+	}
+	kclPkg, err = s.kpmcli.UpdateWithContext(ctx, client.WithUpdatedKclPkg(kclPkg), client.WithOffline(p.Offline))
+	if err != nil {
+		return nil, err
+	}
+	return intoPkgResult(kclPkg), nil
+}
+
+func (s *Server) graph(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &GraphParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	kclPkg, err := s.loadPkg(p.ModPath)
+	if err != nil {
+		return nil, err
+	}
+	depGraph, err := s.kpmcli.GraphWithContext(ctx, client.WithGraphMod(kclPkg))
+	if err != nil {
+		return nil, err
+	}
+	graph, err := depGraph.DisplayGraphFromVertex(module.Version{Path: kclPkg.GetPkgName(), Version: kclPkg.GetPkgVersion()})
+	if err != nil {
+		return nil, err
+	}
+	return &GraphResult{Graph: graph}, nil
+}
+
+func (s *Server) metadata(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &MetadataParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	kclPkg, err := s.loadPkg(p.ModPath)
+	if err != nil {
+		return nil, err
+	}
+	kclPkg.SetVendorMode(p.Vendor)
+	metadata, err := s.kpmcli.ResolveDepsMetadataInJsonStrWithContext(ctx, kclPkg, p.Update)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(metadata), nil
+}
+
+func (s *Server) run(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &RunParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	opts := []client.RunOption{
+		client.WithRunSourceUrls(p.Sources),
+		client.WithSettingFiles(p.Settings),
+		client.WithArguments(p.Arguments),
+		client.WithOverrides(p.Overrides, false),
+		client.WithPathSelectors(p.PathSelectors),
+		client.WithDisableNone(p.DisableNone),
+		client.WithSortKeys(p.SortKeys),
+		client.WithShowHidden(p.ShowHidden),
+		client.WithVendor(p.Vendor),
+	}
+	if p.WorkDir != "" {
+		opts = append(opts, client.WithWorkDir(p.WorkDir))
+	}
+	result, err := s.kpmcli.RunWithContext(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &RunResult{Yaml: result.GetRawYamlResult(), Json: result.GetRawJsonResult()}, nil
+}
+
+func (s *Server) pull(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &PullParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	kclPkg, err := s.kpmcli.PullWithContext(ctx, client.WithPullSourceUrl(p.Source), client.WithLocalPath(p.LocalPath))
+	if err != nil {
+		return nil, err
+	}
+	return intoPkgResult(kclPkg), nil
+}
+
+func (s *Server) push(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	p := &PushParams{}
+	if err := decodeParams(params, p); err != nil {
+		return nil, err
+	}
+	source, err := downloader.NewSourceFromStr(p.Target)
+	if err != nil {
+		return nil, err
+	}
+	if source.Oci == nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("'%s' is not an oci url", p.Target)}
+	}
+	err = s.kpmcli.PushWithContext(
+		ctx,
+		client.WithPushModPath(p.ModPath),
+		client.WithPushSource(*source),
+		client.WithPushVendorMode(p.Vendor),
+		client.WithPushDocs(p.Docs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return struct{}{}, nil
+}
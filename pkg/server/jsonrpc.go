@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const JSONRPC_VERSION = "2.0"
+
+// The error codes of JSON-RPC 2.0, 'CodeRequestCancelled' is the code for the canceled requests borrowed from LSP.
+const (
+	CodeParseError       = -32700
+	CodeInvalidRequest   = -32600
+	CodeMethodNotFound   = -32601
+	CodeInvalidParams    = -32602
+	CodeInternalError    = -32603
+	CodeRequestCancelled = -32800
+)
+
+const (
+	// METHOD_CANCEL_REQUEST is the notification sent by the client to cancel the request with the id in the params.
+	METHOD_CANCEL_REQUEST = "$/cancelRequest"
+	// METHOD_PROGRESS is the notification sent by the server with the progress message of the request.
+	METHOD_PROGRESS = "$/progress"
+)
+
+// Request is the JSON-RPC 2.0 request, it is a notification if the id is missing.
+// The id 'null' is kept as the raw message 'null', so the request with the id 'null' is still responded.
+type Request struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification returns true if the request is a notification without the id, which has no response.
+func (r *Request) IsNotification() bool {
+	return r.Id == nil
+}
+
+// Response is the JSON-RPC 2.0 response.
+// The id is 'null' if the id of the request can not be read.
+type Response struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Notification is the JSON-RPC 2.0 notification sent by the server.
+type Notification struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// Error is the error object of the JSON-RPC 2.0 response.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// CancelParams are the params of the notification '$/cancelRequest'.
+type CancelParams struct {
+	Id json.RawMessage `json:"id"`
+}
+
+// ProgressParams are the params of the notification '$/progress'.
+type ProgressParams struct {
+	Id      json.RawMessage `json:"id"`
+	Message string          `json:"message"`
+}
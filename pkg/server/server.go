@@ -0,0 +1,291 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+
+	"kcl-lang.io/kpm/pkg/client"
+)
+
+// handlerFunc handles the params of a request and returns the result.
+type handlerFunc func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// Server serves the operations of the KpmClient over JSON-RPC 2.0.
+// The settings, the credentials and the caches of the KpmClient are kept by the server across the requests.
+type Server struct {
+	kpmcli   *client.KpmClient
+	handlers map[string]handlerFunc
+	// ops serializes the operations, because the logs of the KpmClient are written to one writer
+	// and streamed as the progress of the running request.
+	ops chan struct{}
+}
+
+// NewServer returns a server of the operations of 'kpmcli'.
+func NewServer(kpmcli *client.KpmClient) *Server {
+	s := &Server{
+		kpmcli: kpmcli,
+		ops:    make(chan struct{}, 1),
+	}
+	s.handlers = map[string]handlerFunc{
+		METHOD_INIT:     s.init,
+		METHOD_ADD:      s.add,
+		METHOD_REMOVE:   s.remove,
+		METHOD_UPDATE:   s.update,
+		METHOD_GRAPH:    s.graph,
+		METHOD_METADATA: s.metadata,
+		METHOD_RUN:      s.run,
+		METHOD_PULL:     s.pull,
+		METHOD_PUSH:     s.push,
+	}
+	return s
+}
+
+// Serve reads the requests from 'r' and writes the responses and the notifications to 'w' until 'r' is closed.
+// The messages are the JSON values one after another, e.g. one message per line.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c := &conn{
+		server:  s,
+		enc:     json.NewEncoder(w),
+		cancels: map[string]context.CancelFunc{},
+	}
+	defer func() {
+		cancel()
+		c.wg.Wait()
+	}()
+
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) || ctx.Err() != nil {
+				return nil
+			}
+			// The decoder can not recover from the invalid JSON.
+			c.reply(nil, nil, &Error{Code: CodeParseError, Message: err.Error()})
+			return err
+		}
+
+		req := &Request{}
+		if err := json.Unmarshal(raw, req); err != nil || req.JsonRpc != JSONRPC_VERSION || req.Method == "" {
+			c.reply(req.Id, nil, &Error{Code: CodeInvalidRequest, Message: "invalid request"})
+			continue
+		}
+		c.handle(ctx, req)
+	}
+}
+
+// ServeUnix listens on the unix socket 'socketPath' and serves the connections until the context is done.
+func (s *Server) ServeUnix(ctx context.Context, socketPath string) error {
+	// The socket left by the previous server is removed.
+	if fileInfo, err := os.Lstat(socketPath); err == nil && fileInfo.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(socketPath); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { _ = listener.Close() })
+	defer stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer netConn.Close()
+			stopConn := context.AfterFunc(ctx, func() { _ = netConn.Close() })
+			defer stopConn()
+			_ = s.Serve(ctx, netConn, netConn)
+		}()
+	}
+}
+
+// call runs the handler of the request after the running operation is done, and streams the logs as the progress.
+// The canceled request is responded after its operation is stopped by the canceled context,
+// so the next operation is not run until the canceled one releases the lock of the package cache.
+func (s *Server) call(ctx context.Context, c *conn, req *Request, handler handlerFunc) (value interface{}, err error) {
+	select {
+	case s.ops <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.ops }()
+
+	logWriter := s.kpmcli.GetLogWriter()
+	s.kpmcli.SetLogWriter(&progressWriter{ctx: ctx, conn: c, id: req.Id})
+	defer s.kpmcli.SetLogWriter(logWriter)
+
+	// acquire the lock of the package cache.
+	if err := s.kpmcli.AcquirePackageCacheLock(); err != nil {
+		return nil, err
+	}
+	// release the lock of the package cache after the operation is done.
+	defer func() {
+		if releaseErr := s.kpmcli.ReleasePackageCacheLock(); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	// The request may be canceled while waiting for the lock of the package cache.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	value, err = handler(ctx, req.Params)
+	// The errors of the operation stopped by the canceled context are reported as the cancellation.
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return value, err
+}
+
+// conn is a connection to the client of the server.
+type conn struct {
+	server *Server
+	// writeMu serializes the messages written to the connection.
+	writeMu sync.Mutex
+	enc     *json.Encoder
+	// cancels are the cancel functions of the running requests by the ids.
+	cancelsMu sync.Mutex
+	cancels   map[string]context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// handle handles the request in a new goroutine, so that the requests can be canceled while they are waiting or running.
+func (c *conn) handle(ctx context.Context, req *Request) {
+	if req.Method == METHOD_CANCEL_REQUEST {
+		params := &CancelParams{}
+		if err := json.Unmarshal(req.Params, params); err == nil {
+			c.cancel(params.Id)
+		}
+		return
+	}
+
+	handler, ok := c.server.handlers[req.Method]
+	if !ok {
+		if !req.IsNotification() {
+			c.reply(req.Id, nil, &Error{Code: CodeMethodNotFound, Message: "method '" + req.Method + "' not found"})
+		}
+		return
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	if !req.IsNotification() {
+		// The request with the same id as a running one is rejected, otherwise the running one can not be canceled.
+		c.cancelsMu.Lock()
+		if _, ok := c.cancels[requestKey(req.Id)]; ok {
+			c.cancelsMu.Unlock()
+			cancel()
+			c.reply(req.Id, nil, &Error{Code: CodeInvalidRequest, Message: "request id " + requestKey(req.Id) + " is already in use"})
+			return
+		}
+		c.cancels[requestKey(req.Id)] = cancel
+		c.cancelsMu.Unlock()
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer cancel()
+
+		result, err := c.server.call(reqCtx, c, req, handler)
+		if req.IsNotification() {
+			return
+		}
+		c.cancelsMu.Lock()
+		delete(c.cancels, requestKey(req.Id))
+		c.cancelsMu.Unlock()
+		c.reply(req.Id, result, intoError(err))
+	}()
+}
+
+// cancel cancels the running request with the id.
+func (c *conn) cancel(id json.RawMessage) {
+	c.cancelsMu.Lock()
+	defer c.cancelsMu.Unlock()
+	if cancel, ok := c.cancels[requestKey(id)]; ok {
+		cancel()
+	}
+}
+
+func (c *conn) reply(id json.RawMessage, result interface{}, err *Error) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	resp := &Response{JsonRpc: JSONRPC_VERSION, Id: id, Error: err}
+	if err == nil {
+		resp.Result = result
+		if result == nil {
+			resp.Result = struct{}{}
+		}
+	}
+	c.write(resp)
+}
+
+func (c *conn) notify(method string, params interface{}) {
+	c.write(&Notification{JsonRpc: JSONRPC_VERSION, Method: method, Params: params})
+}
+
+func (c *conn) write(msg interface{}) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	// The client is gone if the message can not be written, the requests are canceled when the reader is closed.
+	_ = c.enc.Encode(msg)
+}
+
+// requestKey returns the key of the request id, the ids '1' and ' 1 ' are the same request.
+func requestKey(id json.RawMessage) string {
+	return string(bytes.TrimSpace(id))
+}
+
+// intoError transforms the error returned by the handler into the error of the response.
+func intoError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+	if errors.Is(err, context.Canceled) {
+		return &Error{Code: CodeRequestCancelled, Message: "request canceled"}
+	}
+	return &Error{Code: CodeInternalError, Message: err.Error()}
+}
+
+// progressWriter streams the logs of the KpmClient as the progress notifications of the request.
+type progressWriter struct {
+	ctx  context.Context
+	conn *conn
+	id   json.RawMessage
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	// The logs of the canceled requests and the notifications are dropped.
+	if w.id == nil || w.ctx.Err() != nil {
+		return len(p), nil
+	}
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		if message := string(bytes.TrimSpace(line)); message != "" {
+			w.conn.notify(METHOD_PROGRESS, &ProgressParams{Id: w.id, Message: message})
+		}
+	}
+	return len(p), nil
+}
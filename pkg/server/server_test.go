@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"kcl-lang.io/kpm/pkg/client"
+)
+
+// testConn is the client side of a connection to the server in the tests.
+type testConn struct {
+	t   *testing.T
+	enc *json.Encoder
+	dec *json.Decoder
+	// progress are the progress messages received by the request ids.
+	progress map[string][]string
+}
+
+type testMessage struct {
+	Id     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+func newTestConn(t *testing.T, w io.Writer, r io.Reader) *testConn {
+	return &testConn{t: t, enc: json.NewEncoder(w), dec: json.NewDecoder(r), progress: map[string][]string{}}
+}
+
+func (c *testConn) send(id int, method string, params interface{}) {
+	content, err := json.Marshal(params)
+	assert.Nil(c.t, err)
+	req := map[string]interface{}{"jsonrpc": JSONRPC_VERSION, "method": method, "params": json.RawMessage(content)}
+	if id != 0 {
+		req["id"] = id
+	}
+	assert.Nil(c.t, c.enc.Encode(req))
+}
+
+// recv returns the next response, the progress notifications before it are collected.
+func (c *testConn) recv() *testMessage {
+	for {
+		msg := &testMessage{}
+		assert.Nil(c.t, c.dec.Decode(msg))
+		if msg.Method == METHOD_PROGRESS {
+			progress := &ProgressParams{}
+			assert.Nil(c.t, json.Unmarshal(msg.Params, progress))
+			c.progress[string(progress.Id)] = append(c.progress[string(progress.Id)], progress.Message)
+			continue
+		}
+		return msg
+	}
+}
+
+func newTestServer(t *testing.T) *Server {
+	kpmcli, err := client.NewKpmClient()
+	assert.Nil(t, err)
+	kpmcli.SetHomePath(t.TempDir())
+	return NewServer(kpmcli)
+}
+
+func TestServe(t *testing.T) {
+	s := newTestServer(t)
+	reqReader, reqWriter := io.Pipe()
+	respReader, respWriter := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(context.Background(), reqReader, respWriter)
+	}()
+	c := newTestConn(t, reqWriter, respReader)
+
+	modPath := filepath.Join(t.TempDir(), "demo")
+	c.send(1, METHOD_INIT, &InitParams{ModPath: modPath})
+	resp := c.recv()
+	assert.Equal(t, "1", string(resp.Id))
+	assert.Nil(t, resp.Error)
+	result := &PkgResult{}
+	assert.Nil(t, json.Unmarshal(resp.Result, result))
+	assert.Equal(t, "demo", result.Name)
+	assert.Equal(t, modPath, result.HomePath)
+	assert.NotEmpty(t, c.progress["1"])
+
+	c.send(2, METHOD_REMOVE, &RemoveParams{ModPath: modPath, Names: []string{"helper"}})
+	resp = c.recv()
+	assert.Equal(t, CodeInternalError, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "dependency 'helper' not found in 'demo'")
+
+	c.send(3, "unknown", struct{}{})
+	resp = c.recv()
+	assert.Equal(t, CodeMethodNotFound, resp.Error.Code)
+
+	c.send(4, METHOD_GRAPH, "demo")
+	resp = c.recv()
+	assert.Equal(t, CodeInvalidParams, resp.Error.Code)
+
+	// The request waiting for the running operation is canceled.
+	s.ops <- struct{}{}
+	c.send(5, METHOD_GRAPH, &GraphParams{ModPath: modPath})
+	c.send(0, METHOD_CANCEL_REQUEST, &CancelParams{Id: json.RawMessage("5")})
+	resp = c.recv()
+	assert.Equal(t, "5", string(resp.Id))
+	assert.Equal(t, CodeRequestCancelled, resp.Error.Code)
+	<-s.ops
+
+	c.send(6, METHOD_GRAPH, &GraphParams{ModPath: modPath})
+	resp = c.recv()
+	assert.Nil(t, resp.Error)
+
+	assert.Nil(t, reqWriter.Close())
+	assert.Nil(t, <-served)
+}
+
+func TestServeCancelRunning(t *testing.T) {
+	s := newTestServer(t)
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	s.handlers["block"] = func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		// The operation takes a while to stop after the context is canceled.
+		time.Sleep(50 * time.Millisecond)
+		close(stopped)
+		return nil, fmt.Errorf("failed to download: %w", ctx.Err())
+	}
+
+	reqReader, reqWriter := io.Pipe()
+	respReader, respWriter := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(context.Background(), reqReader, respWriter)
+	}()
+	c := newTestConn(t, reqWriter, respReader)
+
+	c.send(1, "block", struct{}{})
+	<-started
+	c.send(0, METHOD_CANCEL_REQUEST, &CancelParams{Id: json.RawMessage("1")})
+	resp := c.recv()
+	assert.Equal(t, "1", string(resp.Id))
+	assert.Equal(t, CodeRequestCancelled, resp.Error.Code)
+
+	// The canceled request is responded after the operation is stopped.
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("the canceled request is responded before the operation is stopped")
+	}
+	assert.Equal(t, 0, len(s.ops))
+
+	assert.Nil(t, reqWriter.Close())
+	assert.Nil(t, <-served)
+}
+
+func TestServeRequestIds(t *testing.T) {
+	s := newTestServer(t)
+	started := make(chan struct{})
+	s.handlers["block"] = func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	reqReader, reqWriter := io.Pipe()
+	respReader, respWriter := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve(context.Background(), reqReader, respWriter)
+	}()
+	c := newTestConn(t, reqWriter, respReader)
+
+	// The request with the id 'null' is not a notification.
+	assert.Nil(t, c.enc.Encode(map[string]interface{}{"jsonrpc": JSONRPC_VERSION, "id": nil, "method": "unknown"}))
+	resp := c.recv()
+	assert.Equal(t, "null", string(resp.Id))
+	assert.Equal(t, CodeMethodNotFound, resp.Error.Code)
+
+	// The request with the id of a running request is rejected, and the running one can still be canceled.
+	c.send(1, "block", struct{}{})
+	<-started
+	c.send(1, "block", struct{}{})
+	resp = c.recv()
+	assert.Equal(t, "1", string(resp.Id))
+	assert.Equal(t, CodeInvalidRequest, resp.Error.Code)
+	assert.Contains(t, resp.Error.Message, "request id 1 is already in use")
+
+	c.send(0, METHOD_CANCEL_REQUEST, &CancelParams{Id: json.RawMessage("1")})
+	resp = c.recv()
+	assert.Equal(t, "1", string(resp.Id))
+	assert.Equal(t, CodeRequestCancelled, resp.Error.Code)
+
+	assert.Nil(t, reqWriter.Close())
+	assert.Nil(t, <-served)
+}
+
+func TestServeUnix(t *testing.T) {
+	s := newTestServer(t)
+	socketPath := filepath.Join(t.TempDir(), "kpm.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.ServeUnix(ctx, socketPath)
+	}()
+
+	var netConn net.Conn
+	var err error
+	for i := 0; i < 100; i++ {
+		if netConn, err = net.Dial("unix", socketPath); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Nil(t, err, fmt.Sprintf("failed to connect to '%s'", socketPath))
+	defer netConn.Close()
+
+	c := newTestConn(t, netConn, netConn)
+	c.send(1, METHOD_INIT, &InitParams{ModPath: t.TempDir(), ModName: "unix_demo"})
+	resp := c.recv()
+	assert.Nil(t, resp.Error)
+	result := &PkgResult{}
+	assert.Nil(t, json.Unmarshal(resp.Result, result))
+	assert.Equal(t, "unix_demo", result.Name)
+
+	cancel()
+	assert.Nil(t, <-served)
+}
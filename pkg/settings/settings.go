@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -31,6 +32,66 @@ type KpmConf struct {
 	DefaultOciRegistry  string
 	DefaultOciRepo      string
 	DefaultOciPlainHttp *bool `json:",omitempty"`
+	// CredHelpers maps the OCI registry host to the suffix of the docker credential helper 'docker-credential-<suffix>'.
+	CredHelpers map[string]string `json:",omitempty"`
+	// CredsStore is the suffix of the docker credential helper used for the OCI registries not in 'CredHelpers'.
+	CredsStore string `json:",omitempty"`
+	// GitCredentials maps the git host to the credential used to access the git repositories over https.
+	GitCredentials map[string]GitCredential `json:",omitempty"`
+	// Registries are the named OCI registries to resolve the shorthand dependencies like 'helloworld = "0.1.0"' by their scopes,
+	// the dependencies that match no registry are resolved from 'DefaultOciRegistry' and 'DefaultOciRepo'.
+	Registries []OciRegistry `json:",omitempty"`
+}
+
+// OciRegistry is a named OCI registry, the packages whose name matches its scopes are resolved from it.
+type OciRegistry struct {
+	// Name is the name of the registry, the registry in kcl.mod overrides the one with the same name in the settings.
+	Name string `toml:"-"`
+	// Registry is the host of the registry, e.g. 'registry.acme.com'.
+	Registry string `toml:"registry"`
+	// Repo is the repo of the packages in the registry, e.g. 'kcl'.
+	Repo string `json:",omitempty" toml:"repo,omitempty"`
+	// Scopes are the glob patterns of the package names, e.g. 'acme-*', the registry matches all the packages if it is empty.
+	Scopes []string `json:",omitempty" toml:"scopes,omitempty"`
+	// Priority selects the registry if several registries match the package, the registry with the higher priority is selected.
+	// The registries with the same priority are selected by the order of their names in kcl.mod, and then their order in the settings.
+	Priority int `json:",omitempty" toml:"priority,omitempty"`
+}
+
+// Matches returns true if the package 'name' matches the scopes of the registry.
+func (r *OciRegistry) Matches(name string) bool {
+	if len(r.Scopes) == 0 {
+		return true
+	}
+	for _, scope := range r.Scopes {
+		if matched, err := path.Match(scope, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GitCredential is the credential of a git host.
+type GitCredential struct {
+	// Username is the username used with 'Token', some git hosts require a specific username, e.g. 'oauth2' for GitLab.
+	Username string `json:",omitempty"`
+	// Token is the personal access token used as the password of the https basic authentication.
+	Token string `json:",omitempty"`
+	// BearerToken is the token used in the https bearer authentication, it takes precedence over 'Token'.
+	BearerToken string `json:",omitempty"`
+	// Helper is the git credential helper used if 'Token' and 'BearerToken' are empty,
+	// it can be a helper name 'xxx' for 'git-credential-xxx', an absolute path, a shell command starting with '!',
+	// or 'git' to use the credential helpers configured in the system git.
+	Helper string `json:",omitempty"`
+	// SSHKeyFile is the path of the private key used over ssh.
+	SSHKeyFile string `json:",omitempty"`
+	// SSHKnownHosts is the path of the known_hosts file used to verify the host key over ssh.
+	SSHKnownHosts string `json:",omitempty"`
+	// SSHAgent means to use the ssh agent together with 'SSHKeyFile'.
+	SSHAgent bool `json:",omitempty"`
+	// CAFile is the path of the PEM encoded certificates of the CAs trusted to access the git host over https,
+	// in addition to the system ones.
+	CAFile string `json:",omitempty"`
 }
 
 const ON = "on"
@@ -153,6 +214,41 @@ func (settings *Settings) ForceOciPlainHttp() (bool, bool) {
 	return *settings.Conf.DefaultOciPlainHttp, true
 }
 
+// ResolveOciRepo returns the OCI registry and repo of the package 'name' for the shorthand dependencies.
+// The package is resolved from the named registry with the highest priority whose scopes match the name,
+// and the 'registries' declared in kcl.mod take precedence over the ones with the same name in the settings.
+// The default OCI registry and repo are used if no named registry matches the package.
+func (settings *Settings) ResolveOciRepo(name string, registries ...OciRegistry) (string, string) {
+	candidates := append([]OciRegistry{}, registries...)
+	for _, r := range settings.Conf.Registries {
+		overridden := false
+		for _, modRegistry := range registries {
+			if modRegistry.Name != "" && modRegistry.Name == r.Name {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			candidates = append(candidates, r)
+		}
+	}
+
+	var selected *OciRegistry
+	for i := range candidates {
+		if !candidates[i].Matches(name) {
+			continue
+		}
+		if selected == nil || candidates[i].Priority > selected.Priority {
+			selected = &candidates[i]
+		}
+	}
+
+	if selected == nil {
+		return settings.DefaultOciRegistry(), utils.JoinPath(settings.DefaultOciRepo(), name)
+	}
+	return selected.Registry, utils.JoinPath(selected.Repo, name)
+}
+
 // DefaultOciRef return the default OCI ref 'ghcr.io/kcl-lang'.
 func (settings *Settings) DefaultOciRef() string {
 	return utils.JoinPath(settings.Conf.DefaultOciRegistry, settings.Conf.DefaultOciRepo)
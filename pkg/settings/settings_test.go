@@ -187,3 +187,34 @@ func TestSettingEnv(t *testing.T) {
 	settings = GetSettings()
 	assert.Equal(t, settings.DefaultOciPlainHttp(), false)
 }
+
+func TestResolveOciRepo(t *testing.T) {
+	settings := Settings{
+		Conf: KpmConf{
+			DefaultOciRegistry: DEFAULT_REGISTRY,
+			DefaultOciRepo:     DEFAULT_REPO,
+			Registries: []OciRegistry{
+				{Name: "acme", Registry: "registry.acme.com", Repo: "kcl", Scopes: []string{"acme-*"}},
+				{Name: "mirror", Registry: "mirror.acme.com", Scopes: []string{"acme-*", "k8s"}, Priority: 1},
+			},
+		},
+	}
+
+	reg, repo := settings.ResolveOciRepo("helloworld")
+	assert.Equal(t, DEFAULT_REGISTRY, reg)
+	assert.Equal(t, "kcl-lang/helloworld", repo)
+
+	// The registry with the higher priority is selected.
+	reg, repo = settings.ResolveOciRepo("acme-base")
+	assert.Equal(t, "mirror.acme.com", reg)
+	assert.Equal(t, "acme-base", repo)
+
+	// The registry in kcl.mod overrides the one with the same name in the settings.
+	reg, repo = settings.ResolveOciRepo("acme-base", OciRegistry{Name: "mirror", Registry: "localhost:5001", Scopes: []string{"k8s"}})
+	assert.Equal(t, "registry.acme.com", reg)
+	assert.Equal(t, "kcl/acme-base", repo)
+
+	reg, repo = settings.ResolveOciRepo("k8s", OciRegistry{Name: "mirror", Registry: "localhost:5001", Scopes: []string{"k8s"}})
+	assert.Equal(t, "localhost:5001", reg)
+	assert.Equal(t, "k8s", repo)
+}
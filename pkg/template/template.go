@@ -0,0 +1,98 @@
+// Package template scaffolds the kcl packages from the template packages.
+//
+// A template is a directory tree, the files with the suffix '.tmpl' are rendered by 'text/template'
+// with the name, the version and the edition of the new package and the suffix is trimmed,
+// the other files are copied as is.
+package template
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	gotemplate "text/template"
+)
+
+// TemplateSuffix is the suffix of the files rendered by 'text/template'.
+const TemplateSuffix = ".tmpl"
+
+const builtinRoot = "templates"
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// Values are the values substituted into the template files, e.g. '{{ .Name }}'.
+type Values struct {
+	Name    string
+	Version string
+	Edition string
+}
+
+// Builtin returns the built-in template 'name', false is returned if there is no such template.
+func Builtin(name string) (fs.FS, bool) {
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return nil, false
+	}
+	if _, err := fs.Stat(builtinTemplates, path.Join(builtinRoot, name)); err != nil {
+		return nil, false
+	}
+	tmpl, err := fs.Sub(builtinTemplates, path.Join(builtinRoot, name))
+	if err != nil {
+		return nil, false
+	}
+	return tmpl, true
+}
+
+// BuiltinNames returns the sorted names of the built-in templates.
+func BuiltinNames() []string {
+	entries, err := builtinTemplates.ReadDir(builtinRoot)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render renders the files of the template 'tmpl' with 'values',
+// 'write' is called with the slash-separated path relative to the template root and the content of each file.
+// The '.git' directories are skipped.
+func Render(tmpl fs.FS, values Values, write func(path string, content []byte) error) error {
+	return fs.WalkDir(tmpl, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		content, err := fs.ReadFile(tmpl, filePath)
+		if err != nil {
+			return err
+		}
+		if !strings.HasSuffix(filePath, TemplateSuffix) {
+			return write(filePath, content)
+		}
+
+		t, err := gotemplate.New(path.Base(filePath)).Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse the template file '%s': %w", filePath, err)
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, values); err != nil {
+			return fmt.Errorf("failed to render the template file '%s': %w", filePath, err)
+		}
+		return write(strings.TrimSuffix(filePath, TemplateSuffix), buf.Bytes())
+	})
+}
@@ -0,0 +1,62 @@
+package template
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltin(t *testing.T) {
+	assert.Equal(t, []string{"k8s", "lib", "policy"}, BuiltinNames())
+
+	for _, name := range BuiltinNames() {
+		tmpl, ok := Builtin(name)
+		assert.True(t, ok)
+
+		files := map[string]string{}
+		err := Render(tmpl, Values{Name: "demo", Version: "0.0.1", Edition: "v0.11.0"}, func(path string, content []byte) error {
+			files[path] = string(content)
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Contains(t, files["kcl.mod"], `name = "demo"`)
+		assert.Contains(t, files["kcl.mod"], `edition = "v0.11.0"`)
+		assert.Contains(t, files["kcl.mod"], `version = "0.0.1"`)
+		assert.Contains(t, files, "main.k")
+	}
+
+	for _, name := range []string{"", "unknown", "../templates", "lib/main.k"} {
+		_, ok := Builtin(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tmpl := fstest.MapFS{
+		"kcl.mod.tmpl":   {Data: []byte(`name = "{{ .Name }}"`)},
+		"sub/main.k":     {Data: []byte(`a = "{{ .Name }}"`)},
+		".git/HEAD":      {Data: []byte("ref: refs/heads/main")},
+		"README.md.tmpl": {Data: []byte("# {{ .Name }} {{ .Version }}")},
+		"edition.k.tmpl": {Data: []byte("{{ .Edition }}")},
+	}
+
+	files := map[string]string{}
+	err := Render(tmpl, Values{Name: "demo", Version: "0.0.1", Edition: "v0.11.0"}, func(path string, content []byte) error {
+		files[path] = string(content)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"kcl.mod":    `name = "demo"`,
+		"sub/main.k": `a = "{{ .Name }}"`,
+		"README.md":  "# demo 0.0.1",
+		"edition.k":  "v0.11.0",
+	}, files)
+
+	err = Render(fstest.MapFS{"broken.k.tmpl": {Data: []byte("{{ .Name ")}}, Values{}, func(string, []byte) error { return nil })
+	assert.ErrorContains(t, err, "failed to parse the template file 'broken.k.tmpl'")
+
+	err = Render(fstest.MapFS{"unknown.k.tmpl": {Data: []byte("{{ .Unknown }}")}}, Values{}, func(string, []byte) error { return nil })
+	assert.ErrorContains(t, err, "failed to render the template file 'unknown.k.tmpl'")
+}
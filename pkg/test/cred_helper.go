@@ -0,0 +1,52 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FAKE_CREDENTIAL_HELPER is the suffix of the fake credential helpers created by SetupFakeCredentialHelpers,
+// i.e. 'docker-credential-kpmtest' and 'git-credential-kpmtest'.
+const FAKE_CREDENTIAL_HELPER = "kpmtest"
+
+// fakeDockerCredentialHelper returns the credential of 'example.com' and the identity token of 'token.example.com'.
+const fakeDockerCredentialHelper = `#!/bin/sh
+[ "$1" = "get" ] || exit 1
+read -r host
+case "$host" in
+example.com) echo '{"ServerURL":"example.com","Username":"kpm","Secret":"kpm-secret"}' ;;
+token.example.com) echo '{"ServerURL":"token.example.com","Username":"<token>","Secret":"kpm-identity-token"}' ;;
+*) echo "credentials not found in native keychain"; exit 1 ;;
+esac
+`
+
+// fakeGitCredentialHelper returns the credential of 'example.com'.
+const fakeGitCredentialHelper = `#!/bin/sh
+[ "$1" = "get" ] || exit 0
+while read -r line && [ -n "$line" ]; do
+	case "$line" in
+	host=*) host="${line#host=}" ;;
+	esac
+done
+if [ "$host" = "example.com" ]; then
+	echo "username=kpm"
+	echo "password=kpm-token"
+fi
+`
+
+// SetupFakeCredentialHelpers creates the fake docker and git credential helpers in a temporary directory,
+// and prepends the directory to $PATH during the test. It returns the directory of the helpers.
+func SetupFakeCredentialHelpers(t *testing.T) string {
+	helperDir := t.TempDir()
+	for name, script := range map[string]string{
+		"docker-credential-" + FAKE_CREDENTIAL_HELPER: fakeDockerCredentialHelper,
+		"git-credential-" + FAKE_CREDENTIAL_HELPER:    fakeGitCredentialHelper,
+	} {
+		if err := os.WriteFile(filepath.Join(helperDir, name), []byte(script), 0755); err != nil {
+			t.Fatalf("failed to create the fake credential helper '%s': %v", name, err)
+		}
+	}
+	t.Setenv("PATH", helperDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return helperDir
+}
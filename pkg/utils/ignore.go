@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"kcl-lang.io/kpm/pkg/constants"
+)
+
+// defaultIgnores are the patterns always ignored when packaging or hashing a package.
+// Files in the ".git" directory cause the same repository, cloned at different times,
+// to have different checksums, and the "*.tar" and "*.tgz" files are the outputs of the previous packaging.
+var defaultIgnores = []string{".git", "*" + constants.TarPathSuffix, "*" + constants.TgzPathSuffix}
+
+// ignoreFiles are the files loaded from every directory of the package.
+// The patterns in '.kclignore' are loaded after '.gitignore', so they take precedence.
+var ignoreFiles = []string{constants.GIT_IGNORE, constants.KCL_IGNORE}
+
+// PkgWalkFunc is the type of the function called for each file or directory visited by WalkPkgDir.
+// 'relPath' is the slash separated path relative to the package root.
+type PkgWalkFunc func(path, relPath string, info os.FileInfo) error
+
+// WalkPkgDir walks the package under 'root' in lexical order and calls 'fn' for each file and directory,
+// skipping the ones ignored by the default ignores, the '.gitignore' and '.kclignore' files
+// in the package, and the gitignore-style 'exclude' patterns.
+// If 'include' is not empty, only the paths matched by any of the 'include' patterns are visited.
+func WalkPkgDir(root string, include, exclude []string, fn PkgWalkFunc) error {
+	// The default ignores are matched on their own, so they can not be negated by the patterns of the package.
+	var defaults []gitignore.Pattern
+	for _, p := range defaultIgnores {
+		defaults = append(defaults, gitignore.ParsePattern(p, nil))
+	}
+	defaultMatcher := gitignore.NewMatcher(defaults)
+
+	var patterns []gitignore.Pattern
+	for _, p := range exclude {
+		patterns = append(patterns, gitignore.ParsePattern(p, nil))
+	}
+
+	var includes []gitignore.Pattern
+	for _, p := range include {
+		includes = append(includes, gitignore.ParsePattern(p, nil))
+	}
+
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		var parts []string
+		if relPath != "." {
+			parts = strings.Split(relPath, "/")
+			if defaultMatcher.Match(parts, info.IsDir()) || gitignore.NewMatcher(patterns).Match(parts, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		if info.IsDir() {
+			ps, err := loadIgnorePatterns(path, parts)
+			if err != nil {
+				return err
+			}
+			patterns = append(patterns, ps...)
+		}
+
+		if relPath != "." && len(includes) != 0 && !matchAny(includes, parts, info.IsDir()) {
+			return nil
+		}
+
+		return fn(path, relPath, info)
+	})
+}
+
+// matchAny returns true if the path 'parts' is matched by any of the 'patterns'.
+func matchAny(patterns []gitignore.Pattern, parts []string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.Match(parts, isDir) == gitignore.Exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIgnorePatterns loads the patterns in the ignore files under 'dir',
+// 'domain' is the path of 'dir' relative to the package root.
+func loadIgnorePatterns(dir string, domain []string) ([]gitignore.Pattern, error) {
+	var patterns []gitignore.Pattern
+	for _, name := range ignoreFiles {
+		f, err := os.Open(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "#") || len(strings.TrimSpace(line)) == 0 {
+				continue
+			}
+			patterns = append(patterns, gitignore.ParsePattern(line, domain))
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return patterns, nil
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkPkgDir(t *testing.T) {
+	testDir := getTestDir("test_ignore")
+
+	// The files ignored by the '.gitignore' in the test data can not be committed, create them here.
+	secretPath := filepath.Join(testDir, "secrets", "token.txt")
+	logPath := filepath.Join(testDir, "sub", "debug.log")
+	assert.Equal(t, os.MkdirAll(filepath.Dir(secretPath), 0755), nil)
+	assert.Equal(t, os.WriteFile(secretPath, []byte("token"), 0644), nil)
+	assert.Equal(t, os.WriteFile(logPath, []byte("noise"), 0644), nil)
+	defer func() {
+		_ = os.RemoveAll(filepath.Dir(secretPath))
+		_ = os.Remove(logPath)
+	}()
+
+	walk := func(include, exclude []string) []string {
+		var files []string
+		err := WalkPkgDir(testDir, include, exclude, func(_, relPath string, info os.FileInfo) error {
+			if !info.IsDir() {
+				files = append(files, relPath)
+			}
+			return nil
+		})
+		assert.Equal(t, err, nil)
+		return files
+	}
+
+	assert.Equal(t, walk(nil, nil), []string{
+		".gitignore",
+		".kclignore",
+		"kcl.mod",
+		"main.k",
+		"my.git.helpers.k",
+		"sub/.kclignore",
+		"sub/deep/keep.k",
+	})
+
+	assert.Equal(t, walk(nil, []string{"**/.*ignore", "my.*.k"}), []string{
+		"kcl.mod",
+		"main.k",
+		"sub/deep/keep.k",
+	})
+
+	assert.Equal(t, walk([]string{"*.k"}, nil), []string{
+		"main.k",
+		"my.git.helpers.k",
+		"sub/deep/keep.k",
+	})
+
+	// The paths matched by any of the include patterns are visited.
+	assert.Equal(t, walk([]string{"kcl.mod", "sub/**/*.k"}, nil), []string{
+		"kcl.mod",
+		"sub/deep/keep.k",
+	})
+
+	// The default ignores can not be negated.
+	gitPath := filepath.Join(testDir, ".git", "HEAD")
+	tarPath := filepath.Join(testDir, "old.tar")
+	assert.Equal(t, os.MkdirAll(filepath.Dir(gitPath), 0755), nil)
+	assert.Equal(t, os.WriteFile(gitPath, []byte("ref: refs/heads/main"), 0644), nil)
+	assert.Equal(t, os.WriteFile(tarPath, []byte("tar"), 0644), nil)
+	defer func() {
+		_ = os.RemoveAll(filepath.Dir(gitPath))
+		_ = os.Remove(tarPath)
+	}()
+	assert.Equal(t, walk(nil, []string{"!.git", "!*.tar"}), []string{
+		".gitignore",
+		".kclignore",
+		"kcl.mod",
+		"main.k",
+		"my.git.helpers.k",
+		"sub/.kclignore",
+		"sub/deep/keep.k",
+	})
+}
+
+func TestHashDirWithIgnore(t *testing.T) {
+	testDir := filepath.Join(t.TempDir(), "test_ignore")
+	assert.Equal(t, copy.Copy(getTestDir("test_ignore"), testDir), nil)
+	sum, err := HashDir(testDir)
+	assert.Equal(t, err, nil)
+
+	// The ignored files are not packaged, so they are not in the checksum.
+	assert.Equal(t, os.WriteFile(filepath.Join(testDir, "sub", "debug.log"), []byte("noise"), 0644), nil)
+	sumWithIgnored, err := HashDir(testDir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, sum, sumWithIgnored)
+
+	// The files with '.git' in the names are packaged, so they are in the checksum.
+	assert.Equal(t, os.WriteFile(filepath.Join(testDir, "my.git.helpers.k"), []byte("helper = 2"), 0644), nil)
+	sumWithChanged, err := HashDir(testDir)
+	assert.Equal(t, err, nil)
+	assert.NotEqual(t, sum, sumWithChanged)
+
+	// The files excluded by the kcl.mod are not in the checksum.
+	kclMod, err := os.ReadFile(filepath.Join(testDir, "kcl.mod"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, os.WriteFile(filepath.Join(testDir, "kcl.mod"), append(kclMod, []byte("exclude = [\"my.git.helpers.k\"]\n")...), 0644), nil)
+	sumWithExcluded, err := HashDir(testDir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, os.Remove(filepath.Join(testDir, "my.git.helpers.k")), nil)
+	sumWithRemoved, err := HashDir(testDir)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, sumWithExcluded, sumWithRemoved)
+}
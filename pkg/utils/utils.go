@@ -10,14 +10,15 @@ import (
 	goerrors "errors"
 	"fmt"
 	"io"
-	"log"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/dchest/siphash"
 
@@ -32,28 +33,27 @@ import (
 	"kcl-lang.io/kpm/pkg/reporter"
 )
 
-// HashDir computes the checksum of a directory by concatenating all files and
+// HashDir computes the checksum of the package under 'dir' by concatenating all files and
 // hashing them by sha256.
+// The files are walked by WalkPkgDir with the include and exclude patterns in the kcl.mod of the package,
+// so the checksum covers the same files as the tarball packaged by 'kpm pkg' and 'kpm push'.
 func HashDir(dir string) (string, error) {
-	hasher := sha256.New()
-
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	include, exclude, err := loadPkgPatterns(dir)
+	if err != nil {
+		return "", err
+	}
+	return HashPkgDir(dir, include, exclude)
+}
 
+// HashPkgDir is the same as HashDir, but the files are selected by the 'include' and 'exclude' patterns
+// instead of the ones in the kcl.mod of the package.
+func HashPkgDir(dir string, include, exclude []string) (string, error) {
+	hasher := sha256.New()
+	err := WalkPkgDir(dir, include, exclude, func(path, relPath string, info os.FileInfo) error {
 		if info.IsDir() {
 			return nil
 		}
 
-		// files in the ".git "directory will cause the same repository, cloned at different times,
-		// has different checksum.
-		for _, ignore := range ignores {
-			if strings.Contains(path, ignore) {
-				return nil
-			}
-		}
-
 		f, err := os.Open(path)
 		if err != nil {
 			return err
@@ -74,6 +74,27 @@ func HashDir(dir string) (string, error) {
 	return base64.StdEncoding.EncodeToString(hasher.Sum(nil)), nil
 }
 
+// loadPkgPatterns loads the include and exclude patterns in the kcl.mod under 'dir',
+// no patterns are returned if there is no kcl.mod.
+func loadPkgPatterns(dir string) ([]string, []string, error) {
+	var modFile struct {
+		Package struct {
+			Include []string `toml:"include"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"package"`
+	}
+
+	kclModPath := filepath.Join(dir, constants.KCL_MOD)
+	if !FileExists(kclModPath) {
+		return nil, nil, nil
+	}
+	if _, err := toml.DecodeFile(kclModPath, &modFile); err != nil {
+		return nil, nil, fmt.Errorf("failed to load the patterns in '%s': %w", kclModPath, err)
+	}
+
+	return modFile.Package.Include, modFile.Package.Exclude, nil
+}
+
 // StoreToFile will store 'data' into toml file under 'filePath'.
 func StoreToFile(filePath string, dataStr string) error {
 	err := os.WriteFile(filePath, []byte(dataStr), 0644)
@@ -119,64 +140,121 @@ func Exists(path string) (bool, error) {
 	return true, nil
 }
 
-// todo: Consider using the OCI tarball as the standard tar format.
-var ignores = []string{".git", ".tar"}
+// SOURCE_DATE_EPOCH is the env used to specify the timestamp of the files in the package tar.
+// See https://reproducible-builds.org/specs/source-date-epoch/
+const SOURCE_DATE_EPOCH = "SOURCE_DATE_EPOCH"
+
+// SourceDateEpoch returns the time in the env $SOURCE_DATE_EPOCH,
+// or the unix epoch if $SOURCE_DATE_EPOCH is not set.
+func SourceDateEpoch() (time.Time, error) {
+	epoch := os.Getenv(SOURCE_DATE_EPOCH)
+	if epoch == "" {
+		return time.Unix(0, 0).UTC(), nil
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid $%s '%s': %w", SOURCE_DATE_EPOCH, epoch, err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
 
-func TarDir(srcDir string, tarPath string, include []string, exclude []string) error {
+// TarDir will tar the package under 'srcDir' into 'tarPath'.
+// The files ignored by '.kclignore', '.gitignore' and the gitignore-style 'exclude' patterns are skipped,
+// and if 'include' is not empty, only the files matched by 'include' are packaged.
+//
+// The tar is reproducible, the same sources always produce the same tar bytes:
+// the entries are sorted by path, the timestamps are set to $SOURCE_DATE_EPOCH,
+// the ownership is zeroed and the modes are normalized.
+// todo: Consider using the OCI tarball as the standard tar format.
+func TarDir(srcDir string, tarPath string, include []string, exclude []string) (err error) {
 	fw, err := os.Create(tarPath)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer fw.Close()
+	// The error of closing the file is returned, the buffered data may fail to be written.
+	defer func() {
+		if closeErr := fw.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
 
-	tw := tar.NewWriter(fw)
-	defer tw.Close()
+	return writeTar(fw, srcDir, include, exclude)
+}
 
-	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+// TarGzDir is the same as TarDir, but the tar is compressed by gzip with fixed headers into 'tgzPath'.
+func TarGzDir(srcDir string, tgzPath string, include []string, exclude []string) (err error) {
+	fw, err := os.Create(tgzPath)
+	if err != nil {
+		return err
+	}
+	// The error of closing the file is returned, the buffered data may fail to be written.
+	defer func() {
+		if closeErr := fw.Close(); closeErr != nil && err == nil {
+			err = closeErr
 		}
+	}()
 
-		for _, ignore := range ignores {
-			if strings.Contains(path, ignore) {
-				return nil
-			}
-		}
+	// The gzip header has no name and modification time,
+	// so the same tar always produces the same gzip bytes.
+	zw, err := gzip.NewWriterLevel(fw, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
 
-		getNewPattern := func(ex string) string {
-			newPath := ex
-			if !strings.HasPrefix(ex, srcDir+string(filepath.Separator)) {
-				newPath = filepath.Join(srcDir, ex)
-			}
-			return newPath
-		}
+	err = writeTar(zw, srcDir, include, exclude)
+	if err != nil {
+		return err
+	}
 
-		for _, ex := range exclude {
-			if matched, _ := filepath.Match(getNewPattern(ex), path); matched {
-				return nil
-			}
-		}
+	return zw.Close()
+}
 
-		for _, inc := range include {
-			if matched, _ := filepath.Match(getNewPattern(inc), path); !matched {
-				return nil
-			}
-		}
+// writeTar writes the reproducible tar of the package under 'srcDir' into 'w'.
+func writeTar(w io.Writer, srcDir string, include []string, exclude []string) error {
+	modTime, err := SourceDateEpoch()
+	if err != nil {
+		return err
+	}
 
-		relPath, _ := filepath.Rel(srcDir, path)
-		relPath = filepath.ToSlash(relPath)
+	tw := tar.NewWriter(w)
 
-		hdr, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return err
+	// WalkPkgDir visits the files in lexical order, so the entries are sorted.
+	err = WalkPkgDir(srcDir, include, exclude, func(path, relPath string, info os.FileInfo) error {
+		hdr := &tar.Header{
+			Name:    relPath,
+			ModTime: modTime,
+			Format:  tar.FormatPAX,
+		}
+
+		switch {
+		case info.IsDir():
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0755
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = filepath.ToSlash(link)
+			hdr.Mode = 0777
+		case info.Mode().IsRegular():
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = info.Size()
+			hdr.Mode = 0644
+			if info.Mode()&0111 != 0 {
+				hdr.Mode = 0755
+			}
+		default:
+			// Skip the sockets, devices and named pipes.
+			return nil
 		}
-		hdr.Name = relPath
 
 		if err := tw.WriteHeader(hdr); err != nil {
 			return err
 		}
 
-		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
+		if hdr.Typeflag != tar.TypeReg {
 			return nil
 		}
 
@@ -193,7 +271,11 @@ func TarDir(srcDir string, tarPath string, include []string, exclude []string) e
 		return nil
 	})
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return tw.Close()
 }
 
 // UnTarDir will extract tar from 'tarPath' to 'destDir'.
@@ -299,6 +381,12 @@ func DirExists(path string) bool {
 	return err == nil
 }
 
+// FileExists will check whether the file 'path' exists and is not a directory.
+func FileExists(path string) bool {
+	fileInfo, err := os.Stat(path)
+	return err == nil && !fileInfo.IsDir()
+}
+
 const ModRelativePathPattern = `\$\{([a-zA-Z0-9_-]+:)?KCL_MOD\}/`
 
 // If the path preffix is `${KCL_MOD}` or `${KCL_MOD:xxx}`
@@ -520,6 +608,21 @@ func readLine(prompt string, silent bool) (string, error) {
 	return string(line), nil
 }
 
+// IsStdinTerminal returns true if the stdin is a terminal that the user can answer the prompts from.
+func IsStdinTerminal() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
+
+// Confirm asks the user to confirm with the 'prompt', only 'y' and 'yes' are treated as confirmed.
+func Confirm(prompt string) (bool, error) {
+	answer, err := readLine(prompt+" [y/N]: ", false)
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
 // FindKFiles will find all the '.k' files in the 'path' directory.
 func FindKFiles(path string) ([]string, error) {
 	var files []string
@@ -8,7 +8,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/otiai10/copy"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -134,17 +136,84 @@ func TestTarDir(t *testing.T) {
 	assert.Equal(t, err, nil)
 	os.Remove(tarPath)
 
+	// The files matched by any of the include patterns are packaged.
 	_ = TarDir(testSrcDir, tarPath, []string{"*/*.lock", "*.mod"}, []string{})
 	fileNames, _ = getTarFileNames(tarPath)
+	assert.NotEqual(t, len(fileNames), 0)
 	for _, fileName := range fileNames {
-		flag, _ := filepath.Match(getNewPattern("*/*.lock"), fileName)
-		assert.Equal(t, flag, true)
+		lockFlag, _ := filepath.Match(getNewPattern("*/*.lock"), fileName)
+		modFlag, _ := filepath.Match(getNewPattern("*.mod"), fileName)
+		assert.Equal(t, lockFlag || modFlag, true)
 	}
 	_, err = os.Stat(tarPath)
 	assert.Equal(t, err, nil)
 	os.Remove(tarPath)
 }
 
+func TestTarDirReproducible(t *testing.T) {
+	// The test data is copied, because the modification time of the files is changed.
+	testSrcDir := filepath.Join(t.TempDir(), "test_src")
+	assert.Equal(t, copy.Copy(filepath.Join(getTestDir("test_tar"), "test_src"), testSrcDir), nil)
+	tmpDir := t.TempDir()
+	t.Setenv(SOURCE_DATE_EPOCH, "1700000000")
+
+	for _, tarDir := range []func(string, string, []string, []string) error{TarDir, TarGzDir} {
+		firstPath := filepath.Join(tmpDir, "first")
+		secondPath := filepath.Join(tmpDir, "second")
+
+		assert.Equal(t, tarDir(testSrcDir, firstPath, nil, nil), nil)
+		// Touch a file, the modification time should not change the tar.
+		assert.Equal(t, os.Chtimes(filepath.Join(testSrcDir, "test.txt"), time.Now(), time.Now()), nil)
+		assert.Equal(t, tarDir(testSrcDir, secondPath, nil, nil), nil)
+
+		first, err := os.ReadFile(firstPath)
+		assert.Equal(t, err, nil)
+		second, err := os.ReadFile(secondPath)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, first, second)
+	}
+
+	tarPath := filepath.Join(tmpDir, "test.tar")
+	assert.Equal(t, TarDir(testSrcDir, tarPath, nil, nil), nil)
+	file, err := os.Open(tarPath)
+	assert.Equal(t, err, nil)
+	defer file.Close()
+
+	var names []string
+	reader := tar.NewReader(file)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, err, nil)
+		names = append(names, header.Name)
+		assert.Equal(t, header.ModTime.Unix(), int64(1700000000))
+		assert.Equal(t, header.Uid, 0)
+		assert.Equal(t, header.Gid, 0)
+		assert.Equal(t, header.Uname, "")
+		if header.Typeflag == tar.TypeDir {
+			assert.Equal(t, header.Mode, int64(0755))
+		} else {
+			assert.Equal(t, header.Mode, int64(0644))
+		}
+	}
+	assert.Equal(t, names, []string{
+		".",
+		"test.mod",
+		"test.txt",
+		"test_src.txt",
+		"test_sub",
+		"test_sub/test_sub.txt",
+		"test_tar_dir",
+		"test_tar_dir/test_1.lock",
+		"test_tar_dir/test_1.txt",
+	})
+
+	t.Setenv(SOURCE_DATE_EPOCH, "invalid")
+	assert.NotEqual(t, TarDir(testSrcDir, tarPath, nil, nil), nil)
+}
+
 func TestUnTarDir(t *testing.T) {
 	testDir := getTestDir("test_un_tar")
 	tarPath := filepath.Join(testDir, "test.tar")
@@ -238,6 +307,16 @@ func TestIsKfile(t *testing.T) {
 	assert.Equal(t, IsKfile("xxx.k"), true)
 }
 
+func TestFileExists(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "kcl.mod")
+	assert.False(t, FileExists(filePath))
+	assert.NoError(t, os.WriteFile(filePath, []byte(""), 0644))
+	assert.True(t, FileExists(filePath))
+	assert.False(t, FileExists(dir))
+	assert.True(t, DirExists(dir))
+}
+
 func TestAbsTarPath(t *testing.T) {
 	pkgPath := getTestDir("test_check_tar_path")
 	expectAbsTarPath, _ := filepath.Abs(filepath.Join(pkgPath, "test.tar"))
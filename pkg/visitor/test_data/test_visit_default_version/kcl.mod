@@ -0,0 +1,4 @@
+[package]
+name = "helloworld"
+edition = "v0.9.0"
+version = "0.1.4"
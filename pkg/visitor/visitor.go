@@ -1,6 +1,7 @@
 package visitor
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -20,8 +21,9 @@ import (
 type visitFunc func(pkg *pkg.KclPkg) error
 
 // Visitor is the interface for visiting a package which is a local path, a remote git/oci path, or a local tar path.
+// The visiting should be stopped when the context 'ctx' is canceled.
 type Visitor interface {
-	Visit(s *downloader.Source, v visitFunc) error
+	Visit(ctx context.Context, s *downloader.Source, v visitFunc) error
 }
 
 // PkgVisitor is the visitor for visiting a local package.
@@ -31,7 +33,10 @@ type PkgVisitor struct {
 }
 
 // Visit visits a local package.
-func (pv *PkgVisitor) Visit(s *downloader.Source, v visitFunc) error {
+func (pv *PkgVisitor) Visit(ctx context.Context, s *downloader.Source, v visitFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if !s.IsLocalPath() {
 		return fmt.Errorf("source is not local")
 	}
@@ -75,7 +80,10 @@ func NewVirtualPkgVisitor(pv *PkgVisitor) *VirtualPkgVisitor {
 // Visit visits a package which do not have a kcl.mod file in the root path.
 // It will create a virtual kcl.mod file in the root path.
 // And then kcl.mod file will be cleaned after the visitFunc is executed.
-func (vpv *VirtualPkgVisitor) Visit(s *downloader.Source, v visitFunc) error {
+func (vpv *VirtualPkgVisitor) Visit(ctx context.Context, s *downloader.Source, v visitFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if !s.IsLocalPath() {
 		return fmt.Errorf("source is not local")
 	}
@@ -104,6 +112,8 @@ type RemoteVisitor struct {
 	Downloader            downloader.Downloader
 	InsecureSkipTLSverify bool
 	Offline               bool
+	// Registries are the named registries in kcl.mod to resolve the sources with only the package spec.
+	Registries []settings.OciRegistry
 }
 
 // NewRemoteVisitor creates a new RemoteVisitor.
@@ -116,20 +126,18 @@ func NewRemoteVisitor(pv *PkgVisitor) *RemoteVisitor {
 // Visit visits a remote package.
 // It will download the remote package to a temporary directory.
 // And the tmp directory will be cleaned after the visitFunc is executed.
-func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
+// The download is aborted when the context is canceled, and the downloaded files are cleaned.
+func (rv *RemoteVisitor) Visit(ctx context.Context, s *downloader.Source, v visitFunc) error {
 	var err error
+	if err = ctx.Err(); err != nil {
+		return err
+	}
 	if !s.IsRemote() {
 		return fmt.Errorf("source is not remote")
 	}
 
-	// For some sources with only the spec, the default registry and repo will be used.
-	if s.SpecOnly() {
-		s.Oci = &downloader.Oci{
-			Reg:  rv.Settings.DefaultOciRegistry(),
-			Repo: utils.JoinPath(rv.Settings.DefaultOciRepo(), s.ModSpec.Name),
-			Tag:  s.ModSpec.Version,
-		}
-	}
+	// For some sources with only the spec, the registry and repo resolved by the named registries in kcl.mod and the settings will be used.
+	s.ResolveSpecOnly(rv.Settings, rv.Registries...)
 
 	var cacheFullPath string
 	var modFullPath string
@@ -156,8 +164,11 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		defer os.RemoveAll(cacheFullPath)
 	}
 
+	// newModPath is true if the local path of the package is created for the download.
+	var newModPath bool
+
 	// 1. Load the credential file.
-	credCli, err := downloader.LoadCredentialFile(rv.Settings.CredentialsFile)
+	credCli, err := downloader.LoadCredentialFileWithSettings(rv.Settings)
 	if err != nil {
 		return err
 	}
@@ -165,8 +176,9 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 	// 2. If the version is not specified, get the latest version.
 	// For Oci, the latest tag
 	// For Git, the main branch
-	if (s.Oci != nil && s.Oci.NoRef()) || (s.Git != nil && s.Git.NoRef()) {
-		latest, err := rv.Downloader.LatestVersion(downloader.NewDownloadOptions(
+	// For the custom scheme, the latest version from its registered downloader
+	if (s.Oci != nil && s.Oci.NoRef()) || (s.Git != nil && s.Git.NoRef()) || (s.Custom != nil && s.Custom.NoRef()) {
+		latest, err := rv.Downloader.LatestVersion(ctx, downloader.NewDownloadOptions(
 			downloader.WithSource(*s),
 			downloader.WithLogWriter(rv.LogWriter),
 			downloader.WithSettings(*rv.Settings),
@@ -191,6 +203,9 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		if s.Git != nil {
 			s.Git.Commit = latest
 		}
+		if s.Custom != nil {
+			s.Custom.Tag = latest
+		}
 	}
 
 	// Generate the local path for the remote package after the version is specified.
@@ -212,6 +227,7 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 				if err != nil {
 					return err
 				}
+				newModPath = true
 			}
 		}
 		// update the cache path with the latest version.
@@ -236,12 +252,12 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		defer os.RemoveAll(tmpDir)
 	}
 
-	credCli, err = downloader.LoadCredentialFile(rv.Settings.CredentialsFile)
+	credCli, err = downloader.LoadCredentialFileWithSettings(rv.Settings)
 	if err != nil {
 		return err
 	}
 
-	err = rv.Downloader.Download(downloader.NewDownloadOptions(
+	err = rv.Downloader.Download(ctx, downloader.NewDownloadOptions(
 		downloader.WithLocalPath(modFullPath),
 		downloader.WithSource(*s),
 		downloader.WithLogWriter(rv.LogWriter),
@@ -257,6 +273,10 @@ func (rv *RemoteVisitor) Visit(s *downloader.Source, v visitFunc) error {
 		if errors.Is(err, downloader.ErrNotFoundAndOffline) && rv.Offline {
 			return nil
 		}
+		// The package downloaded partially is removed if the download is canceled.
+		if newModPath && ctx.Err() != nil {
+			_ = os.RemoveAll(modFullPath)
+		}
 		return err
 	}
 	if !s.ModSpec.IsNil() {
@@ -330,8 +350,10 @@ func (av *ArchiveVisitor) extractArchiveFromSource(s *downloader.Source, extract
 // Visit visits a package which is a local tar/tgz path.
 // It will extract the archive file to a temporary directory.
 // And the tmp directory will be cleaned after the visitFunc is executed.
-func (av *ArchiveVisitor) Visit(s *downloader.Source, v visitFunc) error {
-
+func (av *ArchiveVisitor) Visit(ctx context.Context, s *downloader.Source, v visitFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if !s.IsLocalTarPath() && !s.IsLocalTgzPath() {
 		return fmt.Errorf("source is not local tar path")
 	}
@@ -2,12 +2,16 @@ package visitor
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"gotest.tools/v3/assert"
 	"kcl-lang.io/kpm/pkg/downloader"
+	"kcl-lang.io/kpm/pkg/mock"
 	pkg "kcl-lang.io/kpm/pkg/package"
 	"kcl-lang.io/kpm/pkg/settings"
 )
@@ -30,7 +34,7 @@ func TestVisitPkgDir(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = pVisitor.Visit(source, func(pkg *pkg.KclPkg) error {
+	err = pVisitor.Visit(context.Background(), source, func(pkg *pkg.KclPkg) error {
 		assert.Equal(t, pkg.GetPkgName(), "test_visit_dir")
 		assert.Equal(t, pkg.GetPkgVersion(), "0.0.1")
 		return nil
@@ -46,7 +50,7 @@ func TestVisitPkgTar(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = pVisitor.Visit(source, func(pkg *pkg.KclPkg) error {
+	err = pVisitor.Visit(context.Background(), source, func(pkg *pkg.KclPkg) error {
 		assert.Equal(t, pkg.GetPkgName(), "test_visit_tar")
 		assert.Equal(t, pkg.GetPkgVersion(), "0.0.1")
 		return nil
@@ -54,44 +58,64 @@ func TestVisitPkgTar(t *testing.T) {
 	assert.NilError(t, err)
 }
 
+// newTestSettings returns the settings accessing the registry 'reg' with the package 'helloworld' of the versions 'versions'
+// pushed to the repository 'test/helloworld'.
+func newTestSettings(t *testing.T, reg *mock.OciRegistry, versions ...string) *settings.Settings {
+	for _, version := range versions {
+		_, err := reg.PushPkg(filepath.Join("..", "mock", "test_pkg", "helloworld_"+version), "test/helloworld")
+		assert.NilError(t, err)
+	}
+	regSettings := *settings.GetSettings()
+	reg.Configure(&regSettings)
+	return &regSettings
+}
+
 func TestVisitPkgRemote(t *testing.T) {
+	reg, err := mock.NewOciRegistry()
+	assert.NilError(t, err)
+	defer reg.Close()
+	server, err := mock.NewGitServer()
+	assert.NilError(t, err)
+	defer server.Close()
+	_, err = server.Seed("helloworld", filepath.Join("..", "mock", "test_pkg", "helloworld_0.2.0"))
+	assert.NilError(t, err)
+
 	var buf bytes.Buffer
 	remotePkgVisitor := RemoteVisitor{
 		PkgVisitor: &PkgVisitor{
 			LogWriter: &buf,
-			Settings:  settings.GetSettings(),
+			Settings:  newTestSettings(t, reg, "0.1.0"),
 		},
 		Downloader: &downloader.DepDownloader{},
 	}
 
 	tests := []struct {
-		sourceStr       string
+		source          *downloader.Source
 		expectedPkgName string
 		expectedPkgVer  string
 		expectedLog     string
 	}{
 		{
-			sourceStr:       "oci://ghcr.io/kcl-lang/helloworld?tag=0.1.2",
+			source: &downloader.Source{
+				Oci: &downloader.Oci{Reg: reg.Host, Repo: "test/helloworld", Tag: "0.1.0"},
+			},
 			expectedPkgName: "helloworld",
-			expectedPkgVer:  "0.1.2",
-			expectedLog:     "downloading 'kcl-lang/helloworld:0.1.2' from 'ghcr.io/kcl-lang/helloworld:0.1.2'\n",
+			expectedPkgVer:  "0.1.0",
+			expectedLog:     fmt.Sprintf("downloading 'test/helloworld:0.1.0' from '%s/test/helloworld:0.1.0'\n", reg.Host),
 		},
 		{
-			sourceStr:       "git://github.com/kcl-lang/flask-demo-kcl-manifests.git?branch=main",
-			expectedPkgName: "flask_manifests",
-			expectedPkgVer:  "0.0.1",
-			expectedLog:     "cloning 'https://github.com/kcl-lang/flask-demo-kcl-manifests.git' with branch 'main'\n",
+			source: &downloader.Source{
+				Git: &downloader.Git{Url: server.RepoUrl("helloworld"), Branch: "main"},
+			},
+			expectedPkgName: "helloworld",
+			expectedPkgVer:  "0.2.0",
+			expectedLog:     fmt.Sprintf("cloning '%s' with branch 'main'\n", server.RepoUrl("helloworld")),
 		},
 	}
 
 	for _, tt := range tests {
 		buf.Reset()
-		source, err := downloader.NewSourceFromStr(tt.sourceStr)
-		if err != nil {
-			t.Fatal(err)
-		}
-
-		err = remotePkgVisitor.Visit(source, func(pkg *pkg.KclPkg) error {
+		err = remotePkgVisitor.Visit(context.Background(), tt.source, func(pkg *pkg.KclPkg) error {
 			assert.Equal(t, pkg.GetPkgName(), tt.expectedPkgName)
 			assert.Equal(t, pkg.GetPkgVersion(), tt.expectedPkgVer)
 			return nil
@@ -117,7 +141,7 @@ func TestVisitedSpace(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = remotePkgVisitor.Visit(source, func(pkg *pkg.KclPkg) error {
+	err = remotePkgVisitor.Visit(context.Background(), source, func(pkg *pkg.KclPkg) error {
 		assert.Equal(t, pkg.GetPkgName(), "helloworld")
 		assert.Equal(t, pkg.GetPkgVersion(), "0.1.2")
 		assert.Equal(t, pkg.HomePath, source.LocalPath((remotePkgVisitor.VisitedSpace)))
@@ -127,17 +151,24 @@ func TestVisitedSpace(t *testing.T) {
 }
 
 func TestVisitedPkgWithDefaultVersion(t *testing.T) {
+	reg, err := mock.NewOciRegistry()
+	assert.NilError(t, err)
+	defer reg.Close()
+	regSettings := newTestSettings(t, reg, "0.1.0")
+	_, err = reg.PushPkg(getTestDir("test_visit_default_version"), "test/helloworld")
+	assert.NilError(t, err)
+
 	var buf bytes.Buffer
 	remotePkgVisitor := RemoteVisitor{
 		PkgVisitor: &PkgVisitor{
 			LogWriter: &buf,
-			Settings:  settings.GetSettings(),
+			Settings:  regSettings,
 		},
 		Downloader: &downloader.DepDownloader{},
 	}
 
 	buf.Reset()
-	source, err := downloader.NewSourceFromStr("oci://ghcr.io/kcl-lang/helloworld")
+	source, err := downloader.NewSourceFromStr("oci://" + reg.Host + "/test/helloworld")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -146,8 +177,33 @@ func TestVisitedPkgWithDefaultVersion(t *testing.T) {
 		Name: "subhelloworld",
 	}
 
-	err = remotePkgVisitor.Visit(source, func(pkg *pkg.KclPkg) error { return nil })
+	err = remotePkgVisitor.Visit(context.Background(), source, func(pkg *pkg.KclPkg) error { return nil })
 	assert.Equal(t, source.ModSpec.Version, "0.0.1")
 	assert.Equal(t, source.Oci.Tag, "0.1.4")
 	assert.NilError(t, err)
 }
+
+func TestVisitWithContext(t *testing.T) {
+	source, err := downloader.NewSourceFromStr(getTestDir("test_visit_dir"))
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The package is not visited if the context is canceled.
+	visited := false
+	err = (&PkgVisitor{}).Visit(ctx, source, func(kclPkg *pkg.KclPkg) error {
+		visited = true
+		return nil
+	})
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	assert.Assert(t, !visited)
+
+	var visitedPkg *pkg.KclPkg
+	err = (&PkgVisitor{}).Visit(context.Background(), source, func(kclPkg *pkg.KclPkg) error {
+		visitedPkg = kclPkg
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, visitedPkg.GetPkgName(), "test_visit_dir")
+}
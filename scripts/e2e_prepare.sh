@@ -14,5 +14,5 @@ fi
 # pull the package 'k8s' from 'ghcr.io/kcl-lang/k8s'
 ./scripts/pull_pkg.sh
 
-# push the package 'k8s' to 'localhost:5001/test'
-./scripts/push_pkg.sh
+# the packages are pushed to the test registry 'localhost:5001/test' started by the e2e suite,
+# see 'test/e2e/e2e_suite_test.go'.
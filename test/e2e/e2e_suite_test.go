@@ -7,13 +7,28 @@ import (
 
 	"github.com/onsi/ginkgo/v2"
 	"github.com/onsi/gomega"
+	"kcl-lang.io/kpm/pkg/mock"
 )
 
+// registry is the test registry at 'localhost:5001' with the account 'test' and the password '1234'.
+var registry *mock.OciRegistry
+
 func TestE2e(t *testing.T) {
 	gomega.RegisterFailHandler(ginkgo.Fail)
 	ginkgo.RunSpecs(t, "E2e Suite")
 }
 
+var _ = ginkgo.BeforeSuite(func() {
+	ginkgo.By("start the test registry and push the packages", func() {
+		var err error
+		registry, err = mock.NewOciRegistry(mock.WithOciAuth("test", "1234"), mock.WithOciAddr("127.0.0.1:5001"))
+		gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+
+		_, err = ExecWithWorkDir("bash ./scripts/push_pkg.sh", filepath.Join(GetWorkDir(), "../.."))
+		gomega.Expect(err).ShouldNot(gomega.HaveOccurred())
+	})
+})
+
 var _ = ginkgo.BeforeEach(func() {
 	ginkgo.By("create kpm test workspace", func() {
 		_ = CreateTestWorkspace()
@@ -27,6 +42,9 @@ var _ = ginkgo.AfterEach(func() {
 })
 
 var _ = ginkgo.AfterSuite(func() {
+	ginkgo.By("stop the test registry", func() {
+		registry.Close()
+	})
 	ginkgo.By("clean up kpm bin", func() {
 		path := filepath.Join(GetWorkDir(), "../..", "bin")
 		cli := fmt.Sprintf("rm -rf %s", path)